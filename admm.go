@@ -0,0 +1,220 @@
+package linear
+
+import (
+	"math"
+	"sort"
+)
+
+// ProximalOperator returns the point that minimizes h(x) +
+// (rho/2)*|x - v|^2 for some function h, which is a step towards
+// minimizing h that doesn't stray far from v.
+type ProximalOperator func(v Matrix, rho float64) Matrix
+
+// ADMM is the alternating direction method of multipliers for
+// minimizing f(x) + g(x), where f and g are only known through their
+// proximal operators. It alternates between the two, splitting x into
+// a copy for each and driving the copies together with a running sum
+// of their difference.
+type ADMM struct {
+	ProxF, ProxG ProximalOperator
+	// Rho weighs agreement between the copies against progress on f
+	// and g.
+	Rho float64
+	// MaxIter is how many iterations to run before giving up.
+	MaxIter int
+	// AbsTol and RelTol decide when the copies agree closely enough,
+	// and are changing slowly enough, to stop.
+	AbsTol, RelTol float64
+}
+
+// Solve runs ADMM starting from x0, returning the solution and how
+// many iterations it took.
+func (a *ADMM) Solve(x0 Matrix) (x Matrix, iterations int) {
+	ins, outs := x0.Shape()
+	z := Copy(x0)
+	u := NewArrayMatrix(ins, outs)
+	sqrtN := math.Sqrt(float64(ins * outs))
+	rho := a.Rho
+	for iterations = 1; iterations <= a.MaxIter; iterations++ {
+		// The proximal operators may return their argument, so each
+		// gets a fresh one.
+		v := NewArrayMatrix(ins, outs)
+		addScaledInto(z, u, -1, v)
+		x = a.ProxF(v, rho)
+
+		zPrev := z
+		v = NewArrayMatrix(ins, outs)
+		addScaledInto(x, u, 1, v)
+		z = a.ProxG(v, rho)
+
+		// u accumulates the disagreement between the copies.
+		addScaledInto(u, x, 1, u)
+		addScaledInto(u, z, -1, u)
+
+		primal := frobeniusDistance(x, z)
+		dual := rho * frobeniusDistance(z, zPrev)
+		primalTol := sqrtN*a.AbsTol + a.RelTol*math.Max(frobeniusNorm(x), frobeniusNorm(z))
+		dualTol := sqrtN*a.AbsTol + a.RelTol*rho*frobeniusNorm(u)
+		if primal <= primalTol && dual <= dualTol {
+			break
+		}
+	}
+	if iterations > a.MaxIter {
+		iterations = a.MaxIter
+	}
+	return z, iterations
+}
+
+// SoftThreshold is the proximal operator of lambda times the sum of the
+// absolute values of the entries, which shrinks each entry towards zero
+// by lambda/rho and zeroes it if it's closer than that.
+func SoftThreshold(lambda float64) ProximalOperator {
+	return func(v Matrix, rho float64) Matrix {
+		return mapEntries(v, func(f float64) float64 {
+			return softThreshold(f, lambda/rho)
+		})
+	}
+}
+
+func softThreshold(f, t float64) float64 {
+	if f > t {
+		return f - t
+	}
+	if f < -t {
+		return f + t
+	}
+	return 0
+}
+
+// ProjectBox is the proximal operator of the constraint that each entry
+// is between lo and hi, which clamps them.
+func ProjectBox(lo, hi float64) ProximalOperator {
+	return func(v Matrix, rho float64) Matrix {
+		return mapEntries(v, func(f float64) float64 {
+			return math.Min(hi, math.Max(lo, f))
+		})
+	}
+}
+
+// ProjectSimplex is the proximal operator of the constraint that each
+// column (input) is non-negative and sums to 1, a probability
+// distribution.
+func ProjectSimplex() ProximalOperator {
+	return func(v Matrix, rho float64) Matrix {
+		ins, outs := v.Shape()
+		p := NewArrayMatrix(ins, outs)
+		sorted := make([]float64, outs)
+		for i := 0; i < ins; i++ {
+			for o := 0; o < outs; o++ {
+				sorted[o] = v.Get(i, o)
+			}
+			// Find the shift that makes the positive parts sum to 1,
+			// by trying the entries from largest to smallest.
+			sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+			sum, shift := 0.0, 0.0
+			for k, f := range sorted {
+				sum += f
+				t := (sum - 1) / float64(k+1)
+				if f-t > 0 {
+					shift = t
+				}
+			}
+			for o := 0; o < outs; o++ {
+				p.Set(i, o, math.Max(0, v.Get(i, o)-shift))
+			}
+		}
+		return p
+	}
+}
+
+// LeastSquaresProx is the proximal operator of |A*x - b|^2 / 2, which
+// solves (Dual(A)*A + rho*I)*x = Dual(A)*b + rho*v. The factorization
+// is reused for as long as rho stays the same.
+func LeastSquaresProx(A, b Matrix) ProximalOperator {
+	At := Dual(A)
+	AtA := Compose(A, At)
+	Atb := Apply(At, b)
+	var L Matrix
+	lastRho := math.NaN()
+	return func(v Matrix, rho float64) Matrix {
+		if rho != lastRho {
+			M := Copy(AtA)
+			ins, _ := M.Shape()
+			for d := 0; d < ins; d++ {
+				M.Set(d, d, M.Get(d, d)+rho)
+			}
+			L = DecomposeCholesky(M)
+			lastRho = rho
+		}
+		rhs := NewArrayMatrix(v.Shape())
+		addScaledInto(Atb, v, rho, rhs)
+		return SolveCholesky(L, rhs)
+	}
+}
+
+// Lasso finds the theta that minimizes |X*theta - y|^2 / 2 +
+// lambda*|theta|_1 by ADMM, which gives sparse coefficients.
+func Lasso(X, y Matrix, lambda float64) Matrix {
+	CheckVector(y)
+	ins, _ := X.Shape()
+	admm := &ADMM{
+		ProxF:   LeastSquaresProx(X, y),
+		ProxG:   SoftThreshold(lambda),
+		Rho:     1,
+		MaxIter: 1000,
+		AbsTol:  1e-10,
+		RelTol:  1e-8,
+	}
+	theta, _ := admm.Solve(NewVector(ins))
+	return theta
+}
+
+// addScaledInto writes A + s*B into dst, which may be A or B.
+func addScaledInto(A, B Matrix, s float64, dst Matrix) {
+	CheckSameShape(A, B)
+	CheckSameShape(A, dst)
+	ins, outs := A.Shape()
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			dst.Set(i, o, A.Get(i, o)+s*B.Get(i, o))
+		}
+	}
+}
+
+// mapEntries returns a new Matrix with f applied to each entry.
+func mapEntries(A Matrix, f func(float64) float64) Matrix {
+	ins, outs := A.Shape()
+	B := NewArrayMatrix(ins, outs)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			B.Set(i, o, f(A.Get(i, o)))
+		}
+	}
+	return B
+}
+
+// frobeniusNorm is the L2 norm of all of the entries.
+func frobeniusNorm(A Matrix) float64 {
+	ins, outs := A.Shape()
+	sum := 0.0
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			sum += A.Get(i, o) * A.Get(i, o)
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// frobeniusDistance is the L2 norm of all of the entries of A - B.
+func frobeniusDistance(A, B Matrix) float64 {
+	CheckSameShape(A, B)
+	ins, outs := A.Shape()
+	sum := 0.0
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			d := A.Get(i, o) - B.Get(i, o)
+			sum += d * d
+		}
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,73 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSoftThreshold(t *testing.T) {
+	v := NewVector(3)
+	v.Set(0, 0, 3)
+	v.Set(0, 1, -0.5)
+	v.Set(0, 2, -2)
+
+	x := SoftThreshold(2)(v, 2)
+
+	ExpectFloat(2, x.Get(0, 0), t)
+	ExpectFloat(0, x.Get(0, 1), t)
+	ExpectFloat(-1, x.Get(0, 2), t)
+}
+
+func TestProjectSimplex(t *testing.T) {
+	v := NewVector(3)
+	v.Set(0, 0, 0.5)
+	v.Set(0, 1, 1)
+	v.Set(0, 2, -1)
+
+	x := ProjectSimplex()(v, 1)
+
+	ExpectFloat(0.25, x.Get(0, 0), t)
+	ExpectFloat(0.75, x.Get(0, 1), t)
+	ExpectFloat(0, x.Get(0, 2), t)
+}
+
+func TestADMMBoxConstrainedLeastSquares(t *testing.T) {
+	A := Identity(2)
+	b := NewVector(2)
+	b.Set(0, 0, 2)
+	b.Set(0, 1, -0.5)
+
+	admm := &ADMM{
+		ProxF:   LeastSquaresProx(A, b),
+		ProxG:   ProjectBox(0, 1),
+		Rho:     1,
+		MaxIter: 500,
+		AbsTol:  1e-12,
+		RelTol:  1e-10,
+	}
+	x, iterations := admm.Solve(NewVector(2))
+
+	if iterations >= 500 {
+		t.Errorf("expected to converge")
+	}
+	ExpectFloat(1, x.Get(0, 0), t)
+	ExpectFloat(0, x.Get(0, 1), t)
+}
+
+func TestLasso(t *testing.T) {
+	// With orthonormal features the lasso is a soft threshold on the
+	// least squares coefficients.
+	X := Identity(3)
+	y := NewVector(3)
+	y.Set(0, 0, 3)
+	y.Set(0, 1, 0.5)
+	y.Set(0, 2, -2)
+
+	theta := Lasso(X, y, 1)
+
+	for d, expect := range []float64{2, 0, -1} {
+		if math.Abs(theta.Get(0, d)-expect) > 1e-6 {
+			t.Errorf("expected %f but got %f", expect, theta.Get(0, d))
+		}
+	}
+}
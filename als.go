@@ -0,0 +1,67 @@
+package linear
+
+import (
+	"math/rand"
+)
+
+// ALS factors implicit feedback R, with a row (output) for each user
+// and a column (input) for each item, into k features per user (the
+// rows of U) and per item (the rows of V) so that U*Dual(V) predicts
+// preference. Every entry of R is taken as a 0 or 1 preference for
+// whether it's non-zero, weighted by a confidence of 1 plus the entry,
+// so entries should be scaled counts like alpha*clicks. Lambda is the
+// ridge penalty on the features. It alternates between solving for
+// every user's features with the items fixed and vice versa, each a
+// small ridge regression that only has to look at the non-zero entries.
+func ALS(R *CSR, k int, lambda float64) (U, V Matrix) {
+	items, users := R.Shape()
+	Rt := transposeCSR(R)
+
+	rng := rand.New(rand.NewSource(1))
+	U = NewArrayMatrix(k, users)
+	V = NewArrayMatrix(k, items)
+	for o := 0; o < items; o++ {
+		for i := 0; i < k; i++ {
+			V.Set(i, o, 0.1*rng.NormFloat64())
+		}
+	}
+
+	for sweep := 0; sweep < 20; sweep++ {
+		alsSolve(R, V, U, lambda)
+		alsSolve(Rt, U, V, lambda)
+	}
+	return U, V
+}
+
+// alsSolve updates the row of X for every row of R given Y, the other
+// side of the factorization.
+func alsSolve(R *CSR, Y, X Matrix, lambda float64) {
+	k, _ := Y.Shape()
+	_, rows := R.Shape()
+
+	// Dual(Y)*Y is shared by every row since zero entries all have
+	// confidence 1. Only the non-zero entries need correcting.
+	YtY := Compose(Y, Dual(Y))
+	for o := 0; o < rows; o++ {
+		A := Copy(YtY)
+		b := NewVector(k)
+		for j := R.rowStart[o]; j < R.rowStart[o+1]; j++ {
+			y := R.cols[j]
+			c := 1 + R.values[j]
+			for p := 0; p < k; p++ {
+				yp := Y.Get(p, y)
+				b.Set(0, p, b.Get(0, p)+c*yp)
+				for q := 0; q < k; q++ {
+					A.Set(q, p, A.Get(q, p)+(c-1)*yp*Y.Get(q, y))
+				}
+			}
+		}
+		for d := 0; d < k; d++ {
+			A.Set(d, d, A.Get(d, d)+lambda)
+		}
+		x := SolveCholesky(DecomposeCholesky(A), b)
+		for p := 0; p < k; p++ {
+			X.Set(p, o, x.Get(0, p))
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestALS(t *testing.T) {
+	// Users 0-2 use items 0-2 and users 3-5 use items 3-5, except user
+	// 2 hasn't got to item 2 yet.
+	var entries []SparseEntry
+	for u := 0; u < 6; u++ {
+		for i := 0; i < 6; i++ {
+			if u/3 == i/3 && !(u == 2 && i == 2) {
+				entries = append(entries, SparseEntry{i, u, 10})
+			}
+		}
+	}
+	R := NewCSRFromEntries(6, 6, entries)
+
+	U, V := ALS(R, 2, 0.1)
+
+	P := Apply(U, Dual(V))
+	for u := 0; u < 6; u++ {
+		for i := 0; i < 6; i++ {
+			p := P.Get(i, u)
+			if R.Get(i, u) != 0 && p < 0.8 {
+				t.Errorf("expected (%d, %d) near 1 but got %f", i, u, p)
+			}
+			if u/3 != i/3 && p > 0.2 {
+				t.Errorf("expected (%d, %d) near 0 but got %f", i, u, p)
+			}
+		}
+	}
+	if P.Get(2, 2) < 0.5 {
+		t.Errorf("expected user 2 to be recommended item 2 but got %f", P.Get(2, 2))
+	}
+}
@@ -0,0 +1,99 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// Apache Arrow stores a Float64 array as one contiguous buffer of
+// little endian float64s (plus a validity bitmap for nulls), which is
+// exactly a []float64 on every platform Go supports. A record batch of
+// Float64 columns is then a column per feature, so these functions
+// convert between matrices and such column buffers, like the ones
+// from array.Float64.Float64Values() in the Arrow Go library, without
+// copying whenever the memory layout allows it. Nulls aren't
+// represented, so columns with nulls need to be filled first.
+
+type columnsMatrix struct {
+	columns [][]float64
+	outs    int
+}
+
+// ColumnsMatrix returns a Matrix with an input (column) per buffer,
+// which read and write the buffers in place. When the buffers are
+// consecutive pieces of one allocation, as Arrow's builders often
+// produce, the result is a column-major array matrix that the fast
+// kernels can use directly.
+func ColumnsMatrix(columns [][]float64) Matrix {
+	if len(columns) == 0 {
+		return NewArrayMatrix(0, 0)
+	}
+	outs := len(columns[0])
+	for k, c := range columns {
+		if len(c) != outs {
+			panic(fmt.Errorf("column %d has %d rows but expected %d", k, len(c), outs))
+		}
+	}
+	if a, ok := contiguousColumns(columns, outs); ok {
+		return a
+	}
+	return &columnsMatrix{columns, outs}
+}
+
+// contiguousColumns returns the column-major array matrix that the
+// columns are views of, if there is one.
+func contiguousColumns(columns [][]float64, outs int) (*arrayMatrix, bool) {
+	ins := len(columns)
+	if outs == 0 || cap(columns[0]) < ins*outs {
+		return nil, false
+	}
+	all := columns[0][:ins*outs]
+	for k, c := range columns {
+		if &all[k*outs] != &c[0] {
+			return nil, false
+		}
+	}
+	return &arrayMatrix{array: all, ins: ins, outs: outs, inStride: outs, outStride: 1}, true
+}
+
+func (m *columnsMatrix) Shape() (ins, outs int) { return len(m.columns), m.outs }
+func (m *columnsMatrix) Get(in, out int) float64 {
+	m.checkBounds(in, out)
+	return m.columns[in][out]
+}
+func (m *columnsMatrix) Set(in, out int, value float64) {
+	m.checkBounds(in, out)
+	m.columns[in][out] = value
+}
+func (m *columnsMatrix) checkBounds(in, out int) {
+	if in < 0 || in >= len(m.columns) || out < 0 || out >= m.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds (%d, %d)", in, out, len(m.columns), m.outs))
+	}
+}
+
+// MatrixColumns returns a buffer per input (column) of A, suitable for
+// building Arrow Float64 arrays with array.NewFloat64Data or
+// memory.NewBufferBytes. The buffers share A's memory when A stores
+// each column contiguously (like a matrix from NewArrayMatrixColMajor
+// or ColumnsMatrix) and are copies otherwise, or if A is read-only so
+// that writing to them would fault.
+func MatrixColumns(A Matrix) [][]float64 {
+	ins, outs := A.Shape()
+	columns := make([][]float64, ins)
+	if c, ok := A.(*columnsMatrix); ok {
+		copy(columns, c.columns)
+		return columns
+	}
+	if a, ok := asWritableArrayMatrix(A); ok && a.outStride == 1 {
+		for i := range columns {
+			columns[i] = a.array[i*a.inStride : i*a.inStride+outs : i*a.inStride+outs]
+		}
+		return columns
+	}
+	for i := range columns {
+		columns[i] = make([]float64, outs)
+		for o := 0; o < outs; o++ {
+			columns[i][o] = A.Get(i, o)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,56 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestColumnsMatrix(t *testing.T) {
+	x := []float64{1, 2, 3}
+	y := []float64{4, 5, 6}
+
+	A := ColumnsMatrix([][]float64{x, y})
+
+	ExpectMatrix(MatrixFromSlice([]float64{
+		1, 4,
+		2, 5,
+		3, 6,
+	}, 2, 3, 2), A, t)
+	A.Set(1, 2, 9)
+	ExpectFloat(9, y[2], t)
+}
+
+func TestColumnsMatrixContiguous(t *testing.T) {
+	// Columns cut from one buffer become a column-major array.
+	buffer := []float64{1, 2, 3, 4, 5, 6}
+	A := ColumnsMatrix([][]float64{buffer[0:3], buffer[3:6]})
+
+	if _, ok := asArrayMatrix(A); !ok {
+		t.Errorf("expected an array matrix")
+	}
+	ExpectFloat(6, A.Get(1, 2), t)
+
+	columns := MatrixColumns(A)
+	if &columns[1][0] != &buffer[3] {
+		t.Errorf("expected the columns to share the buffer")
+	}
+}
+
+func TestMatrixColumns(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		1, 2,
+		3, 4,
+	}, 2, 2, 2)
+
+	columns := MatrixColumns(A)
+
+	ExpectInt(2, len(columns), t)
+	ExpectFloat(3, columns[0][1], t)
+	ExpectFloat(2, columns[1][0], t)
+	// A is row-major so the columns are copies.
+	columns[0][0] = 7
+	ExpectFloat(1, A.Get(0, 0), t)
+
+	C := NewArrayMatrixColMajor(2, 3)
+	MatrixColumns(C)[1][2] = 5
+	ExpectFloat(5, C.Get(1, 2), t)
+}
@@ -0,0 +1,142 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// Backend runs dense work on a device with memory of its own, like a
+// GPU. Matrices are uploaded to the device, worked on there with as
+// many calls as needed, and downloaded at the end, so that transfers
+// are explicit and don't happen behind every operation.
+type Backend interface {
+	// Upload copies A to the device.
+	Upload(A Matrix) DeviceMatrix
+	// Alloc makes a zero matrix on the device.
+	Alloc(ins, outs int) DeviceMatrix
+	// Download copies D back to a new Matrix on the host.
+	Download(D DeviceMatrix) Matrix
+	// Gemm sets C to alpha*A*B + beta*C.
+	Gemm(alpha float64, A, B DeviceMatrix, beta float64, C DeviceMatrix)
+	// BatchedGemm is Gemm for each As[k], Bs[k] and Cs[k], which
+	// devices can run together.
+	BatchedGemm(alpha float64, As, Bs []DeviceMatrix, beta float64, Cs []DeviceMatrix)
+	// Elementwise sets each entry of dst to op of the entries of A and
+	// B, which are all the same shape.
+	Elementwise(op ElementwiseOp, A, B, dst DeviceMatrix)
+}
+
+// DeviceMatrix is a matrix in a Backend's memory. It has a shape but
+// no Get or Set; its entries are reached by downloading it.
+type DeviceMatrix interface {
+	Shape() (ins, outs int)
+}
+
+// ElementwiseOp is an operation on corresponding entries of two
+// matrices.
+type ElementwiseOp int
+
+const (
+	ElementwiseAdd ElementwiseOp = iota
+	ElementwiseSub
+	// ElementwiseMul is the Hadamard product.
+	ElementwiseMul
+	ElementwiseDiv
+)
+
+// CPU is the reference Backend, whose device is the host and whose
+// kernels are the array kernels the rest of the package uses.
+var CPU Backend = cpuBackend{}
+
+// defaultBackend starts out as CPU and may be replaced at init by
+// builds with a device backend, the way the array kernels are.
+var defaultBackend = CPU
+
+// DefaultBackend returns the best Backend this build has.
+func DefaultBackend() Backend { return defaultBackend }
+
+type cpuBackend struct{}
+
+// cpuMatrix is a DeviceMatrix of the CPU backend.
+type cpuMatrix struct {
+	a *arrayMatrix
+}
+
+func (m *cpuMatrix) Shape() (ins, outs int) { return m.a.ins, m.a.outs }
+
+// array returns the arrayMatrix of a DeviceMatrix of the CPU backend.
+func (cpuBackend) array(D DeviceMatrix) *arrayMatrix {
+	m, ok := D.(*cpuMatrix)
+	if !ok {
+		panic(fmt.Errorf("%T isn't on the CPU backend", D))
+	}
+	return m.a
+}
+
+func (cpuBackend) Upload(A Matrix) DeviceMatrix {
+	ins, outs := A.Shape()
+	dst := NewArrayMatrix(ins, outs)
+	CopyInto(A, dst)
+	return &cpuMatrix{dst.(*arrayMatrix)}
+}
+
+func (cpuBackend) Alloc(ins, outs int) DeviceMatrix {
+	return &cpuMatrix{NewArrayMatrix(ins, outs).(*arrayMatrix)}
+}
+
+func (b cpuBackend) Download(D DeviceMatrix) Matrix {
+	return Copy(b.array(D))
+}
+
+func (b cpuBackend) Gemm(alpha float64, A, B DeviceMatrix, beta float64, C DeviceMatrix) {
+	a, x, c := b.array(A), b.array(B), b.array(C)
+	if a.ins != x.outs {
+		panic(fmt.Errorf("dimension mismatch %d vs %d", a.ins, x.outs))
+	}
+	if c.ins != x.ins || c.outs != a.outs {
+		panic(fmt.Errorf("dimension mismatch (%d, %d) vs (%d, %d)", x.ins, a.outs, c.ins, c.outs))
+	}
+	defer beginOp("Multiply")()
+	countFlops(2*a.ins*a.outs*x.ins + 3*c.ins*c.outs)
+	ax := NewArrayMatrix(c.ins, c.outs).(*arrayMatrix)
+	composeArrays(x, a, ax)
+	for o := 0; o < c.outs; o++ {
+		for i := 0; i < c.ins; i++ {
+			k := o*c.outStride + i*c.inStride
+			c.array[k] = alpha*ax.array[o*ax.outStride+i] + beta*c.array[k]
+		}
+	}
+}
+
+func (b cpuBackend) BatchedGemm(alpha float64, As, Bs []DeviceMatrix, beta float64, Cs []DeviceMatrix) {
+	if len(As) != len(Bs) || len(As) != len(Cs) {
+		panic(fmt.Errorf("batch sizes %d, %d and %d differ", len(As), len(Bs), len(Cs)))
+	}
+	for k := range As {
+		b.Gemm(alpha, As[k], Bs[k], beta, Cs[k])
+	}
+}
+
+func (b cpuBackend) Elementwise(op ElementwiseOp, A, B, dst DeviceMatrix) {
+	x, y, d := b.array(A), b.array(B), b.array(dst)
+	CheckSameShape(x, y)
+	CheckSameShape(x, d)
+	var f func(x, y float64) float64
+	switch op {
+	case ElementwiseAdd:
+		f = func(x, y float64) float64 { return x + y }
+	case ElementwiseSub:
+		f = func(x, y float64) float64 { return x - y }
+	case ElementwiseMul:
+		f = func(x, y float64) float64 { return x * y }
+	case ElementwiseDiv:
+		f = func(x, y float64) float64 { return x / y }
+	default:
+		panic(fmt.Errorf("unknown elementwise op %d", op))
+	}
+	countFlops(d.ins * d.outs)
+	for o := 0; o < d.outs; o++ {
+		for i := 0; i < d.ins; i++ {
+			d.Set(i, o, f(x.Get(i, o), y.Get(i, o)))
+		}
+	}
+}
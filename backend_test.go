@@ -0,0 +1,39 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCPUBackend(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	A := randomMatrix(rng, 5, 9)
+	B := randomMatrix(rng, 11, 5)
+	C := randomMatrix(rng, 11, 9)
+
+	dev := DefaultBackend()
+	dA, dB, dC := dev.Upload(A), dev.Upload(B), dev.Upload(C)
+	dev.Gemm(2, dA, dB, -1, dC)
+
+	expect := Copy(Apply(A, B))
+	addScaledInto(mapEntries(expect, func(f float64) float64 { return 2 * f }), C, -1, expect)
+	ExpectMatrix(expect, dev.Download(dC), t)
+	// Uploads are copies, so the host matrix is untouched.
+	ExpectMatrix(randomMatrix(rand.New(rand.NewSource(1)), 5, 9), A, t)
+
+	dD := dev.Alloc(11, 9)
+	dev.BatchedGemm(1, []DeviceMatrix{dA}, []DeviceMatrix{dB}, 0, []DeviceMatrix{dD})
+	ExpectMatrix(Apply(A, B), dev.Download(dD), t)
+
+	dev.Elementwise(ElementwiseMul, dC, dD, dD)
+	got := dev.Download(dD)
+	AB := Apply(A, B)
+	for o := 0; o < 9; o++ {
+		for i := 0; i < 11; i++ {
+			ExpectFloat(expect.Get(i, o)*AB.Get(i, o), got.Get(i, o), t)
+		}
+	}
+
+	expectPanic(t, func() { dev.Gemm(1, dB, dA, 0, dC) })
+	expectPanic(t, func() { dev.Elementwise(ElementwiseAdd, dA, dB, dC) })
+}
@@ -0,0 +1,201 @@
+package linear
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BigFloatMatrix is a Matrix of arbitrary precision floating point
+// numbers, all with the same mantissa precision in bits. As a Matrix it
+// reads entries rounded to float64, so any solver can use it, but the
+// solvers here that work with GetBig and SetBig keep every intermediate
+// result at the full precision, which makes them good for reference
+// solutions and for watching rounding error grow as prec shrinks.
+type BigFloatMatrix struct {
+	ins, outs int
+	prec      uint
+	// entries are by output (row), then input.
+	entries []*big.Float
+}
+
+// NewBigFloatMatrix makes a new zero BigFloatMatrix with the given
+// shape and precision.
+func NewBigFloatMatrix(ins, outs int, prec uint) *BigFloatMatrix {
+	if ins < 0 || outs < 0 {
+		panic(fmt.Errorf("invalid shape (%d, %d)", ins, outs))
+	}
+	if prec == 0 {
+		panic(fmt.Errorf("can't have 0 bits of precision"))
+	}
+	m := &BigFloatMatrix{ins: ins, outs: outs, prec: prec, entries: make([]*big.Float, ins*outs)}
+	for j := range m.entries {
+		m.entries[j] = new(big.Float).SetPrec(prec)
+	}
+	return m
+}
+
+// BigFloatMatrixFrom makes a BigFloatMatrix with the entries of A,
+// rounded to prec bits if that's less than float64's 53.
+func BigFloatMatrixFrom(A Matrix, prec uint) *BigFloatMatrix {
+	ins, outs := A.Shape()
+	m := NewBigFloatMatrix(ins, outs, prec)
+	CopyInto(A, m)
+	return m
+}
+
+func (m *BigFloatMatrix) Shape() (ins, outs int) { return m.ins, m.outs }
+
+func (m *BigFloatMatrix) Get(in, out int) float64 {
+	f, _ := m.GetBig(in, out).Float64()
+	return f
+}
+
+func (m *BigFloatMatrix) Set(in, out int, value float64) {
+	m.checkBounds(in, out)
+	m.entries[out*m.ins+in].SetFloat64(value)
+}
+
+// Prec returns the precision of the entries in bits.
+func (m *BigFloatMatrix) Prec() uint { return m.prec }
+
+// GetBig returns the entry, which must not be modified.
+func (m *BigFloatMatrix) GetBig(in, out int) *big.Float {
+	m.checkBounds(in, out)
+	return m.entries[out*m.ins+in]
+}
+
+// SetBig sets the entry to value, rounded to the matrix's precision.
+func (m *BigFloatMatrix) SetBig(in, out int, value *big.Float) {
+	m.checkBounds(in, out)
+	m.entries[out*m.ins+in].Set(value)
+}
+
+func (m *BigFloatMatrix) checkBounds(in, out int) {
+	if in < 0 || in >= m.ins || out < 0 || out >= m.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds for shape (%d, %d)", in, out, m.ins, m.outs))
+	}
+}
+
+func (m *BigFloatMatrix) float() *big.Float { return new(big.Float).SetPrec(m.prec) }
+
+// FindInputUpperTriangularBig is FindInputUpperTriangular at the
+// precision of R.
+func FindInputUpperTriangularBig(R, b *BigFloatMatrix) *BigFloatMatrix {
+	CheckVector(b)
+	CheckSameOuts(R, b)
+	CheckUpperTriangular(R)
+	n := R.ins
+	x := NewBigFloatMatrix(1, n, R.prec)
+	t := R.float()
+	for o := n - 1; o >= 0; o-- {
+		s := R.float().Set(b.GetBig(0, o))
+		for i := o + 1; i < n; i++ {
+			s.Sub(s, t.Mul(R.GetBig(i, o), x.GetBig(0, i)))
+		}
+		d := R.GetBig(o, o)
+		if d.Sign() == 0 {
+			panic(fmt.Errorf("singular at %d", o))
+		}
+		x.SetBig(0, o, s.Quo(s, d))
+	}
+	return x
+}
+
+// DecomposeQRBig is DecomposeQR at the precision of A, by Householder
+// reflections. Columns that are already zero below the diagonal are
+// skipped exactly rather than with a tolerance.
+func DecomposeQRBig(A *BigFloatMatrix) (Q, R *BigFloatMatrix) {
+	ins, outs := A.Shape()
+	prec := A.prec
+	R = NewBigFloatMatrix(ins, outs, prec)
+	for j, e := range A.entries {
+		R.entries[j].Set(e)
+	}
+	Q = NewBigFloatMatrix(outs, outs, prec)
+	for d := 0; d < outs; d++ {
+		Q.GetBig(d, d).SetInt64(1)
+	}
+
+	t := R.float()
+	v := make([]*big.Float, outs)
+	for k := 0; k < ins && k < outs; k++ {
+		below := R.float()
+		for o := k + 1; o < outs; o++ {
+			below.Add(below, t.Mul(R.GetBig(k, o), R.GetBig(k, o)))
+		}
+		if below.Sign() == 0 {
+			continue
+		}
+
+		// v = x - alpha*e with alpha = -sign(x_0)*|x|, which never
+		// subtracts nearly equal numbers.
+		x0 := R.GetBig(k, k)
+		norm := R.float().Add(below, t.Mul(x0, x0))
+		norm.Sqrt(norm)
+		if x0.Sign() < 0 {
+			norm.Neg(norm)
+		}
+		v[k] = R.float().Add(x0, norm)
+		for o := k + 1; o < outs; o++ {
+			v[o] = R.float().Set(R.GetBig(k, o))
+		}
+		vv := R.float().Mul(v[k], v[k])
+		vv.Add(vv, below)
+		scale := R.float().Quo(big.NewFloat(2), vv)
+
+		// R = (I - scale*v*Dual(v))*R on the trailing rows.
+		for i := k; i < ins; i++ {
+			s := R.float()
+			for o := k; o < outs; o++ {
+				s.Add(s, t.Mul(v[o], R.GetBig(i, o)))
+			}
+			s.Mul(s, scale)
+			for o := k; o < outs; o++ {
+				e := R.GetBig(i, o)
+				e.Sub(e, t.Mul(s, v[o]))
+			}
+		}
+		for o := k + 1; o < outs; o++ {
+			R.GetBig(k, o).SetInt64(0)
+		}
+
+		// Q = Q*(I - scale*v*Dual(v)) on the trailing columns.
+		for o := 0; o < outs; o++ {
+			s := R.float()
+			for i := k; i < outs; i++ {
+				s.Add(s, t.Mul(Q.GetBig(i, o), v[i]))
+			}
+			s.Mul(s, scale)
+			for i := k; i < outs; i++ {
+				e := Q.GetBig(i, o)
+				e.Sub(e, t.Mul(s, v[i]))
+			}
+		}
+	}
+	return Q, R
+}
+
+// OrdinaryLeastSquaresBig is OrdinaryLeastSquares for a vector y at the
+// precision of X.
+func OrdinaryLeastSquaresBig(X, y *BigFloatMatrix) *BigFloatMatrix {
+	CheckVector(y)
+	CheckSameOuts(X, y)
+	ins, outs := X.Shape()
+	Q, R := DecomposeQRBig(X)
+	// Dual(Q)*y, then only the first ins rows matter.
+	qty := NewBigFloatMatrix(1, ins, X.prec)
+	t := R.float()
+	for i := 0; i < ins; i++ {
+		s := qty.GetBig(0, i)
+		for o := 0; o < outs; o++ {
+			s.Add(s, t.Mul(Q.GetBig(i, o), y.GetBig(0, o)))
+		}
+	}
+	top := NewBigFloatMatrix(ins, ins, X.prec)
+	for o := 0; o < ins; o++ {
+		for i := o; i < ins; i++ {
+			top.SetBig(i, o, R.GetBig(i, o))
+		}
+	}
+	return FindInputUpperTriangularBig(top, qty)
+}
@@ -0,0 +1,43 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestDecomposeQRBig(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		12, -51, 4,
+		6, 167, -68,
+		-4, 24, -41,
+		1, 2, 3,
+	}, 3, 4, 3)
+
+	Q, R := DecomposeQRBig(BigFloatMatrixFrom(A, 200))
+
+	ExpectMatrix(A, Apply(Q, R), t)
+	ExpectMatrix(Identity(4), Compose(Q, Dual(Q)), t)
+	for i := 0; i < 3; i++ {
+		for o := i + 1; o < 4; o++ {
+			ExpectFloat(0, R.Get(i, o), t)
+		}
+	}
+}
+
+func TestOrdinaryLeastSquaresBig(t *testing.T) {
+	X := vandermonde(11, 40)
+	theta := vandermonde(0, 12)
+	y := Apply(X, theta)
+
+	// Far more precision than float64 recovers the coefficients to
+	// float64 accuracy, while fewer bits lose more of them.
+	errorAt := func(prec uint) float64 {
+		got := OrdinaryLeastSquaresBig(BigFloatMatrixFrom(X, prec), BigFloatMatrixFrom(y, prec))
+		return frobeniusDistance(got, theta)
+	}
+	if e := errorAt(256); e > 1e-6 {
+		t.Errorf("expected an accurate solution but got error %g", e)
+	}
+	if coarse, fine := errorAt(40), errorAt(100); coarse < 100*fine {
+		t.Errorf("expected more error at lower precision but got %g vs %g", coarse, fine)
+	}
+}
@@ -0,0 +1,69 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// SolveBlock finds x and y such that
+//
+//	[A B] [x]   [f]
+//	[C D] [y] = [g]
+//
+// given a Solver for A, by eliminating x to get the smaller system
+// S*y = g - C*A^-1*f in the Schur complement S = D - C*A^-1*B.
+func SolveBlock(A Solver, B, C, D Matrix, f, g Vector) (x, y Vector) {
+	CheckVector(f)
+	CheckVector(g)
+	CheckSameOuts(B, f)
+	CheckSameOuts(C, g)
+	CheckComposable(B, C)
+	CheckSameIns(B, D)
+	CheckSameOuts(C, D)
+	m, n := B.Shape()
+	if _, k := D.Shape(); k != m {
+		panic(fmt.Errorf("not square shape=(%d, %d)", m, k))
+	}
+
+	// A^-1*B one column at a time.
+	AinvB := NewArrayMatrix(m, n)
+	for i := 0; i < m; i++ {
+		CopyInto(A.SolveVec(Slice(B, i, i+1, 0, n)), Slice(AinvB, i, i+1, 0, n))
+	}
+	Ainvf := A.SolveVec(f)
+
+	S := Apply(C, AinvB)
+	for o := 0; o < m; o++ {
+		for i := 0; i < m; i++ {
+			S.Set(i, o, D.Get(i, o)-S.Get(i, o))
+		}
+	}
+	rhs := Apply(C, Ainvf)
+	for d := 0; d < m; d++ {
+		rhs.Set(0, d, g.Get(0, d)-rhs.Get(0, d))
+	}
+	y = FactorLU(S).SolveVec(rhs)
+
+	// Back substitute y to get x = A^-1*f - A^-1*B*y.
+	x = Apply(AinvB, y)
+	for d := 0; d < n; d++ {
+		x.Set(0, d, Ainvf.Get(0, d)-x.Get(0, d))
+	}
+	return x, y
+}
+
+// ConstrainedLeastSquares finds the input theta that minimizes the L2
+// distance between X*theta and y subject to C*theta = d, by solving
+// the saddle point system of the normal equations and the constraints
+// with SolveBlock.
+func ConstrainedLeastSquares(X, y, C, d Matrix) Matrix {
+	CheckVector(y)
+	CheckVector(d)
+	CheckSameIns(X, C)
+	CheckSameOuts(C, d)
+	_, k := C.Shape()
+
+	L := DecomposeCholesky(Compose(X, Dual(X)))
+	A := SolverFunc(func(b Vector) Vector { return SolveCholesky(L, b) })
+	theta, _ := SolveBlock(A, Dual(C), C, NewArrayMatrix(k, k), Apply(Dual(X), y), d)
+	return theta
+}
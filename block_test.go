@@ -0,0 +1,64 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestSolveBlock(t *testing.T) {
+	// [2 0 | 1] [x0]   [3]
+	// [0 4 | 1] [x1] = [9]
+	// [1 1 | 0] [y0]   [2]
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 2)
+	A.Set(1, 1, 4)
+	L := DecomposeCholesky(A)
+	solveA := SolverFunc(func(b Vector) Vector { return SolveCholesky(L, b) })
+
+	B := NewArrayMatrix(1, 2)
+	B.Set(0, 0, 1)
+	B.Set(0, 1, 1)
+
+	C := Copy(Dual(B))
+	D := NewArrayMatrix(1, 1)
+
+	f := NewVector(2)
+	f.Set(0, 0, 3)
+	f.Set(0, 1, 9)
+
+	g := NewVector(1)
+	g.Set(0, 0, 2)
+
+	x, y := SolveBlock(solveA, B, C, D, f, g)
+
+	ExpectFloat(1.0/3.0, x.Get(0, 0), t)
+	ExpectFloat(5.0/3.0, x.Get(0, 1), t)
+	ExpectFloat(7.0/3.0, y.Get(0, 0), t)
+
+	expectPanic(t, func() { SolveBlock(solveA, B, C, NewArrayMatrix(2, 1), f, g) })
+}
+
+func TestConstrainedLeastSquares(t *testing.T) {
+	X := NewArrayMatrix(2, 3)
+	X.Set(0, 0, 1)
+	X.Set(1, 1, 1)
+	X.Set(0, 2, 1)
+	X.Set(1, 2, 1)
+
+	y := NewVector(3)
+	y.Set(0, 0, 1)
+	y.Set(0, 1, 2)
+	y.Set(0, 2, 3)
+
+	// theta0 = theta1
+	C := NewArrayMatrix(2, 1)
+	C.Set(0, 0, 1)
+	C.Set(1, 0, -1)
+
+	d := NewVector(1)
+
+	theta := ConstrainedLeastSquares(X, y, C, d)
+
+	// Minimizing (t-1)^2 + (t-2)^2 + (2t-3)^2 gives t = 3/2.
+	ExpectFloat(1.5, theta.Get(0, 0), t)
+	ExpectFloat(1.5, theta.Get(0, 1), t)
+}
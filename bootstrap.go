@@ -0,0 +1,86 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// BootstrapResult holds the parameters fit to each bootstrap
+// resample.
+type BootstrapResult struct {
+	// Samples has a parameter per input and a resample per output.
+	Samples Matrix
+}
+
+// Bootstrap estimates the uncertainty in the parameters that fit finds
+// for X and y by fitting B resamples of the observations (outputs),
+// each drawn with replacement from the originals. The resamples are
+// Gather views, so no data is copied. fit must return a vector of
+// parameters, like OrdinaryLeastSquares.
+func Bootstrap(fit func(X, y Matrix) Matrix, X, y Matrix, B int, rng *rand.Rand) *BootstrapResult {
+	CheckSameOuts(X, y)
+	_, n := X.Shape()
+	if B <= 0 || n == 0 {
+		panic(fmt.Errorf("can't take %d resamples of %d observations", B, n))
+	}
+	var samples Matrix
+	rows := make([]int, n)
+	for b := 0; b < B; b++ {
+		for o := range rows {
+			rows[o] = rng.Intn(n)
+		}
+		theta := fit(Gather(X, rows), Gather(y, rows))
+		CheckVector(theta)
+		_, p := theta.Shape()
+		if samples == nil {
+			samples = NewArrayMatrix(p, B)
+		}
+		CopyInto(Dual(theta), Slice(samples, 0, p, b, b+1))
+	}
+	return &BootstrapResult{samples}
+}
+
+// Mean returns the mean of the bootstrap parameters.
+func (r *BootstrapResult) Mean() Covector {
+	return ColumnMeans(r.Samples)
+}
+
+// StdErr returns the standard deviation of each bootstrap parameter,
+// the bootstrap estimate of its standard error.
+func (r *BootstrapResult) StdErr() Covector {
+	return mapEntries(ColumnVariances(r.Samples), math.Sqrt)
+}
+
+// ConfidenceInterval returns the percentile interval of each parameter
+// that holds the given fraction (like 0.95) of the bootstrap samples,
+// between the (1-level)/2 and (1+level)/2 quantiles.
+func (r *BootstrapResult) ConfidenceInterval(level float64) (lo, hi Covector) {
+	if level <= 0 || level >= 1 {
+		panic(fmt.Errorf("confidence level %v isn't in (0, 1)", level))
+	}
+	p, B := r.Samples.Shape()
+	lo, hi = NewCovector(p), NewCovector(p)
+	sorted := make([]float64, B)
+	for i := 0; i < p; i++ {
+		for b := range sorted {
+			sorted[b] = r.Samples.Get(i, b)
+		}
+		sort.Float64s(sorted)
+		lo.Set(i, 0, quantileSorted(sorted, (1-level)/2))
+		hi.Set(i, 0, quantileSorted(sorted, (1+level)/2))
+	}
+	return lo, hi
+}
+
+// quantileSorted interpolates linearly between the order statistics of
+// sorted.
+func quantileSorted(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	k := int(pos)
+	if k+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[k] + (pos-float64(k))*(sorted[k+1]-sorted[k])
+}
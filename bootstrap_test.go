@@ -0,0 +1,50 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBootstrap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	n := 200
+	X := NewArrayMatrix(2, n)
+	y := NewVector(n)
+	for o := 0; o < n; o++ {
+		x := r.NormFloat64()
+		X.Set(0, o, 1)
+		X.Set(1, o, x)
+		y.Set(0, o, 3+2*x+r.NormFloat64())
+	}
+
+	result := Bootstrap(OrdinaryLeastSquares, X, y, 300, rand.New(rand.NewSource(2)))
+
+	p, B := result.Samples.Shape()
+	ExpectInt(2, p, t)
+	ExpectInt(300, B, t)
+	mean := result.Mean()
+	if math.Abs(mean.Get(1, 0)-2) > 0.3 {
+		t.Errorf("expected a slope near 2 but got %v", mean.Get(1, 0))
+	}
+	// The standard error of the slope is about sigma/sqrt(n).
+	se := result.StdErr().Get(1, 0)
+	if se < 0.04 || se > 0.1 {
+		t.Errorf("expected a standard error near 0.07 but got %v", se)
+	}
+	lo, hi := result.ConfidenceInterval(0.95)
+	theta := OrdinaryLeastSquares(X, y)
+	for i := 0; i < 2; i++ {
+		if lo.Get(i, 0) > theta.Get(0, i) || hi.Get(i, 0) < theta.Get(0, i) {
+			t.Errorf("expected [%v, %v] to contain %v", lo.Get(i, 0), hi.Get(i, 0), theta.Get(0, i))
+		}
+	}
+}
+
+func TestQuantileSorted(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	ExpectFloat(1, quantileSorted(sorted, 0), t)
+	ExpectFloat(3, quantileSorted(sorted, 0.5), t)
+	ExpectFloat(4.5, quantileSorted(sorted, 0.875), t)
+	ExpectFloat(5, quantileSorted(sorted, 1), t)
+}
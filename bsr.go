@@ -0,0 +1,215 @@
+package linear
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BSR is a sparse Matrix in block compressed sparse row form: the
+// matrix is divided into blocks of blockOuts by blockIns entries, and
+// only the blocks with a non-zero are stored, densely, block row by
+// block row. Storing one index per block instead of per entry, and
+// running dense loops inside each block, suits matrices made of small
+// dense pieces like the 3x3 blocks of finite element or graphics
+// problems.
+type BSR struct {
+	ins, outs           int
+	blockIns, blockOuts int
+	// The blocks of block row r are at rowStart[r] up to rowStart[r+1]
+	// in blockCols, in order of block column.
+	rowStart  []int
+	blockCols []int
+	// Block j is values[j*blockOuts*blockIns:], row by row.
+	values []float64
+}
+
+// NewBSR makes a new zero block sparse Matrix with the given shape,
+// which must be a whole number of blocks.
+func NewBSR(ins, outs, blockIns, blockOuts int) *BSR {
+	if blockIns <= 0 || blockOuts <= 0 || ins%blockIns != 0 || outs%blockOuts != 0 {
+		panic(fmt.Errorf("shape (%d, %d) isn't made of (%d, %d) blocks", ins, outs, blockIns, blockOuts))
+	}
+	return &BSR{
+		ins:       ins,
+		outs:      outs,
+		blockIns:  blockIns,
+		blockOuts: blockOuts,
+		rowStart:  make([]int, outs/blockOuts+1),
+	}
+}
+
+// NewBSRFromEntries makes a block sparse Matrix with the given entries,
+// in any order. Entries at the same position are added together.
+func NewBSRFromEntries(ins, outs, blockIns, blockOuts int, entries []SparseEntry) *BSR {
+	m := NewBSR(ins, outs, blockIns, blockOuts)
+	sorted := append([]SparseEntry(nil), entries...)
+	sort.Slice(sorted, func(a, b int) bool {
+		ra, rb := sorted[a].Out/blockOuts, sorted[b].Out/blockOuts
+		if ra != rb {
+			return ra < rb
+		}
+		return sorted[a].In/blockIns < sorted[b].In/blockIns
+	})
+	size := blockIns * blockOuts
+	for j, e := range sorted {
+		m.checkBounds(e.In, e.Out)
+		r, c := e.Out/blockOuts, e.In/blockIns
+		if j == 0 || sorted[j-1].Out/blockOuts != r || sorted[j-1].In/blockIns != c {
+			m.blockCols = append(m.blockCols, c)
+			m.values = append(m.values, make([]float64, size)...)
+			m.rowStart[r+1]++
+		}
+		block := m.values[len(m.values)-size:]
+		block[(e.Out%blockOuts)*blockIns+e.In%blockIns] += e.Value
+	}
+	for r := 0; r+1 < len(m.rowStart); r++ {
+		m.rowStart[r+1] += m.rowStart[r]
+	}
+	return m
+}
+
+func (m *BSR) Shape() (ins, outs int) { return m.ins, m.outs }
+
+func (m *BSR) Get(in, out int) float64 {
+	if j, ok := m.find(in, out); ok {
+		return m.block(j)[(out%m.blockOuts)*m.blockIns+in%m.blockIns]
+	}
+	return 0
+}
+
+// Set changes an entry. Making an entry non-zero in a block that isn't
+// stored yet has to shift all the blocks after it.
+func (m *BSR) Set(in, out int, value float64) {
+	j, ok := m.find(in, out)
+	if !ok {
+		if value == 0 {
+			return
+		}
+		size := m.blockIns * m.blockOuts
+		m.blockCols = append(m.blockCols, 0)
+		copy(m.blockCols[j+1:], m.blockCols[j:])
+		m.blockCols[j] = in / m.blockIns
+		m.values = append(m.values, make([]float64, size)...)
+		copy(m.values[(j+1)*size:], m.values[j*size:])
+		for k := range m.block(j) {
+			m.block(j)[k] = 0
+		}
+		for r := out/m.blockOuts + 1; r < len(m.rowStart); r++ {
+			m.rowStart[r]++
+		}
+	}
+	m.block(j)[(out%m.blockOuts)*m.blockIns+in%m.blockIns] = value
+}
+
+// BlockShape returns the shape of each block.
+func (m *BSR) BlockShape() (ins, outs int) { return m.blockIns, m.blockOuts }
+
+// NonZeroBlocks returns the number of stored blocks.
+func (m *BSR) NonZeroBlocks() int { return len(m.blockCols) }
+
+func (m *BSR) block(j int) []float64 {
+	size := m.blockIns * m.blockOuts
+	return m.values[j*size : (j+1)*size]
+}
+
+// find returns where the block holding the entry is stored, or where
+// it would go.
+func (m *BSR) find(in, out int) (int, bool) {
+	m.checkBounds(in, out)
+	r, c := out/m.blockOuts, in/m.blockIns
+	lo, hi := m.rowStart[r], m.rowStart[r+1]
+	j := lo + sort.SearchInts(m.blockCols[lo:hi], c)
+	return j, j < hi && m.blockCols[j] == c
+}
+
+func (m *BSR) checkBounds(in, out int) {
+	if in < 0 || in >= m.ins || out < 0 || out >= m.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds (%d, %d)", in, out, m.ins, m.outs))
+	}
+}
+
+// ToCSR returns the same matrix in compressed sparse row form, dropping
+// the zeros inside blocks.
+func (m *BSR) ToCSR() *CSR {
+	var entries []SparseEntry
+	m.each(func(in, out int, v float64) {
+		if v != 0 {
+			entries = append(entries, SparseEntry{in, out, v})
+		}
+	})
+	return NewCSRFromEntries(m.ins, m.outs, entries)
+}
+
+// each calls f on every entry of every stored block.
+func (m *BSR) each(f func(in, out int, v float64)) {
+	for r := 0; r+1 < len(m.rowStart); r++ {
+		for j := m.rowStart[r]; j < m.rowStart[r+1]; j++ {
+			block := m.block(j)
+			for a := 0; a < m.blockOuts; a++ {
+				for b := 0; b < m.blockIns; b++ {
+					f(m.blockCols[j]*m.blockIns+b, r*m.blockOuts+a, block[a*m.blockIns+b])
+				}
+			}
+		}
+	}
+}
+
+// asBSR sees through Dual to a BSR, reporting whether A is its
+// transpose.
+func asBSR(A Matrix) (m *BSR, transposed, ok bool) {
+	switch a := A.(type) {
+	case *BSR:
+		return a, false, true
+	case *dualMatrix:
+		if m, transposed, ok := asBSR(a.A); ok {
+			return m, !transposed, true
+		}
+	}
+	return nil, false, false
+}
+
+// bsrCompose is ComposeInto for when one of A and B is block sparse
+// and the other is dense. It returns false if it doesn't apply.
+func bsrCompose(A, B, dst Matrix) bool {
+	mb, tb, bBlock := asBSR(B)
+	ma, ta, aBlock := asBSR(A)
+	switch {
+	case bBlock && !isSparse(A):
+		cols, _ := A.Shape()
+		countFlops(2 * len(mb.values) * cols)
+		bsrmm(mb, tb, A, dst)
+		return true
+	case aBlock && !isSparse(B):
+		_, outs := B.Shape()
+		countFlops(2 * len(ma.values) * outs)
+		bsrmm(ma, !ta, Dual(B), Dual(dst))
+		return true
+	}
+	return false
+}
+
+// bsrmm writes S*X into dst, where S is m or, if transposed, Dual(m),
+// a block at a time.
+func bsrmm(m *BSR, transposed bool, X, dst Matrix) {
+	addRow := rowAdder(X, dst)
+	bi, bo := m.blockIns, m.blockOuts
+	for r := 0; r+1 < len(m.rowStart); r++ {
+		for j := m.rowStart[r]; j < m.rowStart[r+1]; j++ {
+			block := m.block(j)
+			c := m.blockCols[j]
+			for a := 0; a < bo; a++ {
+				for b := 0; b < bi; b++ {
+					v := block[a*bi+b]
+					if v == 0 {
+						continue
+					}
+					if transposed {
+						addRow(v, r*bo+a, c*bi+b)
+					} else {
+						addRow(v, c*bi+b, r*bo+a)
+					}
+				}
+			}
+		}
+	}
+}
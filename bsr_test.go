@@ -0,0 +1,38 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBSR(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	entries := randomSparse(r, 6, 9, 12)
+	B := NewBSRFromEntries(6, 9, 2, 3, entries)
+	S := NewCSRFromEntries(6, 9, entries)
+
+	ExpectMatrix(S, B, t)
+	ExpectMatrix(S, B.ToCSR(), t)
+
+	B.Set(5, 8, 7)
+	S.Set(5, 8, 7)
+	B.Set(0, 0, -1)
+	S.Set(0, 0, -1)
+
+	ExpectMatrix(S, B, t)
+
+	X := NewArrayMatrix(2, 6)
+	Y := NewArrayMatrix(9, 3)
+	for _, M := range []Matrix{X, Y} {
+		ins, outs := M.Shape()
+		for o := 0; o < outs; o++ {
+			for i := 0; i < ins; i++ {
+				M.Set(i, o, r.NormFloat64())
+			}
+		}
+	}
+
+	ExpectMatrix(Apply(S, X), Apply(B, X), t)
+	ExpectMatrix(Apply(Dual(S), Copy(Dual(Y))), Apply(Dual(B), Copy(Dual(Y))), t)
+	ExpectMatrix(Apply(Y, S), Apply(Y, B), t)
+}
@@ -0,0 +1,90 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// CameraIntrinsics returns the 3x3 calibration matrix K of a pinhole
+// camera with focal lengths fx and fy and principal point (cx, cy), all
+// in pixels.
+func CameraIntrinsics(fx, fy, cx, cy float64) Matrix {
+	return Matrix3{
+		{fx, 0, cx},
+		{0, fy, cy},
+		{0, 0, 1},
+	}.Matrix()
+}
+
+// ProjectionMatrix returns the 3x4 projection K*[R | t] of a pinhole
+// camera with calibration K, whose frame is R*x + t for a point x in
+// world coordinates.
+func ProjectionMatrix(K, R Matrix, t Vector) Matrix {
+	checkFixedVector(t, 3)
+	Rt := NewArrayMatrix(4, 3)
+	CopyInto(R, Slice(Rt, 0, 3, 0, 3))
+	CopyInto(t, Slice(Rt, 3, 4, 0, 3))
+	return Apply(K, Rt)
+}
+
+// ProjectPoints maps each point (output) of X, with 3 columns, through
+// the 3x4 projection P to a point with 2 columns in the image.
+func ProjectPoints(P, X Matrix) Matrix {
+	checkProjection(P)
+	dim, n := X.Shape()
+	if dim != 3 {
+		panic(fmt.Errorf("expected 3 dimensional points but got %d", dim))
+	}
+	x := NewArrayMatrix(2, n)
+	for o := 0; o < n; o++ {
+		var h [3]float64
+		for r := 0; r < 3; r++ {
+			h[r] = P.Get(3, r)
+			for i := 0; i < 3; i++ {
+				h[r] += P.Get(i, r) * X.Get(i, o)
+			}
+		}
+		CheckNotCloseToZero(h[2])
+		x.Set(0, o, h[0]/h[2])
+		x.Set(1, o, h[1]/h[2])
+	}
+	return x
+}
+
+// Triangulate recovers the 3 dimensional points that project to x1
+// through P1 and to x2 through P2, for corresponding image points
+// (outputs) of x1 and x2. Each point is the homogeneous least squares
+// solution of the 4 linear equations the two projections give.
+func Triangulate(P1, P2, x1, x2 Matrix) Matrix {
+	checkProjection(P1)
+	checkProjection(P2)
+	CheckSameShape(x1, x2)
+	dim, n := x1.Shape()
+	if dim != 2 {
+		panic(fmt.Errorf("expected 2 dimensional image points but got %d", dim))
+	}
+	X := NewArrayMatrix(3, n)
+	A := NewArrayMatrix(4, 4)
+	for o := 0; o < n; o++ {
+		// u*p3 - p1 and v*p3 - p2 for the rows p of each projection.
+		for view, P := range []Matrix{P1, P2} {
+			x := []Matrix{x1, x2}[view]
+			for k := 0; k < 2; k++ {
+				for i := 0; i < 4; i++ {
+					A.Set(i, 2*view+k, x.Get(k, o)*P.Get(i, 2)-P.Get(i, k))
+				}
+			}
+		}
+		h := SolveHomogeneous(A)
+		CheckNotCloseToZero(h.Get(0, 3))
+		for i := 0; i < 3; i++ {
+			X.Set(i, o, h.Get(0, i)/h.Get(0, 3))
+		}
+	}
+	return X
+}
+
+func checkProjection(P Matrix) {
+	if ins, outs := P.Shape(); ins != 4 || outs != 3 {
+		panic(fmt.Errorf("expected a 3x4 projection but got (%d, %d)", ins, outs))
+	}
+}
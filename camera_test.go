@@ -0,0 +1,36 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestProjectPoints(t *testing.T) {
+	K := CameraIntrinsics(100, 200, 320, 240)
+	P := ProjectionMatrix(K, Identity(3), vector3(0, 0, 2))
+	X := MatrixFromSlice([]float64{
+		0, 0, 0,
+		1, -1, 2,
+	}, 3, 2, 3)
+
+	x := ProjectPoints(P, X)
+
+	ExpectMatrix(MatrixFromSlice([]float64{
+		320, 240,
+		345, 190,
+	}, 2, 2, 2), x, t)
+}
+
+func TestTriangulate(t *testing.T) {
+	K := CameraIntrinsics(500, 500, 320, 240)
+	P1 := ProjectionMatrix(K, Identity(3), vector3(0, 0, 0))
+	P2 := ProjectionMatrix(K, RotationY(-0.2), vector3(-1, 0, 0))
+	X := MatrixFromSlice([]float64{
+		0, 0, 5,
+		1, 0.5, 6,
+		-0.7, -0.3, 4,
+	}, 3, 3, 3)
+
+	got := Triangulate(P1, P2, ProjectPoints(P1, X), ProjectPoints(P2, X))
+
+	ExpectMatrix(X, got, t)
+}
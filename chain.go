@@ -0,0 +1,91 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// ComposeAll returns "ms[0] then ms[1] then ..." (aka ...*ms[1]*ms[0]),
+// composing pairs in whichever order takes the fewest multiplications.
+func ComposeAll(ms ...Matrix) Matrix {
+	if len(ms) == 0 {
+		panic(fmt.Errorf("nothing to compose"))
+	}
+	ins, _ := ms[0].Shape()
+	_, outs := ms[len(ms)-1].Shape()
+	dst := NewArrayMatrix(ins, outs)
+	ComposeAllInto(dst, ms...)
+	return dst
+}
+
+// ComposeAllInto writes "ms[0] then ms[1] then ..." into dst. The last
+// composition writes straight into dst so only the intermediate
+// results are allocated.
+func ComposeAllInto(dst Matrix, ms ...Matrix) {
+	if len(ms) == 0 {
+		panic(fmt.Errorf("nothing to compose"))
+	}
+	for j := 0; j+1 < len(ms); j++ {
+		CheckComposable(ms[j], ms[j+1])
+	}
+	split := chainOrder(ms)
+	composeRange(ms, split, 0, len(ms)-1, dst)
+}
+
+// chainOrder finds the cheapest way to parenthesize the chain by
+// dynamic programming over contiguous ranges. split[i][j] is where the
+// range from i to j (inclusive) should be divided.
+func chainOrder(ms []Matrix) [][]int {
+	n := len(ms)
+	// dims[j] is the number of inputs of ms[j], and dims[n] is the
+	// number of outputs of the last one.
+	dims := make([]int, n+1)
+	for j, m := range ms {
+		dims[j], dims[j+1] = m.Shape()
+	}
+
+	cost := make([][]int, n)
+	split := make([][]int, n)
+	for i := range cost {
+		cost[i] = make([]int, n)
+		split[i] = make([]int, n)
+	}
+	for length := 2; length <= n; length++ {
+		for i := 0; i+length-1 < n; i++ {
+			j := i + length - 1
+			cost[i][j] = -1
+			for s := i; s < j; s++ {
+				// Composing [i, s] with [s+1, j] does dims[i]*dims[j+1]
+				// dot products of length dims[s+1].
+				c := cost[i][s] + cost[s+1][j] + dims[i]*dims[s+1]*dims[j+1]
+				if cost[i][j] < 0 || c < cost[i][j] {
+					cost[i][j] = c
+					split[i][j] = s
+				}
+			}
+		}
+	}
+	return split
+}
+
+// composeRange writes the composition of ms[i] through ms[j] into dst.
+func composeRange(ms []Matrix, split [][]int, i, j int, dst Matrix) {
+	if i == j {
+		CopyInto(ms[i], dst)
+		return
+	}
+	s := split[i][j]
+	ComposeInto(composedRange(ms, split, i, s), composedRange(ms, split, s+1, j), dst)
+}
+
+// composedRange returns the composition of ms[i] through ms[j],
+// without copying if it's a single matrix.
+func composedRange(ms []Matrix, split [][]int, i, j int) Matrix {
+	if i == j {
+		return ms[i]
+	}
+	ins, _ := ms[i].Shape()
+	_, outs := ms[j].Shape()
+	dst := NewArrayMatrix(ins, outs)
+	composeRange(ms, split, i, j, dst)
+	return dst
+}
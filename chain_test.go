@@ -0,0 +1,51 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestComposeAll(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	shapes := [][2]int{{1, 30}, {30, 2}, {2, 40}, {40, 3}}
+	var ms []Matrix
+	for _, shape := range shapes {
+		M := NewArrayMatrix(shape[0], shape[1])
+		for o := 0; o < shape[1]; o++ {
+			for i := 0; i < shape[0]; i++ {
+				M.Set(i, o, rng.NormFloat64())
+			}
+		}
+		ms = append(ms, M)
+	}
+
+	expect := Compose(Compose(Compose(ms[0], ms[1]), ms[2]), ms[3])
+	got := ComposeAll(ms...)
+
+	ins, outs := got.Shape()
+	ExpectInt(1, ins, t)
+	ExpectInt(3, outs, t)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			ExpectFloat(expect.Get(i, o), got.Get(i, o), t)
+		}
+	}
+
+	single := ComposeAll(ms[1])
+	ExpectFloat(ms[1].Get(1, 1), single.Get(1, 1), t)
+}
+
+func TestChainOrder(t *testing.T) {
+	// A vector through a wide then tall pair should apply them one at
+	// a time rather than composing the big maps first.
+	ms := []Matrix{
+		NewArrayMatrix(1, 10),
+		NewArrayMatrix(10, 100),
+		NewArrayMatrix(100, 5),
+	}
+
+	split := chainOrder(ms)
+
+	ExpectInt(1, split[0][2], t)
+	ExpectInt(0, split[0][1], t)
+}
@@ -0,0 +1,310 @@
+package linear
+
+import (
+	"fmt"
+	"time"
+)
+
+// The iterative solvers are saved like the models in modelio.go, as a
+// savedModel of their vectors and scalars. Only the state is saved, not
+// the operator or objective (which can't be), so Restore is called on a
+// solver made for the same problem, and b is saved to check that it is.
+
+// Snapshot returns the solver's state in a binary form for Restore.
+func (s *CGSolver) Snapshot() ([]byte, error) {
+	saved := &savedModel{
+		Kind:    "CGSolver",
+		Version: modelFormatVersion,
+		Matrices: map[string]JSONMatrix{
+			"B": {s.b}, "X": {s.x}, "R": {s.r}, "P": {s.p},
+		},
+		Numbers: map[string]float64{
+			"RR": s.rr, "Iterations": float64(s.iterations),
+		},
+	}
+	saveMeasured(saved, s.measured)
+	return saved.MarshalBinary()
+}
+
+// Restore replaces the solver's state with one from Snapshot, after
+// which it carries on as the snapshotted solver would have. It's an
+// error if the snapshot is of a different system.
+func (s *CGSolver) Restore(data []byte) error {
+	saved, err := restoreSolver(data, "CGSolver", s.b)
+	if err != nil {
+		return err
+	}
+	vs, err := savedVectors(saved, s.b, "X", "R", "P")
+	if err != nil {
+		return err
+	}
+	rr, err := saved.number("RR")
+	if err != nil {
+		return err
+	}
+	iterations, err := saved.number("Iterations")
+	if err != nil {
+		return err
+	}
+	measured, err := savedMeasured(saved)
+	if err != nil {
+		return err
+	}
+	s.x, s.r, s.p, s.rr, s.iterations = vs[0], vs[1], vs[2], rr, int(iterations)
+	s.measured = measured
+	return nil
+}
+
+// Snapshot returns the solver's state in a binary form for Restore.
+func (s *GMRESSolver) Snapshot() ([]byte, error) {
+	k := len(s.h)
+	H := NewArrayMatrix(k, k+1)
+	for l, h := range s.h {
+		for j, f := range h {
+			H.Set(l, j, f)
+		}
+	}
+	_, n := s.b.Shape()
+	saved := &savedModel{
+		Kind:    "GMRESSolver",
+		Version: modelFormatVersion,
+		Matrices: map[string]JSONMatrix{
+			"B":  {s.b},
+			"V":  {vectorColumns(s.v, n)},
+			"H":  {H},
+			"CS": {vectorFromSlice(s.cs)},
+			"SN": {vectorFromSlice(s.sn)},
+			"G":  {vectorFromSlice(s.g)},
+		},
+		Numbers: map[string]float64{"Invariant": boolNumber(s.invariant)},
+	}
+	saveMeasured(saved, s.measured)
+	return saved.MarshalBinary()
+}
+
+// Restore replaces the solver's state with one from Snapshot, after
+// which it carries on as the snapshotted solver would have. It's an
+// error if the snapshot is of a different system.
+func (s *GMRESSolver) Restore(data []byte) error {
+	saved, err := restoreSolver(data, "GMRESSolver", s.b)
+	if err != nil {
+		return err
+	}
+	var ms [5]Matrix
+	for m, name := range []string{"V", "H", "CS", "SN", "G"} {
+		if ms[m], err = saved.matrix(name); err != nil {
+			return err
+		}
+	}
+	V, H := ms[0], ms[1]
+	cs, sn, g := vectorFloats(ms[2]), vectorFloats(ms[3]), vectorFloats(ms[4])
+	invariant, err := saved.number("Invariant")
+	if err != nil {
+		return err
+	}
+	measured, err := savedMeasured(saved)
+	if err != nil {
+		return err
+	}
+	_, n := s.b.Shape()
+	k, hOuts := H.Shape()
+	vIns, vOuts := V.Shape()
+	basis := k + 1
+	if invariant != 0 || L2Norm(s.b) == 0 {
+		basis = k
+	}
+	if hOuts != k+1 || vOuts != n || vIns != basis || len(cs) != k || len(sn) != k || len(g) != k+1 {
+		return fmt.Errorf("saved GMRESSolver has inconsistent shapes for %d iterations", k)
+	}
+	s.h = make([][]float64, k)
+	for l := range s.h {
+		s.h[l] = make([]float64, l+2)
+		for j := range s.h[l] {
+			s.h[l][j] = H.Get(l, j)
+		}
+	}
+	s.v = columnVectors(V)
+	s.cs, s.sn, s.g, s.invariant = cs, sn, g, invariant != 0
+	s.measured = measured
+	return nil
+}
+
+// Snapshot returns the solver's state in a binary form for Restore. It
+// evaluates the objective first if no Step has.
+func (s *LBFGSSolver) Snapshot() ([]byte, error) {
+	s.evaluate()
+	_, n := s.x.Shape()
+	saved := &savedModel{
+		Kind:    "LBFGSSolver",
+		Version: modelFormatVersion,
+		Matrices: map[string]JSONMatrix{
+			"X":   {s.x},
+			"G":   {s.g},
+			"S":   {vectorColumns(s.ss, n)},
+			"Y":   {vectorColumns(s.ys, n)},
+			"Rho": {vectorFromSlice(s.rhos)},
+		},
+		Numbers: map[string]float64{
+			"F":          s.fx,
+			"Iterations": float64(s.iterations),
+			"Stalled":    boolNumber(s.stalled),
+		},
+	}
+	saveMeasured(saved, s.measured)
+	return saved.MarshalBinary()
+}
+
+// Restore replaces the solver's state with one from Snapshot, after
+// which it carries on as the snapshotted solver would have, given the
+// same objective. If the solver's Memory is smaller than the
+// snapshot's, the oldest steps are forgotten.
+func (s *LBFGSSolver) Restore(data []byte) error {
+	var saved savedModel
+	if err := saved.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if err := saved.check("LBFGSSolver"); err != nil {
+		return err
+	}
+	vs, err := savedVectors(&saved, s.x, "X", "G")
+	if err != nil {
+		return err
+	}
+	var ms [3]Matrix
+	for m, name := range []string{"S", "Y", "Rho"} {
+		if ms[m], err = saved.matrix(name); err != nil {
+			return err
+		}
+	}
+	ss, ys, rhos := columnVectors(ms[0]), columnVectors(ms[1]), vectorFloats(ms[2])
+	_, n := s.x.Shape()
+	_, sOuts := ms[0].Shape()
+	_, yOuts := ms[1].Shape()
+	if len(ys) != len(ss) || len(rhos) != len(ss) || (len(ss) > 0 && (sOuts != n || yOuts != n)) {
+		return fmt.Errorf("saved LBFGSSolver has inconsistent steps")
+	}
+	var nums [3]float64
+	for k, name := range []string{"F", "Iterations", "Stalled"} {
+		if nums[k], err = saved.number(name); err != nil {
+			return err
+		}
+	}
+	measured, err := savedMeasured(&saved)
+	if err != nil {
+		return err
+	}
+	s.x, s.g, s.fx = vs[0], vs[1], nums[0]
+	s.iterations, s.stalled = int(nums[1]), nums[2] != 0
+	s.measured = measured
+	s.ss, s.ys, s.rhos = nil, nil, nil
+	for k := range ss {
+		s.remember(ss[k], ys[k], rhos[k])
+	}
+	return nil
+}
+
+// saveMeasured saves the flops and duration so far, so that Stats
+// still covers the whole solve after a Restore.
+func saveMeasured(saved *savedModel, measured Stats) {
+	saved.Numbers["Flops"] = float64(measured.Flops)
+	saved.Numbers["Nanoseconds"] = float64(measured.Duration)
+}
+
+func savedMeasured(saved *savedModel) (Stats, error) {
+	flops, err := saved.number("Flops")
+	if err != nil {
+		return Stats{}, err
+	}
+	nanoseconds, err := saved.number("Nanoseconds")
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{Flops: int64(flops), Duration: time.Duration(nanoseconds)}, nil
+}
+
+// restoreSolver reads a snapshot of the given kind and checks that it
+// was for the system with right hand side b.
+func restoreSolver(data []byte, kind string, b Vector) (*savedModel, error) {
+	var saved savedModel
+	if err := saved.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if err := saved.check(kind); err != nil {
+		return nil, err
+	}
+	savedB, err := saved.matrix("B")
+	if err != nil {
+		return nil, err
+	}
+	if !sameEntries(savedB, b) {
+		return nil, fmt.Errorf("saved %s is for a different b", kind)
+	}
+	return &saved, nil
+}
+
+// savedVectors gets the named vectors, checking that they're the shape
+// of like.
+func savedVectors(saved *savedModel, like Vector, names ...string) ([]Vector, error) {
+	vs := make([]Vector, len(names))
+	for k, name := range names {
+		v, err := saved.matrix(name)
+		if err != nil {
+			return nil, err
+		}
+		ins, outs := v.Shape()
+		if likeIns, likeOuts := like.Shape(); ins != likeIns || outs != likeOuts {
+			return nil, fmt.Errorf("saved %s has shape (%d, %d) but expected (%d, %d)", name, ins, outs, likeIns, likeOuts)
+		}
+		vs[k] = v
+	}
+	return vs, nil
+}
+
+func sameEntries(A, B Matrix) bool {
+	ins, outs := A.Shape()
+	if bIns, bOuts := B.Shape(); ins != bIns || outs != bOuts {
+		return false
+	}
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			if A.Get(i, o) != B.Get(i, o) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// vectorColumns puts vectors of dimension n side by side.
+func vectorColumns(vs []Vector, n int) Matrix {
+	A := NewArrayMatrix(len(vs), n)
+	for i, v := range vs {
+		CopyInto(v, Slice(A, i, i+1, 0, n))
+	}
+	return A
+}
+
+func columnVectors(A Matrix) []Vector {
+	ins, outs := A.Shape()
+	vs := make([]Vector, ins)
+	for i := range vs {
+		vs[i] = Copy(Slice(A, i, i+1, 0, outs))
+	}
+	return vs
+}
+
+func vectorFloats(v Matrix) []float64 {
+	_, dim := v.Shape()
+	fs := make([]float64, dim)
+	for d := range fs {
+		fs[d] = v.Get(0, d)
+	}
+	return fs
+}
+
+func boolNumber(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package linear
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func checkpointSystem() (Matrix, Vector) {
+	D := Difference1D(30)
+	A := Apply(Dual(D), D).(*CSR)
+	b := NewVector(30)
+	for d := 0; d < 30; d++ {
+		A.Set(d, d, A.Get(d, d)+0.1)
+		b.Set(0, d, float64(d%4))
+	}
+	return A, b
+}
+
+func TestCGSolverRestore(t *testing.T) {
+	A, b := checkpointSystem()
+	want, wantIterations := ConjugateGradient(MatrixOperator(A), b, 1e-12, 100)
+
+	s := NewCGSolver(MatrixOperator(A), b, 1e-12)
+	s.Run(5)
+	data, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := NewCGSolver(MatrixOperator(A), b, 1e-12)
+	if err := resumed.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	ExpectInt(5, resumed.Iterations(), t)
+	resumed.Run(100)
+
+	ExpectInt(wantIterations, resumed.Iterations(), t)
+	ExpectMatrix(want, resumed.X(), t)
+
+	other := NewVector(30)
+	if err := NewCGSolver(MatrixOperator(A), other, 1e-12).Restore(data); err == nil {
+		t.Error("expected an error restoring for a different b")
+	}
+	if err := NewGMRESSolver(MatrixOperator(A), b, 1e-12).Restore(data); err == nil {
+		t.Error("expected an error restoring the wrong kind")
+	}
+	if err := resumed.Restore(data[:len(data)-1]); err == nil {
+		t.Error("expected an error restoring a truncated snapshot")
+	}
+}
+
+func TestGMRESSolverRestore(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	A := randomMatrix(rng, 8, 8)
+	for d := 0; d < 8; d++ {
+		A.Set(d, d, A.Get(d, d)+4)
+	}
+	b := Copy(Slice(randomMatrix(rng, 1, 8), 0, 1, 0, 8))
+	want, wantIterations := GMRES(MatrixOperator(A), b, 1e-12, 20)
+
+	s := NewGMRESSolver(MatrixOperator(A), b, 1e-12)
+	s.Run(3)
+	data, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := NewGMRESSolver(MatrixOperator(A), b, 1e-12)
+	if err := resumed.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(s.X(), resumed.X(), t)
+	resumed.Run(20)
+
+	ExpectInt(wantIterations, resumed.Iterations(), t)
+	ExpectMatrix(want, resumed.X(), t)
+	ExpectMatrix(b, Apply(A, resumed.X()), t)
+
+	// A zero b is already solved.
+	zero := NewGMRESSolver(MatrixOperator(A), NewVector(8), 1e-12)
+	data, err = zero.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewGMRESSolver(MatrixOperator(A), NewVector(8), 1e-12).Restore(data); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLBFGSSolverRestore(t *testing.T) {
+	evaluations := 0
+	f := func(x Vector) (float64, Vector) {
+		evaluations++
+		return rosenbrock(x)
+	}
+	x0 := NewVector(2)
+	x0.Set(0, 0, -1.2)
+	x0.Set(0, 1, 1)
+	l := &LBFGS{Memory: 5, MaxIter: 200, GradTol: 1e-10}
+	want, wantIterations := l.Minimize(rosenbrock, x0)
+
+	s := l.Start(f, x0)
+	for k := 0; k < 10; k++ {
+		s.Step()
+	}
+	data, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := l.Start(f, x0)
+	before := evaluations
+	if err := resumed.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	ExpectInt(before, evaluations, t)
+	resumed.Run()
+
+	ExpectInt(wantIterations, resumed.Iterations(), t)
+	ExpectMatrix(want, resumed.X(), t)
+
+	// The same state gives the same bytes.
+	again, err := resumed.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := l.Start(f, x0)
+	if err := other.Restore(again); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := other.Snapshot(); !bytes.Equal(data, again) {
+		t.Error("expected the same snapshot after restoring")
+	}
+}
@@ -0,0 +1,50 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// DecomposeCholesky decomposes a symmetric positive definite A into
+// L*Dual(L) where L is lower triangular. It panics if A isn't positive
+// definite (to working precision).
+func DecomposeCholesky(A Matrix) Matrix {
+	ins, outs := A.Shape()
+	if ins != outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", ins, outs))
+	}
+	defer beginOp("Cholesky")()
+	// About n^3/6 multiply-adds, in the loops below.
+	countFlops(ins * ins * ins / 3)
+	L := NewArrayMatrix(ins, outs)
+	for j := 0; j < ins; j++ {
+		// The diagonal entry is whatever is left of A's diagonal after
+		// taking out the contribution of the previous columns.
+		d := A.Get(j, j)
+		for k := 0; k < j; k++ {
+			d -= L.Get(k, j) * L.Get(k, j)
+		}
+		if d <= 0 {
+			panic(fmt.Errorf("not positive definite at %d (%g)", j, d))
+		}
+		ljj := math.Sqrt(d)
+		L.Set(j, j, ljj)
+
+		for o := j + 1; o < outs; o++ {
+			s := A.Get(j, o)
+			for k := 0; k < j; k++ {
+				s -= L.Get(k, o) * L.Get(k, j)
+			}
+			L.Set(j, o, s/ljj)
+		}
+	}
+	return L
+}
+
+// SolveCholesky finds x such that L*Dual(L)*x = b, given the Cholesky
+// factor L from DecomposeCholesky.
+func SolveCholesky(L, b Matrix) Matrix {
+	defer beginOp("Solve")()
+	z := FindInputLowerTriangular(L, b)
+	return FindInputUpperTriangular(Dual(L), z)
+}
@@ -0,0 +1,57 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestDecomposeCholesky(t *testing.T) {
+	A := NewArrayMatrix(3, 3)
+	A.Set(0, 0, 4)
+	A.Set(1, 0, 12)
+	A.Set(2, 0, -16)
+	A.Set(0, 1, 12)
+	A.Set(1, 1, 37)
+	A.Set(2, 1, -43)
+	A.Set(0, 2, -16)
+	A.Set(1, 2, -43)
+	A.Set(2, 2, 98)
+
+	L := DecomposeCholesky(A)
+
+	ExpectFloat(2, L.Get(0, 0), t)
+	ExpectFloat(0, L.Get(1, 0), t)
+	ExpectFloat(0, L.Get(2, 0), t)
+	ExpectFloat(6, L.Get(0, 1), t)
+	ExpectFloat(1, L.Get(1, 1), t)
+	ExpectFloat(0, L.Get(2, 1), t)
+	ExpectFloat(-8, L.Get(0, 2), t)
+	ExpectFloat(5, L.Get(1, 2), t)
+	ExpectFloat(3, L.Get(2, 2), t)
+
+	b := NewVector(3)
+	b.Set(0, 0, 1)
+	b.Set(0, 1, 2)
+	b.Set(0, 2, 3)
+
+	x := SolveCholesky(L, b)
+	Ax := Apply(A, x)
+
+	ExpectFloat(1, Ax.Get(0, 0), t)
+	ExpectFloat(2, Ax.Get(0, 1), t)
+	ExpectFloat(3, Ax.Get(0, 2), t)
+}
+
+func TestDecomposeCholeskyNotPositiveDefinite(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 2)
+	A.Set(1, 1, 1)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	DecomposeCholesky(A)
+}
@@ -0,0 +1,184 @@
+// Command linear runs package linear's operations on matrices in files,
+// for use in shell pipelines:
+//
+//	linear [flags] multiply A B   A*B
+//	linear [flags] solve A B      X such that A*X = B, for a square A
+//	linear [flags] qr A           a factor of A = Q*R
+//	linear [flags] svd A          a factor of A = U*S*Dual(V)
+//	linear [flags] ols X y        theta minimizing |X*theta - y|
+//
+// Matrices are read as Matrix Market (.mtx), NumPy (.npy),
+// whitespace separated text (.txt) or otherwise CSV, with "-" reading
+// CSV from standard input. The result is written to standard output.
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/ornerylawn/linear"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "linear:", err)
+		if errors.Is(err, flag.ErrHelp) || errors.As(err, new(usageError)) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func run(args []string, stdin io.Reader, stdout io.Writer) (err error) {
+	flags := flag.NewFlagSet("linear", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	in := flags.String("in", "", "input format: csv, txt, mtx or npy (default from each file's extension)")
+	out := flags.String("out", "csv", "output format: csv, txt, mtx or npy")
+	header := flags.Bool("header", false, "skip a header line in CSV input")
+	factor := flags.String("factor", "", "factor to write: q or r for qr (default r), u, sigma or v for svd (default sigma)")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return usageError("no operation; expected multiply, solve, qr, svd or ols")
+	}
+	op, paths := args[0], args[1:]
+	arity := map[string]int{"multiply": 2, "solve": 2, "qr": 1, "svd": 1, "ols": 2}
+	n, ok := arity[op]
+	if !ok {
+		return usageError(fmt.Sprintf("unknown operation %q", op))
+	}
+	if len(paths) != n {
+		return usageError(fmt.Sprintf("%s takes %d matrices but got %d", op, n, len(paths)))
+	}
+	write, ok := writers[*out]
+	if !ok {
+		return usageError(fmt.Sprintf("unknown output format %q", *out))
+	}
+
+	var ms []linear.Matrix
+	for _, path := range paths {
+		A, err := readMatrix(path, *in, *header, stdin)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		ms = append(ms, A)
+	}
+
+	// Package linear panics with an error on bad input, like a
+	// singular matrix or mismatched shapes.
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		e, ok := r.(error)
+		if _, isRuntime := r.(runtime.Error); !ok || isRuntime {
+			panic(r)
+		}
+		err = fmt.Errorf("%s: %v", op, e)
+	}()
+	var result linear.Matrix
+	switch op {
+	case "multiply":
+		result = linear.Apply(ms[0], ms[1])
+	case "solve":
+		result = linear.FactorLU(ms[0]).Solve(ms[1])
+	case "qr":
+		Q, R := linear.DecomposeQR(ms[0])
+		result, err = pick(*factor, "r", map[string]linear.Matrix{"q": Q, "r": R})
+	case "svd":
+		U, sigma, V := linear.DecomposeSVD(ms[0])
+		result, err = pick(*factor, "sigma", map[string]linear.Matrix{"u": U, "sigma": sigma, "v": V})
+	case "ols":
+		result = linear.OrdinaryLeastSquares(ms[0], ms[1])
+	}
+	if err != nil {
+		return err
+	}
+	return write(stdout, result)
+}
+
+func pick(name, byDefault string, factors map[string]linear.Matrix) (linear.Matrix, error) {
+	if name == "" {
+		name = byDefault
+	}
+	A, ok := factors[name]
+	if !ok {
+		return nil, usageError(fmt.Sprintf("unknown factor %q", name))
+	}
+	return A, nil
+}
+
+func readMatrix(path, format string, header bool, stdin io.Reader) (linear.Matrix, error) {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(path), ".")
+		}
+	}
+	switch format {
+	case "mtx":
+		return linear.ReadMatrixMarket(r)
+	case "npy":
+		return linear.ReadNPY(r)
+	case "txt":
+		return linear.CollectRows(linear.NewTextRowStream(r))
+	}
+	return linear.CollectRows(linear.NewCSVRowStream(r, header))
+}
+
+var writers = map[string]func(io.Writer, linear.Matrix) error{
+	"csv": writeCSV,
+	"txt": writeText,
+	"mtx": linear.WriteMatrixMarket,
+	"npy": linear.WriteNPY,
+}
+
+func formatRow(A linear.Matrix, o int) []string {
+	ins, _ := A.Shape()
+	row := make([]string, ins)
+	for i := range row {
+		row[i] = strconv.FormatFloat(A.Get(i, o), 'g', -1, 64)
+	}
+	return row
+}
+
+func writeCSV(w io.Writer, A linear.Matrix) error {
+	cw := csv.NewWriter(w)
+	_, outs := A.Shape()
+	for o := 0; o < outs; o++ {
+		cw.Write(formatRow(A, o))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeText(w io.Writer, A linear.Matrix) error {
+	_, outs := A.Shape()
+	for o := 0; o < outs; o++ {
+		if _, err := fmt.Fprintln(w, strings.Join(formatRow(A, o), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
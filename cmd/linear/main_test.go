@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ornerylawn/linear"
+)
+
+func runString(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &out)
+	return out.String(), err
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func expectOutput(t *testing.T, expect, got string, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expect {
+		t.Errorf("expected %q but got %q", expect, got)
+	}
+}
+
+func TestMultiply(t *testing.T) {
+	B := writeFile(t, "b.txt", "1\n1\n")
+	got, err := runString(t, "1,2\n3,4\n", "multiply", "-", B)
+	expectOutput(t, "3\n7\n", got, err)
+}
+
+func TestSolve(t *testing.T) {
+	A := writeFile(t, "a.mtx", "%%MatrixMarket matrix coordinate real general\n2 2 3\n1 1 2\n1 2 1\n2 2 4\n")
+	got, err := runString(t, "x\n3\n8\n", "-header", "-out", "txt", "solve", A, "-")
+	expectOutput(t, "0.5\n2\n", got, err)
+
+	singular := writeFile(t, "s.csv", "1,2\n2,4\n")
+	if _, err := runString(t, "1\n1\n", "solve", singular, "-"); err == nil || !strings.Contains(err.Error(), "singular") {
+		t.Errorf("expected a singular error but got %v", err)
+	}
+}
+
+func TestOLS(t *testing.T) {
+	var b bytes.Buffer
+	if err := linear.WriteNPY(&b, linear.MatrixFromSlice([]float64{1, 0, 1, 1, 1, 2}, 2, 3, 2)); err != nil {
+		t.Fatal(err)
+	}
+	X := writeFile(t, "x.npy", b.String())
+	got, err := runString(t, "1\n3\n5\n", "ols", X, "-")
+	expectOutput(t, "1\n2\n", got, err)
+}
+
+func TestFactors(t *testing.T) {
+	got, err := runString(t, "3,0\n0,-4\n", "svd", "-")
+	expectOutput(t, "4\n3\n", got, err)
+
+	got, err = runString(t, "3,0\n0,-4\n", "-out", "npy", "qr", "-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	R, err := linear.ReadNPY(strings.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	linear.CheckUpperTriangular(R)
+
+	if _, err := runString(t, "1\n", "-factor", "x", "qr", "-"); err == nil {
+		t.Errorf("expected an error for an unknown factor")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"invert", "-"},
+		{"multiply", "-"},
+		{"-out", "xls", "qr", "-"},
+		{"-bogus", "qr", "-"},
+	} {
+		_, err := runString(t, "1\n", args...)
+		if _, ok := err.(usageError); !ok {
+			t.Errorf("expected a usage error for %q but got %v", args, err)
+		}
+	}
+}
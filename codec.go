@@ -0,0 +1,135 @@
+package linear
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// JSONMatrix wraps a Matrix so that it can be encoded as JSON, as
+// {"ins": 2, "outs": 3, "data": [...]} with the entries output (row)
+// by output. Decoding makes a new array Matrix.
+type JSONMatrix struct {
+	Matrix
+}
+
+type jsonMatrix struct {
+	Ins  int       `json:"ins"`
+	Outs int       `json:"outs"`
+	Data []float64 `json:"data"`
+}
+
+func (m JSONMatrix) MarshalJSON() ([]byte, error) {
+	if m.Matrix == nil {
+		return []byte("null"), nil
+	}
+	ins, outs := m.Shape()
+	j := jsonMatrix{ins, outs, make([]float64, 0, ins*outs)}
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			f := m.Get(i, o)
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("can't encode %v at (%d, %d) as JSON", f, i, o)
+			}
+			j.Data = append(j.Data, f)
+		}
+	}
+	return json.Marshal(j)
+}
+
+func (m *JSONMatrix) UnmarshalJSON(data []byte) error {
+	var j *jsonMatrix
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if j == nil {
+		m.Matrix = nil
+		return nil
+	}
+	if j.Ins < 0 || j.Outs < 0 {
+		return fmt.Errorf("%d entries for shape (%d, %d)", len(j.Data), j.Ins, j.Outs)
+	}
+	if size, ok := entryCount(uint64(j.Ins), uint64(j.Outs)); !ok || len(j.Data) != size {
+		return fmt.Errorf("%d entries for shape (%d, %d)", len(j.Data), j.Ins, j.Outs)
+	}
+	m.Matrix = MatrixFromSlice(j.Data, j.Ins, j.Outs, j.Ins)
+	return nil
+}
+
+// WriteMatrix writes A in a simple binary form: the number of inputs
+// and outputs as little endian uint32s, then the entries output (row) by
+// output as little endian float64s.
+func WriteMatrix(w io.Writer, A Matrix) error {
+	ins, outs := A.Shape()
+	if int(uint32(ins)) != ins || int(uint32(outs)) != outs {
+		return fmt.Errorf("shape (%d, %d) is too big to write", ins, outs)
+	}
+	buf := make([]byte, 8+8*ins*outs)
+	binary.LittleEndian.PutUint32(buf[0:], uint32(ins))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(outs))
+	p := buf[8:]
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			binary.LittleEndian.PutUint64(p, math.Float64bits(A.Get(i, o)))
+			p = p[8:]
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadMatrix reads a matrix written by WriteMatrix.
+func ReadMatrix(r io.Reader) (Matrix, error) {
+	var shape [8]byte
+	if _, err := io.ReadFull(r, shape[:]); err != nil {
+		return nil, err
+	}
+	ins := int(binary.LittleEndian.Uint32(shape[0:]))
+	outs := int(binary.LittleEndian.Uint32(shape[4:]))
+	size, ok := entryCount(uint64(ins), uint64(outs))
+	if !ok {
+		return nil, fmt.Errorf("shape (%d, %d) is too big", ins, outs)
+	}
+	// Read in chunks so a corrupt shape can't allocate more than the
+	// data that's actually there.
+	data := make([]float64, 0)
+	buf := make([]byte, 8*1024)
+	for remaining := size; remaining > 0; {
+		n := remaining
+		if n > len(buf)/8 {
+			n = len(buf) / 8
+		}
+		if _, err := io.ReadFull(r, buf[:8*n]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		for k := 0; k < n; k++ {
+			data = append(data, math.Float64frombits(binary.LittleEndian.Uint64(buf[8*k:])))
+		}
+		remaining -= n
+	}
+	if len(data) != size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return MatrixFromSlice(data, ins, outs, ins), nil
+}
+
+// entryCount returns the number of entries of an array with the given
+// dimensions, or false if it doesn't fit in an int. Readers check this
+// before trusting a shape from their input.
+func entryCount(dims ...uint64) (int, bool) {
+	size := uint64(1)
+	for _, n := range dims {
+		hi, lo := bits.Mul64(size, n)
+		if hi != 0 || lo > math.MaxInt {
+			return 0, false
+		}
+		size = lo
+	}
+	return int(size), true
+}
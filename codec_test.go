@@ -0,0 +1,69 @@
+package linear
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestJSONMatrix(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6}, 3, 2, 3)
+
+	data, err := json.Marshal(JSONMatrix{A})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"ins":3,"outs":2,"data":[1,2,3,4,5,6]}` {
+		t.Errorf("unexpected encoding %s", data)
+	}
+	var B JSONMatrix
+	if err := json.Unmarshal(data, &B); err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(A, B, t)
+
+	if err := json.Unmarshal([]byte(`{"ins":2,"outs":2,"data":[1]}`), &B); err == nil {
+		t.Errorf("expected an error for too few entries")
+	}
+	if err := json.Unmarshal([]byte(`{"ins":4294967296,"outs":4294967296,"data":[]}`), &B); err == nil {
+		t.Errorf("expected an error for a shape whose size overflows")
+	}
+	A.Set(0, 0, math.NaN())
+	if _, err := json.Marshal(JSONMatrix{A}); err == nil {
+		t.Errorf("expected an error for NaN")
+	}
+}
+
+func TestWriteReadMatrix(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, -2, math.Inf(1), 4e300, 5, 0}, 2, 3, 2)
+
+	var b bytes.Buffer
+	if err := WriteMatrix(&b, A); err != nil {
+		t.Fatal(err)
+	}
+	ExpectInt(8+6*8, b.Len(), t)
+	B, err := ReadMatrix(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, outs := B.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(3, outs, t)
+	for o := 0; o < 3; o++ {
+		for i := 0; i < 2; i++ {
+			if A.Get(i, o) != B.Get(i, o) {
+				t.Errorf("expected %v but got %v", A.Get(i, o), B.Get(i, o))
+			}
+		}
+	}
+
+	if _, err := ReadMatrix(bytes.NewReader(b.Bytes()[:20])); err == nil {
+		t.Errorf("expected an error for truncated data")
+	}
+	// A shape whose number of entries overflows an int.
+	huge := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	if _, err := ReadMatrix(bytes.NewReader(huge)); err == nil {
+		t.Errorf("expected an error for an overflowing shape")
+	}
+}
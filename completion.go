@@ -0,0 +1,79 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// CompleteMatrix fills in the entries of A where mask is zero with a
+// matrix of the given rank that agrees with A where mask is non-zero.
+// It starts the missing entries at the mean of the observed ones and
+// then alternates between replacing the whole matrix with its best
+// approximation of that rank (by SVD) and putting the observed entries
+// back, until the missing entries settle.
+func CompleteMatrix(A, mask Matrix, rank int) Matrix {
+	CheckSameShape(A, mask)
+	ins, outs := A.Shape()
+	if rank < 1 || rank > ins || rank > outs {
+		panic(fmt.Errorf("can't complete (%d, %d) with rank %d", ins, outs, rank))
+	}
+
+	mean, count := 0.0, 0
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			if mask.Get(i, o) != 0 {
+				mean += A.Get(i, o)
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		panic(fmt.Errorf("nothing is observed"))
+	}
+	mean /= float64(count)
+
+	Z := NewArrayMatrix(ins, outs)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			if mask.Get(i, o) != 0 {
+				Z.Set(i, o, A.Get(i, o))
+			} else {
+				Z.Set(i, o, mean)
+			}
+		}
+	}
+
+	for iter := 0; iter < 10000; iter++ {
+		low := truncateRank(Z, rank)
+		change, size := 0.0, 0.0
+		for o := 0; o < outs; o++ {
+			for i := 0; i < ins; i++ {
+				if mask.Get(i, o) != 0 {
+					continue
+				}
+				d := low.Get(i, o) - Z.Get(i, o)
+				change += d * d
+				size += low.Get(i, o) * low.Get(i, o)
+				Z.Set(i, o, low.Get(i, o))
+			}
+		}
+		if change <= 1e-24*size {
+			break
+		}
+	}
+	return Z
+}
+
+// truncateRank returns the closest matrix to A with the given rank, by
+// keeping only that many of its largest singular values.
+func truncateRank(A Matrix, rank int) Matrix {
+	U, sigma, V := DecomposeSVD(A)
+	_, outs := U.Shape()
+	_, vOuts := V.Shape()
+	US := Copy(Slice(U, 0, rank, 0, outs))
+	for i := 0; i < rank; i++ {
+		for o := 0; o < outs; o++ {
+			US.Set(i, o, US.Get(i, o)*sigma.Get(0, i))
+		}
+	}
+	return Apply(US, Dual(Slice(V, 0, rank, 0, vOuts)))
+}
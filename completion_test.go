@@ -0,0 +1,36 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompleteMatrix(t *testing.T) {
+	// A rank one matrix with a few entries missing.
+	A := NewArrayMatrix(4, 5)
+	mask := NewArrayMatrix(4, 5)
+	for o := 0; o < 5; o++ {
+		for i := 0; i < 4; i++ {
+			A.Set(i, o, float64((i+1)*(o+2)))
+			mask.Set(i, o, 1)
+		}
+	}
+	missing := [][2]int{{0, 0}, {3, 1}, {1, 4}, {2, 2}}
+	for _, m := range missing {
+		mask.Set(m[0], m[1], 0)
+	}
+	observed := Copy(A)
+	for _, m := range missing {
+		observed.Set(m[0], m[1], -100)
+	}
+
+	Z := CompleteMatrix(observed, mask, 1)
+
+	for o := 0; o < 5; o++ {
+		for i := 0; i < 4; i++ {
+			if math.Abs(Z.Get(i, o)-A.Get(i, o)) > 1e-6 {
+				t.Errorf("(%d, %d) expected %f but got %f", i, o, A.Get(i, o), Z.Get(i, o))
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// PaddingMode says which positions of a kernel over a matrix count
+// towards a 2D convolution or correlation.
+type PaddingMode int
+
+const (
+	// PadValid only uses positions where the kernel fits entirely
+	// inside the matrix.
+	PadValid PaddingMode = iota
+	// PadSame pads with zeros so that, with stride 1, the result is
+	// the same shape as the matrix with the kernel centered on each
+	// entry.
+	PadSame
+	// PadFull pads with zeros so that every position where the kernel
+	// overlaps the matrix at all is used.
+	PadFull
+)
+
+// fftKernelEntries is the kernel size at which the FFT becomes cheaper
+// than direct loops.
+const fftKernelEntries = 64
+
+// Correlate2D slides the kernel over A, treating inputs as columns and
+// outputs as rows of an image, and takes the sum of the products of
+// the overlapping entries at each position. This is what deep learning
+// calls convolution.
+func Correlate2D(A, kernel Matrix, pad PaddingMode) Matrix {
+	return Correlate2DStrided(A, kernel, pad, 1)
+}
+
+// Convolve2D is Correlate2D with the kernel flipped in both
+// directions.
+func Convolve2D(A, kernel Matrix, pad PaddingMode) Matrix {
+	return Convolve2DStrided(A, kernel, pad, 1)
+}
+
+// Convolve2DStrided is Convolve2D but only at every stride'th position
+// in each direction.
+func Convolve2DStrided(A, kernel Matrix, pad PaddingMode, stride int) Matrix {
+	kIns, kOuts := kernel.Shape()
+	flipped := NewArrayMatrix(kIns, kOuts)
+	for o := 0; o < kOuts; o++ {
+		for i := 0; i < kIns; i++ {
+			flipped.Set(kIns-1-i, kOuts-1-o, kernel.Get(i, o))
+		}
+	}
+	return Correlate2DStrided(A, flipped, pad, stride)
+}
+
+// Correlate2DStrided is Correlate2D but only at every stride'th
+// position in each direction. Large kernels are done by FFT.
+func Correlate2DStrided(A, kernel Matrix, pad PaddingMode, stride int) Matrix {
+	ins, outs := A.Shape()
+	kIns, kOuts := kernel.Shape()
+	if stride < 1 {
+		panic(fmt.Errorf("stride must be positive but got %d", stride))
+	}
+	inLo, resIns := paddedRange(ins, kIns, pad, stride)
+	outLo, resOuts := paddedRange(outs, kOuts, pad, stride)
+	if resIns <= 0 || resOuts <= 0 {
+		panic(fmt.Errorf("kernel (%d, %d) doesn't fit in (%d, %d)", kIns, kOuts, ins, outs))
+	}
+	if kIns*kOuts >= fftKernelEntries {
+		return correlateFFT(A, kernel, inLo, outLo, resIns, resOuts, stride)
+	}
+
+	res := NewArrayMatrix(resIns, resOuts)
+	for o := 0; o < resOuts; o++ {
+		for i := 0; i < resIns; i++ {
+			// Where the top left of the kernel lands on A.
+			ai, ao := i*stride-inLo, o*stride-outLo
+			sum := 0.0
+			for ko := 0; ko < kOuts; ko++ {
+				if ao+ko < 0 || ao+ko >= outs {
+					continue
+				}
+				for ki := 0; ki < kIns; ki++ {
+					if ai+ki < 0 || ai+ki >= ins {
+						continue
+					}
+					sum += A.Get(ai+ki, ao+ko) * kernel.Get(ki, ko)
+				}
+			}
+			res.Set(i, o, sum)
+		}
+	}
+	return res
+}
+
+// paddedRange returns how far before the start of a dimension of size
+// n the kernel of size k first lands, and how many positions there
+// are.
+func paddedRange(n, k int, pad PaddingMode, stride int) (lo, count int) {
+	switch pad {
+	case PadValid:
+		// Division truncates towards zero, so a kernel a little bigger
+		// than n would otherwise still get a position.
+		if k > n {
+			return 0, 0
+		}
+		return 0, (n-k)/stride + 1
+	case PadSame:
+		return (k - 1) / 2, (n-1)/stride + 1
+	case PadFull:
+		return k - 1, (n+k-2)/stride + 1
+	}
+	panic(fmt.Errorf("unknown padding mode %d", pad))
+}
+
+// correlateFFT computes the full correlation as a product of 2D
+// discrete Fourier transforms and then picks out the positions asked
+// for.
+func correlateFFT(A, kernel Matrix, inLo, outLo, resIns, resOuts, stride int) Matrix {
+	ins, outs := A.Shape()
+	kIns, kOuts := kernel.Shape()
+	w := nextPowerOfTwo(ins + kIns - 1)
+	h := nextPowerOfTwo(outs + kOuts - 1)
+
+	// Correlating with the kernel is convolving with it flipped.
+	a := make([]complex128, w*h)
+	k := make([]complex128, w*h)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			a[o*w+i] = complex(A.Get(i, o), 0)
+		}
+	}
+	for o := 0; o < kOuts; o++ {
+		for i := 0; i < kIns; i++ {
+			k[(kOuts-1-o)*w+kIns-1-i] = complex(kernel.Get(i, o), 0)
+		}
+	}
+	fft2D(a, w, h, false)
+	fft2D(k, w, h, false)
+	for j := range a {
+		a[j] *= k[j]
+	}
+	fft2D(a, w, h, true)
+
+	// Position (ai, ao) of the top left of the kernel on A is at
+	// (ai + kIns-1, ao + kOuts-1) of the full convolution.
+	res := NewArrayMatrix(resIns, resOuts)
+	for o := 0; o < resOuts; o++ {
+		for i := 0; i < resIns; i++ {
+			fi := i*stride - inLo + kIns - 1
+			fo := o*stride - outLo + kOuts - 1
+			res.Set(i, o, real(a[fo*w+fi]))
+		}
+	}
+	return res
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// fft2D transforms the row-major w by h array in place, both
+// dimensions being powers of two. The inverse includes the 1/(w*h)
+// scaling.
+func fft2D(a []complex128, w, h int, inverse bool) {
+	for o := 0; o < h; o++ {
+		fft(a[o*w:(o+1)*w], inverse)
+	}
+	col := make([]complex128, h)
+	for i := 0; i < w; i++ {
+		for o := 0; o < h; o++ {
+			col[o] = a[o*w+i]
+		}
+		fft(col, inverse)
+		for o := 0; o < h; o++ {
+			a[o*w+i] = col[o]
+		}
+	}
+	if inverse {
+		scale := complex(1/float64(w*h), 0)
+		for j := range a {
+			a[j] *= scale
+		}
+	}
+}
+
+// fft is the iterative radix-2 Cooley-Tukey transform, in place and
+// without scaling.
+func fft(a []complex128, inverse bool) {
+	n := len(a)
+	// Bit reversal permutation.
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, sign*2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for j := 0; j < size/2; j++ {
+				u := a[start+j]
+				v := a[start+j+size/2] * w
+				a[start+j] = u + v
+				a[start+j+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCorrelate2D(t *testing.T) {
+	A := NewArrayMatrix(3, 3)
+	for o := 0; o < 3; o++ {
+		for i := 0; i < 3; i++ {
+			A.Set(i, o, float64(o*3+i+1))
+		}
+	}
+
+	K := NewArrayMatrix(2, 2)
+	K.Set(0, 0, 1)
+	K.Set(1, 1, -1)
+
+	C := Correlate2D(A, K, PadValid)
+
+	ins, outs := C.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(2, outs, t)
+	for o := 0; o < 2; o++ {
+		for i := 0; i < 2; i++ {
+			ExpectFloat(-4, C.Get(i, o), t)
+		}
+	}
+
+	C = Correlate2D(A, K, PadFull)
+
+	ins, outs = C.Shape()
+	ExpectInt(4, ins, t)
+	ExpectInt(4, outs, t)
+	ExpectFloat(-1, C.Get(0, 0), t)
+	ExpectFloat(9, C.Get(3, 3), t)
+
+	C = Correlate2D(A, K, PadSame)
+
+	ins, outs = C.Shape()
+	ExpectInt(3, ins, t)
+	ExpectInt(3, outs, t)
+	ExpectFloat(-4, C.Get(0, 0), t)
+	ExpectFloat(9, C.Get(2, 2), t)
+
+	C = Correlate2DStrided(A, K, PadFull, 3)
+
+	ins, outs = C.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(2, outs, t)
+	ExpectFloat(-1, C.Get(0, 0), t)
+	ExpectFloat(9, C.Get(1, 1), t)
+}
+
+func TestConvolve2D(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 3)
+	A.Set(1, 1, 4)
+
+	K := NewArrayMatrix(2, 1)
+	K.Set(0, 0, 1)
+	K.Set(1, 0, 10)
+
+	C := Convolve2D(A, K, PadValid)
+
+	ins, outs := C.Shape()
+	ExpectInt(1, ins, t)
+	ExpectInt(2, outs, t)
+	ExpectFloat(12, C.Get(0, 0), t)
+	ExpectFloat(34, C.Get(0, 1), t)
+}
+
+func TestCorrelate2DStridedTooBig(t *testing.T) {
+	// A 3 wide kernel doesn't fit in 2, whatever the stride.
+	A := NewArrayMatrix(2, 2)
+	K := NewArrayMatrix(3, 1)
+	expectPanic(t, func() { Correlate2DStrided(A, K, PadValid, 2) })
+}
+
+func TestCorrelate2DFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	A := NewArrayMatrix(20, 13)
+	for o := 0; o < 13; o++ {
+		for i := 0; i < 20; i++ {
+			A.Set(i, o, rng.NormFloat64())
+		}
+	}
+	K := NewArrayMatrix(9, 8)
+	for o := 0; o < 8; o++ {
+		for i := 0; i < 9; i++ {
+			K.Set(i, o, rng.NormFloat64())
+		}
+	}
+
+	for _, pad := range []PaddingMode{PadValid, PadSame, PadFull} {
+		for _, stride := range []int{1, 2} {
+			C := Correlate2DStrided(A, K, pad, stride)
+			D := correlateDirect(A, K, pad, stride)
+
+			ins, outs := C.Shape()
+			dIns, dOuts := D.Shape()
+			ExpectInt(dIns, ins, t)
+			ExpectInt(dOuts, outs, t)
+			for o := 0; o < outs; o++ {
+				for i := 0; i < ins; i++ {
+					ExpectFloat(D.Get(i, o), C.Get(i, o), t)
+				}
+			}
+		}
+	}
+}
+
+// correlateDirect is Correlate2DStrided without the FFT, however big
+// the kernel.
+func correlateDirect(A, K Matrix, pad PaddingMode, stride int) Matrix {
+	ins, outs := A.Shape()
+	kIns, kOuts := K.Shape()
+	inLo, resIns := paddedRange(ins, kIns, pad, stride)
+	outLo, resOuts := paddedRange(outs, kOuts, pad, stride)
+	res := NewArrayMatrix(resIns, resOuts)
+	for o := 0; o < resOuts; o++ {
+		for i := 0; i < resIns; i++ {
+			for ko := 0; ko < kOuts; ko++ {
+				for ki := 0; ki < kIns; ki++ {
+					ai, ao := i*stride-inLo+ki, o*stride-outLo+ko
+					if ai >= 0 && ai < ins && ao >= 0 && ao < outs {
+						res.Set(i, o, res.Get(i, o)+A.Get(ai, ao)*K.Get(ki, ko))
+					}
+				}
+			}
+		}
+	}
+	return res
+}
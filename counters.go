@@ -0,0 +1,135 @@
+package linear
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// OpCounts is how much work calls to one high-level operation did.
+type OpCounts struct {
+	Calls int64
+	// Flops counts floating point additions and multiplications in the
+	// main loops, so a multiply-add is 2.
+	Flops int64
+	// Allocs counts new matrices and AllocEntries their total number
+	// of entries.
+	Allocs       int64
+	AllocEntries int64
+}
+
+// Counts maps the name of each operation ("QR", "Solve", "Multiply"
+// and so on) to how much work it did. Work done outside of any named
+// operation is under "Other". When operations nest, like the QR
+// decomposition inside a least squares fit, the work is attributed to
+// the outermost one but each still counts as a call.
+type Counts map[string]OpCounts
+
+// Ops returns the names of the operations in alphabetical order.
+func (c Counts) Ops() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	// counting is checked without the lock on every allocation and
+	// product, so it's atomic; countsMu guards the rest.
+	counting  atomic.Bool
+	countsMu  sync.Mutex
+	counts    = Counts{}
+	currentOp string
+)
+
+// EnableCounters turns counting on or off. Counting is off to begin
+// with, and costs a little when on. The counts are global, so they only
+// make sense when one goroutine at a time is calling into the package.
+func EnableCounters(on bool) {
+	counting.Store(on)
+}
+
+// ResetCounters forgets everything counted so far.
+func ResetCounters() {
+	countsMu.Lock()
+	defer countsMu.Unlock()
+	counts = Counts{}
+}
+
+// ReadCounters returns a copy of everything counted so far.
+func ReadCounters() Counts {
+	countsMu.Lock()
+	defer countsMu.Unlock()
+	c := make(Counts, len(counts))
+	for name, n := range counts {
+		c[name] = n
+	}
+	return c
+}
+
+// beginOp counts a call to the named operation, which the work is
+// attributed to until the returned function is called unless an outer
+// operation is already running. With a logger set, it also logs how
+// long the operation took.
+func beginOp(name string) func() {
+	if logger == nil {
+		return countOp(name)
+	}
+	endCount, endLog := countOp(name), logOp(name)
+	return func() {
+		endCount()
+		endLog()
+	}
+}
+
+func countOp(name string) func() {
+	if !counting.Load() {
+		return func() {}
+	}
+	countsMu.Lock()
+	defer countsMu.Unlock()
+	n := counts[name]
+	n.Calls++
+	counts[name] = n
+	if currentOp != "" {
+		return func() {}
+	}
+	currentOp = name
+	return func() {
+		countsMu.Lock()
+		defer countsMu.Unlock()
+		currentOp = ""
+	}
+}
+
+func countFlops(flops int) {
+	if !counting.Load() {
+		return
+	}
+	countsMu.Lock()
+	defer countsMu.Unlock()
+	n := counts[opName()]
+	n.Flops += int64(flops)
+	counts[opName()] = n
+}
+
+func countAlloc(entries int) {
+	if !counting.Load() {
+		return
+	}
+	countsMu.Lock()
+	defer countsMu.Unlock()
+	n := counts[opName()]
+	n.Allocs++
+	n.AllocEntries += int64(entries)
+	counts[opName()] = n
+}
+
+func opName() string {
+	if currentOp == "" {
+		return "Other"
+	}
+	return currentOp
+}
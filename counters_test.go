@@ -0,0 +1,88 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestCounters(t *testing.T) {
+	EnableCounters(true)
+	defer EnableCounters(false)
+	ResetCounters()
+
+	A := NewArrayMatrix(3, 4)
+	B := NewArrayMatrix(2, 3)
+	Compose(B, A)
+
+	c := ReadCounters()
+
+	ExpectInt(1, int(c["Multiply"].Calls), t)
+	ExpectInt(2*2*3*4, int(c["Multiply"].Flops), t)
+	// The result is allocated by Compose before ComposeInto starts the
+	// operation.
+	ExpectInt(3, int(c["Other"].Allocs), t)
+	ExpectInt(3*4+2*3+2*4, int(c["Other"].AllocEntries), t)
+
+	ResetCounters()
+	X := NewArrayMatrix(2, 3)
+	X.Set(0, 0, 1)
+	X.Set(0, 1, 1)
+	X.Set(1, 1, 2)
+	X.Set(0, 2, -2)
+	X.Set(1, 2, 1)
+	y := NewArrayMatrix(1, 3)
+	OrdinaryLeastSquares(X, y)
+
+	c = ReadCounters()
+
+	ExpectInt(1, int(c["LeastSquares"].Calls), t)
+	ExpectInt(1, int(c["QR"].Calls), t)
+	// Solving with the factorization, then back substitution.
+	ExpectInt(2, int(c["Solve"].Calls), t)
+	// Nested operations are attributed to the outermost.
+	ExpectInt(0, int(c["QR"].Flops), t)
+	if c["LeastSquares"].Flops == 0 || c["LeastSquares"].Allocs == 0 {
+		t.Errorf("expected least squares to do some work but got %+v", c["LeastSquares"])
+	}
+	ops := c.Ops()
+	ExpectInt(len(c), len(ops), t)
+}
+
+func TestCountersSparse(t *testing.T) {
+	EnableCounters(true)
+	defer EnableCounters(false)
+	ResetCounters()
+
+	// Two entries of B each pick out a row of A with one entry.
+	A := NewCSRFromEntries(2, 2, []SparseEntry{{0, 0, 1}, {1, 1, 2}})
+	B := NewCSRFromEntries(2, 1, []SparseEntry{{0, 0, 3}, {1, 0, 4}})
+	Compose(A, B)
+	Apply(B, A)
+
+	c := ReadCounters()
+	ExpectInt(2, int(c["Multiply"].Calls), t)
+	ExpectInt(2*2*2, int(c["Multiply"].Flops), t)
+	ExpectInt(0, int(c["Other"].Flops), t)
+}
+
+func TestCountersDisabled(t *testing.T) {
+	ResetCounters()
+	Compose(NewArrayMatrix(2, 2), NewArrayMatrix(2, 2))
+	ExpectInt(0, len(ReadCounters()), t)
+}
+
+func TestCountersConcurrentToggle(t *testing.T) {
+	// Turning counting on and off while another goroutine works must be
+	// safe (go test -race checks this).
+	done := make(chan bool)
+	go func() {
+		for k := 0; k < 100; k++ {
+			Compose(NewArrayMatrix(2, 2), NewArrayMatrix(2, 2))
+		}
+		done <- true
+	}()
+	for k := 0; k < 100; k++ {
+		EnableCounters(k%2 == 0)
+	}
+	<-done
+	EnableCounters(false)
+}
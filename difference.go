@@ -0,0 +1,69 @@
+package linear
+
+import (
+	"math"
+)
+
+// Difference1D returns the forward difference operator on vectors of
+// dimension n, whose output o is x[o+1] - x[o].
+func Difference1D(n int) *CSR {
+	var entries []SparseEntry
+	for o := 0; o+1 < n; o++ {
+		entries = append(entries, SparseEntry{o, o, -1}, SparseEntry{o + 1, o, 1})
+	}
+	return NewCSRFromEntries(n, n-1, entries)
+}
+
+// Difference2D returns the forward difference operators across (Dx)
+// and down (Dy) an image with the given width and height, flattened
+// into a vector one row after another.
+func Difference2D(width, height int) (Dx, Dy *CSR) {
+	var xEntries, yEntries []SparseEntry
+	for y := 0; y < height; y++ {
+		for x := 0; x+1 < width; x++ {
+			o := y*(width-1) + x
+			xEntries = append(xEntries,
+				SparseEntry{y*width + x, o, -1},
+				SparseEntry{y*width + x + 1, o, 1})
+		}
+	}
+	for y := 0; y+1 < height; y++ {
+		for x := 0; x < width; x++ {
+			o := y*width + x
+			yEntries = append(yEntries,
+				SparseEntry{y*width + x, o, -1},
+				SparseEntry{(y+1)*width + x, o, 1})
+		}
+	}
+	n := width * height
+	Dx = NewCSRFromEntries(n, height*(width-1), xEntries)
+	Dy = NewCSRFromEntries(n, (height-1)*width, yEntries)
+	return Dx, Dy
+}
+
+// TotalVariation returns the sum over the image of the size of its
+// forward differences, with the image's inputs (columns) as x and
+// outputs (rows) as y. Isotropic uses the L2 norm of each gradient and
+// anisotropic the L1 norm, which favors edges along the axes.
+func TotalVariation(image Matrix, isotropic bool) float64 {
+	width, height := image.Shape()
+	tv := 0.0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Differences past the edge are zero.
+			dx, dy := 0.0, 0.0
+			if x+1 < width {
+				dx = image.Get(x+1, y) - image.Get(x, y)
+			}
+			if y+1 < height {
+				dy = image.Get(x, y+1) - image.Get(x, y)
+			}
+			if isotropic {
+				tv += math.Hypot(dx, dy)
+			} else {
+				tv += math.Abs(dx) + math.Abs(dy)
+			}
+		}
+	}
+	return tv
+}
@@ -0,0 +1,63 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestDifference1D(t *testing.T) {
+	D := Difference1D(4)
+
+	ins, outs := D.Shape()
+	ExpectInt(4, ins, t)
+	ExpectInt(3, outs, t)
+
+	x := NewVector(4)
+	x.Set(0, 0, 1)
+	x.Set(0, 1, 4)
+	x.Set(0, 2, 9)
+	x.Set(0, 3, 16)
+
+	dx := Apply(D, x)
+
+	ExpectFloat(3, dx.Get(0, 0), t)
+	ExpectFloat(5, dx.Get(0, 1), t)
+	ExpectFloat(7, dx.Get(0, 2), t)
+}
+
+func TestDifference2D(t *testing.T) {
+	// A 3 wide and 2 high image, x + 10*y.
+	v := NewVector(6)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			v.Set(0, y*3+x, float64(x+10*y))
+		}
+	}
+
+	Dx, Dy := Difference2D(3, 2)
+
+	_, xOuts := Dx.Shape()
+	_, yOuts := Dy.Shape()
+	ExpectInt(4, xOuts, t)
+	ExpectInt(3, yOuts, t)
+
+	dx := Apply(Dx, v)
+	dy := Apply(Dy, v)
+	for o := 0; o < xOuts; o++ {
+		ExpectFloat(1, dx.Get(0, o), t)
+	}
+	for o := 0; o < yOuts; o++ {
+		ExpectFloat(10, dy.Get(0, o), t)
+	}
+}
+
+func TestTotalVariation(t *testing.T) {
+	image := NewArrayMatrix(2, 2)
+	image.Set(1, 0, 3)
+	image.Set(0, 1, 4)
+	image.Set(1, 1, 3)
+
+	// The gradient at the top left is (3, 4) and at the bottom left
+	// it's (-1, 0). Everything else is flat.
+	ExpectFloat(3+4+1, TotalVariation(image, false), t)
+	ExpectFloat(5+1, TotalVariation(image, true), t)
+}
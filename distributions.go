@@ -0,0 +1,99 @@
+package linear
+
+import (
+	"math"
+)
+
+// regularizedIncompleteBeta is I_x(a, b), the CDF of the beta
+// distribution, by the continued fraction of Numerical Recipes, which
+// converges quickly for x < (a+1)/(a+b+2) and otherwise for the
+// reflection I_x(a, b) = 1 - I_{1-x}(b, a).
+func regularizedIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lbeta, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	front := math.Exp(lbeta - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+}
+
+// betaContinuedFraction evaluates the continued fraction for the
+// incomplete beta function by the modified Lentz method.
+func betaContinuedFraction(a, b, x float64) float64 {
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= 300; m++ {
+		fm := float64(m)
+		for _, num := range [2]float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < 1e-15 {
+			break
+		}
+	}
+	return h
+}
+
+// fSurvival is the probability that an F distributed variable with d1
+// and d2 degrees of freedom exceeds f.
+func fSurvival(f, d1, d2 float64) float64 {
+	if f <= 0 {
+		return 1
+	}
+	return regularizedIncompleteBeta(d2/2, d1/2, d2/(d2+d1*f))
+}
+
+// studentTCDF is the CDF of Student's t distribution with nu degrees of
+// freedom.
+func studentTCDF(t, nu float64) float64 {
+	tail := regularizedIncompleteBeta(nu/2, 0.5, nu/(nu+t*t)) / 2
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// studentTQuantile is the t with studentTCDF(t, nu) = p, by bisection.
+func studentTQuantile(p, nu float64) float64 {
+	lo, hi := -1.0, 1.0
+	for studentTCDF(lo, nu) > p {
+		lo *= 2
+	}
+	for studentTCDF(hi, nu) < p {
+		hi *= 2
+	}
+	for k := 0; k < 200 && hi-lo > 1e-12*math.Max(1, math.Abs(hi)); k++ {
+		mid := (lo + hi) / 2
+		if studentTCDF(mid, nu) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,29 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegularizedIncompleteBeta(t *testing.T) {
+	// I_x(1, 1) is uniform, I_x(a, 1) = x^a and I_x(1, b) = 1-(1-x)^b.
+	ExpectFloat(0.3, regularizedIncompleteBeta(1, 1, 0.3), t)
+	ExpectFloat(math.Pow(0.4, 3), regularizedIncompleteBeta(3, 1, 0.4), t)
+	ExpectFloat(1-math.Pow(0.2, 5), regularizedIncompleteBeta(1, 5, 0.8), t)
+	ExpectFloat(0.5, regularizedIncompleteBeta(7, 7, 0.5), t)
+}
+
+func TestFSurvival(t *testing.T) {
+	// The 5% critical value of F(2, 10) is about 4.1028.
+	ExpectFloat(0.05, math.Round(fSurvival(4.102821, 2, 10)*1e5)/1e5, t)
+	ExpectFloat(1, fSurvival(0, 3, 4), t)
+}
+
+func TestStudentT(t *testing.T) {
+	ExpectFloat(0.5, studentTCDF(0, 5), t)
+	// With one degree of freedom it's Cauchy.
+	ExpectFloat(0.75, studentTCDF(1, 1), t)
+	if q := studentTQuantile(0.975, 10); math.Abs(q-2.228139) > 1e-5 {
+		t.Errorf("expected 2.228139 but got %v", q)
+	}
+}
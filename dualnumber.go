@@ -0,0 +1,187 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// DualNumber is Re + Eps*e where e*e = 0. Carrying a derivative in Eps
+// through any computation gives the exact derivative of the result in
+// its Eps, which is forward-mode automatic differentiation.
+type DualNumber struct {
+	Re, Eps float64
+}
+
+// Constant returns the dual number for f, which has zero derivative.
+func Constant(f float64) DualNumber { return DualNumber{f, 0} }
+
+func (a DualNumber) Add(b DualNumber) DualNumber { return DualNumber{a.Re + b.Re, a.Eps + b.Eps} }
+func (a DualNumber) Sub(b DualNumber) DualNumber { return DualNumber{a.Re - b.Re, a.Eps - b.Eps} }
+func (a DualNumber) Mul(b DualNumber) DualNumber {
+	return DualNumber{a.Re * b.Re, a.Re*b.Eps + a.Eps*b.Re}
+}
+func (a DualNumber) Div(b DualNumber) DualNumber {
+	return DualNumber{a.Re / b.Re, (a.Eps*b.Re - a.Re*b.Eps) / (b.Re * b.Re)}
+}
+func (a DualNumber) Scale(s float64) DualNumber { return DualNumber{a.Re * s, a.Eps * s} }
+
+// Sqrt returns the square root and its derivative, which is +Inf at
+// zero.
+func (a DualNumber) Sqrt() DualNumber {
+	r := math.Sqrt(a.Re)
+	return DualNumber{r, a.Eps / (2 * r)}
+}
+
+func (a DualNumber) Exp() DualNumber {
+	e := math.Exp(a.Re)
+	return DualNumber{e, a.Eps * e}
+}
+
+func (a DualNumber) Log() DualNumber { return DualNumber{math.Log(a.Re), a.Eps / a.Re} }
+
+// DualNumberMatrix is a Matrix of dual numbers. As a Matrix it reads
+// the real parts and sets constants, while GetDual and SetDual work
+// with the derivatives too.
+type DualNumberMatrix struct {
+	ins, outs int
+	// entries are by output (row), then input.
+	entries []DualNumber
+}
+
+// NewDualNumberMatrix makes a new zero DualNumberMatrix with the given
+// shape.
+func NewDualNumberMatrix(ins, outs int) *DualNumberMatrix {
+	if ins < 0 || outs < 0 {
+		panic(fmt.Errorf("invalid shape (%d, %d)", ins, outs))
+	}
+	return &DualNumberMatrix{ins: ins, outs: outs, entries: make([]DualNumber, ins*outs)}
+}
+
+// DualNumberMatrixFrom makes a DualNumberMatrix of constants from A.
+func DualNumberMatrixFrom(A Matrix) *DualNumberMatrix {
+	ins, outs := A.Shape()
+	m := NewDualNumberMatrix(ins, outs)
+	CopyInto(A, m)
+	return m
+}
+
+// Seed makes a DualNumberMatrix with the real parts from A and the
+// derivatives from direction, the starting point for differentiating
+// along direction.
+func Seed(A, direction Matrix) *DualNumberMatrix {
+	CheckSameShape(A, direction)
+	m := DualNumberMatrixFrom(A)
+	for o := 0; o < m.outs; o++ {
+		for i := 0; i < m.ins; i++ {
+			m.entries[o*m.ins+i].Eps = direction.Get(i, o)
+		}
+	}
+	return m
+}
+
+func (m *DualNumberMatrix) Shape() (ins, outs int) { return m.ins, m.outs }
+
+func (m *DualNumberMatrix) Get(in, out int) float64 { return m.GetDual(in, out).Re }
+
+func (m *DualNumberMatrix) Set(in, out int, value float64) { m.SetDual(in, out, Constant(value)) }
+
+func (m *DualNumberMatrix) GetDual(in, out int) DualNumber {
+	m.checkBounds(in, out)
+	return m.entries[out*m.ins+in]
+}
+
+func (m *DualNumberMatrix) SetDual(in, out int, value DualNumber) {
+	m.checkBounds(in, out)
+	m.entries[out*m.ins+in] = value
+}
+
+func (m *DualNumberMatrix) checkBounds(in, out int) {
+	if in < 0 || in >= m.ins || out < 0 || out >= m.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds for shape (%d, %d)", in, out, m.ins, m.outs))
+	}
+}
+
+// Derivative returns the matrix of the derivative parts.
+func (m *DualNumberMatrix) Derivative() Matrix {
+	D := NewArrayMatrix(m.ins, m.outs)
+	for o := 0; o < m.outs; o++ {
+		for i := 0; i < m.ins; i++ {
+			D.Set(i, o, m.entries[o*m.ins+i].Eps)
+		}
+	}
+	return D
+}
+
+// ApplyDualNumbers returns A*X.
+func ApplyDualNumbers(A, X *DualNumberMatrix) *DualNumberMatrix {
+	if A.ins != X.outs {
+		panic(fmt.Errorf("dimension mismatch %d vs %d", X.outs, A.ins))
+	}
+	Y := NewDualNumberMatrix(X.ins, A.outs)
+	for o := 0; o < A.outs; o++ {
+		for i := 0; i < X.ins; i++ {
+			var s DualNumber
+			for k := 0; k < A.ins; k++ {
+				s = s.Add(A.GetDual(k, o).Mul(X.GetDual(i, k)))
+			}
+			Y.SetDual(i, o, s)
+		}
+	}
+	return Y
+}
+
+// AddScaledDualNumbers returns A + s*B.
+func AddScaledDualNumbers(A *DualNumberMatrix, s float64, B *DualNumberMatrix) *DualNumberMatrix {
+	CheckSameShape(A, B)
+	C := NewDualNumberMatrix(A.ins, A.outs)
+	for j := range C.entries {
+		C.entries[j] = A.entries[j].Add(B.entries[j].Scale(s))
+	}
+	return C
+}
+
+// DualNumberSumSquares returns the sum of the squares of the entries,
+// the squared Frobenius (or for a vector L2) norm.
+func DualNumberSumSquares(A *DualNumberMatrix) DualNumber {
+	var s DualNumber
+	for _, e := range A.entries {
+		s = s.Add(e.Mul(e))
+	}
+	return s
+}
+
+// DualNumberQuadraticForm returns Dual(x)*A*x for the vector x.
+func DualNumberQuadraticForm(x, A *DualNumberMatrix) DualNumber {
+	CheckVector(x)
+	Ax := ApplyDualNumbers(A, x)
+	var s DualNumber
+	for d := 0; d < x.outs; d++ {
+		s = s.Add(x.GetDual(0, d).Mul(Ax.GetDual(0, d)))
+	}
+	return s
+}
+
+// DirectionalDerivative returns f(x) and the derivative of f at x
+// along direction, exactly, from one evaluation of f on dual numbers.
+func DirectionalDerivative(f func(x *DualNumberMatrix) DualNumber, x, direction Matrix) (value, derivative float64) {
+	y := f(Seed(x, direction))
+	return y.Re, y.Eps
+}
+
+// ForwardGradient returns the gradient of f at x, with the same shape
+// as x, from one evaluation of f per entry of x. That's cheap for a few
+// parameters; reverse mode wins for many.
+func ForwardGradient(f func(x *DualNumberMatrix) DualNumber, x Matrix) Matrix {
+	ins, outs := x.Shape()
+	g := NewArrayMatrix(ins, outs)
+	direction := NewArrayMatrix(ins, outs)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			direction.Set(i, o, 1)
+			_, d := DirectionalDerivative(f, x, direction)
+			g.Set(i, o, d)
+			direction.Set(i, o, 0)
+		}
+	}
+	return g
+}
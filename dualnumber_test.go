@@ -0,0 +1,59 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDualNumber(t *testing.T) {
+	x := DualNumber{2, 1}
+
+	// d/dx x*x/(x+1) = (x^2 + 2x)/(x+1)^2
+	y := x.Mul(x).Div(x.Add(Constant(1)))
+	ExpectFloat(4.0/3, y.Re, t)
+	ExpectFloat(8.0/9, y.Eps, t)
+
+	// d/dx log(exp(x) + sqrt(x))
+	z := x.Exp().Add(x.Sqrt()).Log()
+	ExpectFloat((math.Exp(2)+0.5/math.Sqrt2)/(math.Exp(2)+math.Sqrt2), z.Eps, t)
+}
+
+func TestForwardGradient(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		1, 2,
+		3, 4,
+		5, 6,
+	}, 2, 3, 2)
+	b := MatrixFromSlice([]float64{1, 0, -1}, 1, 3, 1)
+	x := MatrixFromSlice([]float64{0.5, -2}, 1, 2, 1)
+
+	// The gradient of |A*x - b|^2 is 2*Dual(A)*(A*x - b).
+	loss := func(x *DualNumberMatrix) DualNumber {
+		r := AddScaledDualNumbers(ApplyDualNumbers(DualNumberMatrixFrom(A), x), -1, DualNumberMatrixFrom(b))
+		return DualNumberSumSquares(r)
+	}
+	g := ForwardGradient(loss, x)
+
+	r := Apply(A, x)
+	addScaledInto(r, b, -1, r)
+	expect := Apply(Dual(A), r)
+	addScaledInto(expect, expect, 1, expect)
+	ExpectMatrix(expect, g, t)
+}
+
+func TestDirectionalDerivativeQuadraticForm(t *testing.T) {
+	S := MatrixFromSlice([]float64{
+		2, 1,
+		1, 3,
+	}, 2, 2, 2)
+	x := MatrixFromSlice([]float64{1, 2}, 1, 2, 1)
+	d := MatrixFromSlice([]float64{1, -1}, 1, 2, 1)
+
+	value, derivative := DirectionalDerivative(func(x *DualNumberMatrix) DualNumber {
+		return DualNumberQuadraticForm(x, DualNumberMatrixFrom(S))
+	}, x, d)
+
+	// x'Sx = 2 + 4 + 12 and its derivative along d is 2*d'Sx.
+	ExpectFloat(18, value, t)
+	ExpectFloat(2*(4-7), derivative, t)
+}
@@ -0,0 +1,250 @@
+package linear
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Einsum contracts the operands by Einstein summation, like NumPy's
+// einsum: spec gives each operand's subscripts, first row (output) then
+// column (input), separated by commas, and then after "->" those of the
+// result. Subscripts missing from the result are summed over. For
+// example "ij->ji" is Dual, "ij,jk->ik" is the product A*B, "ii->" is
+// the trace, "i,j->ij" is the outer product of two vectors and
+// "ij,jk,kl->il" chains three products. A vector or covector takes a
+// single subscript for its one long side. A result with no subscripts
+// is a 1 by 1 matrix and with one is a vector. Without "->" the result
+// has the subscripts used only once, in alphabetical order.
+func Einsum(spec string, operands ...Matrix) Matrix {
+	spec = strings.ReplaceAll(spec, " ", "")
+	lhs, rhs, explicit := strings.Cut(spec, "->")
+	inputs := strings.Split(lhs, ",")
+	if len(inputs) != len(operands) {
+		panic(fmt.Errorf("%q has %d operands but got %d", spec, len(inputs), len(operands)))
+	}
+
+	sizes := map[byte]int{}
+	counts := map[byte]int{}
+	setSize := func(label byte, size int) {
+		if s, ok := sizes[label]; ok && s != size {
+			panic(fmt.Errorf("%q: %c is %d and %d", spec, label, s, size))
+		}
+		sizes[label] = size
+	}
+	for k, sub := range inputs {
+		ins, outs := operands[k].Shape()
+		for j := 0; j < len(sub); j++ {
+			if c := sub[j]; c < 'a' || c > 'z' {
+				panic(fmt.Errorf("%q: subscript %q isn't a lower case letter", spec, c))
+			}
+			counts[sub[j]]++
+		}
+		switch {
+		case len(sub) == 2:
+			setSize(sub[0], outs)
+			setSize(sub[1], ins)
+		case len(sub) == 1 && ins == 1:
+			setSize(sub[0], outs)
+		case len(sub) == 1 && outs == 1:
+			setSize(sub[0], ins)
+		case len(sub) == 0 && ins == 1 && outs == 1:
+		default:
+			panic(fmt.Errorf("%q: %d subscripts for shape (%d, %d)", spec, len(sub), ins, outs))
+		}
+	}
+	if !explicit {
+		var once []byte
+		for label, n := range counts {
+			if n == 1 {
+				once = append(once, label)
+			}
+		}
+		sort.Slice(once, func(a, b int) bool { return once[a] < once[b] })
+		rhs = string(once)
+	}
+	if len(rhs) > 2 {
+		panic(fmt.Errorf("%q: results can only have 2 subscripts", spec))
+	}
+	for j := 0; j < len(rhs); j++ {
+		if _, ok := sizes[rhs[j]]; !ok || strings.IndexByte(rhs[j+1:], rhs[j]) >= 0 {
+			panic(fmt.Errorf("%q: bad result subscript %c", spec, rhs[j]))
+		}
+	}
+
+	// Contract the operands pairwise from the left through Apply, to
+	// use its kernels, and only loop over every combination of the
+	// subscripts when a pair doesn't reduce to a matrix product.
+	if result, ok := einsumPairwise(inputs, rhs, operands); ok {
+		return result
+	}
+	return einsumLoop(inputs, rhs, operands, sizes)
+}
+
+// einsumTerm is an operand or partial result with the subscript of its
+// rows (outputs) and columns (inputs), empty for a side of size 1.
+type einsumTerm struct {
+	M          Matrix
+	rows, cols string
+	// fresh is true if M isn't an operand or a view of one.
+	fresh bool
+}
+
+func newEinsumTerm(sub string, A Matrix) einsumTerm {
+	ins, _ := A.Shape()
+	switch {
+	case len(sub) == 2 && sub[0] == sub[1]:
+		// A repeated subscript takes the diagonal.
+		d := NewVector(ins)
+		for k := 0; k < ins; k++ {
+			d.Set(0, k, A.Get(k, k))
+		}
+		return einsumTerm{d, sub[:1], "", true}
+	case len(sub) == 2:
+		return einsumTerm{A, sub[:1], sub[1:], false}
+	case len(sub) == 1 && ins == 1:
+		return einsumTerm{A, sub, "", false}
+	}
+	return einsumTerm{A, "", sub, false}
+}
+
+func (t einsumTerm) labels() string { return t.rows + t.cols }
+
+func (t einsumTerm) dual() einsumTerm {
+	return einsumTerm{Dual(t.M), t.cols, t.rows, false}
+}
+
+// sumOut sums over the subscripts of t that aren't in keep.
+func (t einsumTerm) sumOut(keep string) einsumTerm {
+	ins, outs := t.M.Shape()
+	if t.rows != "" && !strings.Contains(keep, t.rows) {
+		ones := NewArrayMatrix(outs, 1)
+		for k := 0; k < outs; k++ {
+			ones.Set(k, 0, 1)
+		}
+		t = einsumTerm{Apply(ones, t.M), "", t.cols, true}
+	}
+	if t.cols != "" && !strings.Contains(keep, t.cols) {
+		ones := NewVector(ins)
+		for k := 0; k < ins; k++ {
+			ones.Set(0, k, 1)
+		}
+		t = einsumTerm{Apply(t.M, ones), t.rows, "", true}
+	}
+	return t
+}
+
+// einsumPairwise contracts the operands left to right, each pair by a
+// matrix product summing over the one subscript they share or by an
+// outer product if they share none. It returns false if some pair
+// shares more, or a subscript that's still needed afterwards.
+func einsumPairwise(inputs []string, rhs string, operands []Matrix) (Matrix, bool) {
+	acc := newEinsumTerm(inputs[0], operands[0]).sumOut(rhs + strings.Join(inputs[1:], ""))
+	for k := 1; k < len(inputs); k++ {
+		later := rhs + strings.Join(inputs[k+1:], "")
+		next := newEinsumTerm(inputs[k], operands[k]).sumOut(later + acc.labels())
+		var shared string
+		for _, label := range []string{acc.rows, acc.cols} {
+			if label != "" && strings.Contains(next.labels(), label) {
+				if strings.Contains(later, label) {
+					return nil, false
+				}
+				shared += label
+			}
+		}
+		switch {
+		case len(shared) == 1:
+			if acc.rows == shared {
+				acc = acc.dual()
+			}
+			if next.cols == shared {
+				next = next.dual()
+			}
+			acc = einsumTerm{Apply(acc.M, next.M), acc.rows, next.cols, true}
+		case len(shared) == 0 && len(acc.labels()) < 2 && len(next.labels()) < 2:
+			// An outer product of a vector and a covector, either of
+			// which may be a scalar.
+			if acc.rows == "" {
+				acc = acc.dual()
+			}
+			if next.cols == "" {
+				next = next.dual()
+			}
+			acc = einsumTerm{Apply(acc.M, next.M), acc.rows, next.cols, true}
+		default:
+			return nil, false
+		}
+	}
+	if len(rhs) > 0 && acc.rows != rhs[:1] {
+		acc = acc.dual()
+	}
+	if !acc.fresh {
+		return Copy(acc.M), true
+	}
+	return acc.M, true
+}
+
+// einsumLoop loops over every combination of the subscripts, adding
+// the products into the result.
+func einsumLoop(inputs []string, rhs string, operands []Matrix, sizes map[byte]int) Matrix {
+	labels := make([]byte, 0, len(sizes))
+	for label := range sizes {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(a, b int) bool { return labels[a] < labels[b] })
+	position := map[byte]int{}
+	for p, label := range labels {
+		position[label] = p
+	}
+	var result Matrix
+	switch len(rhs) {
+	case 0:
+		result = NewArrayMatrix(1, 1)
+	case 1:
+		result = NewVector(sizes[rhs[0]])
+	case 2:
+		result = NewArrayMatrix(sizes[rhs[1]], sizes[rhs[0]])
+	}
+	index := make([]int, len(labels))
+	at := func(sub string, A Matrix) float64 {
+		ins, _ := A.Shape()
+		switch {
+		case len(sub) == 2:
+			return A.Get(index[position[sub[1]]], index[position[sub[0]]])
+		case len(sub) == 1 && ins == 1:
+			return A.Get(0, index[position[sub[0]]])
+		case len(sub) == 1:
+			return A.Get(index[position[sub[0]]], 0)
+		}
+		return A.Get(0, 0)
+	}
+	for {
+		product := 1.0
+		for k, sub := range inputs {
+			product *= at(sub, operands[k])
+		}
+		switch len(rhs) {
+		case 0:
+			result.Set(0, 0, result.Get(0, 0)+product)
+		case 1:
+			o := index[position[rhs[0]]]
+			result.Set(0, o, result.Get(0, o)+product)
+		case 2:
+			i, o := index[position[rhs[1]]], index[position[rhs[0]]]
+			result.Set(i, o, result.Get(i, o)+product)
+		}
+
+		// Step to the next combination, like an odometer.
+		p := 0
+		for ; p < len(index); p++ {
+			index[p]++
+			if index[p] < sizes[labels[p]] {
+				break
+			}
+			index[p] = 0
+		}
+		if p == len(index) {
+			return result
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestEinsum(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6}, 3, 2, 3)
+	B := MatrixFromSlice([]float64{1, 0, 2, 1, 0, -1}, 2, 3, 2)
+	C := MatrixFromSlice([]float64{2, 1, 1, 3}, 2, 2, 2)
+	x := MatrixFromSlice([]float64{1, 2}, 1, 2, 1)
+	y := MatrixFromSlice([]float64{3, 4, 5}, 1, 3, 1)
+
+	ExpectMatrix(Dual(A), Einsum("ij->ji", A), t)
+	ExpectMatrix(Apply(A, B), Einsum("ij,jk->ik", A, B), t)
+	ExpectMatrix(Apply(A, B), Einsum("ij,jk", A, B), t)
+	ExpectMatrix(Dual(Apply(A, B)), Einsum("ij,jk->ki", A, B), t)
+	ExpectMatrix(Apply(Apply(A, B), C), Einsum("ij, jk, kl -> il", A, B, C), t)
+	ExpectMatrix(MatrixFromSlice([]float64{5}, 1, 1, 1), Einsum("ii->", C), t)
+	ExpectMatrix(MatrixFromSlice([]float64{2, 3}, 1, 2, 1), Einsum("ii->i", C), t)
+	ExpectMatrix(MatrixFromSlice([]float64{3, 4, 5, 6, 8, 10}, 3, 2, 3), Einsum("i,j->ij", x, y), t)
+	ExpectMatrix(Apply(A, y), Einsum("ij,j->i", A, y), t)
+	ExpectMatrix(MatrixFromSlice([]float64{5}, 1, 1, 1), Einsum("i,i->", x, x), t)
+	ExpectMatrix(Dual(Apply(Dual(x), A)), Einsum("i,ij->j", x, A), t)
+	// Dual(x) is a covector, which also takes one subscript.
+	ExpectMatrix(MatrixFromSlice([]float64{5}, 1, 1, 1), Einsum("i,i", Dual(x), x), t)
+	ExpectMatrix(MatrixFromSlice([]float64{6, 15}, 1, 2, 1), Einsum("ij->i", A), t)
+	ExpectMatrix(Dual(Apply(Apply(A, B), C)), Einsum("ij,jk,kl->li", A, B, C), t)
+	ExpectMatrix(MatrixFromSlice([]float64{150}, 1, 1, 1), Einsum("i,ij,j->", x, A, y), t)
+
+	// Subscripts shared by a pair but still needed afterwards, like the
+	// Hadamard product, fall back to looping.
+	ExpectMatrix(MatrixFromSlice([]float64{1, 4, 0, 0, 5, -6}, 3, 2, 3), Einsum("ij,ij->ij", A, Dual(B)), t)
+	ExpectMatrix(MatrixFromSlice([]float64{4}, 1, 1, 1), Einsum("ij,ij->", A, Dual(B)), t)
+
+	// The result is never the operand itself.
+	D := Einsum("ij->ij", C)
+	D.Set(0, 0, 100)
+	ExpectFloat(2, C.Get(0, 0), t)
+
+	expectPanic(t, func() { Einsum("ij,jk->ik", A, A) })
+	expectPanic(t, func() { Einsum("ij->ik", A) })
+	expectPanic(t, func() { Einsum("ijk->i", A) })
+	expectPanic(t, func() { Einsum("ij", A, B) })
+}
@@ -0,0 +1,114 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// EulerConvention is the order of the axes that a sequence of three
+// Euler angles rotates around. The rotations are intrinsic: each one is
+// around an axis that has been moved by the ones before it, so XYZ is
+// RotationX(a)*RotationY(b)*RotationZ(c).
+type EulerConvention int
+
+const (
+	// XYZ is roll, pitch and yaw around moving axes, common in
+	// aerospace as Cardan angles.
+	XYZ EulerConvention = iota
+	// ZYX is yaw, pitch and roll, common in robotics and vehicles.
+	ZYX
+	// ZYZ is the classic proper Euler angles.
+	ZYZ
+)
+
+// gimbalTolerance is how close to gimbal lock the middle angle can get
+// before the first and last angles are treated as the same rotation.
+const gimbalTolerance = 1e-9
+
+// RotationX returns the 3x3 rotation by theta radians around the x
+// axis.
+func RotationX(theta float64) Matrix {
+	c, s := math.Cos(theta), math.Sin(theta)
+	return Matrix3{
+		{1, 0, 0},
+		{0, c, -s},
+		{0, s, c},
+	}.Matrix()
+}
+
+// RotationY returns the 3x3 rotation by theta radians around the y
+// axis.
+func RotationY(theta float64) Matrix {
+	c, s := math.Cos(theta), math.Sin(theta)
+	return Matrix3{
+		{c, 0, s},
+		{0, 1, 0},
+		{-s, 0, c},
+	}.Matrix()
+}
+
+// RotationZ returns the 3x3 rotation by theta radians around the z
+// axis.
+func RotationZ(theta float64) Matrix {
+	c, s := math.Cos(theta), math.Sin(theta)
+	return Matrix3{
+		{c, -s, 0},
+		{s, c, 0},
+		{0, 0, 1},
+	}.Matrix()
+}
+
+// RotationFromEuler returns the 3x3 rotation for the Euler angles a, b
+// and c in the given convention.
+func RotationFromEuler(a, b, c float64, convention EulerConvention) Matrix {
+	var first, second, third Matrix
+	switch convention {
+	case XYZ:
+		first, second, third = RotationX(a), RotationY(b), RotationZ(c)
+	case ZYX:
+		first, second, third = RotationZ(a), RotationY(b), RotationX(c)
+	case ZYZ:
+		first, second, third = RotationZ(a), RotationY(b), RotationZ(c)
+	default:
+		panic(fmt.Errorf("unknown Euler convention %d", convention))
+	}
+	// first*second*third applies third to a vector first.
+	return Compose(Compose(third, second), first)
+}
+
+// EulerFromRotation returns Euler angles in the given convention for
+// the 3x3 rotation R. The middle angle b is in [-pi/2, pi/2] for XYZ
+// and ZYX and in [0, pi] for ZYZ. At gimbal lock, where the first and
+// last axes line up and only their sum or difference matters, c is 0.
+func EulerFromRotation(R Matrix, convention EulerConvention) (a, b, c float64) {
+	ins, outs := R.Shape()
+	if ins != 3 || outs != 3 {
+		panic(fmt.Errorf("expected a 3x3 rotation but got (%d, %d)", ins, outs))
+	}
+	r := func(row, col int) float64 { return R.Get(col, row) }
+	switch convention {
+	case XYZ:
+		cb := math.Hypot(r(0, 0), r(0, 1))
+		b = math.Atan2(r(0, 2), cb)
+		if cb < gimbalTolerance {
+			return math.Atan2(r(2, 1), r(1, 1)), b, 0
+		}
+		return math.Atan2(-r(1, 2), r(2, 2)), b, math.Atan2(-r(0, 1), r(0, 0))
+	case ZYX:
+		cb := math.Hypot(r(0, 0), r(1, 0))
+		b = math.Atan2(-r(2, 0), cb)
+		if cb < gimbalTolerance {
+			return math.Atan2(-r(0, 1), r(1, 1)), b, 0
+		}
+		return math.Atan2(r(1, 0), r(0, 0)), b, math.Atan2(r(2, 1), r(2, 2))
+	case ZYZ:
+		sb := math.Hypot(r(0, 2), r(1, 2))
+		b = math.Atan2(sb, r(2, 2))
+		if sb < gimbalTolerance {
+			return math.Atan2(-r(0, 1), r(1, 1)), b, 0
+		}
+		return math.Atan2(r(1, 2), r(0, 2)), b, math.Atan2(r(2, 1), -r(2, 0))
+	default:
+		panic(fmt.Errorf("unknown Euler convention %d", convention))
+	}
+}
@@ -0,0 +1,55 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRotationFromEuler(t *testing.T) {
+	// XYZ with only a yaw is a rotation around z.
+	ExpectMatrix(RotationZ(0.3), RotationFromEuler(0, 0, 0.3, XYZ), t)
+	// X then Y intrinsically is RotationX*RotationY.
+	ExpectMatrix(Apply(RotationX(0.2), RotationY(0.5)), RotationFromEuler(0.2, 0.5, 0, XYZ), t)
+	q := QuaternionFromAxisAngle(vector3(1, 0, 0), 0.7)
+	ExpectMatrix(q.RotationMatrix(), RotationX(0.7), t)
+}
+
+func TestEulerRoundTrip(t *testing.T) {
+	for _, convention := range []EulerConvention{XYZ, ZYX, ZYZ} {
+		for _, angles := range [][3]float64{
+			{0.1, 0.2, 0.3},
+			{-2, 1, 2.5},
+			{3, -0.4, -1},
+		} {
+			if convention == ZYZ {
+				angles[1] = math.Abs(angles[1])
+			}
+			R := RotationFromEuler(angles[0], angles[1], angles[2], convention)
+			a, b, c := EulerFromRotation(R, convention)
+			ExpectFloat(angles[0], a, t)
+			ExpectFloat(angles[1], b, t)
+			ExpectFloat(angles[2], c, t)
+		}
+	}
+}
+
+func TestEulerGimbalLock(t *testing.T) {
+	for _, test := range []struct {
+		convention EulerConvention
+		b          float64
+	}{
+		{XYZ, math.Pi / 2},
+		{XYZ, -math.Pi / 2},
+		{ZYX, math.Pi / 2},
+		{ZYX, -math.Pi / 2},
+		{ZYZ, 0},
+		{ZYZ, math.Pi},
+	} {
+		R := RotationFromEuler(0.4, test.b, 0.9, test.convention)
+		a, b, c := EulerFromRotation(R, test.convention)
+		ExpectFloat(test.b, b, t)
+		ExpectFloat(0, c, t)
+		// The angles are different but the rotation is the same.
+		ExpectMatrix(R, RotationFromEuler(a, b, c, test.convention), t)
+	}
+}
@@ -0,0 +1,185 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// Expr is a formula over matrices that isn't evaluated until EvalInto
+// or Eval, so that it can be rearranged first: transposes become free
+// Dual views, chains of products are multiplied in the cheapest order,
+// and sums accumulate into the destination instead of allocating a
+// result for every operation. Multiplication is in the usual order,
+// so E(A).Mul(B) is A*B which is Compose(B, A).
+type Expr struct {
+	terms     []exprTerm
+	ins, outs int
+}
+
+// exprTerm is scale*factors[0]*factors[1]*...
+type exprTerm struct {
+	scale   float64
+	factors []Matrix
+}
+
+// E starts an expression with A.
+func E(A Matrix) *Expr {
+	ins, outs := A.Shape()
+	return &Expr{
+		terms: []exprTerm{{1, []Matrix{A}}},
+		ins:   ins,
+		outs:  outs,
+	}
+}
+
+// Shape returns the shape the expression will evaluate to.
+func (e *Expr) Shape() (ins, outs int) { return e.ins, e.outs }
+
+// T returns the transpose of the expression.
+func (e *Expr) T() *Expr {
+	// (A*B)' = B'*A', so each product is reversed with each factor
+	// viewed through Dual.
+	terms := make([]exprTerm, len(e.terms))
+	for t, term := range e.terms {
+		n := len(term.factors)
+		factors := make([]Matrix, n)
+		for f, F := range term.factors {
+			factors[n-1-f] = dualOf(F)
+		}
+		terms[t] = exprTerm{term.scale, factors}
+	}
+	return &Expr{terms, e.outs, e.ins}
+}
+
+// Mul returns the expression times B.
+func (e *Expr) Mul(B Matrix) *Expr {
+	return e.MulExpr(E(B))
+}
+
+// MulExpr returns the expression times another one.
+func (e *Expr) MulExpr(f *Expr) *Expr {
+	if e.ins != f.outs {
+		panic(fmt.Errorf("can't multiply (%d, %d) by (%d, %d)", e.ins, e.outs, f.ins, f.outs))
+	}
+	// Products of single terms just concatenate. Otherwise sums are
+	// evaluated first rather than distributed, which could multiply
+	// the number of products.
+	left, right := e, f
+	if len(left.terms) > 1 && len(right.terms) > 1 {
+		left = E(left.Eval())
+	}
+	var terms []exprTerm
+	for _, l := range left.terms {
+		for _, r := range right.terms {
+			factors := append(append([]Matrix(nil), l.factors...), r.factors...)
+			terms = append(terms, exprTerm{l.scale * r.scale, factors})
+		}
+	}
+	return &Expr{terms, f.ins, e.outs}
+}
+
+// Add returns the expression plus B.
+func (e *Expr) Add(B Matrix) *Expr {
+	return e.AddExpr(E(B))
+}
+
+// AddExpr returns the expression plus another one.
+func (e *Expr) AddExpr(f *Expr) *Expr {
+	if e.ins != f.ins || e.outs != f.outs {
+		panic(fmt.Errorf("can't add (%d, %d) to (%d, %d)", f.ins, f.outs, e.ins, e.outs))
+	}
+	terms := append(append([]exprTerm(nil), e.terms...), f.terms...)
+	return &Expr{terms, e.ins, e.outs}
+}
+
+// Sub returns the expression minus B.
+func (e *Expr) Sub(B Matrix) *Expr {
+	return e.AddExpr(E(B).Scale(-1))
+}
+
+// Scale returns the expression times a scalar.
+func (e *Expr) Scale(s float64) *Expr {
+	terms := make([]exprTerm, len(e.terms))
+	for t, term := range e.terms {
+		terms[t] = exprTerm{s * term.scale, term.factors}
+	}
+	return &Expr{terms, e.ins, e.outs}
+}
+
+// Eval evaluates the expression into a new Matrix.
+func (e *Expr) Eval() Matrix {
+	dst := NewArrayMatrix(e.ins, e.outs)
+	e.EvalInto(dst)
+	return dst
+}
+
+// EvalInto evaluates the expression into dst.
+func (e *Expr) EvalInto(dst Matrix) {
+	ins, outs := dst.Shape()
+	if ins != e.ins || outs != e.outs {
+		panic(fmt.Errorf("dimension mismatch (%d, %d) vs (%d, %d)", e.ins, e.outs, ins, outs))
+	}
+	for _, term := range e.terms {
+		for _, F := range term.factors {
+			if sharesArray(F, dst) {
+				// Writing into dst early would change an operand.
+				tmp := NewArrayMatrix(ins, outs)
+				e.EvalInto(tmp)
+				CopyInto(tmp, dst)
+				return
+			}
+		}
+	}
+
+	var scratch Matrix
+	for t, term := range e.terms {
+		target := dst
+		if t > 0 {
+			if scratch == nil {
+				scratch = NewArrayMatrix(ins, outs)
+			}
+			target = scratch
+		}
+		evalProduct(term.factors, target)
+		s := term.scale
+		for o := 0; o < outs; o++ {
+			for i := 0; i < ins; i++ {
+				if t == 0 {
+					dst.Set(i, o, s*dst.Get(i, o))
+				} else {
+					dst.Set(i, o, dst.Get(i, o)+s*scratch.Get(i, o))
+				}
+			}
+		}
+	}
+}
+
+// evalProduct writes factors[0]*factors[1]*... into dst.
+func evalProduct(factors []Matrix, dst Matrix) {
+	// ComposeAll takes the maps in the order they're applied, which is
+	// right to left.
+	n := len(factors)
+	ms := make([]Matrix, n)
+	for f, F := range factors {
+		ms[n-1-f] = F
+	}
+	ComposeAllInto(dst, ms...)
+}
+
+// dualOf is Dual but unwraps Dual(Dual(A)) to A.
+func dualOf(A Matrix) Matrix {
+	if d, ok := A.(*dualMatrix); ok {
+		return d.A
+	}
+	return Dual(A)
+}
+
+// sharesArray returns true if A and B are both array-backed with the
+// same array.
+func sharesArray(A, B Matrix) bool {
+	a, aok := asArrayMatrix(A)
+	b, bok := asArrayMatrix(B)
+	if !aok || !bok || len(a.array) == 0 || len(b.array) == 0 {
+		return A == B
+	}
+	return &a.array[0] == &b.array[0]
+}
@@ -0,0 +1,71 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestExpr(t *testing.T) {
+	X := NewArrayMatrix(2, 3)
+	X.Set(0, 0, 1)
+	X.Set(1, 0, 2)
+	X.Set(0, 1, 3)
+	X.Set(1, 1, 4)
+	X.Set(0, 2, 5)
+	X.Set(1, 2, 6)
+
+	I := Identity(2)
+
+	// The regularized normal equations' Dual(X)*X + 2*I.
+	A := E(X).T().Mul(X).AddExpr(E(I).Scale(2)).Eval()
+
+	ins, outs := A.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(2, outs, t)
+	ExpectFloat(37, A.Get(0, 0), t)
+	ExpectFloat(44, A.Get(1, 0), t)
+	ExpectFloat(44, A.Get(0, 1), t)
+	ExpectFloat(58, A.Get(1, 1), t)
+
+	// (X*Dual(X))' is itself.
+	B := E(X).Mul(Dual(X)).T().Sub(Compose(Dual(X), X)).Eval()
+	bIns, bOuts := B.Shape()
+	for o := 0; o < bOuts; o++ {
+		for i := 0; i < bIns; i++ {
+			ExpectFloat(0, B.Get(i, o), t)
+		}
+	}
+}
+
+func TestExprProductOfSums(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 3)
+	A.Set(1, 1, 4)
+
+	I := Identity(2)
+
+	// (A + I)*(A - I) = A*A - I
+	C := E(A).Add(I).MulExpr(E(A).Sub(I)).Eval()
+	D := Apply(A, A)
+
+	ExpectFloat(D.Get(0, 0)-1, C.Get(0, 0), t)
+	ExpectFloat(D.Get(1, 0), C.Get(1, 0), t)
+	ExpectFloat(D.Get(0, 1), C.Get(0, 1), t)
+	ExpectFloat(D.Get(1, 1)-1, C.Get(1, 1), t)
+}
+
+func TestExprEvalIntoOperand(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 3)
+	A.Set(1, 1, 4)
+
+	E(A).Mul(A).Add(A).EvalInto(A)
+
+	ExpectFloat(8, A.Get(0, 0), t)
+	ExpectFloat(12, A.Get(1, 0), t)
+	ExpectFloat(18, A.Get(0, 1), t)
+	ExpectFloat(26, A.Get(1, 1), t)
+}
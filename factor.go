@@ -0,0 +1,368 @@
+package linear
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Factorization is a factored square (or for QR, tall) matrix that can
+// be reused to solve for any number of right hand sides.
+type Factorization interface {
+	Solver
+	// Solve finds X such that A*X = B, a column at a time.
+	Solve(B Matrix) Matrix
+	// Det returns the determinant of A.
+	Det() float64
+	// Inverse returns the inverse of A.
+	Inverse() Matrix
+}
+
+// QRFactorization is A = Q*R with Q kept as the Householder
+// reflections that make it up rather than as a matrix.
+type QRFactorization struct {
+	r     Matrix
+	vs    []Matrix
+	betas []float64
+}
+
+// FactorQR factors A with DefaultQRTolerance.
+func FactorQR(A Matrix) *QRFactorization {
+	return FactorQRWithTolerance(A, DefaultQRTolerance)
+}
+
+// FactorQRWithTolerance factors A, skipping the reflection for a column
+// when everything below its diagonal is at most tol times the largest
+// entry of A, like DecomposeQRWithTolerance.
+func FactorQRWithTolerance(A Matrix, tol float64) *QRFactorization {
+	defer beginOp("QR")()
+	ins, outs := A.Shape()
+	scale := MaxAbs(A)
+	// R is stored column-major since each step reads a column of it.
+	R := NewArrayMatrixColMajor(ins, outs)
+	CopyInto(A, R)
+	f := &QRFactorization{r: R}
+	for i := 0; i < ins && i < outs; i++ {
+		if IsZeroRelative(Slice(R, i, i+1, i+1, outs), scale, tol) {
+			for o := i + 1; o < outs; o++ {
+				R.Set(i, o, 0)
+			}
+			f.vs = append(f.vs, nil)
+			f.betas = append(f.betas, 0)
+			continue
+		}
+
+		x := Slice(R, i, i+1, i, outs)
+		v, beta := HouseholderVector(x, BasisVector(outs-i, 0))
+
+		// Each reflection only touches the trailing rows of R.
+		ApplyHouseholderLeft(v, beta, Slice(R, i, ins, i, outs))
+		for o := i + 1; o < outs; o++ {
+			R.Set(i, o, 0)
+		}
+		f.vs = append(f.vs, v)
+		f.betas = append(f.betas, beta)
+	}
+	return f
+}
+
+// R returns the upper triangular factor.
+func (f *QRFactorization) R() Matrix { return f.r }
+
+// Q returns the orthogonal factor as a matrix.
+func (f *QRFactorization) Q() Matrix {
+	_, outs := f.r.Shape()
+	Q := Identity(outs)
+	for i, v := range f.vs {
+		if v == nil {
+			continue
+		}
+		ApplyHouseholderRight(v, f.betas[i], Slice(Q, i, outs, 0, outs))
+	}
+	return Q
+}
+
+// ThinQ returns the first min(ins, outs) columns of Q, which are all
+// that's needed to rebuild A from the top rows of R. For a tall A it's
+// much smaller than Q.
+func (f *QRFactorization) ThinQ() Matrix {
+	ins, outs := f.r.Shape()
+	k := ins
+	if outs < k {
+		k = outs
+	}
+	Q := NewArrayMatrixColMajor(k, outs)
+	for d := 0; d < k; d++ {
+		Q.Set(d, d, 1)
+	}
+	f.ApplyQ(Q)
+	return Q
+}
+
+// ApplyQDual replaces B with Dual(Q)*B in place.
+func (f *QRFactorization) ApplyQDual(B Matrix) {
+	CheckSameOuts(f.r, B)
+	ins, outs := B.Shape()
+	for i, v := range f.vs {
+		if v == nil {
+			continue
+		}
+		ApplyHouseholderLeft(v, f.betas[i], Slice(B, 0, ins, i, outs))
+	}
+}
+
+// ApplyQ replaces B with Q*B in place.
+func (f *QRFactorization) ApplyQ(B Matrix) {
+	CheckSameOuts(f.r, B)
+	ins, outs := B.Shape()
+	for i := len(f.vs) - 1; i >= 0; i-- {
+		if f.vs[i] == nil {
+			continue
+		}
+		ApplyHouseholderLeft(f.vs[i], f.betas[i], Slice(B, 0, ins, i, outs))
+	}
+}
+
+// SolveVec finds the x that makes A*x closest to b, which is exact when
+// A is square and nonsingular.
+func (f *QRFactorization) SolveVec(b Vector) Vector {
+	CheckVector(b)
+	return f.Solve(b)
+}
+
+// Solve finds the X that makes A*X closest to B, column by column.
+func (f *QRFactorization) Solve(B Matrix) Matrix {
+	defer beginOp("Solve")()
+	ins, outs := f.r.Shape()
+	if outs < ins {
+		panic(fmt.Errorf("less matix outs (%d) than ins (%d)", outs, ins))
+	}
+	QtB := Copy(B)
+	f.ApplyQDual(QtB)
+	cols, _ := B.Shape()
+	return FindInputUpperTriangular(Slice(f.r, 0, ins, 0, ins), Slice(QtB, 0, cols, 0, ins))
+}
+
+// Det returns the determinant of a square A. Each reflection flips the
+// sign.
+func (f *QRFactorization) Det() float64 {
+	checkSquare(f.r)
+	ins, _ := f.r.Shape()
+	det := 1.0
+	for i := 0; i < ins; i++ {
+		det *= f.r.Get(i, i)
+		if f.betas[i] != 0 {
+			det = -det
+		}
+	}
+	return det
+}
+
+// Inverse returns the inverse of a square A.
+func (f *QRFactorization) Inverse() Matrix {
+	checkSquare(f.r)
+	ins, _ := f.r.Shape()
+	return f.Solve(Identity(ins))
+}
+
+// LUFactorization is P*A = L*U with partial pivoting, where L is unit
+// lower triangular and U is upper triangular.
+type LUFactorization struct {
+	// lu holds U on and above the diagonal and L below it.
+	lu Matrix
+	// Row k was swapped with row piv[k] at step k.
+	piv []int
+}
+
+// smallPivot is how small, relative to the largest entry of A, a pivot
+// has to be for FactorLU to warn that the factors may be inaccurate.
+const smallPivot = 1e-12
+
+// FactorLU factors a square A by Gaussian elimination with partial
+// pivoting. It panics if A is singular.
+func FactorLU(A Matrix) *LUFactorization {
+	defer beginOp("LU")()
+	checkSquare(A)
+	n, _ := A.Shape()
+	countFlops(2 * n * n * n / 3)
+	lu := Copy(A)
+	scale := MaxAbs(A)
+	piv := make([]int, n)
+	for k := 0; k < n; k++ {
+		p := k
+		for o := k + 1; o < n; o++ {
+			if math.Abs(lu.Get(k, o)) > math.Abs(lu.Get(k, p)) {
+				p = o
+			}
+		}
+		piv[k] = p
+		if p != k {
+			for i := 0; i < n; i++ {
+				a, b := lu.Get(i, k), lu.Get(i, p)
+				lu.Set(i, k, b)
+				lu.Set(i, p, a)
+			}
+		}
+		pivot := lu.Get(k, k)
+		if pivot == 0 {
+			panic(fmt.Errorf("singular at %d", k))
+		}
+		if math.Abs(pivot) <= smallPivot*scale {
+			logEvent(slog.LevelWarn, "small pivot", slog.String("op", "LU"),
+				slog.Int("step", k), slog.Float64("pivot", pivot))
+		}
+		for o := k + 1; o < n; o++ {
+			l := lu.Get(k, o) / pivot
+			lu.Set(k, o, l)
+			for i := k + 1; i < n; i++ {
+				lu.Set(i, o, lu.Get(i, o)-l*lu.Get(i, k))
+			}
+		}
+	}
+	return &LUFactorization{lu: lu, piv: piv}
+}
+
+// L returns the unit lower triangular factor.
+func (f *LUFactorization) L() Matrix {
+	n, _ := f.lu.Shape()
+	L := Identity(n)
+	for o := 0; o < n; o++ {
+		for i := 0; i < o; i++ {
+			L.Set(i, o, f.lu.Get(i, o))
+		}
+	}
+	return L
+}
+
+// U returns the upper triangular factor.
+func (f *LUFactorization) U() Matrix {
+	n, _ := f.lu.Shape()
+	U := NewArrayMatrix(n, n)
+	for o := 0; o < n; o++ {
+		for i := o; i < n; i++ {
+			U.Set(i, o, f.lu.Get(i, o))
+		}
+	}
+	return U
+}
+
+// SolveVec finds x such that A*x = b.
+func (f *LUFactorization) SolveVec(b Vector) Vector {
+	CheckVector(b)
+	return f.Solve(b)
+}
+
+// Solve finds X such that A*X = B.
+func (f *LUFactorization) Solve(B Matrix) Matrix {
+	defer beginOp("Solve")()
+	CheckSameOuts(f.lu, B)
+	n, _ := f.lu.Shape()
+	cols, _ := B.Shape()
+	countFlops(2 * n * n * cols)
+	X := Copy(B)
+	for c := 0; c < cols; c++ {
+		for k := 0; k < n; k++ {
+			if p := f.piv[k]; p != k {
+				a, b := X.Get(c, k), X.Get(c, p)
+				X.Set(c, k, b)
+				X.Set(c, p, a)
+			}
+		}
+		for o := 0; o < n; o++ {
+			x := X.Get(c, o)
+			for i := 0; i < o; i++ {
+				x -= f.lu.Get(i, o) * X.Get(c, i)
+			}
+			X.Set(c, o, x)
+		}
+		for o := n - 1; o >= 0; o-- {
+			x := X.Get(c, o)
+			for i := o + 1; i < n; i++ {
+				x -= f.lu.Get(i, o) * X.Get(c, i)
+			}
+			X.Set(c, o, x/f.lu.Get(o, o))
+		}
+	}
+	return X
+}
+
+// Det returns the determinant of A, which is the product of the
+// diagonal of U with a sign flip for each row swap.
+func (f *LUFactorization) Det() float64 {
+	n, _ := f.lu.Shape()
+	det := 1.0
+	for k := 0; k < n; k++ {
+		det *= f.lu.Get(k, k)
+		if f.piv[k] != k {
+			det = -det
+		}
+	}
+	return det
+}
+
+// Inverse returns the inverse of A.
+func (f *LUFactorization) Inverse() Matrix {
+	n, _ := f.lu.Shape()
+	return f.Solve(Identity(n))
+}
+
+// CholeskyFactorization is A = L*Dual(L) for a symmetric positive
+// definite A.
+type CholeskyFactorization struct {
+	l Matrix
+}
+
+// FactorCholesky factors A with DecomposeCholesky.
+func FactorCholesky(A Matrix) *CholeskyFactorization {
+	return &CholeskyFactorization{l: DecomposeCholesky(A)}
+}
+
+// L returns the lower triangular factor.
+func (f *CholeskyFactorization) L() Matrix { return f.l }
+
+// SolveVec finds x such that A*x = b.
+func (f *CholeskyFactorization) SolveVec(b Vector) Vector {
+	return SolveCholesky(f.l, b)
+}
+
+// Solve finds X such that A*X = B.
+func (f *CholeskyFactorization) Solve(B Matrix) Matrix {
+	CheckSameOuts(f.l, B)
+	cols, n := B.Shape()
+	X := NewArrayMatrix(cols, n)
+	for c := 0; c < cols; c++ {
+		CopyInto(f.SolveVec(Slice(B, c, c+1, 0, n)), Slice(X, c, c+1, 0, n))
+	}
+	return X
+}
+
+// Det returns the determinant of A, which is the square of the
+// product of the diagonal of L.
+func (f *CholeskyFactorization) Det() float64 {
+	n, _ := f.l.Shape()
+	det := 1.0
+	for k := 0; k < n; k++ {
+		det *= f.l.Get(k, k)
+	}
+	return det * det
+}
+
+// Inverse returns the inverse of A.
+func (f *CholeskyFactorization) Inverse() Matrix {
+	n, _ := f.l.Shape()
+	return f.Solve(Identity(n))
+}
+
+// Inverse returns the inverse of a square nonsingular A, by LU. Solving
+// with a Factorization is cheaper and more accurate when the inverse
+// is only going to be applied. It panics if A is singular.
+func Inverse(A Matrix) Matrix {
+	return FactorLU(A).Inverse()
+}
+
+func checkSquare(A Matrix) {
+	ins, outs := A.Shape()
+	if ins != outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", ins, outs))
+	}
+}
@@ -0,0 +1,162 @@
+package linear
+
+import (
+	"runtime"
+	"testing"
+)
+
+func factorTestMatrix() Matrix {
+	A := NewArrayMatrix(3, 3)
+	A.Set(0, 0, 2)
+	A.Set(1, 0, 1)
+	A.Set(2, 0, 1)
+	A.Set(0, 1, 4)
+	A.Set(1, 1, -6)
+	A.Set(2, 1, 0)
+	A.Set(0, 2, -2)
+	A.Set(1, 2, 7)
+	A.Set(2, 2, 2)
+	return A
+}
+
+func TestFactorizations(t *testing.T) {
+	A := factorTestMatrix()
+	b := NewVector(3)
+	b.Set(0, 0, 5)
+	b.Set(0, 1, -2)
+	b.Set(0, 2, 9)
+
+	for name, f := range map[string]Factorization{
+		"QR": FactorQR(A),
+		"LU": FactorLU(A),
+	} {
+		ExpectFloat(-16, f.Det(), t)
+
+		x := f.SolveVec(b)
+		Ax := Apply(A, x)
+		for o := 0; o < 3; o++ {
+			ExpectFloat(b.Get(0, o), Ax.Get(0, o), t)
+		}
+
+		AInv := Apply(A, f.Inverse())
+		for o := 0; o < 3; o++ {
+			for i := 0; i < 3; i++ {
+				expect := 0.0
+				if i == o {
+					expect = 1
+				}
+				if got := AInv.Get(i, o); got-expect > 1e-12 || expect-got > 1e-12 {
+					t.Errorf("%s: A*Inverse() at (%d, %d) is %v", name, i, o, got)
+				}
+			}
+		}
+	}
+}
+
+func TestLUFactorization(t *testing.T) {
+	A := factorTestMatrix()
+	f := FactorLU(A)
+
+	// Undo the row swaps of L*U to compare with A.
+	LU := Apply(f.L(), f.U())
+	for k := 2; k >= 0; k-- {
+		if p := f.piv[k]; p != k {
+			for i := 0; i < 3; i++ {
+				a, b := LU.Get(i, k), LU.Get(i, p)
+				LU.Set(i, k, b)
+				LU.Set(i, p, a)
+			}
+		}
+	}
+	for o := 0; o < 3; o++ {
+		for i := 0; i < 3; i++ {
+			ExpectFloat(A.Get(i, o), LU.Get(i, o), t)
+		}
+	}
+}
+
+func TestQRFactorizationManyRightHandSides(t *testing.T) {
+	X := NewArrayMatrix(2, 3)
+	X.Set(0, 0, 1)
+	X.Set(1, 0, 0)
+	X.Set(0, 1, 1)
+	X.Set(1, 1, 2)
+	X.Set(0, 2, -2)
+	X.Set(1, 2, 1)
+
+	Y := NewArrayMatrix(2, 3)
+	Y.Set(0, 0, 6)
+	Y.Set(0, 1, 0)
+	Y.Set(0, 2, -15)
+	Y.Set(1, 0, 1)
+	Y.Set(1, 1, 3)
+	Y.Set(1, 2, -1)
+
+	Theta := FactorQR(X).Solve(Y)
+
+	ExpectFloat(6, Theta.Get(0, 0), t)
+	ExpectFloat(-3, Theta.Get(0, 1), t)
+	ExpectFloat(1, Theta.Get(1, 0), t)
+	ExpectFloat(1, Theta.Get(1, 1), t)
+}
+
+func TestQRFactorizationWide(t *testing.T) {
+	// More parameters than observations isn't a least squares problem
+	// QR can solve, and that should be an ordinary error rather than an
+	// index out of range.
+	X := MatrixFromSlice([]float64{
+		1, 2, 3,
+		4, 5, 6,
+	}, 3, 2, 3)
+	y := MatrixFromSlice([]float64{1, 2}, 1, 2, 1)
+	defer func() {
+		r := recover()
+		if _, ok := r.(runtime.Error); ok || r == nil {
+			t.Errorf("expected a plain error but got %v", r)
+		}
+	}()
+	OrdinaryLeastSquares(X, y)
+}
+
+func TestCholeskyFactorization(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 4)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 2)
+	A.Set(1, 1, 3)
+
+	f := FactorCholesky(A)
+
+	ExpectFloat(8, f.Det(), t)
+	AInv := f.Inverse()
+	ExpectFloat(3.0/8.0, AInv.Get(0, 0), t)
+	ExpectFloat(-2.0/8.0, AInv.Get(1, 0), t)
+	ExpectFloat(4.0/8.0, AInv.Get(1, 1), t)
+}
+
+func TestQRFactorizationApplyQ(t *testing.T) {
+	A := NewArrayMatrix(2, 4)
+	for o := 0; o < 4; o++ {
+		A.Set(0, o, 1)
+		A.Set(1, o, float64(o*o))
+	}
+	f := FactorQR(A)
+
+	B := Copy(f.R())
+	f.ApplyQ(B)
+	ExpectMatrix(A, B, t)
+	f.ApplyQDual(B)
+	ExpectMatrix(f.R(), B, t)
+}
+
+func TestInverse(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		2, 1, 0,
+		1, 3, 1,
+		0, 1, 4,
+	}, 3, 3, 3)
+	ExpectMatrix(Identity(3), Apply(A, Inverse(A)), t)
+	ExpectMatrix(Identity(3), Apply(Inverse(A), A), t)
+
+	expectPanic(t, func() { Inverse(MatrixFromSlice([]float64{1, 2, 2, 4}, 2, 2, 2)) })
+}
@@ -0,0 +1,112 @@
+package linear
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// HashFeatures builds a sparse design matrix with dim features (inputs)
+// and an observation (output) per row, from rows of categorical string
+// fields, by the hashing trick: each field's value is hashed, along
+// with which field it's in, to one of the features, which is
+// incremented. Collisions just add up, so dim only needs to be large
+// enough that they're rare.
+func HashFeatures(rows [][]string, dim int) *CSR {
+	if dim <= 0 {
+		panic(fmt.Errorf("can't hash into %d features", dim))
+	}
+	var entries []SparseEntry
+	for o, row := range rows {
+		for field, value := range row {
+			entries = append(entries, SparseEntry{hashFeature(field, value, dim), o, 1})
+		}
+	}
+	return NewCSRFromEntries(dim, len(rows), entries)
+}
+
+func hashFeature(field int, value string, dim int) int {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d=%s", field, value)
+	return int(h.Sum64() % uint64(dim))
+}
+
+// OneHotEncoder is a dictionary encoding of categorical string fields,
+// with a feature for every value seen in every field.
+type OneHotEncoder struct {
+	// levels[f] maps the values of field f to their features.
+	levels []map[string]int
+	names  []string
+}
+
+// NewOneHotEncoder builds the dictionary for the values in rows, which
+// must all have the same number of fields. The features for each field
+// come together, with the values in sorted order.
+func NewOneHotEncoder(rows [][]string) *OneHotEncoder {
+	fields := 0
+	if len(rows) > 0 {
+		fields = len(rows[0])
+	}
+	seen := make([]map[string]bool, fields)
+	for f := range seen {
+		seen[f] = map[string]bool{}
+	}
+	for o, row := range rows {
+		if len(row) != fields {
+			panic(fmt.Errorf("row %d has %d fields but expected %d", o, len(row), fields))
+		}
+		for f, value := range row {
+			seen[f][value] = true
+		}
+	}
+
+	e := &OneHotEncoder{levels: make([]map[string]int, fields)}
+	for f := range seen {
+		values := make([]string, 0, len(seen[f]))
+		for value := range seen[f] {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		e.levels[f] = map[string]int{}
+		for _, value := range values {
+			e.levels[f][value] = len(e.names)
+			e.names = append(e.names, fmt.Sprintf("%d=%s", f, value))
+		}
+	}
+	return e
+}
+
+// Features returns how many features the encoding has.
+func (e *OneHotEncoder) Features() int { return len(e.names) }
+
+// Names returns a name for each feature, like "2=red" for the value
+// "red" of field 2.
+func (e *OneHotEncoder) Names() []string { return append([]string(nil), e.names...) }
+
+// Feature returns the feature for value in field, and whether the
+// value was in the dictionary.
+func (e *OneHotEncoder) Feature(field int, value string) (int, bool) {
+	if field < 0 || field >= len(e.levels) {
+		panic(fmt.Errorf("field %d isn't in [0, %d)", field, len(e.levels)))
+	}
+	in, ok := e.levels[field][value]
+	return in, ok
+}
+
+// Encode builds a sparse design matrix with a 1 for the value of each
+// field of each row. Values that weren't in the dictionary are left out,
+// so those observations are 0 in every feature of that field.
+func (e *OneHotEncoder) Encode(rows [][]string) *CSR {
+	var entries []SparseEntry
+	for o, row := range rows {
+		if len(row) != len(e.levels) {
+			panic(fmt.Errorf("row %d has %d fields but expected %d", o, len(row), len(e.levels)))
+		}
+		for f, value := range row {
+			if in, ok := e.levels[f][value]; ok {
+				entries = append(entries, SparseEntry{in, o, 1})
+			}
+		}
+	}
+	return NewCSRFromEntries(len(e.names), len(rows), entries)
+}
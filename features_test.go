@@ -0,0 +1,59 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestHashFeatures(t *testing.T) {
+	rows := [][]string{
+		{"red", "small"},
+		{"blue", "small"},
+		{"red", "large"},
+	}
+
+	X := HashFeatures(rows, 1024)
+
+	ins, outs := X.Shape()
+	ExpectInt(1024, ins, t)
+	ExpectInt(3, outs, t)
+	ExpectFloat(1, X.Get(hashFeature(0, "red", 1024), 0), t)
+	ExpectFloat(1, X.Get(hashFeature(0, "red", 1024), 2), t)
+	ExpectFloat(1, X.Get(hashFeature(1, "small", 1024), 1), t)
+	for o := 0; o < outs; o++ {
+		sum := 0.0
+		for i := 0; i < ins; i++ {
+			sum += X.Get(i, o)
+		}
+		ExpectFloat(2, sum, t)
+	}
+}
+
+func TestOneHotEncoder(t *testing.T) {
+	rows := [][]string{
+		{"red", "small"},
+		{"blue", "small"},
+		{"red", "large"},
+	}
+
+	e := NewOneHotEncoder(rows)
+
+	ExpectInt(4, e.Features(), t)
+	names := e.Names()
+	for i, expect := range []string{"0=blue", "0=red", "1=large", "1=small"} {
+		if names[i] != expect {
+			t.Errorf("expected %q but got %q", expect, names[i])
+		}
+	}
+	X := e.Encode(append(rows, []string{"green", "large"}))
+	ExpectMatrix(MatrixFromSlice([]float64{
+		0, 1, 0, 1,
+		1, 0, 0, 1,
+		0, 1, 1, 0,
+		0, 0, 1, 0,
+	}, 4, 4, 4), X, t)
+	in, ok := e.Feature(1, "large")
+	ExpectInt(2, in, t)
+	if !ok {
+		t.Errorf("expected large to be in the dictionary")
+	}
+}
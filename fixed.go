@@ -0,0 +1,586 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// The fixed size vectors and matrices below are plain arrays, so they
+// live on the stack and their operations never allocate, for when a
+// heap allocated 3 by 3 Matrix per rotation is too much (as in a game
+// loop). A matrix is indexed by row then column, so A[o][i] is what
+// Get(i, o) is for a Matrix, and literals read like the math. They're
+// converted to and from a Matrix when the rest of the package is
+// needed.
+
+// Vec2 is a vector of dimension 2.
+type Vec2 [2]float64
+
+// Add returns v + w.
+func (v Vec2) Add(w Vec2) Vec2 {
+	for d := range v {
+		v[d] += w[d]
+	}
+	return v
+}
+
+// Sub returns v - w.
+func (v Vec2) Sub(w Vec2) Vec2 {
+	for d := range v {
+		v[d] -= w[d]
+	}
+	return v
+}
+
+// Scale returns s*v.
+func (v Vec2) Scale(s float64) Vec2 {
+	for d := range v {
+		v[d] *= s
+	}
+	return v
+}
+
+// Dot returns the dot product of v and w.
+func (v Vec2) Dot(w Vec2) float64 {
+	dot := 0.0
+	for d := range v {
+		dot += v[d] * w[d]
+	}
+	return dot
+}
+
+// Norm returns the length of v.
+func (v Vec2) Norm() float64 { return math.Sqrt(v.Dot(v)) }
+
+// Vector returns v as a Vector.
+func (v Vec2) Vector() Vector {
+	x := NewVector(2)
+	for d, f := range v {
+		x.Set(0, d, f)
+	}
+	return x
+}
+
+// Vec2From returns the entries of a Vector of dimension 2.
+func Vec2From(x Matrix) Vec2 {
+	checkFixedVector(x, 2)
+	var v Vec2
+	for d := range v {
+		v[d] = x.Get(0, d)
+	}
+	return v
+}
+
+// Matrix2 is a 2 by 2 matrix, row by row.
+type Matrix2 [2][2]float64
+
+// Identity2 returns the 2 by 2 identity.
+func Identity2() Matrix2 {
+	var A Matrix2
+	for d := range A {
+		A[d][d] = 1
+	}
+	return A
+}
+
+// Apply returns A*B.
+func (A Matrix2) Apply(B Matrix2) Matrix2 {
+	var C Matrix2
+	for o := range C {
+		for i := range C[o] {
+			for k := range B {
+				C[o][i] += A[o][k] * B[k][i]
+			}
+		}
+	}
+	return C
+}
+
+// ApplyVec returns A*v.
+func (A Matrix2) ApplyVec(v Vec2) Vec2 {
+	var w Vec2
+	for o := range A {
+		for i, f := range v {
+			w[o] += A[o][i] * f
+		}
+	}
+	return w
+}
+
+// Dual returns the transpose of A.
+func (A Matrix2) Dual() Matrix2 {
+	var B Matrix2
+	for o := range A {
+		for i := range A[o] {
+			B[i][o] = A[o][i]
+		}
+	}
+	return B
+}
+
+// Add returns A + B.
+func (A Matrix2) Add(B Matrix2) Matrix2 {
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] += B[o][i]
+		}
+	}
+	return A
+}
+
+// Scale returns s*A.
+func (A Matrix2) Scale(s float64) Matrix2 {
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] *= s
+		}
+	}
+	return A
+}
+
+// Det returns the determinant of A.
+func (A Matrix2) Det() float64 {
+	return A[0][0]*A[1][1] - A[0][1]*A[1][0]
+}
+
+// Inverse returns the inverse of A, panicking if A is singular.
+func (A Matrix2) Inverse() Matrix2 {
+	det := A.Det()
+	if det == 0 {
+		panic(fmt.Errorf("singular"))
+	}
+	return Matrix2{
+		{A[1][1] / det, -A[0][1] / det},
+		{-A[1][0] / det, A[0][0] / det},
+	}
+}
+
+// Matrix returns A as a Matrix.
+func (A Matrix2) Matrix() Matrix {
+	M := NewArrayMatrix(2, 2)
+	for o := range A {
+		for i, f := range A[o] {
+			M.Set(i, o, f)
+		}
+	}
+	return M
+}
+
+// Matrix2From returns the entries of a 2 by 2 Matrix.
+func Matrix2From(M Matrix) Matrix2 {
+	if ins, outs := M.Shape(); ins != 2 || outs != 2 {
+		panic(fmt.Errorf("expected shape (2, 2) but got (%d, %d)", ins, outs))
+	}
+	var A Matrix2
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] = M.Get(i, o)
+		}
+	}
+	return A
+}
+
+// Vec3 is a vector of dimension 3.
+type Vec3 [3]float64
+
+// Add returns v + w.
+func (v Vec3) Add(w Vec3) Vec3 {
+	for d := range v {
+		v[d] += w[d]
+	}
+	return v
+}
+
+// Sub returns v - w.
+func (v Vec3) Sub(w Vec3) Vec3 {
+	for d := range v {
+		v[d] -= w[d]
+	}
+	return v
+}
+
+// Scale returns s*v.
+func (v Vec3) Scale(s float64) Vec3 {
+	for d := range v {
+		v[d] *= s
+	}
+	return v
+}
+
+// Dot returns the dot product of v and w.
+func (v Vec3) Dot(w Vec3) float64 {
+	dot := 0.0
+	for d := range v {
+		dot += v[d] * w[d]
+	}
+	return dot
+}
+
+// Norm returns the length of v.
+func (v Vec3) Norm() float64 { return math.Sqrt(v.Dot(v)) }
+
+// Cross returns the cross product of v and w.
+func (v Vec3) Cross(w Vec3) Vec3 {
+	return Vec3{v[1]*w[2] - v[2]*w[1], v[2]*w[0] - v[0]*w[2], v[0]*w[1] - v[1]*w[0]}
+}
+
+// Vector returns v as a Vector.
+func (v Vec3) Vector() Vector {
+	x := NewVector(3)
+	for d, f := range v {
+		x.Set(0, d, f)
+	}
+	return x
+}
+
+// Vec3From returns the entries of a Vector of dimension 3.
+func Vec3From(x Matrix) Vec3 {
+	checkFixedVector(x, 3)
+	var v Vec3
+	for d := range v {
+		v[d] = x.Get(0, d)
+	}
+	return v
+}
+
+// Matrix3 is a 3 by 3 matrix, row by row.
+type Matrix3 [3][3]float64
+
+// Identity3 returns the 3 by 3 identity.
+func Identity3() Matrix3 {
+	var A Matrix3
+	for d := range A {
+		A[d][d] = 1
+	}
+	return A
+}
+
+// Apply returns A*B.
+func (A Matrix3) Apply(B Matrix3) Matrix3 {
+	var C Matrix3
+	for o := range C {
+		for i := range C[o] {
+			for k := range B {
+				C[o][i] += A[o][k] * B[k][i]
+			}
+		}
+	}
+	return C
+}
+
+// ApplyVec returns A*v.
+func (A Matrix3) ApplyVec(v Vec3) Vec3 {
+	var w Vec3
+	for o := range A {
+		for i, f := range v {
+			w[o] += A[o][i] * f
+		}
+	}
+	return w
+}
+
+// Dual returns the transpose of A.
+func (A Matrix3) Dual() Matrix3 {
+	var B Matrix3
+	for o := range A {
+		for i := range A[o] {
+			B[i][o] = A[o][i]
+		}
+	}
+	return B
+}
+
+// Add returns A + B.
+func (A Matrix3) Add(B Matrix3) Matrix3 {
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] += B[o][i]
+		}
+	}
+	return A
+}
+
+// Scale returns s*A.
+func (A Matrix3) Scale(s float64) Matrix3 {
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] *= s
+		}
+	}
+	return A
+}
+
+// Det returns the determinant of A.
+func (A Matrix3) Det() float64 {
+	return A[0][0]*(A[1][1]*A[2][2]-A[1][2]*A[2][1]) -
+		A[0][1]*(A[1][0]*A[2][2]-A[1][2]*A[2][0]) +
+		A[0][2]*(A[1][0]*A[2][1]-A[1][1]*A[2][0])
+}
+
+// Inverse returns the inverse of A, the adjugate over the determinant,
+// panicking if A is singular.
+func (A Matrix3) Inverse() Matrix3 {
+	det := A.Det()
+	if det == 0 {
+		panic(fmt.Errorf("singular"))
+	}
+	var B Matrix3
+	for o := 0; o < 3; o++ {
+		for i := 0; i < 3; i++ {
+			// The cofactor of (o, i) goes at (i, o), and the cyclic order
+			// of the other rows and columns takes care of its sign.
+			o1, o2 := (o+1)%3, (o+2)%3
+			i1, i2 := (i+1)%3, (i+2)%3
+			B[i][o] = (A[o1][i1]*A[o2][i2] - A[o1][i2]*A[o2][i1]) / det
+		}
+	}
+	return B
+}
+
+// Matrix returns A as a Matrix.
+func (A Matrix3) Matrix() Matrix {
+	M := NewArrayMatrix(3, 3)
+	for o := range A {
+		for i, f := range A[o] {
+			M.Set(i, o, f)
+		}
+	}
+	return M
+}
+
+// Matrix3From returns the entries of a 3 by 3 Matrix.
+func Matrix3From(M Matrix) Matrix3 {
+	if ins, outs := M.Shape(); ins != 3 || outs != 3 {
+		panic(fmt.Errorf("expected shape (3, 3) but got (%d, %d)", ins, outs))
+	}
+	var A Matrix3
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] = M.Get(i, o)
+		}
+	}
+	return A
+}
+
+// Vec4 is a vector of dimension 4.
+type Vec4 [4]float64
+
+// Add returns v + w.
+func (v Vec4) Add(w Vec4) Vec4 {
+	for d := range v {
+		v[d] += w[d]
+	}
+	return v
+}
+
+// Sub returns v - w.
+func (v Vec4) Sub(w Vec4) Vec4 {
+	for d := range v {
+		v[d] -= w[d]
+	}
+	return v
+}
+
+// Scale returns s*v.
+func (v Vec4) Scale(s float64) Vec4 {
+	for d := range v {
+		v[d] *= s
+	}
+	return v
+}
+
+// Dot returns the dot product of v and w.
+func (v Vec4) Dot(w Vec4) float64 {
+	dot := 0.0
+	for d := range v {
+		dot += v[d] * w[d]
+	}
+	return dot
+}
+
+// Norm returns the length of v.
+func (v Vec4) Norm() float64 { return math.Sqrt(v.Dot(v)) }
+
+// Vector returns v as a Vector.
+func (v Vec4) Vector() Vector {
+	x := NewVector(4)
+	for d, f := range v {
+		x.Set(0, d, f)
+	}
+	return x
+}
+
+// Vec4From returns the entries of a Vector of dimension 4.
+func Vec4From(x Matrix) Vec4 {
+	checkFixedVector(x, 4)
+	var v Vec4
+	for d := range v {
+		v[d] = x.Get(0, d)
+	}
+	return v
+}
+
+// Matrix4 is a 4 by 4 matrix, row by row.
+type Matrix4 [4][4]float64
+
+// Identity4 returns the 4 by 4 identity.
+func Identity4() Matrix4 {
+	var A Matrix4
+	for d := range A {
+		A[d][d] = 1
+	}
+	return A
+}
+
+// Apply returns A*B.
+func (A Matrix4) Apply(B Matrix4) Matrix4 {
+	var C Matrix4
+	for o := range C {
+		for i := range C[o] {
+			for k := range B {
+				C[o][i] += A[o][k] * B[k][i]
+			}
+		}
+	}
+	return C
+}
+
+// ApplyVec returns A*v.
+func (A Matrix4) ApplyVec(v Vec4) Vec4 {
+	var w Vec4
+	for o := range A {
+		for i, f := range v {
+			w[o] += A[o][i] * f
+		}
+	}
+	return w
+}
+
+// Dual returns the transpose of A.
+func (A Matrix4) Dual() Matrix4 {
+	var B Matrix4
+	for o := range A {
+		for i := range A[o] {
+			B[i][o] = A[o][i]
+		}
+	}
+	return B
+}
+
+// Add returns A + B.
+func (A Matrix4) Add(B Matrix4) Matrix4 {
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] += B[o][i]
+		}
+	}
+	return A
+}
+
+// Scale returns s*A.
+func (A Matrix4) Scale(s float64) Matrix4 {
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] *= s
+		}
+	}
+	return A
+}
+
+// Det returns the determinant of A, by elimination with partial
+// pivoting.
+func (A Matrix4) Det() float64 {
+	det := 1.0
+	for k := 0; k < 4; k++ {
+		p := A.pivot(k)
+		if A[p][k] == 0 {
+			return 0
+		}
+		if p != k {
+			A[k], A[p] = A[p], A[k]
+			det = -det
+		}
+		det *= A[k][k]
+		for o := k + 1; o < 4; o++ {
+			l := A[o][k] / A[k][k]
+			for i := k; i < 4; i++ {
+				A[o][i] -= l * A[k][i]
+			}
+		}
+	}
+	return det
+}
+
+// Inverse returns the inverse of A by Gauss-Jordan elimination with
+// partial pivoting, panicking if A is singular.
+func (A Matrix4) Inverse() Matrix4 {
+	B := Identity4()
+	for k := 0; k < 4; k++ {
+		p := A.pivot(k)
+		if A[p][k] == 0 {
+			panic(fmt.Errorf("singular at %d", k))
+		}
+		A[k], A[p] = A[p], A[k]
+		B[k], B[p] = B[p], B[k]
+		d := A[k][k]
+		for i := 0; i < 4; i++ {
+			A[k][i] /= d
+			B[k][i] /= d
+		}
+		for o := 0; o < 4; o++ {
+			if o == k {
+				continue
+			}
+			l := A[o][k]
+			for i := 0; i < 4; i++ {
+				A[o][i] -= l * A[k][i]
+				B[o][i] -= l * B[k][i]
+			}
+		}
+	}
+	return B
+}
+
+// pivot returns the row at or below k with the largest entry in
+// column k.
+func (A *Matrix4) pivot(k int) int {
+	p := k
+	for o := k + 1; o < 4; o++ {
+		if math.Abs(A[o][k]) > math.Abs(A[p][k]) {
+			p = o
+		}
+	}
+	return p
+}
+
+// Matrix returns A as a Matrix.
+func (A Matrix4) Matrix() Matrix {
+	M := NewArrayMatrix(4, 4)
+	for o := range A {
+		for i, f := range A[o] {
+			M.Set(i, o, f)
+		}
+	}
+	return M
+}
+
+// Matrix4From returns the entries of a 4 by 4 Matrix.
+func Matrix4From(M Matrix) Matrix4 {
+	if ins, outs := M.Shape(); ins != 4 || outs != 4 {
+		panic(fmt.Errorf("expected shape (4, 4) but got (%d, %d)", ins, outs))
+	}
+	var A Matrix4
+	for o := range A {
+		for i := range A[o] {
+			A[o][i] = M.Get(i, o)
+		}
+	}
+	return A
+}
+
+func checkFixedVector(x Matrix, dim int) {
+	CheckVector(x)
+	if _, d := x.Shape(); d != dim {
+		panic(fmt.Errorf("expected a vector of dimension %d but got %d", dim, d))
+	}
+}
@@ -0,0 +1,83 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestVec3(t *testing.T) {
+	v, w := Vec3{1, 2, 3}, Vec3{4, 5, 6}
+
+	ExpectMatrix(Vec3{5, 7, 9}.Vector(), v.Add(w).Vector(), t)
+	ExpectMatrix(Vec3{-3, -3, -3}.Vector(), v.Sub(w).Vector(), t)
+	ExpectMatrix(Vec3{2, 4, 6}.Vector(), v.Scale(2).Vector(), t)
+	ExpectFloat(32, v.Dot(w), t)
+	ExpectFloat(5, Vec2{3, 4}.Norm(), t)
+	ExpectMatrix(Vec3{-3, 6, -3}.Vector(), v.Cross(w).Vector(), t)
+	// Values, so v is unchanged.
+	ExpectFloat(1, v[0], t)
+
+	ExpectMatrix(v.Vector(), Vec3From(v.Vector()).Vector(), t)
+	expectPanic(t, func() { Vec4From(v.Vector()) })
+}
+
+func TestFixedMatrices(t *testing.T) {
+	A := Matrix3{
+		{2, 1, 0},
+		{1, 3, 1},
+		{0, 1, 4},
+	}
+	B := Matrix3{
+		{1, 0, 2},
+		{0, 1, 0},
+		{3, 0, 1},
+	}
+	v := Vec3{1, -1, 2}
+
+	ExpectMatrix(Apply(A.Matrix(), B.Matrix()), A.Apply(B).Matrix(), t)
+	ExpectMatrix(Apply(A.Matrix(), v.Vector()), A.ApplyVec(v).Vector(), t)
+	ExpectMatrix(Dual(B.Matrix()), B.Dual().Matrix(), t)
+	ExpectMatrix(Matrix3{{3, 1, 2}, {1, 4, 1}, {3, 1, 5}}.Matrix(), A.Add(B).Matrix(), t)
+	ExpectMatrix(Matrix3{{4, 2, 0}, {2, 6, 2}, {0, 2, 8}}.Matrix(), A.Scale(2).Matrix(), t)
+	ExpectMatrix(A.Matrix(), Matrix3From(A.Matrix()).Matrix(), t)
+	// Rows then columns, like Get(i, o).
+	ExpectFloat(2, B.Matrix().Get(2, 0), t)
+	expectPanic(t, func() { Matrix2From(A.Matrix()) })
+}
+
+func TestFixedInverse(t *testing.T) {
+	A2 := Matrix2{{4, 7}, {2, 6}}
+	A3 := Matrix3{{0, 2, 1}, {1, 1, 0}, {3, 0, 2}}
+	A4 := Matrix4{
+		{0, 1, 0, 2},
+		{1, 0, 3, 0},
+		{2, 1, 1, 1},
+		{0, 4, 1, 1},
+	}
+
+	ExpectFloat(FactorLU(A2.Matrix()).Det(), A2.Det(), t)
+	ExpectFloat(FactorLU(A3.Matrix()).Det(), A3.Det(), t)
+	ExpectFloat(FactorLU(A4.Matrix()).Det(), A4.Det(), t)
+	ExpectMatrix(Identity(2), A2.Apply(A2.Inverse()).Matrix(), t)
+	ExpectMatrix(Identity(3), A3.Apply(A3.Inverse()).Matrix(), t)
+	ExpectMatrix(Identity(4), A4.Apply(A4.Inverse()).Matrix(), t)
+	ExpectMatrix(Identity(4), Identity4().Matrix(), t)
+
+	singular := Matrix4{{1, 2, 3, 4}, {2, 4, 6, 8}}
+	ExpectFloat(0, singular.Det(), t)
+	expectPanic(t, func() { singular.Inverse() })
+	expectPanic(t, func() { Matrix3{}.Inverse() })
+	expectPanic(t, func() { Matrix2{}.Inverse() })
+}
+
+func TestFixedNoAllocs(t *testing.T) {
+	A := Matrix4{{1, 2, 0, 0}, {0, 1, 0, 3}, {0, 0, 2, 0}, {1, 0, 0, 1}}
+	R := Matrix3From(RotationZ(0.3))
+	v := Vec3{1, 2, 3}
+	var sink float64
+	allocs := testing.AllocsPerRun(100, func() {
+		B := A.Inverse().Apply(A).Dual().Add(A).Scale(2)
+		w := R.Apply(R.Dual()).ApplyVec(v).Cross(v).Add(v)
+		sink += B.Det() + R.Inverse().Det() + w.Norm()
+	})
+	ExpectInt(0, int(allocs), t)
+}
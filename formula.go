@@ -0,0 +1,189 @@
+package linear
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ParseFormula parses an R-style model formula like
+// "y ~ x1 + x2 + x1:x2 - 1". The response before ~ is optional. Terms
+// are joined by + and removed by -, a:b is the interaction of a and b,
+// a*b is shorthand for a + b + a:b, and 1 and 0 add and remove the
+// intercept, which is included unless removed.
+func ParseFormula(s string) (Formula, error) {
+	f := Formula{Intercept: true}
+	rhs := s
+	if tilde := strings.Index(s, "~"); tilde >= 0 {
+		f.Response = strings.TrimSpace(s[:tilde])
+		rhs = s[tilde+1:]
+		if f.Response != "" && !isFormulaName(f.Response) {
+			return Formula{}, fmt.Errorf("invalid response %q", f.Response)
+		}
+	}
+
+	tokens, err := tokenizeFormula(rhs)
+	if err != nil {
+		return Formula{}, err
+	}
+	// Each + or - is followed by a product of factors, which are
+	// interactions of names.
+	seen := map[string]bool{}
+	sign := "+"
+	for len(tokens) > 0 {
+		if tokens[0] == "+" || tokens[0] == "-" {
+			sign, tokens = tokens[0], tokens[1:]
+		}
+		var product [][]string
+		product, tokens, err = parseFormulaProduct(tokens)
+		if err != nil {
+			return Formula{}, err
+		}
+		if len(tokens) > 0 && tokens[0] != "+" && tokens[0] != "-" {
+			return Formula{}, fmt.Errorf("unexpected %q in formula %q", tokens[0], s)
+		}
+		for _, term := range product {
+			if len(term) == 1 && (term[0] == "0" || term[0] == "1") {
+				f.Intercept = (term[0] == "1") == (sign == "+")
+				continue
+			}
+			key := termKey(term)
+			if sign == "+" && !seen[key] {
+				seen[key] = true
+				f.Terms = append(f.Terms, term)
+			} else if sign == "-" && seen[key] {
+				delete(seen, key)
+				for t := range f.Terms {
+					if termKey(f.Terms[t]) == key {
+						f.Terms = append(f.Terms[:t], f.Terms[t+1:]...)
+						break
+					}
+				}
+			}
+		}
+		sign = "+"
+	}
+	return f, nil
+}
+
+// parseFormulaProduct parses factors joined by *, expanding them into
+// every interaction of them.
+func parseFormulaProduct(tokens []string) (terms [][]string, rest []string, err error) {
+	terms = [][]string{nil}
+	for {
+		var factor []string
+		factor, tokens, err = parseFormulaInteraction(tokens)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Everything so far, then factor, then everything so far
+		// interacted with factor.
+		var next [][]string
+		for _, t := range terms {
+			if t != nil {
+				next = append(next, t)
+			}
+		}
+		next = append(next, factor)
+		for _, t := range terms {
+			if t != nil {
+				next = append(next, append(append([]string(nil), t...), factor...))
+			}
+		}
+		terms = next
+		if len(tokens) == 0 || tokens[0] != "*" {
+			return terms, tokens, nil
+		}
+		tokens = tokens[1:]
+	}
+}
+
+// parseFormulaInteraction parses names joined by :.
+func parseFormulaInteraction(tokens []string) (term []string, rest []string, err error) {
+	for {
+		if len(tokens) == 0 {
+			return nil, nil, fmt.Errorf("formula ends in an operator")
+		}
+		if !isFormulaName(tokens[0]) && tokens[0] != "0" && tokens[0] != "1" {
+			return nil, nil, fmt.Errorf("expected a column name but got %q", tokens[0])
+		}
+		term = append(term, tokens[0])
+		tokens = tokens[1:]
+		if len(tokens) == 0 || tokens[0] != ":" {
+			return term, tokens, nil
+		}
+		tokens = tokens[1:]
+	}
+}
+
+func tokenizeFormula(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		r := rune(s[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("+-:*", r):
+			tokens = append(tokens, s[i:i+1])
+			i++
+		case isFormulaNameRune(r):
+			j := i
+			for j < len(s) && isFormulaNameRune(rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q in formula", r)
+		}
+	}
+	return tokens, nil
+}
+
+func isFormulaNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+func isFormulaName(s string) bool {
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		return false
+	}
+	for _, r := range s {
+		if !isFormulaNameRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// termKey identifies a term regardless of the order of its columns, so
+// that a:b and b:a are the same.
+func termKey(term []string) string {
+	sorted := append([]string(nil), term...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ":")
+}
+
+// DesignMatrixFromFormula parses formula and builds its design matrix
+// from the table, as DesignMatrix does.
+func (t *Table) DesignMatrixFromFormula(formula string) (X *LabeledMatrix, y Vector, err error) {
+	f, err := ParseFormula(formula)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, term := range f.Terms {
+		for _, name := range term {
+			if !t.has(name) {
+				return nil, nil, fmt.Errorf("no column %q", name)
+			}
+		}
+	}
+	if f.Response != "" {
+		if _, ok := t.numeric[f.Response]; !ok {
+			return nil, nil, fmt.Errorf("no numeric column %q for the response", f.Response)
+		}
+	}
+	X, y = t.DesignMatrix(f)
+	return X, y, nil
+}
@@ -0,0 +1,63 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestParseFormula(t *testing.T) {
+	for _, test := range []struct {
+		formula, expect string
+	}{
+		{"y ~ x1 + x2 + x1:x2 - 1", "y ~ x1 + x2 + x1:x2 - 1"},
+		{"y ~ a*b", "y ~ a + b + a:b"},
+		{"y~a*b*c - a:b:c", "y ~ a + b + a:b + c + a:c + b:c"},
+		{"~ x + 0", " ~ x - 1"},
+		{"y ~ 0 + x + 1", "y ~ x"},
+		{"y ~ a + a + b:a - a:b", "y ~ a"},
+		{"y ~ 1", "y ~ 1"},
+	} {
+		f, err := ParseFormula(test.formula)
+		if err != nil {
+			t.Errorf("%q: %v", test.formula, err)
+			continue
+		}
+		if got := f.String(); got != test.expect {
+			t.Errorf("%q: expected %q but got %q", test.formula, test.expect, got)
+		}
+	}
+}
+
+func TestParseFormulaErrors(t *testing.T) {
+	for _, formula := range []string{
+		"y ~ x +",
+		"y ~ x x",
+		"y ~ (x)",
+		"2y ~ x",
+		"y ~ :x",
+	} {
+		if _, err := ParseFormula(formula); err == nil {
+			t.Errorf("%q: expected an error", formula)
+		}
+	}
+}
+
+func TestDesignMatrixFromFormula(t *testing.T) {
+	table := exampleTable()
+
+	X, y, err := table.DesignMatrixFromFormula("y ~ x*color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, _ := table.DesignMatrix(Formula{
+		Response:  "y",
+		Terms:     [][]string{{"x"}, {"color"}, {"x", "color"}},
+		Intercept: true,
+	})
+	expectLabels(expect.InLabels, X.InLabels, t)
+	ExpectMatrix(expect, X, t)
+	ExpectFloat(12, y.Get(0, 5), t)
+
+	if _, _, err := table.DesignMatrixFromFormula("y ~ size"); err == nil {
+		t.Errorf("expected an error for a missing column")
+	}
+}
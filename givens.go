@@ -0,0 +1,95 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// Givens is a rotation in the plane of dimensions I and J: the
+// identity except for C at (I, I) and (J, J), S at (J, I) (row I,
+// column J) and -S at (I, J). Unlike a Householder reflection it only
+// touches two rows, which is all it takes to zero one entry, so it
+// suits matrices that are already nearly triangular, like Hessenberg
+// or banded ones, and updating factorizations a row at a time.
+type Givens struct {
+	C, S float64
+	I, J int
+}
+
+// NewGivens returns the rotation in dimensions i and j that takes a
+// vector with a at i and b at j to one with sqrt(a^2 + b^2) at i and 0
+// at j.
+func NewGivens(a, b float64, i, j int) Givens {
+	if b == 0 {
+		return Givens{1, 0, i, j}
+	}
+	r := math.Hypot(a, b)
+	return Givens{a / r, b / r, i, j}
+}
+
+// Dual returns the inverse rotation.
+func (g Givens) Dual() Givens { return Givens{g.C, -g.S, g.I, g.J} }
+
+// Matrix returns the rotation as a dim by dim matrix.
+func (g Givens) Matrix(dim int) Matrix {
+	G := Identity(dim)
+	G.Set(g.I, g.I, g.C)
+	G.Set(g.J, g.J, g.C)
+	G.Set(g.J, g.I, g.S)
+	G.Set(g.I, g.J, -g.S)
+	return G
+}
+
+// ApplyLeft replaces A with G*A in place, mixing outputs (rows) I and
+// J.
+func (g Givens) ApplyLeft(A Matrix) {
+	ins, outs := A.Shape()
+	g.check(outs)
+	countFlops(6 * ins)
+	for i := 0; i < ins; i++ {
+		a, b := A.Get(i, g.I), A.Get(i, g.J)
+		A.Set(i, g.I, g.C*a+g.S*b)
+		A.Set(i, g.J, g.C*b-g.S*a)
+	}
+}
+
+// ApplyRight replaces A with A*G in place, mixing inputs (columns) I
+// and J.
+func (g Givens) ApplyRight(A Matrix) {
+	ins, outs := A.Shape()
+	g.check(ins)
+	countFlops(6 * outs)
+	rotateColumns(A, g.I, g.J, g.C, g.S)
+}
+
+func (g Givens) check(dim int) {
+	if g.I == g.J || g.I < 0 || g.J < 0 || g.I >= dim || g.J >= dim {
+		panic(fmt.Errorf("rotation of %d and %d in dimension %d", g.I, g.J, dim))
+	}
+}
+
+// DecomposeQRGivens decomposes A into Q*R like DecomposeQR, but zeroes
+// the entries below the diagonal one at a time with Givens rotations,
+// from the bottom of each column up. Entries that are already zero are
+// skipped, so an upper Hessenberg A takes only one rotation per column
+// instead of a reflection of the whole column.
+func DecomposeQRGivens(A Matrix) (Q, R Matrix) {
+	defer beginOp("QR")()
+	ins, outs := A.Shape()
+	R = Copy(A)
+	Q = Identity(outs)
+	for i := 0; i < ins && i < outs; i++ {
+		for o := outs - 1; o > i; o-- {
+			b := R.Get(i, o)
+			if b == 0 {
+				continue
+			}
+			g := NewGivens(R.Get(i, o-1), b, o-1, o)
+			// Columns before i are already zero in both rows.
+			g.ApplyLeft(Slice(R, i, ins, 0, outs))
+			R.Set(i, o, 0)
+			g.Dual().ApplyRight(Q)
+		}
+	}
+	return Q, R
+}
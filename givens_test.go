@@ -0,0 +1,53 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGivens(t *testing.T) {
+	g := NewGivens(3, 4, 0, 2)
+	x := MatrixFromSlice([]float64{3, 7, 4}, 1, 3, 1)
+	y := Copy(x)
+	g.ApplyLeft(y)
+	ExpectMatrix(MatrixFromSlice([]float64{5, 7, 0}, 1, 3, 1), y, t)
+	ExpectMatrix(Apply(g.Matrix(3), x), y, t)
+
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9}, 3, 3, 3)
+	B := Copy(A)
+	g.ApplyRight(B)
+	ExpectMatrix(Apply(A, g.Matrix(3)), B, t)
+	g.Dual().ApplyRight(B)
+	ExpectMatrix(A, B, t)
+
+	expectPanic(t, func() { Givens{1, 0, 1, 1}.ApplyLeft(A) })
+	expectPanic(t, func() { Givens{1, 0, 0, 3}.ApplyRight(A) })
+}
+
+func TestDecomposeQRGivens(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, shape := range [][2]int{{3, 5}, {4, 4}, {5, 3}} {
+		A := NewArrayMatrix(shape[0], shape[1])
+		for o := 0; o < shape[1]; o++ {
+			for i := 0; i < shape[0]; i++ {
+				A.Set(i, o, rng.NormFloat64())
+			}
+		}
+		Q, R := DecomposeQRGivens(A)
+		ExpectMatrix(Identity(shape[1]), Compose(Q, Dual(Q)), t)
+		ExpectMatrix(A, Apply(Q, R), t)
+		for o := 0; o < shape[1]; o++ {
+			for i := 0; i < o && i < shape[0]; i++ {
+				ExpectFloat(0, R.Get(i, o), t)
+			}
+		}
+		// R agrees with Householder QR up to the signs of its rows.
+		_, RH := DecomposeQR(A)
+		for o := 0; o < shape[1]; o++ {
+			for i := 0; i < shape[0]; i++ {
+				ExpectFloat(math.Abs(RH.Get(i, o)), math.Abs(R.Get(i, o)), t)
+			}
+		}
+	}
+}
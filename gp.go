@@ -0,0 +1,80 @@
+package linear
+
+import (
+	"math"
+)
+
+// GP is Gaussian process regression with a squared exponential kernel
+// and Gaussian observation noise.
+type GP struct {
+	// SignalVariance is the prior variance of the function at any
+	// point.
+	SignalVariance float64
+	// LengthScale is how far apart observations can be before their
+	// function values are mostly unrelated.
+	LengthScale float64
+	// NoiseVariance is the variance of the noise added to each
+	// observed function value.
+	NoiseVariance float64
+
+	x     Matrix // training observations
+	l     Matrix // Cholesky factor of the noisy kernel matrix
+	alpha Vector // (K + noise*I)^-1 * y
+	y     Vector // training responses
+}
+
+// Kernel returns the prior covariance between function values.
+func (gp *GP) Kernel() KernelFunc {
+	rbf := RBFKernel(1 / (2 * gp.LengthScale * gp.LengthScale))
+	return func(x, y Covector) float64 {
+		return gp.SignalVariance * rbf(x, y)
+	}
+}
+
+// Fit conditions the process on the observations (outputs) of X
+// having responses y.
+func (gp *GP) Fit(X, y Matrix) {
+	CheckVector(y)
+	CheckSameOuts(X, y)
+	K := KernelMatrix(X, gp.Kernel())
+	_, n := K.Shape()
+	for d := 0; d < n; d++ {
+		K.Set(d, d, K.Get(d, d)+gp.NoiseVariance)
+	}
+	gp.x = X
+	gp.y = y
+	gp.l = DecomposeCholesky(K)
+	gp.alpha = SolveCholesky(gp.l, y)
+}
+
+// Predict returns the posterior mean and variance of the function at
+// each observation (output) of Xstar. The variance doesn't include the
+// observation noise.
+func (gp *GP) Predict(Xstar Matrix) (mean, variance Vector) {
+	k := gp.Kernel()
+	Kstar := CrossKernelMatrix(Xstar, gp.x, k)
+	mean = Apply(Kstar, gp.alpha)
+
+	ins, outs := Xstar.Shape()
+	_, n := gp.x.Shape()
+	variance = NewVector(outs)
+	for o := 0; o < outs; o++ {
+		x := Slice(Xstar, 0, ins, o, o+1)
+		// The prior variance less what's explained by the training
+		// data, Dual(k*)*(K + noise*I)^-1*k*.
+		v := FindInputLowerTriangular(gp.l, Dual(Slice(Kstar, 0, n, o, o+1)))
+		variance.Set(0, o, k(x, x)-DotProduct(v, Dual(v)))
+	}
+	return mean, variance
+}
+
+// LogMarginalLikelihood returns the log probability of the training
+// responses under the process, for comparing hyperparameters.
+func (gp *GP) LogMarginalLikelihood() float64 {
+	_, n := gp.y.Shape()
+	logDet := 0.0
+	for d := 0; d < n; d++ {
+		logDet += math.Log(gp.l.Get(d, d))
+	}
+	return -0.5*DotProduct(gp.alpha, Dual(gp.y)) - logDet - 0.5*float64(n)*math.Log(2*math.Pi)
+}
@@ -0,0 +1,52 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGP(t *testing.T) {
+	X := NewArrayMatrix(1, 3)
+	X.Set(0, 0, -1)
+	X.Set(0, 1, 0)
+	X.Set(0, 2, 1)
+
+	y := NewVector(3)
+	y.Set(0, 0, 1)
+	y.Set(0, 1, 2)
+	y.Set(0, 2, 0)
+
+	gp := &GP{SignalVariance: 1, LengthScale: 1, NoiseVariance: 1e-10}
+	gp.Fit(X, y)
+
+	// With almost no noise the posterior goes through the data with
+	// almost no variance there.
+	mean, variance := gp.Predict(X)
+	for o := 0; o < 3; o++ {
+		if math.Abs(mean.Get(0, o)-y.Get(0, o)) > 1e-6 {
+			t.Errorf("expected %f but got %f", y.Get(0, o), mean.Get(0, o))
+		}
+		if math.Abs(variance.Get(0, o)) > 1e-6 {
+			t.Errorf("expected 0 but got %f", variance.Get(0, o))
+		}
+	}
+
+	// Far from the data the posterior reverts to the prior.
+	Xstar := NewArrayMatrix(1, 1)
+	Xstar.Set(0, 0, 100)
+	mean, variance = gp.Predict(Xstar)
+	ExpectFloat(0, mean.Get(0, 0), t)
+	ExpectFloat(1, variance.Get(0, 0), t)
+}
+
+func TestGPLogMarginalLikelihood(t *testing.T) {
+	X := NewArrayMatrix(1, 1)
+	y := NewVector(1)
+	y.Set(0, 0, 2)
+
+	gp := &GP{SignalVariance: 3, LengthScale: 1, NoiseVariance: 1}
+	gp.Fit(X, y)
+
+	// One observation is a Gaussian with variance 4.
+	ExpectFloat(-0.5*4/4-0.5*math.Log(2*math.Pi*4), gp.LogMarginalLikelihood(), t)
+}
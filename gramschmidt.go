@@ -0,0 +1,81 @@
+package linear
+
+// GramSchmidtOptions chooses the variant of Gram-Schmidt that
+// OrthogonalizeWithOptions uses and the inner product it's under.
+type GramSchmidtOptions struct {
+	// Classical projects each column against the earlier basis vectors
+	// all at once, instead of one after the other as modified
+	// Gram-Schmidt does. It's the textbook version and parallelizes
+	// better, but loses orthogonality with the square of the condition
+	// number rather than with it.
+	Classical bool
+	// Reorthogonalize projects each column a second time, which is
+	// enough to make the basis orthogonal to working precision either
+	// way ("twice is enough").
+	Reorthogonalize bool
+	// InnerProduct is what the basis is orthonormal under, Euclidean
+	// if nil.
+	InnerProduct InnerProduct
+}
+
+// gramSchmidtTolerance is how small, relative to its original length,
+// what's left of a column has to be to treat it as dependent on the
+// earlier ones.
+const gramSchmidtTolerance = 1e-12
+
+// Orthogonalize returns an orthonormal basis for the column space of A
+// by modified Gram-Schmidt, as the columns of an outs by r matrix.
+// Columns of A that are (to working precision) combinations of earlier
+// ones are skipped, so r is the rank of A, and the basis vectors span
+// the same spaces as the leading columns they came from. Unlike
+// DecomposeQR it gives the basis directly, but it's only as orthogonal
+// as A is well conditioned; see OrthogonalizeWithOptions.
+func Orthogonalize(A Matrix) Matrix {
+	return OrthogonalizeWithOptions(A, GramSchmidtOptions{})
+}
+
+// OrthogonalizeWithOptions is Orthogonalize with the given variant of
+// Gram-Schmidt.
+func OrthogonalizeWithOptions(A Matrix, opts GramSchmidtOptions) Matrix {
+	defer beginOp("GramSchmidt")()
+	ins, outs := A.Shape()
+	ip := opts.InnerProduct
+	if ip == nil {
+		ip = Euclidean
+	}
+	passes := 1
+	if opts.Reorthogonalize {
+		passes = 2
+	}
+	var qs []Vector
+	for i := 0; i < ins; i++ {
+		v := Copy(Slice(A, i, i+1, 0, outs))
+		mag := Norm(ip, v)
+		for pass := 0; pass < passes; pass++ {
+			// The inner products count their own flops.
+			countFlops(2 * outs * len(qs))
+			if opts.Classical {
+				coeffs := make([]float64, len(qs))
+				for j, q := range qs {
+					coeffs[j] = ip.Inner(q, v)
+				}
+				for j, q := range qs {
+					addScaledInto(v, q, -coeffs[j], v)
+				}
+			} else {
+				for _, q := range qs {
+					addScaledInto(v, q, -ip.Inner(q, v), v)
+				}
+			}
+		}
+		left := Norm(ip, v)
+		if left == 0 || left <= gramSchmidtTolerance*mag {
+			continue
+		}
+		for o := 0; o < outs; o++ {
+			v.Set(0, o, v.Get(0, o)/left)
+		}
+		qs = append(qs, v)
+	}
+	return vectorColumns(qs, outs)
+}
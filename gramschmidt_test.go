@@ -0,0 +1,91 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// orthogonalityLoss is the size of Dual(Q)*Q - I.
+func orthogonalityLoss(Q Matrix) float64 {
+	ins, _ := Q.Shape()
+	return frobeniusDistance(Apply(Dual(Q), Q), Identity(ins))
+}
+
+func TestOrthogonalize(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	A := randomMatrix(rng, 3, 6)
+	Q := Orthogonalize(A)
+
+	ins, outs := Q.Shape()
+	ExpectInt(3, ins, t)
+	ExpectInt(6, outs, t)
+	ExpectFloat(0, orthogonalityLoss(Q), t)
+	// The same column space: projecting A onto it changes nothing.
+	ExpectMatrix(A, Apply(Q, Apply(Dual(Q), A)), t)
+	// And the first column is just normalized.
+	a := Slice(A, 0, 1, 0, 6)
+	ExpectMatrix(mapEntries(a, func(f float64) float64 { return f / L2Norm(a) }), Slice(Q, 0, 1, 0, 6), t)
+}
+
+func TestOrthogonalizeDependent(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		1, 2, 0, 1,
+		1, 2, 0, 0,
+		0, 0, 0, 1,
+	}, 4, 3, 4)
+	Q := Orthogonalize(A)
+
+	// The second column is twice the first and the third is zero.
+	ins, _ := Q.Shape()
+	ExpectInt(2, ins, t)
+	ExpectFloat(0, orthogonalityLoss(Q), t)
+	ExpectMatrix(A, Apply(Q, Apply(Dual(Q), A)), t)
+}
+
+func TestOrthogonalizeVariants(t *testing.T) {
+	// The Hilbert matrix is about as badly conditioned as it gets.
+	n := 10
+	H := NewArrayMatrix(n, n)
+	for o := 0; o < n; o++ {
+		for i := 0; i < n; i++ {
+			H.Set(i, o, 1/float64(i+o+1))
+		}
+	}
+	classical := orthogonalityLoss(OrthogonalizeWithOptions(H, GramSchmidtOptions{Classical: true}))
+	modified := orthogonalityLoss(Orthogonalize(H))
+	twice := orthogonalityLoss(OrthogonalizeWithOptions(H, GramSchmidtOptions{Reorthogonalize: true}))
+	classicalTwice := orthogonalityLoss(OrthogonalizeWithOptions(H, GramSchmidtOptions{Classical: true, Reorthogonalize: true}))
+
+	if !(modified < classical) {
+		t.Errorf("expected modified (%g) to lose less than classical (%g)", modified, classical)
+	}
+	if twice > 1e-12 || classicalTwice > 1e-12 {
+		t.Errorf("expected reorthogonalized to be orthogonal but lost %g and %g", twice, classicalTwice)
+	}
+}
+
+func TestOrthogonalizeFlops(t *testing.T) {
+	EnableCounters(true)
+	defer EnableCounters(false)
+	ResetCounters()
+	Orthogonalize(randomMatrix(rand.New(rand.NewSource(1)), 3, 6))
+
+	// An inner product and an axpy of 2*6 flops each against the 0, 1
+	// and 2 earlier basis vectors.
+	ExpectInt(72, int(ReadCounters()["GramSchmidt"].Flops), t)
+}
+
+func TestOrthogonalizeInnerProduct(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	A := randomMatrix(rng, 3, 4)
+	M := MatrixFromSlice([]float64{
+		4, 1, 0, 0,
+		1, 3, 0, 0,
+		0, 0, 2, 0,
+		0, 0, 0, 1,
+	}, 4, 4, 4)
+	Q := OrthogonalizeWithOptions(A, GramSchmidtOptions{InnerProduct: MetricInnerProduct(M)})
+
+	// Orthonormal under M rather than the dot product.
+	ExpectMatrix(Identity(3), Apply(Dual(Q), Apply(M, Q)), t)
+}
@@ -0,0 +1,205 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// halfFormat is a 16 bit binary floating point format with a sign bit,
+// then expBits of exponent and mantBits of mantissa.
+type halfFormat struct {
+	expBits, mantBits uint
+}
+
+var (
+	float16Format  = halfFormat{5, 10}
+	bfloat16Format = halfFormat{8, 7}
+)
+
+// encode rounds f to the nearest value in the format, ties to even,
+// directly from float64 so there's no double rounding.
+func (h halfFormat) encode(f float64) uint16 {
+	maxExp := uint64(1)<<h.expBits - 1
+	inf := uint16(maxExp << h.mantBits)
+	bits := math.Float64bits(f)
+	sign := uint16(bits>>63) << 15
+	exp := int(bits >> 52 & 0x7ff)
+	mant := bits & (1<<52 - 1)
+	switch {
+	case exp == 0x7ff && mant != 0:
+		return sign | inf | 1<<(h.mantBits-1)
+	case exp == 0x7ff:
+		return sign | inf
+	case exp == 0:
+		// Subnormal float64s are far too small for either format.
+		return sign
+	}
+	bias := 1<<(h.expBits-1) - 1
+	e := exp - 1023 + bias
+	full := 1<<52 | mant
+	// Normal results keep the implicit bit, which the exponent field
+	// absorbs as base+m; subnormal ones shift it down.
+	shift := 52 - h.mantBits
+	var base uint64
+	if e >= 1 {
+		base = uint64(e-1) << h.mantBits
+	} else {
+		shift += uint(1 - e)
+	}
+	if shift > 60 {
+		return sign
+	}
+	m := full >> shift
+	rem := full & (1<<shift - 1)
+	half := uint64(1) << (shift - 1)
+	if rem > half || rem == half && m&1 == 1 {
+		m++
+	}
+	r := base + m
+	if r >= uint64(inf) {
+		return sign | inf
+	}
+	return sign | uint16(r)
+}
+
+// decode converts exactly from the format to float64.
+func (h halfFormat) decode(x uint16) float64 {
+	maxExp := uint16(1)<<h.expBits - 1
+	bias := 1<<(h.expBits-1) - 1
+	e := x >> h.mantBits & maxExp
+	m := x & (1<<h.mantBits - 1)
+	var f float64
+	switch {
+	case e == maxExp && m != 0:
+		return math.NaN()
+	case e == maxExp:
+		f = math.Inf(1)
+	case e == 0:
+		f = math.Ldexp(float64(m), 1-bias-int(h.mantBits))
+	default:
+		f = math.Ldexp(float64(1<<h.mantBits|m), int(e)-bias-int(h.mantBits))
+	}
+	if x&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+func (h halfFormat) encodeAll(dst []uint16, src []float64) {
+	if len(dst) != len(src) {
+		panic(fmt.Errorf("length mismatch %d vs %d", len(dst), len(src)))
+	}
+	for k, f := range src {
+		dst[k] = h.encode(f)
+	}
+}
+
+func (h halfFormat) decodeAll(dst []float64, src []uint16) {
+	if len(dst) != len(src) {
+		panic(fmt.Errorf("length mismatch %d vs %d", len(dst), len(src)))
+	}
+	for k, x := range src {
+		dst[k] = h.decode(x)
+	}
+}
+
+// EncodeFloat16 rounds each of src to IEEE half precision in dst.
+func EncodeFloat16(dst []uint16, src []float64) { float16Format.encodeAll(dst, src) }
+
+// DecodeFloat16 converts each IEEE half precision value of src into dst.
+func DecodeFloat16(dst []float64, src []uint16) { float16Format.decodeAll(dst, src) }
+
+// EncodeBFloat16 rounds each of src to bfloat16 in dst.
+func EncodeBFloat16(dst []uint16, src []float64) { bfloat16Format.encodeAll(dst, src) }
+
+// DecodeBFloat16 converts each bfloat16 value of src into dst.
+func DecodeBFloat16(dst []float64, src []uint16) { bfloat16Format.decodeAll(dst, src) }
+
+// halfMatrix stores entries in a 16 bit format, output (row) by
+// output, converting on every Get and Set.
+type halfMatrix struct {
+	data      []uint16
+	ins, outs int
+	format    halfFormat
+}
+
+func newHalfMatrix(ins, outs int, format halfFormat) halfMatrix {
+	if ins < 0 || outs < 0 {
+		panic(fmt.Errorf("invalid shape (%d, %d)", ins, outs))
+	}
+	return halfMatrix{make([]uint16, ins*outs), ins, outs, format}
+}
+
+func (m *halfMatrix) Shape() (ins, outs int) { return m.ins, m.outs }
+
+func (m *halfMatrix) Get(in, out int) float64 {
+	return m.format.decode(m.data[m.offset(in, out)])
+}
+
+// Set rounds value to the nearest representable one.
+func (m *halfMatrix) Set(in, out int, value float64) {
+	m.data[m.offset(in, out)] = m.format.encode(value)
+}
+
+// Bits returns the stored entries, output by output, for saving or
+// handing to other code that understands the format.
+func (m *halfMatrix) Bits() []uint16 { return m.data }
+
+func (m *halfMatrix) offset(in, out int) int {
+	if in < 0 || in >= m.ins || out < 0 || out >= m.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds (%d, %d)", in, out, m.ins, m.outs))
+	}
+	return out*m.ins + in
+}
+
+func (m *halfMatrix) copyFrom(A Matrix) {
+	row := make([]float64, m.ins)
+	for o := 0; o < m.outs; o++ {
+		for i := range row {
+			row[i] = A.Get(i, o)
+		}
+		m.format.encodeAll(m.data[o*m.ins:(o+1)*m.ins], row)
+	}
+}
+
+// Float16Matrix is a Matrix stored in IEEE half precision, which has
+// about 3 decimal digits and a range up to 65504, for halving (or
+// quartering) the memory and bandwidth of large parameter matrices.
+// Only storage is in half precision: Get returns float64s, so
+// everything computed from it accumulates at full precision.
+type Float16Matrix struct {
+	halfMatrix
+}
+
+// NewFloat16Matrix makes a new zero Float16Matrix.
+func NewFloat16Matrix(ins, outs int) *Float16Matrix {
+	return &Float16Matrix{newHalfMatrix(ins, outs, float16Format)}
+}
+
+// Float16MatrixFrom makes a Float16Matrix with A's entries rounded to
+// half precision.
+func Float16MatrixFrom(A Matrix) *Float16Matrix {
+	m := NewFloat16Matrix(A.Shape())
+	m.copyFrom(A)
+	return m
+}
+
+// BFloat16Matrix is a Matrix stored in bfloat16, which has the range of
+// float32 but only about 2 decimal digits, the usual trade for neural
+// network weights. Like Float16Matrix, only storage is 16 bit.
+type BFloat16Matrix struct {
+	halfMatrix
+}
+
+// NewBFloat16Matrix makes a new zero BFloat16Matrix.
+func NewBFloat16Matrix(ins, outs int) *BFloat16Matrix {
+	return &BFloat16Matrix{newHalfMatrix(ins, outs, bfloat16Format)}
+}
+
+// BFloat16MatrixFrom makes a BFloat16Matrix with A's entries rounded to
+// bfloat16.
+func BFloat16MatrixFrom(A Matrix) *BFloat16Matrix {
+	m := NewBFloat16Matrix(A.Shape())
+	m.copyFrom(A)
+	return m
+}
@@ -0,0 +1,94 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat16Encoding(t *testing.T) {
+	for _, c := range []struct {
+		f    float64
+		bits uint16
+	}{
+		{0, 0x0000},
+		{math.Copysign(0, -1), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{65504, 0x7bff},
+		// Halfway to 65536 rounds to even, which overflows.
+		{65520, 0x7c00},
+		{math.Inf(-1), 0xfc00},
+		{math.Ldexp(1, -14), 0x0400},
+		{math.Ldexp(1, -24), 0x0001},
+		{math.Ldexp(1, -25), 0x0000},
+		{math.Ldexp(3, -26), 0x0001},
+		{1 + math.Ldexp(1, -11), 0x3c00},
+		{1 + math.Ldexp(3, -11), 0x3c02},
+		{1.0 / 3, 0x3555},
+	} {
+		dst := make([]uint16, 1)
+		EncodeFloat16(dst, []float64{c.f})
+		if dst[0] != c.bits {
+			t.Errorf("expected %v to encode as %#04x but got %#04x", c.f, c.bits, dst[0])
+		}
+	}
+	dst := make([]uint16, 1)
+	EncodeFloat16(dst, []float64{math.NaN()})
+	back := make([]float64, 1)
+	DecodeFloat16(back, dst)
+	if !math.IsNaN(back[0]) {
+		t.Errorf("expected NaN but got %v", back[0])
+	}
+}
+
+func TestHalfRoundTrip(t *testing.T) {
+	// Every value in both formats converts to float64 and back exactly.
+	src := make([]uint16, 1<<16)
+	for k := range src {
+		src[k] = uint16(k)
+	}
+	values := make([]float64, len(src))
+	back := make([]uint16, len(src))
+	for _, format := range []halfFormat{float16Format, bfloat16Format} {
+		format.decodeAll(values, src)
+		format.encodeAll(back, values)
+		for k, x := range src {
+			if math.IsNaN(values[k]) {
+				continue
+			}
+			if back[k] != x {
+				t.Fatalf("%v: %#04x decodes as %v but encodes back as %#04x", format, x, values[k], back[k])
+			}
+		}
+	}
+}
+
+func TestBFloat16Encoding(t *testing.T) {
+	dst := make([]uint16, 4)
+	EncodeBFloat16(dst, []float64{1, math.Pi, -3e38, 1e39})
+	for k, bits := range []uint16{0x3f80, 0x4049, 0xff62, 0x7f80} {
+		if dst[k] != bits {
+			t.Errorf("expected %#04x but got %#04x", bits, dst[k])
+		}
+	}
+}
+
+func TestHalfMatrices(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 0.1, -3, 1000.3}, 2, 2, 2)
+
+	H := Float16MatrixFrom(A)
+	ExpectFloat(1, H.Get(0, 0), t)
+	ExpectFloat(0.0999755859375, H.Get(1, 0), t)
+	ExpectFloat(1000.5, H.Get(1, 1), t)
+	ExpectInt(4, len(H.Bits()), t)
+
+	B := BFloat16MatrixFrom(A)
+	ExpectFloat(0.10009765625, B.Get(1, 0), t)
+	ExpectFloat(1000, B.Get(1, 1), t)
+
+	// They work anywhere a Matrix does, computing in float64.
+	ExpectMatrix(Apply(Copy(H), Copy(B)), Apply(H, B), t)
+	B.Set(0, 1, 2.5)
+	ExpectFloat(2.5, B.Get(0, 1), t)
+	expectPanic(t, func() { NewFloat16Matrix(2, 2).Get(2, 0) })
+}
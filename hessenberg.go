@@ -0,0 +1,38 @@
+package linear
+
+// ReduceHessenberg reduces a square A to upper Hessenberg form H, zero
+// below the first subdiagonal, with A = Q*H*Dual(Q) for an orthogonal
+// Q. It's the usual first step of eigenvalue algorithms like QR
+// iteration, since H has the same eigenvalues and each step on it
+// keeps its shape and costs n^2 rather than n^3. Each column is
+// handled by a Householder reflection applied from both sides, like
+// FactorQR but leaving the subdiagonal so the similarity transform
+// doesn't undo it. A symmetric A comes out tridiagonal.
+func ReduceHessenberg(A Matrix) (H, Q Matrix) {
+	defer beginOp("Hessenberg")()
+	checkSquare(A)
+	n, _ := A.Shape()
+	H = Copy(A)
+	Q = Identity(n)
+	scale := MaxAbs(A)
+	for k := 0; k+2 < n; k++ {
+		if IsZeroRelative(Slice(H, k, k+1, k+2, n), scale, DefaultQRTolerance) {
+			for o := k + 2; o < n; o++ {
+				H.Set(k, o, 0)
+			}
+			continue
+		}
+		x := Slice(H, k, k+1, k+1, n)
+		v, beta := HouseholderVector(x, BasisVector(n-k-1, 0))
+
+		// The left reflection mixes rows k+1 and down, where column k
+		// is zeroed, and the right one mixes the same columns back.
+		ApplyHouseholderLeft(v, beta, Slice(H, k, n, k+1, n))
+		ApplyHouseholderRight(v, beta, Slice(H, k+1, n, 0, n))
+		ApplyHouseholderRight(v, beta, Slice(Q, k+1, n, 0, n))
+		for o := k + 2; o < n; o++ {
+			H.Set(k, o, 0)
+		}
+	}
+	return H, Q
+}
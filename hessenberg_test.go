@@ -0,0 +1,50 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestReduceHessenberg(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	A := NewArrayMatrix(5, 5)
+	for o := 0; o < 5; o++ {
+		for i := 0; i < 5; i++ {
+			A.Set(i, o, rng.NormFloat64())
+		}
+	}
+
+	H, Q := ReduceHessenberg(A)
+
+	for o := 0; o < 5; o++ {
+		for i := 0; i+1 < o; i++ {
+			ExpectFloat(0, H.Get(i, o), t)
+		}
+	}
+	ExpectMatrix(Identity(5), Compose(Q, Dual(Q)), t)
+	ExpectMatrix(A, Apply(Q, Apply(H, Dual(Q))), t)
+}
+
+func TestReduceHessenbergSymmetric(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		4, 1, 2, 2,
+		1, 2, 0, 1,
+		2, 0, 3, 2,
+		2, 1, 2, 1,
+	}, 4, 4, 4)
+
+	H, Q := ReduceHessenberg(A)
+
+	// Symmetric in, tridiagonal out.
+	for o := 0; o < 4; o++ {
+		for i := o + 2; i < 4; i++ {
+			ExpectFloat(0, H.Get(i, o), t)
+		}
+	}
+	ExpectMatrix(A, Apply(Q, Apply(H, Dual(Q))), t)
+
+	// Already Hessenberg matrices are left alone.
+	H2, Q2 := ReduceHessenberg(MatrixFromSlice([]float64{1, 2, 3, 4}, 2, 2, 2))
+	ExpectMatrix(MatrixFromSlice([]float64{1, 2, 3, 4}, 2, 2, 2), H2, t)
+	ExpectMatrix(Identity(2), Q2, t)
+}
@@ -0,0 +1,112 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// InnerProduct measures lengths of and angles between vectors.
+type InnerProduct interface {
+	// Inner returns the inner product of x and y, which must be
+	// symmetric, linear in each argument, and positive for x = y != 0.
+	Inner(x, y Vector) float64
+}
+
+type euclidean struct{}
+
+// Euclidean is the standard inner product, the dot product.
+var Euclidean InnerProduct = euclidean{}
+
+func (euclidean) Inner(x, y Vector) float64 {
+	return DotProduct(x, Dual(y))
+}
+
+type metricInnerProduct struct {
+	M Matrix
+}
+
+// MetricInnerProduct returns the inner product Dual(x)*M*y for a
+// symmetric positive definite M, such as the inverse of a covariance
+// for Mahalanobis geometry.
+func MetricInnerProduct(M Matrix) InnerProduct {
+	ins, outs := M.Shape()
+	if ins != outs {
+		panic(fmt.Errorf("metric isn't square shape=(%d, %d)", ins, outs))
+	}
+	return metricInnerProduct{M}
+}
+
+func (ip metricInnerProduct) Inner(x, y Vector) float64 {
+	return Pair(DualOf(x, ip.M), y)
+}
+
+// Norm returns the length of v under the inner product.
+func Norm(ip InnerProduct, v Vector) float64 {
+	if ip == Euclidean {
+		return L2Norm(v)
+	}
+	return math.Sqrt(ip.Inner(v, v))
+}
+
+// NormalizeIntoWith writes into dst a vector in the same direction as
+// src but with unit length under the inner product.
+func NormalizeIntoWith(ip InnerProduct, src, dst Vector) {
+	CheckVector(src)
+	CheckVector(dst)
+	CheckSameShape(src, dst)
+	mag := Norm(ip, src)
+	if mag == 0 || math.IsInf(mag, 0) || math.IsNaN(mag) {
+		panic(fmt.Errorf("can't normalize a vector with length %f", mag))
+	}
+	_, dim := dst.Shape()
+	for d := 0; d < dim; d++ {
+		dst.Set(0, d, src.Get(0, d)/mag)
+	}
+}
+
+// NormalizeWith makes v unit length under the inner product.
+func NormalizeWith(ip InnerProduct, v Vector) {
+	NormalizeIntoWith(ip, v, v)
+}
+
+// Project returns the component of v in the direction of u, which is
+// orthogonal to what's left over under the inner product.
+func Project(ip InnerProduct, v, u Vector) Vector {
+	CheckVector(v)
+	CheckVector(u)
+	CheckSameShape(v, u)
+	scale := ip.Inner(u, v) / ip.Inner(u, u)
+	_, dim := u.Shape()
+	p := NewVector(dim)
+	for d := 0; d < dim; d++ {
+		p.Set(0, d, scale*u.Get(0, d))
+	}
+	return p
+}
+
+// BilinearForm returns Dual(x)*A*y without forming A*y.
+func BilinearForm(A Matrix, x, y Vector) float64 {
+	CheckVector(x)
+	CheckVector(y)
+	CheckComposable(y, A)
+	CheckSameOuts(A, x)
+	ins, outs := A.Shape()
+	sum := 0.0
+	for o := 0; o < outs; o++ {
+		xo := x.Get(0, o)
+		if xo == 0 {
+			continue
+		}
+		dot := 0.0
+		for i := 0; i < ins; i++ {
+			dot += A.Get(i, o) * y.Get(0, i)
+		}
+		sum += xo * dot
+	}
+	return sum
+}
+
+// QuadraticForm returns Dual(x)*A*x for a square A.
+func QuadraticForm(A Matrix, x Vector) float64 {
+	return BilinearForm(A, x, x)
+}
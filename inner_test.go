@@ -0,0 +1,112 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEuclidean(t *testing.T) {
+	x := NewVector(2)
+	x.Set(0, 0, 3)
+	x.Set(0, 1, 4)
+
+	y := NewVector(2)
+	y.Set(0, 0, 1)
+	y.Set(0, 1, 2)
+
+	ExpectFloat(11, Euclidean.Inner(x, y), t)
+	ExpectFloat(5, Norm(Euclidean, x), t)
+}
+
+func TestMetricInnerProduct(t *testing.T) {
+	M := NewArrayMatrix(2, 2)
+	M.Set(0, 0, 2)
+	M.Set(1, 0, 1)
+	M.Set(0, 1, 1)
+	M.Set(1, 1, 3)
+	ip := MetricInnerProduct(M)
+
+	x := NewVector(2)
+	x.Set(0, 0, 1)
+	x.Set(0, 1, 0)
+
+	y := NewVector(2)
+	y.Set(0, 0, 0)
+	y.Set(0, 1, 1)
+
+	ExpectFloat(1, ip.Inner(x, y), t)
+	ExpectFloat(1, ip.Inner(y, x), t)
+	ExpectFloat(math.Sqrt(2), Norm(ip, x), t)
+
+	NormalizeWith(ip, y)
+
+	ExpectFloat(1/math.Sqrt(3), y.Get(0, 1), t)
+	ExpectFloat(1, Norm(ip, y), t)
+}
+
+func TestProject(t *testing.T) {
+	v := NewVector(2)
+	v.Set(0, 0, 2)
+	v.Set(0, 1, 1)
+
+	u := NewVector(2)
+	u.Set(0, 0, 3)
+	u.Set(0, 1, 0)
+
+	p := Project(Euclidean, v, u)
+
+	ExpectFloat(2, p.Get(0, 0), t)
+	ExpectFloat(0, p.Get(0, 1), t)
+
+	M := NewArrayMatrix(2, 2)
+	M.Set(0, 0, 2)
+	M.Set(1, 0, 1)
+	M.Set(0, 1, 1)
+	M.Set(1, 1, 3)
+	ip := MetricInnerProduct(M)
+
+	p = Project(ip, v, u)
+
+	// What's left over is orthogonal to u under the metric.
+	r := NewVector(2)
+	r.Set(0, 0, v.Get(0, 0)-p.Get(0, 0))
+	r.Set(0, 1, v.Get(0, 1)-p.Get(0, 1))
+	ExpectFloat(0, ip.Inner(u, r), t)
+	ExpectFloat(2.5, p.Get(0, 0), t)
+}
+
+func TestBilinearForm(t *testing.T) {
+	A := NewArrayMatrix(2, 3)
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 3)
+	A.Set(1, 1, 4)
+	A.Set(0, 2, 5)
+	A.Set(1, 2, 6)
+
+	x := NewVector(3)
+	x.Set(0, 0, 1)
+	x.Set(0, 1, 0)
+	x.Set(0, 2, -1)
+
+	y := NewVector(2)
+	y.Set(0, 0, 2)
+	y.Set(0, 1, 1)
+
+	ExpectFloat(-12, BilinearForm(A, x, y), t)
+}
+
+func TestQuadraticForm(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 2)
+	A.Set(1, 0, 1)
+	A.Set(0, 1, 1)
+	A.Set(1, 1, 3)
+
+	x := NewVector(2)
+	x.Set(0, 0, 1)
+	x.Set(0, 1, 2)
+
+	ExpectFloat(18, QuadraticForm(A, x), t)
+	ExpectFloat(MetricInnerProduct(A).Inner(x, x), QuadraticForm(A, x), t)
+}
@@ -0,0 +1,185 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// Interval is the set of reals from Lo to Hi. Arithmetic on intervals
+// rounds outwards, so the result always contains the exact result for
+// every choice of reals from the operands, and a computation done
+// entirely in intervals carries a rigorous bound on its own rounding
+// error.
+type Interval struct {
+	Lo, Hi float64
+}
+
+// Point returns the interval containing only f.
+func Point(f float64) Interval { return Interval{f, f} }
+
+// outward widens [lo, hi] by an ulp on each side, which covers the half
+// ulp of rounding in the operation that produced them.
+func outward(lo, hi float64) Interval {
+	return Interval{math.Nextafter(lo, math.Inf(-1)), math.Nextafter(hi, math.Inf(1))}
+}
+
+func (a Interval) Add(b Interval) Interval { return outward(a.Lo+b.Lo, a.Hi+b.Hi) }
+func (a Interval) Sub(b Interval) Interval { return outward(a.Lo-b.Hi, a.Hi-b.Lo) }
+
+func (a Interval) Mul(b Interval) Interval {
+	p := [4]float64{a.Lo * b.Lo, a.Lo * b.Hi, a.Hi * b.Lo, a.Hi * b.Hi}
+	lo, hi := p[0], p[0]
+	for _, f := range p[1:] {
+		lo, hi = math.Min(lo, f), math.Max(hi, f)
+	}
+	return outward(lo, hi)
+}
+
+// Div panics if b contains zero, since the quotient would be unbounded.
+func (a Interval) Div(b Interval) Interval {
+	if b.Contains(0) {
+		panic(fmt.Errorf("can't divide by %v, which contains zero", b))
+	}
+	return a.Mul(outward(1/b.Hi, 1/b.Lo))
+}
+
+// Contains reports whether f is in the interval.
+func (a Interval) Contains(f float64) bool { return a.Lo <= f && f <= a.Hi }
+
+// Mid returns the midpoint of the interval.
+func (a Interval) Mid() float64 { return a.Lo + (a.Hi-a.Lo)/2 }
+
+// Width returns Hi - Lo, an upper bound on the error of taking any
+// point in the interval as the answer.
+func (a Interval) Width() float64 { return a.Hi - a.Lo }
+
+func (a Interval) String() string { return fmt.Sprintf("[%g, %g]", a.Lo, a.Hi) }
+
+// IntervalMatrix is a Matrix of intervals. As a Matrix it reads the
+// midpoints and sets point intervals, while GetInterval and
+// SetInterval work with the enclosures themselves.
+type IntervalMatrix struct {
+	ins, outs int
+	// entries are by output (row), then input.
+	entries []Interval
+}
+
+// NewIntervalMatrix makes a new zero IntervalMatrix with the given
+// shape.
+func NewIntervalMatrix(ins, outs int) *IntervalMatrix {
+	if ins < 0 || outs < 0 {
+		panic(fmt.Errorf("invalid shape (%d, %d)", ins, outs))
+	}
+	return &IntervalMatrix{ins: ins, outs: outs, entries: make([]Interval, ins*outs)}
+}
+
+// IntervalMatrixFrom makes an IntervalMatrix of the point intervals of
+// A.
+func IntervalMatrixFrom(A Matrix) *IntervalMatrix {
+	ins, outs := A.Shape()
+	m := NewIntervalMatrix(ins, outs)
+	CopyInto(A, m)
+	return m
+}
+
+func (m *IntervalMatrix) Shape() (ins, outs int) { return m.ins, m.outs }
+
+func (m *IntervalMatrix) Get(in, out int) float64 { return m.GetInterval(in, out).Mid() }
+
+func (m *IntervalMatrix) Set(in, out int, value float64) { m.SetInterval(in, out, Point(value)) }
+
+func (m *IntervalMatrix) GetInterval(in, out int) Interval {
+	m.checkBounds(in, out)
+	return m.entries[out*m.ins+in]
+}
+
+func (m *IntervalMatrix) SetInterval(in, out int, value Interval) {
+	m.checkBounds(in, out)
+	if value.Lo > value.Hi {
+		panic(fmt.Errorf("empty interval %v", value))
+	}
+	m.entries[out*m.ins+in] = value
+}
+
+func (m *IntervalMatrix) checkBounds(in, out int) {
+	if in < 0 || in >= m.ins || out < 0 || out >= m.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds for shape (%d, %d)", in, out, m.ins, m.outs))
+	}
+}
+
+// Contains reports whether every entry of A is in the corresponding
+// interval.
+func (m *IntervalMatrix) Contains(A Matrix) bool {
+	CheckSameShape(m, A)
+	for o := 0; o < m.outs; o++ {
+		for i := 0; i < m.ins; i++ {
+			if !m.GetInterval(i, o).Contains(A.Get(i, o)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MaxWidth returns the width of the widest entry.
+func (m *IntervalMatrix) MaxWidth() float64 {
+	w := 0.0
+	for _, e := range m.entries {
+		w = math.Max(w, e.Width())
+	}
+	return w
+}
+
+// ApplyInterval returns an enclosure of A*X for every choice of
+// matrices from the intervals of A and X.
+func ApplyInterval(A, X *IntervalMatrix) *IntervalMatrix {
+	if A.ins != X.outs {
+		panic(fmt.Errorf("dimension mismatch %d vs %d", X.outs, A.ins))
+	}
+	Y := NewIntervalMatrix(X.ins, A.outs)
+	for o := 0; o < A.outs; o++ {
+		for i := 0; i < X.ins; i++ {
+			s := Point(0)
+			for k := 0; k < A.ins; k++ {
+				s = s.Add(A.GetInterval(k, o).Mul(X.GetInterval(i, k)))
+			}
+			Y.SetInterval(i, o, s)
+		}
+	}
+	return Y
+}
+
+// FindInputUpperTriangularInterval returns an enclosure of the x with
+// R*x = b for every choice of R and b from their intervals, by back
+// substitution. It panics if a diagonal interval of R contains zero.
+func FindInputUpperTriangularInterval(R, b *IntervalMatrix) *IntervalMatrix {
+	CheckVector(b)
+	CheckSameOuts(R, b)
+	n := R.ins
+	x := NewIntervalMatrix(1, n)
+	for o := n - 1; o >= 0; o-- {
+		s := b.GetInterval(0, o)
+		for i := o + 1; i < n; i++ {
+			s = s.Sub(R.GetInterval(i, o).Mul(x.GetInterval(0, i)))
+		}
+		x.SetInterval(0, o, s.Div(R.GetInterval(o, o)))
+	}
+	return x
+}
+
+// FindInputLowerTriangularInterval is FindInputUpperTriangularInterval
+// for a lower triangular L, by forward substitution.
+func FindInputLowerTriangularInterval(L, b *IntervalMatrix) *IntervalMatrix {
+	CheckVector(b)
+	CheckSameOuts(L, b)
+	n := L.ins
+	x := NewIntervalMatrix(1, n)
+	for o := 0; o < n; o++ {
+		s := b.GetInterval(0, o)
+		for i := 0; i < o; i++ {
+			s = s.Sub(L.GetInterval(i, o).Mul(x.GetInterval(0, i)))
+		}
+		x.SetInterval(0, o, s.Div(L.GetInterval(o, o)))
+	}
+	return x
+}
@@ -0,0 +1,75 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestIntervalArithmetic(t *testing.T) {
+	a := Interval{1, 2}
+	b := Interval{-3, 0.5}
+
+	for _, test := range []struct {
+		got    Interval
+		lo, hi float64
+	}{
+		{a.Add(b), -2, 2.5},
+		{a.Sub(b), 0.5, 5},
+		{a.Mul(b), -6, 1},
+		{a.Div(Interval{2, 4}), 0.25, 1},
+	} {
+		if !test.got.Contains(test.lo) || !test.got.Contains(test.hi) {
+			t.Errorf("expected %v to contain [%g, %g]", test.got, test.lo, test.hi)
+		}
+		if test.got.Width() > test.hi-test.lo+1e-12 {
+			t.Errorf("expected %v to be tight around [%g, %g]", test.got, test.lo, test.hi)
+		}
+	}
+
+	// 0.1 isn't representable, but the enclosure of 0.1+0.2 contains
+	// both 0.3 and the float64 sum.
+	s := Point(0.1).Add(Point(0.2))
+	if !s.Contains(0.1+0.2) || !s.Contains(0.3) {
+		t.Errorf("expected %v to contain 0.3", s)
+	}
+}
+
+func TestFindInputUpperTriangularInterval(t *testing.T) {
+	R := MatrixFromSlice([]float64{
+		3, 1, -2,
+		0, 1e-3, 5,
+		0, 0, 7,
+	}, 3, 3, 3)
+	b := MatrixFromSlice([]float64{1, 2, 3}, 1, 3, 1)
+
+	x := FindInputUpperTriangularInterval(IntervalMatrixFrom(R), IntervalMatrixFrom(b))
+
+	if !x.Contains(FindInputUpperTriangular(R, b)) {
+		t.Errorf("expected the float64 solution to be enclosed")
+	}
+	// The exact solution, computed by hand.
+	x2 := 3.0 / 7
+	x1 := (2 - 5*x2) / 1e-3
+	x0 := (1 - x1 + 2*x2) / 3
+	if !x.Contains(MatrixFromSlice([]float64{x0, x1, x2}, 1, 3, 1)) {
+		t.Errorf("expected the exact solution to be enclosed")
+	}
+	if w := x.MaxWidth(); w == 0 || w > 1e-9 {
+		t.Errorf("expected a small but non-zero error bound, got %g", w)
+	}
+}
+
+func TestApplyInterval(t *testing.T) {
+	A := NewIntervalMatrix(2, 1)
+	A.SetInterval(0, 0, Interval{1, 1.5})
+	A.SetInterval(1, 0, Point(-1))
+	x := IntervalMatrixFrom(MatrixFromSlice([]float64{2, 1}, 1, 2, 1))
+
+	y := ApplyInterval(A, x)
+
+	got := y.GetInterval(0, 0)
+	if !got.Contains(1) || !got.Contains(2) || got.Width() > 1+1e-12 {
+		t.Errorf("expected about [1, 2] but got %v", got)
+	}
+	I := IntervalMatrixFrom(Identity(3))
+	ExpectMatrix(Identity(3), ApplyInterval(I, I), t)
+}
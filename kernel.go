@@ -0,0 +1,85 @@
+package linear
+
+import (
+	"math"
+)
+
+// KernelFunc is an inner product between two observations (covectors)
+// taken after some, possibly implicit, feature map.
+type KernelFunc func(x, y Covector) float64
+
+// LinearKernel is the dot product of the observations themselves.
+func LinearKernel(x, y Covector) float64 {
+	return DotProduct(Dual(x), y)
+}
+
+// RBFKernel returns the Gaussian kernel exp(-gamma*|x-y|^2).
+func RBFKernel(gamma float64) KernelFunc {
+	return func(x, y Covector) float64 {
+		CheckSameShape(x, y)
+		ins, _ := x.Shape()
+		dist := 0.0
+		for i := 0; i < ins; i++ {
+			d := x.Get(i, 0) - y.Get(i, 0)
+			dist += d * d
+		}
+		return math.Exp(-gamma * dist)
+	}
+}
+
+// PolynomialKernel returns the kernel (x.y + c)^degree.
+func PolynomialKernel(degree int, c float64) KernelFunc {
+	return func(x, y Covector) float64 {
+		return math.Pow(LinearKernel(x, y)+c, float64(degree))
+	}
+}
+
+// GramMatrix returns the pairwise inner products of the observations
+// (outputs) of X, which is X*Dual(X). Use Dual(X) for the pairwise
+// inner products of the features instead.
+func GramMatrix(X Matrix) Matrix {
+	return Compose(Dual(X), X)
+}
+
+// KernelMatrix returns the pairwise kernel values of the observations
+// (outputs) of X.
+func KernelMatrix(X Matrix, k KernelFunc) Matrix {
+	return CrossKernelMatrix(X, X, k)
+}
+
+// CrossKernelMatrix returns the kernel values between each observation
+// of X (outputs) and each observation of Y (inputs).
+func CrossKernelMatrix(X, Y Matrix, k KernelFunc) Matrix {
+	CheckSameIns(X, Y)
+	ins, xOuts := X.Shape()
+	_, yOuts := Y.Shape()
+	K := NewArrayMatrix(yOuts, xOuts)
+	for o := 0; o < xOuts; o++ {
+		x := Slice(X, 0, ins, o, o+1)
+		for i := 0; i < yOuts; i++ {
+			K.Set(i, o, k(x, Slice(Y, 0, ins, i, i+1)))
+		}
+	}
+	return K
+}
+
+// KernelRidge fits kernel ridge regression, finding the weights alpha
+// on the training observations that solve (K + lambda*I)*alpha = y by
+// Cholesky decomposition.
+func KernelRidge(X, y Matrix, k KernelFunc, lambda float64) Vector {
+	CheckVector(y)
+	CheckSameOuts(X, y)
+	K := KernelMatrix(X, k)
+	_, n := K.Shape()
+	for d := 0; d < n; d++ {
+		K.Set(d, d, K.Get(d, d)+lambda)
+	}
+	return SolveCholesky(DecomposeCholesky(K), y)
+}
+
+// KernelRidgePredict predicts the responses of the observations in
+// Xstar given the training observations X and weights alpha from
+// KernelRidge.
+func KernelRidgePredict(X Matrix, alpha Vector, k KernelFunc, Xstar Matrix) Vector {
+	return Apply(CrossKernelMatrix(Xstar, X, k), alpha)
+}
@@ -0,0 +1,75 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGramMatrix(t *testing.T) {
+	X := NewArrayMatrix(2, 3)
+	X.Set(0, 0, 1)
+	X.Set(1, 0, 2)
+	X.Set(0, 1, 3)
+	X.Set(1, 1, 4)
+	X.Set(0, 2, 5)
+	X.Set(1, 2, 6)
+
+	G := GramMatrix(X)
+
+	ins, outs := G.Shape()
+	ExpectInt(3, ins, t)
+	ExpectInt(3, outs, t)
+	ExpectFloat(5, G.Get(0, 0), t)
+	ExpectFloat(11, G.Get(1, 0), t)
+	ExpectFloat(17, G.Get(2, 0), t)
+	ExpectFloat(61, G.Get(2, 2), t)
+
+	K := KernelMatrix(X, LinearKernel)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			ExpectFloat(G.Get(i, o), K.Get(i, o), t)
+		}
+	}
+}
+
+func TestKernels(t *testing.T) {
+	x := NewCovector(2)
+	x.Set(0, 0, 1)
+	x.Set(1, 0, 2)
+
+	y := NewCovector(2)
+	y.Set(0, 0, 2)
+	y.Set(1, 0, 0)
+
+	ExpectFloat(2, LinearKernel(x, y), t)
+	ExpectFloat(math.Exp(-0.5*5), RBFKernel(0.5)(x, y), t)
+	ExpectFloat(9, PolynomialKernel(2, 1)(x, y), t)
+}
+
+func TestKernelRidge(t *testing.T) {
+	X := NewArrayMatrix(1, 5)
+	y := NewVector(5)
+	for o := 0; o < 5; o++ {
+		x := float64(o) - 2
+		X.Set(0, o, x)
+		y.Set(0, o, x*x)
+	}
+
+	k := PolynomialKernel(2, 1)
+	alpha := KernelRidge(X, y, k, 1e-9)
+
+	Xstar := NewArrayMatrix(1, 2)
+	Xstar.Set(0, 0, 0.5)
+	Xstar.Set(0, 1, 3)
+
+	ystar := KernelRidgePredict(X, alpha, k, Xstar)
+
+	_, dim := ystar.Shape()
+	ExpectInt(2, dim, t)
+	if math.Abs(ystar.Get(0, 0)-0.25) > 1e-6 {
+		t.Errorf("expected 0.25 but got %f", ystar.Get(0, 0))
+	}
+	if math.Abs(ystar.Get(0, 1)-9) > 1e-6 {
+		t.Errorf("expected 9 but got %f", ystar.Get(0, 1))
+	}
+}
@@ -0,0 +1,95 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// The innermost loops of the array kernels. These start out as the
+// portable versions below and may be replaced at init with assembly
+// versions for the CPU the program is running on.
+var (
+	dotKernel  = dotGeneric
+	axpyKernel = axpyGeneric
+	gemmKernel = gemm4x8Generic
+)
+
+// dot returns the sum of the products of corresponding entries of x and
+// y, which must be the same length.
+func dot(x, y []float64) float64 {
+	if len(x) != len(y) {
+		panic(fmt.Errorf("length mismatch %d vs %d", len(x), len(y)))
+	}
+	return parallelDot(x, y)
+}
+
+// axpy adds a*x to y, which must be the same length.
+func axpy(a float64, x, y []float64) {
+	if len(x) != len(y) {
+		panic(fmt.Errorf("length mismatch %d vs %d", len(x), len(y)))
+	}
+	axpyKernel(a, x, y)
+}
+
+// gemm4x8 sets the 4 by 8 block of c, with rows cStride apart, to the
+// product of the 4 by k panel packed in lp, column by column, and the
+// k by 8 panel of r, with rows rStride apart.
+func gemm4x8(k int, lp, r []float64, rStride int, c []float64, cStride int) {
+	if len(lp) < 4*k || k > 0 && len(r) < (k-1)*rStride+8 || len(c) < 3*cStride+8 {
+		panic(fmt.Errorf("gemm panels too short for k=%d", k))
+	}
+	gemmKernel(k, lp, r, rStride, c, cStride)
+}
+
+// dotGeneric is unrolled so that the compiler can keep four
+// independent sums in flight.
+func dotGeneric(x, y []float64) float64 {
+	var s0, s1, s2, s3 float64
+	n := len(x)
+	y = y[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		s0 += x[i] * y[i]
+		s1 += x[i+1] * y[i+1]
+		s2 += x[i+2] * y[i+2]
+		s3 += x[i+3] * y[i+3]
+	}
+	for ; i < n; i++ {
+		s0 += x[i] * y[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+func axpyGeneric(a float64, x, y []float64) {
+	n := len(x)
+	y = y[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		y[i] += a * x[i]
+		y[i+1] += a * x[i+1]
+		y[i+2] += a * x[i+2]
+		y[i+3] += a * x[i+3]
+	}
+	for ; i < n; i++ {
+		y[i] += a * x[i]
+	}
+}
+
+// gemm4x8Generic keeps the 32 sums of the block in local arrays, so
+// each entry of the panels is loaded once per step of k.
+func gemm4x8Generic(k int, lp, r []float64, rStride int, c []float64, cStride int) {
+	var c0, c1, c2, c3 [8]float64
+	for p := 0; p < k; p++ {
+		l := lp[4*p : 4*p+4]
+		rp := r[p*rStride : p*rStride+8]
+		for j, x := range rp {
+			c0[j] += l[0] * x
+			c1[j] += l[1] * x
+			c2[j] += l[2] * x
+			c3[j] += l[3] * x
+		}
+	}
+	copy(c[:8], c0[:])
+	copy(c[cStride:cStride+8], c1[:])
+	copy(c[2*cStride:2*cStride+8], c2[:])
+	copy(c[3*cStride:3*cStride+8], c3[:])
+}
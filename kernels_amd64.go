@@ -0,0 +1,47 @@
+//go:build !purego
+
+package linear
+
+func init() {
+	if hasAVXFMA() {
+		dotKernel = dotAVXFMA
+		axpyKernel = axpyAVXFMA
+		gemmKernel = gemm4x8AVXFMA
+	}
+}
+
+// hasAVXFMA checks that both the CPU and the operating system support
+// the 256-bit AVX registers and fused multiply-add.
+func hasAVXFMA() bool {
+	max, _, _, _ := cpuid(0, 0)
+	if max < 1 {
+		return false
+	}
+	_, _, ecx, _ := cpuid(1, 0)
+	const (
+		fma     = 1 << 12
+		osxsave = 1 << 27
+		avx     = 1 << 28
+	)
+	if ecx&(fma|osxsave|avx) != fma|osxsave|avx {
+		return false
+	}
+	// The OS must save the XMM and YMM registers on context switches.
+	eax, _ := xgetbv()
+	return eax&6 == 6
+}
+
+//go:noescape
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+//go:noescape
+func xgetbv() (eax, edx uint32)
+
+//go:noescape
+func dotAVXFMA(x, y []float64) float64
+
+//go:noescape
+func axpyAVXFMA(a float64, x, y []float64)
+
+//go:noescape
+func gemm4x8AVXFMA(k int, lp, r []float64, rStride int, c []float64, cStride int)
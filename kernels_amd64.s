@@ -0,0 +1,158 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
+
+// func dotAVXFMA(x, y []float64) float64
+TEXT ·dotAVXFMA(SB), NOSPLIT, $0-56
+	MOVQ x_base+0(FP), SI
+	MOVQ x_len+8(FP), CX
+	MOVQ y_base+24(FP), DI
+	VXORPD Y0, Y0, Y0
+	VXORPD Y1, Y1, Y1
+
+dotloop:
+	// Two accumulators of four lanes each, eight entries at a time.
+	CMPQ CX, $8
+	JL   dotreduce
+	VMOVUPD 0(SI), Y2
+	VMOVUPD 32(SI), Y3
+	VFMADD231PD 0(DI), Y2, Y0
+	VFMADD231PD 32(DI), Y3, Y1
+	ADDQ $64, SI
+	ADDQ $64, DI
+	SUBQ $8, CX
+	JMP  dotloop
+
+dotreduce:
+	VADDPD       Y1, Y0, Y0
+	VEXTRACTF128 $1, Y0, X1
+	VADDPD       X1, X0, X0
+	VHADDPD      X0, X0, X0
+
+dottail:
+	TESTQ CX, CX
+	JE    dotdone
+	VMOVSD      0(SI), X2
+	VFMADD231SD 0(DI), X2, X0
+	ADDQ $8, SI
+	ADDQ $8, DI
+	DECQ CX
+	JMP  dottail
+
+dotdone:
+	VMOVSD X0, ret+48(FP)
+	VZEROUPPER
+	RET
+
+// func axpyAVXFMA(a float64, x, y []float64)
+TEXT ·axpyAVXFMA(SB), NOSPLIT, $0-56
+	VBROADCASTSD a+0(FP), Y0
+	MOVQ x_base+8(FP), SI
+	MOVQ x_len+16(FP), CX
+	MOVQ y_base+32(FP), DI
+
+axpyloop:
+	CMPQ CX, $8
+	JL   axpytail
+	VMOVUPD 0(DI), Y1
+	VMOVUPD 32(DI), Y2
+	VFMADD231PD 0(SI), Y0, Y1
+	VFMADD231PD 32(SI), Y0, Y2
+	VMOVUPD Y1, 0(DI)
+	VMOVUPD Y2, 32(DI)
+	ADDQ $64, SI
+	ADDQ $64, DI
+	SUBQ $8, CX
+	JMP  axpyloop
+
+axpytail:
+	TESTQ CX, CX
+	JE    axpydone
+	VMOVSD      0(DI), X1
+	VFMADD231SD 0(SI), X0, X1
+	VMOVSD      X1, 0(DI)
+	ADDQ $8, SI
+	ADDQ $8, DI
+	DECQ CX
+	JMP  axpytail
+
+axpydone:
+	VZEROUPPER
+	RET
+
+// func gemm4x8AVXFMA(k int, lp, r []float64, rStride int, c []float64, cStride int)
+TEXT ·gemm4x8AVXFMA(SB), NOSPLIT, $0-96
+	MOVQ k+0(FP), CX
+	MOVQ lp_base+8(FP), SI
+	MOVQ r_base+32(FP), DI
+	MOVQ rStride+56(FP), R8
+	SHLQ $3, R8
+	MOVQ c_base+64(FP), DX
+	MOVQ cStride+88(FP), R9
+	SHLQ $3, R9
+	// The block lives in Y0 to Y7, two registers per row.
+	VXORPD Y0, Y0, Y0
+	VXORPD Y1, Y1, Y1
+	VXORPD Y2, Y2, Y2
+	VXORPD Y3, Y3, Y3
+	VXORPD Y4, Y4, Y4
+	VXORPD Y5, Y5, Y5
+	VXORPD Y6, Y6, Y6
+	VXORPD Y7, Y7, Y7
+
+gemmloop:
+	// Add the outer product of a column of lp and a row of r.
+	TESTQ CX, CX
+	JE    gemmstore
+	VMOVUPD      0(DI), Y8
+	VMOVUPD      32(DI), Y9
+	VBROADCASTSD 0(SI), Y10
+	VFMADD231PD  Y8, Y10, Y0
+	VFMADD231PD  Y9, Y10, Y1
+	VBROADCASTSD 8(SI), Y11
+	VFMADD231PD  Y8, Y11, Y2
+	VFMADD231PD  Y9, Y11, Y3
+	VBROADCASTSD 16(SI), Y12
+	VFMADD231PD  Y8, Y12, Y4
+	VFMADD231PD  Y9, Y12, Y5
+	VBROADCASTSD 24(SI), Y13
+	VFMADD231PD  Y8, Y13, Y6
+	VFMADD231PD  Y9, Y13, Y7
+	ADDQ $32, SI
+	ADDQ R8, DI
+	DECQ CX
+	JMP  gemmloop
+
+gemmstore:
+	VMOVUPD Y0, 0(DX)
+	VMOVUPD Y1, 32(DX)
+	ADDQ    R9, DX
+	VMOVUPD Y2, 0(DX)
+	VMOVUPD Y3, 32(DX)
+	ADDQ    R9, DX
+	VMOVUPD Y4, 0(DX)
+	VMOVUPD Y5, 32(DX)
+	ADDQ    R9, DX
+	VMOVUPD Y6, 0(DX)
+	VMOVUPD Y7, 32(DX)
+	VZEROUPPER
+	RET
@@ -0,0 +1,141 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDotKernels(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 40; n++ {
+		x := make([]float64, n)
+		y := make([]float64, n)
+		expect := 0.0
+		for i := range x {
+			x[i] = rng.NormFloat64()
+			y[i] = rng.NormFloat64()
+			expect += x[i] * y[i]
+		}
+		ExpectFloat(expect, dotGeneric(x, y), t)
+		ExpectFloat(expect, dot(x, y), t)
+	}
+}
+
+func TestAxpyKernels(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 40; n++ {
+		x := make([]float64, n)
+		y := make([]float64, n)
+		for i := range x {
+			x[i] = rng.NormFloat64()
+			y[i] = rng.NormFloat64()
+		}
+		a := rng.NormFloat64()
+
+		generic := append([]float64(nil), y...)
+		axpyGeneric(a, x, generic)
+		axpy(a, x, y)
+
+		for i := range x {
+			ExpectFloat(generic[i], y[i], t)
+		}
+	}
+}
+
+func TestDotProductKernel(t *testing.T) {
+	v := NewVector(9)
+	c := NewCovector(9)
+	for d := 0; d < 9; d++ {
+		v.Set(0, d, float64(d))
+		c.Set(d, 0, 2)
+	}
+
+	ExpectFloat(72, DotProduct(v, c), t)
+	ExpectFloat(204, DotProduct(v, Dual(v)), t)
+}
+
+func BenchmarkDot(b *testing.B) {
+	x := make([]float64, 1024)
+	y := make([]float64, 1024)
+	for i := range x {
+		x[i] = rand.Float64()
+		y[i] = rand.Float64()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dot(x, y)
+	}
+}
+
+func BenchmarkDotGeneric(b *testing.B) {
+	x := make([]float64, 1024)
+	y := make([]float64, 1024)
+	for i := range x {
+		x[i] = rand.Float64()
+		y[i] = rand.Float64()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dotGeneric(x, y)
+	}
+}
+
+func TestGemmKernels(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for k := 0; k < 20; k++ {
+		lp := make([]float64, 4*k)
+		for p := range lp {
+			lp[p] = rng.NormFloat64()
+		}
+		// Rows of r and c are further apart than the 8 entries used.
+		r := make([]float64, 11*k)
+		for p := range r {
+			r[p] = rng.NormFloat64()
+		}
+		generic := make([]float64, 4*10)
+		c := make([]float64, 4*10)
+		gemm4x8Generic(k, lp, r, 11, generic, 10)
+		gemm4x8(k, lp, r, 11, c, 10)
+
+		for row := 0; row < 4; row++ {
+			for col := 0; col < 8; col++ {
+				expect := 0.0
+				for p := 0; p < k; p++ {
+					expect += lp[4*p+row] * r[11*p+col]
+				}
+				ExpectFloat(expect, generic[10*row+col], t)
+				ExpectFloat(expect, c[10*row+col], t)
+			}
+		}
+	}
+	expectPanic(t, func() { gemm4x8(2, make([]float64, 8), make([]float64, 8), 8, make([]float64, 32), 8) })
+}
+
+func TestComposeBlocked(t *testing.T) {
+	// Sizes around the edges of the 4 by 8 blocks, into both row and
+	// column major destinations.
+	rng := rand.New(rand.NewSource(1))
+	for _, shape := range [][3]int{{8, 4, 3}, {9, 5, 7}, {17, 13, 1}, {7, 12, 5}, {16, 3, 9}} {
+		n, m, k := shape[0], shape[1], shape[2]
+		A := randomMatrix(rng, n, k)
+		B := randomMatrix(rng, k, m)
+		expect := NewArrayMatrix(n, m)
+		for o := 0; o < m; o++ {
+			for i := 0; i < n; i++ {
+				sum := 0.0
+				for p := 0; p < k; p++ {
+					sum += B.Get(p, o) * A.Get(i, p)
+				}
+				expect.Set(i, o, sum)
+			}
+		}
+		ExpectMatrix(expect, Compose(A, B), t)
+		BColMajor := NewArrayMatrixColMajor(k, m)
+		CopyInto(B, BColMajor)
+		colMajor := NewArrayMatrixColMajor(n, m)
+		ComposeInto(A, BColMajor, colMajor)
+		ExpectMatrix(expect, colMajor, t)
+	}
+}
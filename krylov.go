@@ -0,0 +1,250 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// ConjugateGradient finds x such that A*x = b for a symmetric positive
+// definite A, needing only A*v for one v per iteration. It stops when
+// the residual is at most tol times the length of b, or after maxIter
+// iterations, returning how many it took; check the residual if it
+// might not have converged.
+func ConjugateGradient(A LinearOperator, b Vector, tol float64, maxIter int) (x Vector, iterations int) {
+	s := NewCGSolver(A, b, tol)
+	s.Run(maxIter)
+	return s.X(), s.Iterations()
+}
+
+// ConjugateGradientWithStats is ConjugateGradient, returning how it
+// went instead of just the iterations.
+func ConjugateGradientWithStats(A LinearOperator, b Vector, tol float64, maxIter int) (x Vector, stats Stats) {
+	s := NewCGSolver(A, b, tol)
+	s.Run(maxIter)
+	return s.X(), s.Stats()
+}
+
+// CGSolver is ConjugateGradient a step at a time, so that a long solve
+// can be saved with Snapshot and carried on with Restore, even in
+// another process.
+type CGSolver struct {
+	a          LinearOperator
+	b          Vector
+	tol        float64
+	x, r, p    Vector
+	rr         float64
+	iterations int
+	measured   Stats
+}
+
+// NewCGSolver starts solving A*x = b from x = 0.
+func NewCGSolver(A LinearOperator, b Vector, tol float64) *CGSolver {
+	n := checkOperatorSystem(A, b)
+	s := &CGSolver{a: A, b: b, tol: tol, x: NewVector(n), r: Copy(b), p: Copy(b)}
+	s.rr = DotProduct(s.r, Dual(s.r))
+	return s
+}
+
+// Done reports whether the residual is small enough.
+func (s *CGSolver) Done() bool {
+	return math.Sqrt(s.rr) <= s.tol*L2Norm(s.b)
+}
+
+// Step does one iteration.
+func (s *CGSolver) Step() {
+	defer measure(&s.measured)()
+	_, n := s.b.Shape()
+	// Two dot products and three updates.
+	s.measured.Flops += operatorFlops(s.a) + 10*int64(n)
+	Ap := s.a.ApplyVec(s.p)
+	pAp := DotProduct(s.p, Dual(Ap))
+	if pAp <= 0 {
+		panic(fmt.Errorf("not positive definite (%g)", pAp))
+	}
+	alpha := s.rr / pAp
+	addScaledInto(s.x, s.p, alpha, s.x)
+	addScaledInto(s.r, Ap, -alpha, s.r)
+	next := DotProduct(s.r, Dual(s.r))
+	addScaledInto(s.r, s.p, next/s.rr, s.p)
+	s.rr = next
+	s.iterations++
+}
+
+// Run steps until Done or until maxIter iterations in all, counting
+// any done before a Restore.
+func (s *CGSolver) Run(maxIter int) {
+	for s.iterations < maxIter && !s.Done() {
+		s.Step()
+	}
+	logConvergence("CG", s.Done(), s.Stats())
+}
+
+// X returns a copy of the current solution.
+func (s *CGSolver) X() Vector { return Copy(s.x) }
+
+// Iterations returns how many iterations have been done.
+func (s *CGSolver) Iterations() int { return s.iterations }
+
+// Stats returns how the solve has gone so far. Its flops and duration
+// cover the steps.
+func (s *CGSolver) Stats() Stats {
+	stats := s.measured
+	stats.Iterations, stats.Residual = s.iterations, math.Sqrt(s.rr)
+	return stats
+}
+
+// GMRES finds x such that A*x = b for a square nonsingular A, by
+// finding the x that minimizes the residual over a growing Krylov
+// space span(b, A*b, A*A*b, ...), needing only A*v for one v per
+// iteration. It stops when the residual is at most tol times the length
+// of b, or after maxIter iterations, returning how many it took. It
+// keeps a basis vector per iteration, so maxIter also bounds memory.
+func GMRES(A LinearOperator, b Vector, tol float64, maxIter int) (x Vector, iterations int) {
+	s := NewGMRESSolver(A, b, tol)
+	s.Run(maxIter)
+	return s.X(), s.Iterations()
+}
+
+// GMRESWithStats is GMRES, returning how it went instead of just the
+// iterations.
+func GMRESWithStats(A LinearOperator, b Vector, tol float64, maxIter int) (x Vector, stats Stats) {
+	s := NewGMRESSolver(A, b, tol)
+	s.Run(maxIter)
+	return s.X(), s.Stats()
+}
+
+// GMRESSolver is GMRES a step at a time, so that it can be saved with
+// Snapshot and carried on with Restore. The snapshot holds the whole
+// Krylov basis, a vector per iteration.
+type GMRESSolver struct {
+	a   LinearOperator
+	b   Vector
+	tol float64
+
+	// Arnoldi builds an orthonormal basis V of the Krylov space with
+	// A*V[:k] = V[:k+1]*H, and Givens rotations keep H triangular so
+	// that the residual of the best solution is known as it goes.
+	v         []Vector
+	h         [][]float64
+	cs, sn, g []float64
+	// invariant is set when the space stops growing under A, so the
+	// solution is in it.
+	invariant bool
+	measured  Stats
+}
+
+// NewGMRESSolver starts solving A*x = b from x = 0.
+func NewGMRESSolver(A LinearOperator, b Vector, tol float64) *GMRESSolver {
+	n := checkOperatorSystem(A, b)
+	s := &GMRESSolver{a: A, b: b, tol: tol}
+	beta := L2Norm(b)
+	s.g = []float64{beta}
+	if beta == 0 {
+		return s
+	}
+	v0 := Copy(b)
+	for d := 0; d < n; d++ {
+		v0.Set(0, d, v0.Get(0, d)/beta)
+	}
+	s.v = append(s.v, v0)
+	return s
+}
+
+// Done reports whether the residual is small enough.
+func (s *GMRESSolver) Done() bool {
+	return s.invariant || math.Abs(s.g[len(s.h)]) <= s.tol*L2Norm(s.b)
+}
+
+// Step does one iteration, adding a basis vector.
+func (s *GMRESSolver) Step() {
+	defer measure(&s.measured)()
+	k := len(s.h)
+	_, n := s.b.Shape()
+	// Orthogonalizing against k+1 basis vectors, normalizing, and the
+	// rotations.
+	s.measured.Flops += operatorFlops(s.a) + int64(4*n*(k+1)+3*n+6*k)
+	w := s.a.ApplyVec(s.v[k])
+	h := make([]float64, k+2)
+	for j := 0; j <= k; j++ {
+		h[j] = DotProduct(w, Dual(s.v[j]))
+		addScaledInto(w, s.v[j], -h[j], w)
+	}
+	h[k+1] = L2Norm(w)
+
+	for j := 0; j < k; j++ {
+		h[j], h[j+1] = s.cs[j]*h[j]+s.sn[j]*h[j+1], -s.sn[j]*h[j]+s.cs[j]*h[j+1]
+	}
+	r := math.Hypot(h[k], h[k+1])
+	if r == 0 {
+		panic(fmt.Errorf("singular at iteration %d", k))
+	}
+	s.cs = append(s.cs, h[k]/r)
+	s.sn = append(s.sn, h[k+1]/r)
+	s.g = append(s.g, -s.sn[k]*s.g[k])
+	s.g[k] *= s.cs[k]
+	wnorm := h[k+1]
+	h[k], h[k+1] = r, 0
+	s.h = append(s.h, h)
+
+	if wnorm == 0 {
+		s.invariant = true
+		return
+	}
+	for d := 0; d < n; d++ {
+		w.Set(0, d, w.Get(0, d)/wnorm)
+	}
+	s.v = append(s.v, w)
+}
+
+// Run steps until Done or until maxIter iterations in all, counting
+// any done before a Restore.
+func (s *GMRESSolver) Run(maxIter int) {
+	for len(s.h) < maxIter && !s.Done() {
+		s.Step()
+	}
+	logConvergence("GMRES", s.Done(), s.Stats())
+}
+
+// X returns the best solution in the space so far.
+func (s *GMRESSolver) X() Vector {
+	// Back substitution with the triangular H for the coefficients of
+	// the basis vectors.
+	_, n := s.b.Shape()
+	iterations := len(s.h)
+	y := make([]float64, iterations)
+	for j := iterations - 1; j >= 0; j-- {
+		sum := s.g[j]
+		for l := j + 1; l < iterations; l++ {
+			sum -= s.h[l][j] * y[l]
+		}
+		y[j] = sum / s.h[j][j]
+	}
+	x := NewVector(n)
+	for j, c := range y {
+		addScaledInto(x, s.v[j], c, x)
+	}
+	return x
+}
+
+// Iterations returns how many iterations have been done.
+func (s *GMRESSolver) Iterations() int { return len(s.h) }
+
+// Stats returns how the solve has gone so far. Its flops and duration
+// cover the steps, not X.
+func (s *GMRESSolver) Stats() Stats {
+	stats := s.measured
+	stats.Iterations, stats.Residual = len(s.h), math.Abs(s.g[len(s.h)])
+	return stats
+}
+
+func checkOperatorSystem(A LinearOperator, b Vector) int {
+	CheckVector(b)
+	ins, outs := A.Shape()
+	if ins != outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", ins, outs))
+	}
+	if _, dim := b.Shape(); dim != outs {
+		panic(fmt.Errorf("expected dimension %d but got %d", outs, dim))
+	}
+	return outs
+}
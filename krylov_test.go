@@ -0,0 +1,66 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestConjugateGradient(t *testing.T) {
+	D := Difference1D(10)
+	A := Apply(Dual(D), D).(*CSR)
+	for d := 0; d < 10; d++ {
+		A.Set(d, d, A.Get(d, d)+1)
+	}
+	b := NewVector(10)
+	for d := 0; d < 10; d++ {
+		b.Set(0, d, float64(d%3))
+	}
+
+	x, iterations := ConjugateGradient(MatrixOperator(A), b, 1e-12, 100)
+
+	if iterations > 10 {
+		t.Errorf("expected at most 10 iterations but took %d", iterations)
+	}
+	ExpectMatrix(b, Apply(A, x), t)
+}
+
+func TestGMRES(t *testing.T) {
+	A := factorTestMatrix()
+	b := NewVector(3)
+	b.Set(0, 0, 5)
+	b.Set(0, 1, -2)
+	b.Set(0, 2, 9)
+
+	x, iterations := GMRES(MatrixOperator(A), b, 1e-12, 10)
+
+	ExpectInt(3, iterations, t)
+	ExpectMatrix(b, Apply(A, x), t)
+}
+
+func TestNewtonKrylov(t *testing.T) {
+	// Solve x^2 = 2, y^3 = y + 6 by Newton's method with matrix-free
+	// Jacobian solves.
+	f := func(v Vector) Vector {
+		x, y := v.Get(0, 0), v.Get(0, 1)
+		out := NewVector(2)
+		out.Set(0, 0, x*x-2)
+		out.Set(0, 1, y*y*y-y-6)
+		return out
+	}
+	x := NewVector(2)
+	x.Set(0, 0, 1)
+	x.Set(0, 1, 3)
+	for step := 0; step < 20; step++ {
+		fx := f(x)
+		if L2Norm(fx) < 1e-12 {
+			break
+		}
+		for d := 0; d < 2; d++ {
+			fx.Set(0, d, -fx.Get(0, d))
+		}
+		dx, _ := GMRES(JacobianOperator(f, x, 0), fx, 1e-10, 2)
+		addScaledInto(x, dx, 1, x)
+	}
+
+	ExpectFloat(1.4142135623730951, x.Get(0, 0), t)
+	ExpectFloat(2, x.Get(0, 1), t)
+}
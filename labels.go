@@ -0,0 +1,206 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// LabeledMatrix is a Matrix with a name for each input (column) and
+// output (row), so entries can be found by name and results can be
+// reported by name instead of position. Either set of labels may be
+// nil to leave that side unnamed.
+type LabeledMatrix struct {
+	A                   Matrix
+	InLabels, OutLabels []string
+	inIndex, outIndex   map[string]int
+}
+
+// Label returns A with the given names for its inputs and outputs,
+// which must be unique.
+func Label(A Matrix, inLabels, outLabels []string) *LabeledMatrix {
+	ins, outs := A.Shape()
+	return &LabeledMatrix{
+		A:         A,
+		InLabels:  inLabels,
+		OutLabels: outLabels,
+		inIndex:   labelIndex(inLabels, ins),
+		outIndex:  labelIndex(outLabels, outs),
+	}
+}
+
+func labelIndex(labels []string, dim int) map[string]int {
+	if labels == nil {
+		return nil
+	}
+	if len(labels) != dim {
+		panic(fmt.Errorf("%d labels for dimension %d", len(labels), dim))
+	}
+	index := make(map[string]int, dim)
+	for d, name := range labels {
+		if _, ok := index[name]; ok {
+			panic(fmt.Errorf("duplicate label %q", name))
+		}
+		index[name] = d
+	}
+	return index
+}
+
+func (m *LabeledMatrix) Shape() (ins, outs int)             { return m.A.Shape() }
+func (m *LabeledMatrix) Get(in, out int) float64            { return m.A.Get(in, out) }
+func (m *LabeledMatrix) Set(in, out int, value float64)     { m.A.Set(in, out, value) }
+func (m *LabeledMatrix) backingArray() (*arrayMatrix, bool) { return asArrayMatrix(m.A) }
+func (m *LabeledMatrix) readOnlyArray() bool                { return isReadOnlyArray(m.A) }
+
+// InIndex returns the input named name, and whether there is one.
+func (m *LabeledMatrix) InIndex(name string) (int, bool) {
+	in, ok := m.inIndex[name]
+	return in, ok
+}
+
+// OutIndex returns the output named name, and whether there is one.
+func (m *LabeledMatrix) OutIndex(name string) (int, bool) {
+	out, ok := m.outIndex[name]
+	return out, ok
+}
+
+// At returns the entry at the named input and output.
+func (m *LabeledMatrix) At(in, out string) float64 {
+	i, o := m.mustIndex(in, out)
+	return m.A.Get(i, o)
+}
+
+// SetAt sets the entry at the named input and output.
+func (m *LabeledMatrix) SetAt(in, out string, value float64) {
+	i, o := m.mustIndex(in, out)
+	m.A.Set(i, o, value)
+}
+
+func (m *LabeledMatrix) mustIndex(in, out string) (int, int) {
+	i, ok := m.InIndex(in)
+	if !ok {
+		panic(fmt.Errorf("no input labeled %q", in))
+	}
+	o, ok := m.OutIndex(out)
+	if !ok {
+		panic(fmt.Errorf("no output labeled %q", out))
+	}
+	return i, o
+}
+
+// Slice is Slice on the matrix, keeping the labels of what's in the
+// view.
+func (m *LabeledMatrix) Slice(inLo, inHi, outLo, outHi int) *LabeledMatrix {
+	var in, out []string
+	if m.InLabels != nil {
+		in = m.InLabels[inLo:inHi]
+	}
+	if m.OutLabels != nil {
+		out = m.OutLabels[outLo:outHi]
+	}
+	return Label(Slice(m.A, inLo, inHi, outLo, outHi), in, out)
+}
+
+// Select returns a copy with just the named inputs and outputs, in the
+// given order. A nil list keeps every input (or output).
+func (m *LabeledMatrix) Select(in, out []string) *LabeledMatrix {
+	ins, outs := m.Shape()
+	inIdx := m.selection(in, ins, m.InIndex, "input")
+	outIdx := m.selection(out, outs, m.OutIndex, "output")
+	B := NewArrayMatrix(len(inIdx), len(outIdx))
+	for o, mo := range outIdx {
+		for i, mi := range inIdx {
+			B.Set(i, o, m.A.Get(mi, mo))
+		}
+	}
+	if in == nil {
+		in = m.InLabels
+	}
+	if out == nil {
+		out = m.OutLabels
+	}
+	return Label(B, in, out)
+}
+
+func (m *LabeledMatrix) selection(names []string, dim int, index func(string) (int, bool), side string) []int {
+	if names == nil {
+		all := make([]int, dim)
+		for d := range all {
+			all[d] = d
+		}
+		return all
+	}
+	indices := make([]int, len(names))
+	for k, name := range names {
+		d, ok := index(name)
+		if !ok {
+			panic(fmt.Errorf("no %s labeled %q", side, name))
+		}
+		indices[k] = d
+	}
+	return indices
+}
+
+// Dual returns the transpose, with the labels swapped to match.
+func (m *LabeledMatrix) Dual() *LabeledMatrix {
+	return Label(Dual(m.A), m.OutLabels, m.InLabels)
+}
+
+// JoinInputs puts the inputs (columns) of B after those of A. When
+// both have output labels the outputs are matched by name, keeping
+// those of A that B also has, in A's order; otherwise they're matched
+// by position and must be the same in number.
+func JoinInputs(A, B *LabeledMatrix) *LabeledMatrix {
+	aIns, aOuts := A.Shape()
+	bIns, bOuts := B.Shape()
+	aRows, bRows := make([]int, 0, aOuts), make([]int, 0, aOuts)
+	var outLabels []string
+	if A.OutLabels != nil && B.OutLabels != nil {
+		for o, name := range A.OutLabels {
+			if bo, ok := B.OutIndex(name); ok {
+				aRows = append(aRows, o)
+				bRows = append(bRows, bo)
+				outLabels = append(outLabels, name)
+			}
+		}
+	} else {
+		if aOuts != bOuts {
+			panic(fmt.Errorf("dimension mismatch %d vs %d", aOuts, bOuts))
+		}
+		for o := 0; o < aOuts; o++ {
+			aRows = append(aRows, o)
+			bRows = append(bRows, o)
+		}
+		outLabels = A.OutLabels
+		if outLabels == nil {
+			outLabels = B.OutLabels
+		}
+	}
+
+	C := NewArrayMatrix(aIns+bIns, len(aRows))
+	for o := range aRows {
+		for i := 0; i < aIns; i++ {
+			C.Set(i, o, A.Get(i, aRows[o]))
+		}
+		for i := 0; i < bIns; i++ {
+			C.Set(aIns+i, o, B.Get(i, bRows[o]))
+		}
+	}
+	var inLabels []string
+	if A.InLabels != nil && B.InLabels != nil {
+		inLabels = append(append([]string(nil), A.InLabels...), B.InLabels...)
+	}
+	return Label(C, inLabels, outLabels)
+}
+
+// JoinOutputs puts the outputs (rows) of B after those of A, matching
+// inputs the same way JoinInputs matches outputs.
+func JoinOutputs(A, B *LabeledMatrix) *LabeledMatrix {
+	return JoinInputs(A.Dual(), B.Dual()).Dual()
+}
+
+// LabelParameters labels the outputs of theta, parameters found for
+// the design matrix X (say by OrdinaryLeastSquares), with the names of
+// X's features, so that coefficients can be looked up by name.
+func (m *LabeledMatrix) LabelParameters(theta Matrix) *LabeledMatrix {
+	CheckSameOuts(Dual(m.A), theta)
+	return Label(theta, nil, m.InLabels)
+}
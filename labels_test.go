@@ -0,0 +1,65 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestLabeledMatrix(t *testing.T) {
+	X := Label(MatrixFromSlice([]float64{
+		1, 2, 3,
+		4, 5, 6,
+	}, 3, 2, 3), []string{"a", "b", "c"}, []string{"x", "y"})
+
+	ExpectFloat(6, X.At("c", "y"), t)
+	X.SetAt("a", "x", 7)
+	ExpectFloat(7, X.Get(0, 0), t)
+
+	s := X.Slice(1, 3, 0, 2)
+	ExpectFloat(2, s.At("b", "x"), t)
+	if _, ok := s.InIndex("a"); ok {
+		t.Errorf("expected a to be sliced away")
+	}
+
+	sel := X.Select([]string{"c", "a"}, []string{"y"})
+	ExpectMatrix(MatrixFromSlice([]float64{6, 4}, 2, 1, 2), sel, t)
+	ExpectFloat(4, sel.At("a", "y"), t)
+}
+
+func TestJoinLabeled(t *testing.T) {
+	A := Label(MatrixFromSlice([]float64{
+		1,
+		2,
+		3,
+	}, 1, 3, 1), []string{"a"}, []string{"x", "y", "z"})
+	B := Label(MatrixFromSlice([]float64{
+		20,
+		10,
+	}, 1, 2, 1), []string{"b"}, []string{"y", "x"})
+
+	// Only x and y are in both, and they're matched by name.
+	C := JoinInputs(A, B)
+	ExpectMatrix(MatrixFromSlice([]float64{
+		1, 10,
+		2, 20,
+	}, 2, 2, 2), C, t)
+	ExpectFloat(20, C.At("b", "y"), t)
+
+	D := JoinOutputs(A.Dual(), B.Dual())
+	ExpectFloat(20, D.At("y", "b"), t)
+}
+
+func TestLabelParameters(t *testing.T) {
+	X := Label(MatrixFromSlice([]float64{
+		1, 0,
+		1, 1,
+		1, 2,
+	}, 2, 3, 2), []string{"intercept", "slope"}, nil)
+	y := MatrixFromSlice([]float64{1, 3, 5}, 1, 3, 1)
+
+	theta := X.LabelParameters(OrdinaryLeastSquares(X, y))
+
+	o, _ := theta.OutIndex("slope")
+	ExpectFloat(2, theta.Get(0, o), t)
+	o, _ = theta.OutIndex("intercept")
+	ExpectFloat(1, theta.Get(0, o), t)
+}
@@ -0,0 +1,205 @@
+package linear
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Objective returns the value of a function to minimize and its
+// gradient at x.
+type Objective func(x Vector) (value float64, gradient Vector)
+
+// LBFGS minimizes smooth functions with the limited memory BFGS
+// quasi-Newton method, which approximates the inverse Hessian from the
+// last few steps and changes in gradient instead of storing it.
+type LBFGS struct {
+	// Memory is how many steps to remember, typically 5 to 20.
+	Memory int
+	// MaxIter is how many iterations to run before giving up.
+	MaxIter int
+	// GradTol is how small the gradient has to get, relative to the
+	// larger of 1 and the length of x, to stop.
+	GradTol float64
+}
+
+// Minimize runs L-BFGS from x0, returning the minimizer it found and
+// how many iterations it took.
+func (l *LBFGS) Minimize(f Objective, x0 Vector) (x Vector, iterations int) {
+	s := l.Start(f, x0)
+	s.Run()
+	return s.X(), s.Iterations()
+}
+
+// MinimizeWithStats is Minimize, returning how it went instead of just
+// the iterations.
+func (l *LBFGS) MinimizeWithStats(f Objective, x0 Vector) (x Vector, stats Stats) {
+	s := l.Start(f, x0)
+	s.Run()
+	return s.X(), s.Stats()
+}
+
+// LBFGSSolver is LBFGS.Minimize a step at a time, so that a long
+// minimization can be saved with Snapshot and carried on with Restore.
+type LBFGSSolver struct {
+	l          LBFGS
+	f          Objective
+	x          Vector
+	fx         float64
+	g          Vector
+	ss, ys     []Vector
+	rhos       []float64
+	iterations int
+	// stalled is set when no step along the search direction makes
+	// progress.
+	stalled  bool
+	measured Stats
+}
+
+// Start begins minimizing f from x0 with l's settings. f isn't called
+// until the first Step, so a Restore straight after is free.
+func (l *LBFGS) Start(f Objective, x0 Vector) *LBFGSSolver {
+	CheckVector(x0)
+	if l.Memory < 1 {
+		panic(fmt.Errorf("need a memory of at least 1 but got %d", l.Memory))
+	}
+	return &LBFGSSolver{l: *l, f: f, x: Copy(x0)}
+}
+
+func (s *LBFGSSolver) evaluate() {
+	if s.g == nil {
+		s.fx, s.g = s.f(s.x)
+		CheckSameShape(s.g, s.x)
+	}
+}
+
+// Done reports whether the gradient is small enough or no step makes
+// progress.
+func (s *LBFGSSolver) Done() bool {
+	s.evaluate()
+	return s.stalled || L2Norm(s.g) <= s.l.GradTol*max1(L2Norm(s.x))
+}
+
+// Step does one iteration, or marks the solver stalled if the line
+// search fails.
+func (s *LBFGSSolver) Step() {
+	defer measure(&s.measured)()
+	s.evaluate()
+	_, n := s.x.Shape()
+	x, fx, g := s.x, s.fx, s.g
+	// The two loops and the slope, then the updates at the end; the
+	// line search adds its trials below.
+	s.measured.Flops += int64(8*n*len(s.ss) + 16*n)
+	d := negated(lbfgsTwoLoop(g, s.ss, s.ys, s.rhos))
+	slope := DotProduct(g, Dual(d))
+	if slope >= 0 {
+		// The approximation has lost positive definiteness, so start
+		// again from steepest descent.
+		s.ss, s.ys, s.rhos = nil, nil, nil
+		d = negated(g)
+		slope = DotProduct(g, Dual(d))
+	}
+
+	// Backtrack until the decrease is at least a fraction of what the
+	// slope promises (the Armijo condition). Close to the minimum the
+	// decrease gets lost in rounding, so then a step that doesn't
+	// increase the value by more than rounding and flattens the slope
+	// is good enough (as Hager and Zhang do).
+	step := 1.0
+	if len(s.ss) == 0 {
+		step = 1 / max1(L2Norm(g))
+	}
+	xNew := NewVector(n)
+	var fNew float64
+	var gNew Vector
+	for {
+		addScaledInto(x, d, step, xNew)
+		s.measured.Flops += 2 * int64(n)
+		fNew, gNew = s.f(xNew)
+		if fNew <= fx+1e-4*step*slope {
+			break
+		}
+		if fNew <= fx+1e-12*math.Abs(fx) && math.Abs(DotProduct(gNew, Dual(d))) <= 0.9*-slope {
+			break
+		}
+		step /= 2
+		if step < 1e-20 {
+			s.stalled = true
+			logEvent(slog.LevelWarn, "line search stalled", slog.Int("iteration", s.iterations))
+			return
+		}
+	}
+
+	sv := NewVector(n)
+	y := NewVector(n)
+	addScaledInto(xNew, x, -1, sv)
+	addScaledInto(gNew, g, -1, y)
+	// Only remember steps along which the function curves upward,
+	// which keeps the approximation positive definite.
+	if sy := DotProduct(sv, Dual(y)); sy > 1e-12*L2Norm(sv)*L2Norm(y) {
+		s.remember(sv, y, 1/sy)
+	}
+	s.x, s.fx, s.g = xNew, fNew, gNew
+	s.iterations++
+}
+
+func (s *LBFGSSolver) remember(sv, y Vector, rho float64) {
+	s.ss, s.ys, s.rhos = append(s.ss, sv), append(s.ys, y), append(s.rhos, rho)
+	if len(s.ss) > s.l.Memory {
+		s.ss, s.ys, s.rhos = s.ss[1:], s.ys[1:], s.rhos[1:]
+	}
+}
+
+// Run steps until Done or until MaxIter iterations in all, counting
+// any done before a Restore.
+func (s *LBFGSSolver) Run() {
+	for s.iterations < s.l.MaxIter && !s.Done() {
+		s.Step()
+	}
+	logConvergence("L-BFGS", s.Done() && !s.stalled, s.Stats())
+}
+
+// X returns a copy of the current point.
+func (s *LBFGSSolver) X() Vector { return Copy(s.x) }
+
+// Iterations returns how many iterations have been done.
+func (s *LBFGSSolver) Iterations() int { return s.iterations }
+
+// Stats returns how the minimization has gone so far, with the length
+// of the gradient as the residual. Its flops and duration cover the
+// steps.
+func (s *LBFGSSolver) Stats() Stats {
+	s.evaluate()
+	stats := s.measured
+	stats.Iterations, stats.Residual = s.iterations, L2Norm(s.g)
+	return stats
+}
+
+// lbfgsTwoLoop applies the inverse Hessian approximation from the
+// remembered steps to g without forming it.
+func lbfgsTwoLoop(g Vector, ss, ys []Vector, rhos []float64) Vector {
+	q := Copy(g)
+	alphas := make([]float64, len(ss))
+	for k := len(ss) - 1; k >= 0; k-- {
+		alphas[k] = rhos[k] * DotProduct(ss[k], Dual(q))
+		addScaledInto(q, ys[k], -alphas[k], q)
+	}
+	if k := len(ss) - 1; k >= 0 {
+		// Scale so the initial approximation has the curvature of the
+		// most recent step.
+		gamma := 1 / (rhos[k] * DotProduct(ys[k], Dual(ys[k])))
+		q = mapEntries(q, func(f float64) float64 { return gamma * f })
+	}
+	for k := range ss {
+		beta := rhos[k] * DotProduct(ys[k], Dual(q))
+		addScaledInto(q, ss[k], alphas[k]-beta, q)
+	}
+	return q
+}
+
+func max1(f float64) float64 {
+	if f < 1 {
+		return 1
+	}
+	return f
+}
@@ -0,0 +1,57 @@
+package linear
+
+import (
+	"testing"
+)
+
+func rosenbrock(x Vector) (float64, Vector) {
+	_, n := x.Shape()
+	value := 0.0
+	g := NewVector(n)
+	for d := 0; d+1 < n; d++ {
+		a, b := x.Get(0, d), x.Get(0, d+1)
+		value += 100*(b-a*a)*(b-a*a) + (1-a)*(1-a)
+		g.Set(0, d, g.Get(0, d)-400*a*(b-a*a)-2*(1-a))
+		g.Set(0, d+1, g.Get(0, d+1)+200*(b-a*a))
+	}
+	return value, g
+}
+
+func TestLBFGS(t *testing.T) {
+	x0 := NewVector(4)
+	for d := 0; d < 4; d++ {
+		x0.Set(0, d, -1.2)
+	}
+	l := &LBFGS{Memory: 7, MaxIter: 500, GradTol: 1e-10}
+
+	x, iterations := l.Minimize(rosenbrock, x0)
+
+	if iterations >= 500 {
+		t.Errorf("didn't converge")
+	}
+	for d := 0; d < 4; d++ {
+		ExpectFloat(1, x.Get(0, d), t)
+	}
+}
+
+func TestLBFGSQuadratic(t *testing.T) {
+	// Minimizing x'*A'*A*x/2 - b'*x solves A'*A*x = b, and it has to
+	// get all the way there despite rounding in the value.
+	A := factorTestMatrix()
+	AtA := Apply(Dual(A), A)
+	b := NewVector(3)
+	b.Set(0, 0, 1)
+	b.Set(0, 1, -1)
+	b.Set(0, 2, 2)
+	f := func(x Vector) (float64, Vector) {
+		Ax := Apply(AtA, x)
+		g := NewVector(3)
+		addScaledInto(Ax, b, -1, g)
+		return DotProduct(x, Dual(Ax))/2 - DotProduct(x, Dual(b)), g
+	}
+	l := &LBFGS{Memory: 5, MaxIter: 100, GradTol: 1e-12}
+
+	x, _ := l.Minimize(f, NewVector(3))
+
+	ExpectMatrix(b, Apply(AtA, x), t)
+}
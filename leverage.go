@@ -0,0 +1,102 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ApproximateLeverageScores estimates the leverage of each observation
+// (output) of a tall X with full column rank, the diagonal of its hat
+// matrix that LinearModel.Leverage computes exactly. It sketches X
+// down to sketchSize rows with an SRHT and factors that, which is much
+// cheaper than a QR of X: the rows of X times the inverse of the
+// sketch's R are nearly orthonormal, so their squared lengths are close
+// to the leverages. A sketchSize of a few times the number of columns
+// is usually enough.
+func ApproximateLeverageScores(X Matrix, sketchSize int, rng *rand.Rand) Vector {
+	p, n := X.Shape()
+	if sketchSize < p {
+		panic(fmt.Errorf("sketch size %d is less than %d columns", sketchSize, p))
+	}
+	R := FactorQR(ApplyOperator(SRHT(n, sketchSize, rng), X)).R()
+	Rt := Dual(Slice(R, 0, p, 0, p))
+	scores := NewVector(n)
+	for o := 0; o < n; o++ {
+		z := FindInputLowerTriangular(Rt, Dual(Slice(X, 0, p, o, o+1)))
+		scores.Set(0, o, DotProduct(z, Dual(z)))
+	}
+	return scores
+}
+
+// SampleRows picks s rows independently, each with probability in
+// proportion to its score, like leverage scores. It returns the rows
+// picked (maybe repeated) and a weight for each of 1/sqrt(s*p) for its
+// probability p, so that scaling the sampled rows by their weights
+// makes Dual(SX)*SX an unbiased estimate of Dual(X)*X.
+func SampleRows(scores Vector, s int, rng *rand.Rand) (rows []int, weights []float64) {
+	CheckVector(scores)
+	_, n := scores.Shape()
+	cumulative := make([]float64, n)
+	total := 0.0
+	for o := 0; o < n; o++ {
+		score := scores.Get(0, o)
+		if score < 0 || math.IsNaN(score) {
+			panic(fmt.Errorf("score %g at %d isn't a weight", score, o))
+		}
+		total += score
+		cumulative[o] = total
+	}
+	if total == 0 || math.IsInf(total, 0) {
+		panic(fmt.Errorf("scores total %g", total))
+	}
+	rows = make([]int, s)
+	weights = make([]float64, s)
+	for k := 0; k < s; k++ {
+		o := sort.SearchFloat64s(cumulative, rng.Float64()*total)
+		// Skip over zero scores, which share a cumulative total with
+		// the row before them.
+		for scores.Get(0, o) == 0 {
+			o++
+		}
+		rows[k] = o
+		weights[k] = 1 / math.Sqrt(float64(s)*scores.Get(0, o)/total)
+	}
+	return rows, weights
+}
+
+// WeightedRows returns a new matrix of the given outputs (rows) of A,
+// each scaled by its weight, as from SampleRows.
+func WeightedRows(A Matrix, rows []int, weights []float64) Matrix {
+	if len(rows) != len(weights) {
+		panic(fmt.Errorf("%d rows but %d weights", len(rows), len(weights)))
+	}
+	S := Copy(Gather(A, rows))
+	ins, _ := A.Shape()
+	for k, w := range weights {
+		for i := 0; i < ins; i++ {
+			S.Set(i, k, w*S.Get(i, k))
+		}
+	}
+	return S
+}
+
+// LeverageCoreset samples s observations of a regression of y on X by
+// their approximate leverage (from a sketch of twice the size), giving
+// a much smaller weighted problem whose least squares solution is close
+// to that of the whole. Leverage sampling keeps the rare influential
+// observations that uniform sampling would likely miss.
+func LeverageCoreset(X, y Matrix, s int, rng *rand.Rand) (Xc, yc Matrix) {
+	CheckSameOuts(X, y)
+	p, n := X.Shape()
+	sketchSize := 2 * s
+	if sketchSize < 4*p {
+		sketchSize = 4 * p
+	}
+	if padded := 1 << uint(math.Ceil(math.Log2(float64(n)))); sketchSize > padded {
+		sketchSize = padded
+	}
+	rows, weights := SampleRows(ApproximateLeverageScores(X, sketchSize, rng), s, rng)
+	return WeightedRows(X, rows, weights), WeightedRows(y, rows, weights)
+}
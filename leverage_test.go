@@ -0,0 +1,71 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// leverageExample is a regression with one far out observation.
+func leverageExample(rng *rand.Rand, n int) (X, y Matrix) {
+	X = NewArrayMatrix(2, n)
+	y = NewVector(n)
+	for o := 0; o < n; o++ {
+		x := rng.NormFloat64()
+		if o == n-1 {
+			x = 50
+		}
+		X.Set(0, o, 1)
+		X.Set(1, o, x)
+		y.Set(0, o, 3-x+0.1*rng.NormFloat64())
+	}
+	return X, y
+}
+
+func TestApproximateLeverageScores(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	X, y := leverageExample(rng, 500)
+	exact := FitLinearModel(X, y).Leverage()
+	approx := ApproximateLeverageScores(X, 64, rng)
+	for o := 0; o < 500; o++ {
+		if e, a := exact.Get(0, o), approx.Get(0, o); math.Abs(a-e) > 0.5*e {
+			t.Errorf("expected leverage near %f but got %f at %d", e, a, o)
+		}
+	}
+}
+
+func TestSampleRows(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	scores := MatrixFromSlice([]float64{0, 1, 0, 3}, 1, 4, 1)
+	rows, weights := SampleRows(scores, 4000, rng)
+	counts := make([]int, 4)
+	for k, o := range rows {
+		counts[o]++
+		ExpectFloat(1/math.Sqrt(4000*scores.Get(0, o)/4), weights[k], t)
+	}
+	ExpectInt(0, counts[0]+counts[2], t)
+	if math.Abs(float64(counts[3])/4000-0.75) > 0.03 {
+		t.Errorf("expected about 3/4 of the rows to be 3 but got %d", counts[3])
+	}
+	expectPanic(t, func() { SampleRows(NewVector(3), 1, rng) })
+}
+
+func TestWeightedRows(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6}, 2, 3, 2)
+	ExpectMatrix(MatrixFromSlice([]float64{10, 12, 3, 4, 10, 12}, 2, 3, 2), WeightedRows(A, []int{2, 1, 2}, []float64{2, 1, 2}), t)
+}
+
+func TestLeverageCoreset(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	X, y := leverageExample(rng, 2000)
+	Xc, yc := LeverageCoreset(X, y, 40, rng)
+	_, outs := Xc.Shape()
+	ExpectInt(40, outs, t)
+	full := OrdinaryLeastSquares(X, y)
+	coreset := OrdinaryLeastSquares(Xc, yc)
+	for i := 0; i < 2; i++ {
+		if math.Abs(full.Get(0, i)-coreset.Get(0, i)) > 0.05 {
+			t.Errorf("expected %f but got %f", full.Get(0, i), coreset.Get(0, i))
+		}
+	}
+}
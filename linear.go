@@ -7,18 +7,20 @@ package linear
 
 import (
 	"fmt"
+	"math"
 )
 
 // FindInputUpperTriangular finds the input vector that maps to the
-// given output vector in the case of an upper triangular map.
+// given output vector in the case of an upper triangular map. If b has
+// several columns (inputs) then each is solved for separately, giving
+// the same number of columns in the result.
 func FindInputUpperTriangular(A Matrix, b Matrix) Matrix {
+	defer beginOp("Solve")()
 	ins, outs := A.Shape()
-	x := NewArrayMatrix(1, ins)
-	CheckVector(x)
+	cols, _ := b.Shape()
+	x := NewArrayMatrix(cols, ins)
 	CheckUpperTriangular(A)
-	CheckVector(b)
-	CheckComposable(x, A)
-	CheckSameIns(x, b) // redundant since they're both vectors
+	CheckSameIns(x, b)
 	CheckSameOuts(A, b)
 
 	if outs < ins {
@@ -28,14 +30,52 @@ func FindInputUpperTriangular(A Matrix, b Matrix) Matrix {
 	// Since A is upper triangular we can solve the last row on the
 	// diagonal (the rest are zeros) by simple division, and then use
 	// that to solve the previous row and so on.
-	for o := ins - 1; o >= 0; o-- {
-		dot := DotProduct(
-			Slice(x, 0, 1, o+1, ins),
-			Slice(A, o+1, ins, o, o+1))
-		numer := b.Get(0, o) - dot
-		denom := A.Get(o, o)
-		CheckNotCloseToZero(denom)
-		x.Set(0, o, numer/denom)
+	for c := 0; c < cols; c++ {
+		for o := ins - 1; o >= 0; o-- {
+			dot := DotProduct(
+				Slice(x, c, c+1, o+1, ins),
+				Slice(A, o+1, ins, o, o+1))
+			numer := b.Get(c, o) - dot
+			denom := A.Get(o, o)
+			CheckNotCloseToZero(denom)
+			x.Set(c, o, numer/denom)
+		}
+	}
+
+	return x
+}
+
+// FindInputLowerTriangular finds the input vector that maps to the
+// given output vector in the case of a lower triangular map, by
+// forward substitution. Like FindInputUpperTriangular, each column of
+// b is solved for separately. This is what's needed for the L factors
+// of LU and Cholesky, or for Dual(R) of a QR factorization.
+func FindInputLowerTriangular(A Matrix, b Matrix) Matrix {
+	defer beginOp("Solve")()
+	ins, outs := A.Shape()
+	cols, _ := b.Shape()
+	x := NewArrayMatrix(cols, ins)
+	CheckUpperTriangular(Dual(A))
+	CheckSameIns(x, b)
+	CheckSameOuts(A, b)
+
+	if outs < ins {
+		panic(fmt.Errorf("less matix outs (%d) than ins (%d)", outs, ins))
+	}
+	countFlops(cols * ins * ins)
+
+	// Just like the upper triangular case but starting from the top,
+	// where the first row only has its diagonal.
+	for c := 0; c < cols; c++ {
+		for o := 0; o < ins; o++ {
+			dot := 0.0
+			for i := 0; i < o; i++ {
+				dot += A.Get(i, o) * x.Get(c, i)
+			}
+			denom := A.Get(o, o)
+			CheckNotCloseToZero(denom)
+			x.Set(c, o, (b.Get(c, o)-dot)/denom)
+		}
 	}
 
 	return x
@@ -45,69 +85,143 @@ func FindInputUpperTriangular(A Matrix, b Matrix) Matrix {
 // same length in the direction of e via reflection over their
 // bisection.
 func Householder(x, e Matrix) Matrix {
+	v, beta := HouseholderVector(x, e)
+	_, dim := x.Shape()
+
+	H := Identity(dim)
+	for o := 0; o < dim; o++ {
+		for i := 0; i < dim; i++ {
+			H.Set(i, o, H.Get(i, o)-beta*v.Get(0, o)*v.Get(0, i))
+		}
+	}
+
+	return H
+}
+
+// HouseholderVector finds the same reflection as Householder but in
+// the compact form I - beta*v*Dual(v), with v scaled so that its
+// component along e is 1 (as LAPACK does). The reflection is towards
+// the side of e opposite x, so forming v never subtracts nearly equal
+// numbers even when x is already close to a multiple of e. If x is zero
+// then beta is zero and the reflection is the identity.
+func HouseholderVector(x, e Matrix) (v Matrix, beta float64) {
 	CheckVector(x)
 	CheckVector(e)
 	CheckSameOuts(x, e)
 	_, dim := x.Shape()
 
-	H := Identity(dim)
-
 	xmag := L2Norm(x)
-	x0sign := 1.0
-	if x.Get(0, 0) < 0.0 {
-		x0sign = -1.0
+	xe := DotProduct(x, Dual(e))
+	v = NewArrayMatrix(1, dim)
+	if xmag == 0 {
+		return v, 0
+	}
+	sign := 1.0
+	if xe < 0.0 {
+		sign = -1.0
 	}
 
-	u := NewArrayMatrix(1, dim)
+	// x + sign*xmag*e has length squared 2*xmag*(xmag+|xe|), and its
+	// component along e is sign*(xmag+|xe|), so both the scaling and
+	// beta come out without cancellation.
+	scale := sign * (xmag + math.Abs(xe))
 	for d := 0; d < dim; d++ {
-		u.Set(0, d, x.Get(0, d)+x0sign*xmag*e.Get(0, d))
+		v.Set(0, d, (x.Get(0, d)+sign*xmag*e.Get(0, d))/scale)
 	}
-	Normalize(u)
+	beta = (xmag + math.Abs(xe)) / xmag
+	return v, beta
+}
 
-	for o := 0; o < dim; o++ {
-		for i := 0; i < dim; i++ {
-			H.Set(i, o, H.Get(i, o)-2*u.Get(0, o)*u.Get(0, i))
+// ApplyHouseholderLeft replaces A with H*A in place, where H is the
+// reflection I - beta*v*Dual(v), without ever forming H.
+func ApplyHouseholderLeft(v Matrix, beta float64, A Matrix) {
+	CheckVector(v)
+	CheckSameOuts(v, A)
+	ins, outs := A.Shape()
+	countFlops(4 * ins * outs)
+	if beta == 0 {
+		return
+	}
+	for i := 0; i < ins; i++ {
+		w := 0.0
+		for o := 0; o < outs; o++ {
+			w += v.Get(0, o) * A.Get(i, o)
+		}
+		w *= beta
+		for o := 0; o < outs; o++ {
+			A.Set(i, o, A.Get(i, o)-w*v.Get(0, o))
 		}
 	}
+}
 
-	return H
+// ApplyHouseholderRight replaces A with A*H in place, where H is the
+// reflection I - beta*v*Dual(v), without ever forming H.
+func ApplyHouseholderRight(v Matrix, beta float64, A Matrix) {
+	CheckVector(v)
+	ins, outs := A.Shape()
+	if _, dim := v.Shape(); dim != ins {
+		panic(fmt.Errorf("reflection of dim %d can't follow %d ins", dim, ins))
+	}
+	countFlops(4 * ins * outs)
+	if beta == 0 {
+		return
+	}
+	for o := 0; o < outs; o++ {
+		w := 0.0
+		for i := 0; i < ins; i++ {
+			w += A.Get(i, o) * v.Get(0, i)
+		}
+		w *= beta
+		for i := 0; i < ins; i++ {
+			A.Set(i, o, A.Get(i, o)-w*v.Get(0, i))
+		}
+	}
 }
 
+// DefaultQRTolerance is how small, relative to the largest entry of
+// A, the part of a column below the diagonal has to be for DecomposeQR
+// to treat it as already zero.
+const DefaultQRTolerance = 1e-15
+
 // DecomposeQR decomposes A into Q*R by transforming it into an upper
 // triangular matrix R. Applying the opposite of the transformation,
 // which is Q, to R gets you back to A.
 func DecomposeQR(A Matrix) (Q Matrix, R Matrix) {
-	ins, outs := A.Shape()
-	Q = Identity(outs)
-	R = Slice(A, 0, ins, 0, outs)
-	for i := 0; i < ins; i++ {
-		if IsZero(Slice(R, i, i+1, i+1, outs)) {
-			continue
-		}
-
-		x := Slice(R, i, i+1, i, outs)
-		e := BasisVector(outs-i, 0)
-		H := Householder(x, e)
+	return DecomposeQRWithTolerance(A, DefaultQRTolerance)
+}
 
-		// Extend.
-		HE := Identity(outs)
-		_, xdim := x.Shape()
-		for ho := 0; ho < xdim; ho++ {
-			for hi := 0; hi < xdim; hi++ {
-				HE.Set(i+hi, i+ho, H.Get(hi, ho))
-			}
-		}
+// DecomposeQRWithTolerance is DecomposeQR but skips the reflection for
+// a column when everything below its diagonal is at most tol times the
+// largest entry of A, zeroing it instead. A tol of 0 only skips columns
+// that are exactly zero.
+func DecomposeQRWithTolerance(A Matrix, tol float64) (Q Matrix, R Matrix) {
+	f := FactorQRWithTolerance(A, tol)
+	return f.Q(), f.R()
+}
 
-		R = Apply(HE, R)
-		Q = Compose(Dual(HE), Q)
+// DecomposeThinQR decomposes A into Q*R like DecomposeQR, but keeps
+// only the first k = min(ins, outs) columns of Q and rows of R, so for
+// a tall A, Q is outs by k with orthonormal columns and R is k by k
+// upper triangular. The rest of the full Q only ever multiplies zero
+// rows of R, and for regression on many observations it would be much
+// the largest thing stored.
+func DecomposeThinQR(A Matrix) (Q Matrix, R Matrix) {
+	f := FactorQR(A)
+	ins, outs := A.Shape()
+	k := ins
+	if outs < k {
+		k = outs
 	}
-	return Q, R
+	return f.ThinQ(), Copy(Slice(f.R(), 0, ins, 0, k))
 }
 
 // OrdinaryLeastSquares finds the input (parameters) that when mapped
 // (by the dataset inputs) is closest to the output (the dataset
-// outputs) in terms of L2 distance.
+// outputs) in terms of L2 distance. If y has several columns (inputs),
+// one for each response, then the result has a column of parameters
+// for each, all sharing one QR decomposition of X.
 func OrdinaryLeastSquares(X Matrix, y Matrix) Matrix {
+	defer beginOp("LeastSquares")()
 	// X*theta_hat != y, but we want the left to come as close as
 	// possible to y, the projection of y onto the column space of X.
 	//
@@ -131,8 +245,72 @@ func OrdinaryLeastSquares(X Matrix, y Matrix) Matrix {
 	//
 	// This is valid only if Dual(R) is invertible so that we can cancel
 	// it.
+	CheckSameOuts(X, y)
+	return FactorQR(X).Solve(y)
+}
+
+// OrdinaryLeastSquaresNormalEq solves the same problem as
+// OrdinaryLeastSquares, but directly from the normal equation
+// Dual(X)*X*theta_hat = Dual(X)*y by Cholesky. It's cheaper, but
+// forming Dual(X)*X squares the condition number of X, so about twice
+// as many digits are lost, and it panics if Dual(X)*X isn't positive
+// definite to working precision. It's here mostly to be compared
+// against the QR path; see CompareLeastSquares.
+func OrdinaryLeastSquaresNormalEq(X Matrix, y Matrix) Matrix {
+	defer beginOp("LeastSquares")()
+	CheckSameOuts(X, y)
+	Xt := Dual(X)
+	return FactorCholesky(Compose(X, Xt)).Solve(Apply(Xt, y))
+}
+
+// LeastSquaresComparison is how the QR and normal equation solutions
+// of a least squares problem compare to a reference solution.
+type LeastSquaresComparison struct {
+	// Condition is the ratio of the largest to smallest singular
+	// values of X. The normal equations see its square.
+	Condition float64
+	// QRError and NormalEqError are the L2 distances of each solution
+	// from the reference, relative to the size of the reference.
+	// NormalEqError is +Inf if Cholesky failed.
+	QRError, NormalEqError float64
+	// QRResidual and NormalEqResidual are the L2 norms of X*theta_hat
+	// - y for each solution.
+	QRResidual, NormalEqResidual float64
+}
+
+// CompareLeastSquares solves the least squares problem for X and the
+// vector y both with QR and with the normal equations, and measures
+// each solution against reference, the exact solution (for example
+// known from how y was made, or computed in higher precision).
+func CompareLeastSquares(X, y, reference Matrix) LeastSquaresComparison {
 	CheckVector(y)
-	Q, R := DecomposeQR(X)
-	b := Apply(Dual(Q), y)
-	return FindInputUpperTriangular(R, b)
+	CheckVector(reference)
+	_, sigma, _ := DecomposeSVD(X)
+	_, k := sigma.Shape()
+	c := LeastSquaresComparison{Condition: sigma.Get(0, 0) / sigma.Get(0, k-1)}
+
+	qr := OrdinaryLeastSquares(X, y)
+	c.QRError = frobeniusDistance(qr, reference) / frobeniusNorm(reference)
+	c.QRResidual = frobeniusDistance(Apply(X, qr), y)
+
+	normal, ok := tryNormalEq(X, y)
+	if !ok {
+		c.NormalEqError = math.Inf(1)
+		c.NormalEqResidual = math.Inf(1)
+		return c
+	}
+	c.NormalEqError = frobeniusDistance(normal, reference) / frobeniusNorm(reference)
+	c.NormalEqResidual = frobeniusDistance(Apply(X, normal), y)
+	return c
+}
+
+// tryNormalEq is OrdinaryLeastSquaresNormalEq, but returns false
+// instead of panicking when Cholesky fails.
+func tryNormalEq(X, y Matrix) (theta Matrix, ok bool) {
+	defer func() {
+		if recover() != nil {
+			theta, ok = nil, false
+		}
+	}()
+	return OrdinaryLeastSquaresNormalEq(X, y), true
 }
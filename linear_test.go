@@ -22,6 +22,18 @@ func ExpectInt(expect, got int, t *testing.T) {
 	}
 }
 
+func ExpectMatrix(expect, got Matrix, t *testing.T) {
+	ins, outs := expect.Shape()
+	gotIns, gotOuts := got.Shape()
+	ExpectInt(ins, gotIns, t)
+	ExpectInt(outs, gotOuts, t)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			ExpectFloat(expect.Get(i, o), got.Get(i, o), t)
+		}
+	}
+}
+
 func TestFindInputUpperTriangular(t *testing.T) {
 	A := NewArrayMatrix(3, 3)
 	A.Set(0, 0, 1)
@@ -48,6 +60,62 @@ func TestFindInputUpperTriangular(t *testing.T) {
 	ExpectFloat(1.0/2.0, x.Get(0, 2), t)
 }
 
+func TestFindInputLowerTriangular(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		2, 0, 0,
+		1, 4, 0,
+		3, 5, 6,
+	}, 3, 3, 3)
+	b := MatrixFromSlice([]float64{
+		2, 4,
+		9, 2,
+		31, 36,
+	}, 2, 3, 2)
+
+	x := FindInputLowerTriangular(A, b)
+
+	ExpectMatrix(MatrixFromSlice([]float64{
+		1, 2,
+		2, 0,
+		3, 5,
+	}, 2, 3, 2), x, t)
+
+	// The L of an LU factorization, and Dual(R) of a QR factorization.
+	M := MatrixFromSlice([]float64{4, 3, 6, 3}, 2, 2, 2)
+	y := MatrixFromSlice([]float64{1, 2}, 1, 2, 1)
+	L := FactorLU(M).L()
+	ExpectMatrix(y, Apply(L, FindInputLowerTriangular(L, y)), t)
+	Rt := Dual(FactorQR(M).R())
+	ExpectMatrix(y, Apply(Rt, FindInputLowerTriangular(Rt, y)), t)
+}
+
+func TestDecomposeThinQR(t *testing.T) {
+	for _, A := range []Matrix{
+		vandermonde(2, 10),
+		Dual(vandermonde(2, 4)),
+	} {
+		ins, outs := A.Shape()
+		k := ins
+		if outs < k {
+			k = outs
+		}
+		Q, R := DecomposeThinQR(A)
+		qIns, qOuts := Q.Shape()
+		ExpectInt(k, qIns, t)
+		ExpectInt(outs, qOuts, t)
+		rIns, rOuts := R.Shape()
+		ExpectInt(ins, rIns, t)
+		ExpectInt(k, rOuts, t)
+		ExpectMatrix(Identity(k), Compose(Q, Dual(Q)), t)
+		ExpectMatrix(A, Compose(R, Q), t)
+		for o := 0; o < k; o++ {
+			for i := 0; i < o; i++ {
+				ExpectFloat(0, R.Get(i, o), t)
+			}
+		}
+	}
+}
+
 func TestHouseholder(t *testing.T) {
 	A0 := NewArrayMatrix(3, 3)
 	A0.Set(0, 0, 12)
@@ -127,6 +195,60 @@ func TestHouseholder(t *testing.T) {
 	ExpectFloat(-35.0, A2.Get(2, 2), t)
 }
 
+func TestHouseholderVector(t *testing.T) {
+	// x is nearly along e, which is where the naive formula cancels.
+	x := NewArrayMatrix(1, 3)
+	x.Set(0, 0, 1)
+	x.Set(0, 1, 1e-10)
+	x.Set(0, 2, -2e-10)
+
+	v, beta := HouseholderVector(x, BasisVector(3, 0))
+
+	ExpectFloat(1, v.Get(0, 0), t)
+	ExpectFloat(2, beta, t)
+
+	y := Copy(x)
+	ApplyHouseholderLeft(v, beta, y)
+
+	ExpectFloat(-L2Norm(x), y.Get(0, 0), t)
+	if math.Abs(y.Get(0, 1)) > 1e-25 || math.Abs(y.Get(0, 2)) > 1e-25 {
+		t.Errorf("expected zeros below the first entry but got %v, %v",
+			y.Get(0, 1), y.Get(0, 2))
+	}
+}
+
+func TestApplyHouseholder(t *testing.T) {
+	x := NewArrayMatrix(1, 3)
+	x.Set(0, 0, 12)
+	x.Set(0, 1, 6)
+	x.Set(0, 2, -4)
+	e := BasisVector(3, 0)
+
+	A := NewArrayMatrix(3, 3)
+	for o := 0; o < 3; o++ {
+		for i := 0; i < 3; i++ {
+			A.Set(i, o, float64(i*3+o*o+1))
+		}
+	}
+
+	H := Householder(x, e)
+	v, beta := HouseholderVector(x, e)
+
+	left := Copy(A)
+	ApplyHouseholderLeft(v, beta, left)
+	right := Copy(A)
+	ApplyHouseholderRight(v, beta, right)
+
+	HA := Apply(H, A)
+	AH := Apply(A, H)
+	for o := 0; o < 3; o++ {
+		for i := 0; i < 3; i++ {
+			ExpectFloat(HA.Get(i, o), left.Get(i, o), t)
+			ExpectFloat(AH.Get(i, o), right.Get(i, o), t)
+		}
+	}
+}
+
 func TestDecomposeQR(t *testing.T) {
 	A := NewArrayMatrix(3, 3)
 	A.Set(0, 0, 12)
@@ -182,6 +304,37 @@ func TestDecomposeQR(t *testing.T) {
 	}
 }
 
+func TestDecomposeQRScaleInvariant(t *testing.T) {
+	// The same matrix in tiny units must still be decomposed.
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 3e-30)
+	A.Set(1, 0, 1e-30)
+	A.Set(0, 1, 4e-30)
+	A.Set(1, 1, 2e-30)
+
+	_, R := DecomposeQR(A)
+
+	ExpectFloat(0, 1e30*R.Get(0, 1), t)
+	ExpectFloat(-5, 1e30*R.Get(0, 0), t)
+
+	// Noise below the diagonal is treated as zero rather than reflected.
+	B := Identity(2)
+	B.Set(0, 1, 1e-17)
+
+	Q, R := DecomposeQR(B)
+
+	ExpectFloat(1, Q.Get(0, 0), t)
+	ExpectFloat(1, R.Get(0, 0), t)
+	if R.Get(0, 1) != 0 {
+		t.Errorf("expected an exact zero below the diagonal but got %v", R.Get(0, 1))
+	}
+
+	// Unless the tolerance says otherwise.
+	_, R = DecomposeQRWithTolerance(B, 0)
+
+	ExpectFloat(-1, R.Get(0, 0), t)
+}
+
 func TestOrdinaryLeastSquares(t *testing.T) {
 	X := NewArrayMatrix(2, 2)
 	X.Set(0, 0, 1)
@@ -223,6 +376,84 @@ func TestOrdinaryLeastSquaresNonSquare(t *testing.T) {
 	ExpectFloat(-3, theta_hat.Get(0, 1), t)
 }
 
+func TestOrdinaryLeastSquaresManyResponses(t *testing.T) {
+	X := NewArrayMatrix(2, 3)
+	X.Set(0, 0, 1)
+	X.Set(1, 0, 0)
+	X.Set(0, 1, 1)
+	X.Set(1, 1, 2)
+	X.Set(0, 2, -2)
+	X.Set(1, 2, 1)
+
+	Y := NewArrayMatrix(2, 3)
+	Y.Set(0, 0, 6)
+	Y.Set(0, 1, 0)
+	Y.Set(0, 2, -15)
+	Y.Set(1, 0, 1)
+	Y.Set(1, 1, 3)
+	Y.Set(1, 2, -1)
+
+	Theta := OrdinaryLeastSquares(X, Y)
+
+	ins, outs := Theta.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(2, outs, t)
+	ExpectFloat(6, Theta.Get(0, 0), t)
+	ExpectFloat(-3, Theta.Get(0, 1), t)
+	ExpectFloat(1, Theta.Get(1, 0), t)
+	ExpectFloat(1, Theta.Get(1, 1), t)
+}
+
+func TestOrdinaryLeastSquaresNormalEq(t *testing.T) {
+	X := NewArrayMatrix(2, 3)
+	y := NewArrayMatrix(1, 3)
+	for o := 0; o < 3; o++ {
+		X.Set(0, o, 1)
+		X.Set(1, o, float64(o))
+		y.Set(0, o, float64(2*o+1))
+	}
+
+	theta_hat := OrdinaryLeastSquaresNormalEq(X, y)
+
+	ExpectMatrix(OrdinaryLeastSquares(X, y), theta_hat, t)
+	ExpectFloat(1, theta_hat.Get(0, 0), t)
+	ExpectFloat(2, theta_hat.Get(0, 1), t)
+}
+
+// vandermonde has columns 1, t, t^2, ... for n evenly spaced t in
+// [0, 1], which gets badly conditioned quickly as degree grows.
+func vandermonde(degree, n int) Matrix {
+	X := NewArrayMatrix(degree+1, n)
+	for o := 0; o < n; o++ {
+		x := float64(o) / float64(n-1)
+		for i := 0; i <= degree; i++ {
+			X.Set(i, o, math.Pow(x, float64(i)))
+		}
+	}
+	return X
+}
+
+func TestCompareLeastSquares(t *testing.T) {
+	X := vandermonde(9, 40)
+	theta := NewArrayMatrix(1, 10)
+	for i := 0; i < 10; i++ {
+		theta.Set(0, i, 1)
+	}
+	y := Apply(X, theta)
+
+	c := CompareLeastSquares(X, y, theta)
+
+	if c.Condition < 1e6 {
+		t.Errorf("expected a badly conditioned problem but got %g", c.Condition)
+	}
+	if c.QRError > 1e-6 {
+		t.Errorf("expected QR to be accurate but got relative error %g", c.QRError)
+	}
+	if c.NormalEqError < 100*c.QRError {
+		t.Errorf("expected the normal equations to lose accuracy but got %g vs %g", c.NormalEqError, c.QRError)
+	}
+}
+
 func BenchmarkFindInputUpperTriangular(b *testing.B) {
 	ins := 512
 	outs := 512
@@ -264,3 +495,21 @@ func BenchmarkDecomposeQR(b *testing.B) {
 		DecomposeQR(A)
 	}
 }
+
+func BenchmarkOrdinaryLeastSquares(b *testing.B) {
+	X := vandermonde(9, 100)
+	y := Apply(X, vandermonde(0, 10))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		OrdinaryLeastSquares(X, y)
+	}
+}
+
+func BenchmarkOrdinaryLeastSquaresNormalEq(b *testing.B) {
+	X := vandermonde(9, 100)
+	y := Apply(X, vandermonde(0, 10))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		OrdinaryLeastSquaresNormalEq(X, y)
+	}
+}
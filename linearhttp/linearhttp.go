@@ -0,0 +1,223 @@
+// Package linearhttp serves a few of package linear's solvers over
+// HTTP, with matrices encoded as JSON by linear.JSONMatrix. It's mostly
+// an example of wiring the package into a service: package linear
+// panics on bad input, so each endpoint recovers those panics into
+// errors with the error-returning functions here.
+package linearhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+
+	"github.com/ornerylawn/linear"
+)
+
+// SolveRequest is the body of POST /solve, for A*X = B with a square A.
+type SolveRequest struct {
+	A linear.JSONMatrix `json:"a"`
+	B linear.JSONMatrix `json:"b"`
+}
+
+// LeastSquaresRequest is the body of POST /leastsquares, for the Theta
+// that makes X*Theta closest to Y.
+type LeastSquaresRequest struct {
+	X linear.JSONMatrix `json:"x"`
+	Y linear.JSONMatrix `json:"y"`
+}
+
+// DecomposeRequest is the body of POST /decompose. Kind is "qr",
+// "cholesky" or "svd".
+type DecomposeRequest struct {
+	Kind string            `json:"kind"`
+	A    linear.JSONMatrix `json:"a"`
+}
+
+func (r *SolveRequest) matrices() map[string]linear.Matrix {
+	return map[string]linear.Matrix{"a": r.A.Matrix, "b": r.B.Matrix}
+}
+
+func (r *LeastSquaresRequest) matrices() map[string]linear.Matrix {
+	return map[string]linear.Matrix{"x": r.X.Matrix, "y": r.Y.Matrix}
+}
+
+func (r *DecomposeRequest) matrices() map[string]linear.Matrix {
+	return map[string]linear.Matrix{"a": r.A.Matrix}
+}
+
+// Response is the body of every reply: the named result matrices, or
+// an error.
+type Response struct {
+	Result map[string]linear.JSONMatrix `json:"result,omitempty"`
+	Error  string                       `json:"error,omitempty"`
+}
+
+// Limits bound how much work a request can ask for.
+type Limits struct {
+	// MaxBodyBytes is the size of the largest request body.
+	MaxBodyBytes int64
+	// MaxDim is the most inputs or outputs of any matrix in a request.
+	MaxDim int
+}
+
+// DefaultLimits are the limits of NewHandler.
+var DefaultLimits = Limits{MaxBodyBytes: 32 << 20, MaxDim: 1000}
+
+// NewHandler returns a handler for POST /solve, /leastsquares and
+// /decompose, with DefaultLimits.
+func NewHandler() http.Handler {
+	return NewHandlerWithLimits(DefaultLimits)
+}
+
+// NewHandlerWithLimits is NewHandler with the given limits. Bodies that
+// are too big get status 413 and matrices that are too big status 400,
+// without calling into package linear.
+func NewHandlerWithLimits(limits Limits) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solve", func(w http.ResponseWriter, r *http.Request) {
+		var req SolveRequest
+		if decodeRequest(w, r, limits, &req) {
+			X, err := Solve(req.A.Matrix, req.B.Matrix)
+			writeResult(w, map[string]linear.Matrix{"x": X}, err)
+		}
+	})
+	mux.HandleFunc("/leastsquares", func(w http.ResponseWriter, r *http.Request) {
+		var req LeastSquaresRequest
+		if decodeRequest(w, r, limits, &req) {
+			theta, err := LeastSquares(req.X.Matrix, req.Y.Matrix)
+			writeResult(w, map[string]linear.Matrix{"theta": theta}, err)
+		}
+	})
+	mux.HandleFunc("/decompose", func(w http.ResponseWriter, r *http.Request) {
+		var req DecomposeRequest
+		if decodeRequest(w, r, limits, &req) {
+			factors, err := Decompose(req.Kind, req.A.Matrix)
+			writeResult(w, factors, err)
+		}
+	})
+	return mux
+}
+
+// Solve finds X such that A*X = B for a square nonsingular A, by LU.
+func Solve(A, B linear.Matrix) (X linear.Matrix, err error) {
+	if A == nil || B == nil {
+		return nil, fmt.Errorf("missing a or b")
+	}
+	defer recoverError(&err)
+	return linear.FactorLU(A).Solve(B), nil
+}
+
+// LeastSquares finds the Theta that makes X*Theta closest to Y, by QR.
+func LeastSquares(X, Y linear.Matrix) (theta linear.Matrix, err error) {
+	if X == nil || Y == nil {
+		return nil, fmt.Errorf("missing x or y")
+	}
+	defer recoverError(&err)
+	return linear.OrdinaryLeastSquares(X, Y), nil
+}
+
+// Decompose factors A, returning the factors by name: "q" and "r" for
+// "qr", "l" for "cholesky", and "u", "sigma" and "v" for "svd".
+func Decompose(kind string, A linear.Matrix) (factors map[string]linear.Matrix, err error) {
+	if A == nil {
+		return nil, fmt.Errorf("missing a")
+	}
+	defer recoverError(&err)
+	switch kind {
+	case "qr":
+		Q, R := linear.DecomposeQR(A)
+		return map[string]linear.Matrix{"q": Q, "r": R}, nil
+	case "cholesky":
+		return map[string]linear.Matrix{"l": linear.DecomposeCholesky(A)}, nil
+	case "svd":
+		U, sigma, V := linear.DecomposeSVD(A)
+		return map[string]linear.Matrix{"u": U, "sigma": sigma, "v": V}, nil
+	}
+	return nil, fmt.Errorf("unknown decomposition %q", kind)
+}
+
+// recoverError turns a panic with an error from package linear into
+// *err. Runtime errors are bugs rather than bad input, so they keep
+// panicking.
+func recoverError(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	e, ok := r.(error)
+	if _, isRuntime := r.(runtime.Error); !ok || isRuntime {
+		panic(r)
+	}
+	*err = e
+}
+
+// request is implemented by the request bodies, to name their
+// matrices for checking against the limits.
+type request interface {
+	matrices() map[string]linear.Matrix
+}
+
+// decodeRequest decodes the JSON body of a POST into req and checks it
+// against the limits, or writes an error response and returns false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, limits Limits, req request) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeResponse(w, http.StatusMethodNotAllowed, Response{Error: "only POST is allowed"})
+		return false
+	}
+	body := http.MaxBytesReader(w, r.Body, limits.MaxBodyBytes)
+	if err := json.NewDecoder(body).Decode(req); err != nil {
+		status := http.StatusBadRequest
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeResponse(w, status, Response{Error: err.Error()})
+		return false
+	}
+	matrices := req.matrices()
+	names := make([]string, 0, len(matrices))
+	for name := range matrices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if A := matrices[name]; A != nil {
+			if ins, outs := A.Shape(); ins > limits.MaxDim || outs > limits.MaxDim {
+				writeResponse(w, http.StatusBadRequest, Response{
+					Error: fmt.Sprintf("%s has shape (%d, %d), bigger than %d", name, ins, outs, limits.MaxDim),
+				})
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// writeResult writes the results, or err with status 400.
+func writeResult(w http.ResponseWriter, results map[string]linear.Matrix, err error) {
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+	resp := Response{Result: map[string]linear.JSONMatrix{}}
+	for name, A := range results {
+		resp.Result[name] = linear.JSONMatrix{Matrix: A}
+	}
+	writeResponse(w, http.StatusOK, resp)
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp Response) {
+	body, err := json.Marshal(resp)
+	if err != nil {
+		// A result with NaN or infinite entries can't be encoded.
+		status = http.StatusUnprocessableEntity
+		body, _ = json.Marshal(Response{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -0,0 +1,131 @@
+package linearhttp
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ornerylawn/linear"
+)
+
+func post(t *testing.T, h http.Handler, path, body string) (int, Response) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s: %v in %q", path, err, w.Body.String())
+	}
+	return w.Code, resp
+}
+
+func TestSolveEndpoint(t *testing.T) {
+	h := NewHandler()
+	code, resp := post(t, h, "/solve", `{"a": {"ins": 2, "outs": 2, "data": [2, 1, 1, 3]}, "b": {"ins": 1, "outs": 2, "data": [3, 5]}}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200 but got %d: %s", code, resp.Error)
+	}
+	expectMatrix(linear.MatrixFromSlice([]float64{0.8, 1.4}, 1, 2, 1), resp.Result["x"].Matrix, t)
+
+	// Singular and mismatched systems are reported, not panicked on.
+	code, resp = post(t, h, "/solve", `{"a": {"ins": 2, "outs": 2, "data": [1, 2, 2, 4]}, "b": {"ins": 1, "outs": 2, "data": [1, 1]}}`)
+	if code != http.StatusBadRequest || !strings.Contains(resp.Error, "singular") {
+		t.Errorf("expected a singular error but got %d %q", code, resp.Error)
+	}
+	code, resp = post(t, h, "/solve", `{"a": {"ins": 2, "outs": 2, "data": [2, 1, 1, 3]}, "b": {"ins": 1, "outs": 3, "data": [1, 1, 1]}}`)
+	if code != http.StatusBadRequest || resp.Error == "" {
+		t.Errorf("expected a shape error but got %d %q", code, resp.Error)
+	}
+	code, resp = post(t, h, "/solve", `{"a": {"ins": 2, "outs": 2, "data": [1]}}`)
+	if code != http.StatusBadRequest || resp.Error == "" {
+		t.Errorf("expected a decoding error but got %d %q", code, resp.Error)
+	}
+}
+
+func TestLeastSquaresEndpoint(t *testing.T) {
+	// y = 1 + 2x exactly.
+	code, resp := post(t, NewHandler(), "/leastsquares", `{"x": {"ins": 2, "outs": 3, "data": [1, 0, 1, 1, 1, 2]}, "y": {"ins": 1, "outs": 3, "data": [1, 3, 5]}}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200 but got %d: %s", code, resp.Error)
+	}
+	expectMatrix(linear.MatrixFromSlice([]float64{1, 2}, 1, 2, 1), resp.Result["theta"].Matrix, t)
+}
+
+func TestDecomposeEndpoint(t *testing.T) {
+	h := NewHandler()
+	A := `{"ins": 2, "outs": 2, "data": [4, 2, 2, 3]}`
+	code, resp := post(t, h, "/decompose", `{"kind": "cholesky", "a": `+A+`}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200 but got %d: %s", code, resp.Error)
+	}
+	L := resp.Result["l"].Matrix
+	expectMatrix(linear.MatrixFromSlice([]float64{4, 2, 2, 3}, 2, 2, 2), linear.Compose(linear.Dual(L), L), t)
+
+	code, resp = post(t, h, "/decompose", `{"kind": "qr", "a": `+A+`}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200 but got %d: %s", code, resp.Error)
+	}
+	expectMatrix(linear.MatrixFromSlice([]float64{4, 2, 2, 3}, 2, 2, 2), linear.Compose(resp.Result["r"].Matrix, resp.Result["q"].Matrix), t)
+
+	code, resp = post(t, h, "/decompose", `{"kind": "svd", "a": `+A+`}`)
+	if code != http.StatusOK || resp.Result["sigma"].Matrix == nil {
+		t.Fatalf("expected singular values but got %d: %s", code, resp.Error)
+	}
+
+	code, resp = post(t, h, "/decompose", `{"kind": "cholesky", "a": {"ins": 2, "outs": 2, "data": [1, 2, 2, 1]}}`)
+	if code != http.StatusBadRequest || !strings.Contains(resp.Error, "positive definite") {
+		t.Errorf("expected a positive definite error but got %d %q", code, resp.Error)
+	}
+	code, resp = post(t, h, "/decompose", `{"kind": "eig", "a": `+A+`}`)
+	if code != http.StatusBadRequest || resp.Error == "" {
+		t.Errorf("expected an unknown kind error but got %d %q", code, resp.Error)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	h := NewHandlerWithLimits(Limits{MaxBodyBytes: 200, MaxDim: 2})
+	code, resp := post(t, h, "/decompose", `{"kind": "svd", "a": {"ins": 3, "outs": 1, "data": [1, 2, 3]}}`)
+	if code != http.StatusBadRequest || !strings.Contains(resp.Error, "bigger than 2") {
+		t.Errorf("expected a shape limit error but got %d %q", code, resp.Error)
+	}
+	code, resp = post(t, h, "/decompose", `{"kind": "svd", "a": {"ins": 1, "outs": 1, "data": [1]}, "pad": "`+strings.Repeat("x", 200)+`"}`)
+	if code != http.StatusRequestEntityTooLarge || resp.Error == "" {
+		t.Errorf("expected a body size error but got %d %q", code, resp.Error)
+	}
+	// A shape whose size overflows is a decoding error rather than a
+	// panic in the solver.
+	code, resp = post(t, NewHandler(), "/solve", `{"a": {"ins": 4294967296, "outs": 4294967296, "data": []}, "b": {"ins": 1, "outs": 1, "data": [1]}}`)
+	if code != http.StatusBadRequest || resp.Error == "" {
+		t.Errorf("expected a decoding error but got %d %q", code, resp.Error)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/solve", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 but got %d", w.Code)
+	}
+}
+
+func expectMatrix(expect, got linear.Matrix, t *testing.T) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("expected a matrix but got none")
+	}
+	ins, outs := expect.Shape()
+	gotIns, gotOuts := got.Shape()
+	if ins != gotIns || outs != gotOuts {
+		t.Fatalf("expected shape (%d, %d) but got (%d, %d)", ins, outs, gotIns, gotOuts)
+	}
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			if math.Abs(expect.Get(i, o)-got.Get(i, o)) > 1e-9 {
+				t.Errorf("expected %v but got %v at (%d, %d)", expect.Get(i, o), got.Get(i, o), i, o)
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// LinearModel is an ordinary least squares fit of a response y to the
+// observations (outputs) of a design matrix X, kept together with what
+// it takes to ask questions about the fit afterwards.
+type LinearModel struct {
+	X Matrix
+	Y Vector
+	// Theta has a parameter per feature (input) of X.
+	Theta Vector
+	// Residuals are y - X*Theta.
+	Residuals Vector
+	// RSS is the residual sum of squares.
+	RSS float64
+	// Rank is the rank of X, which is the number of features, and DF is
+	// the residual degrees of freedom, the number of observations minus
+	// Rank.
+	Rank, DF int
+
+	qr *QRFactorization
+}
+
+// FitLinearModel fits y to X by ordinary least squares. It panics if
+// the features of X are collinear, since then there's no one best
+// Theta; drop or combine features first.
+func FitLinearModel(X Matrix, y Vector) *LinearModel {
+	CheckVector(y)
+	CheckSameOuts(X, y)
+	p, n := X.Shape()
+	qr := FactorQR(X)
+	rank := qrRank(qr.R())
+	if rank < p {
+		panic(fmt.Errorf("X has rank %d but %d features, so some are collinear", rank, p))
+	}
+	m := &LinearModel{X: X, Y: y, Rank: rank, DF: n - rank, qr: qr}
+	m.Theta = qr.SolveVec(y)
+	m.Residuals = Apply(X, m.Theta)
+	addScaledInto(y, m.Residuals, -1, m.Residuals)
+	m.RSS = frobeniusNorm(m.Residuals)
+	m.RSS *= m.RSS
+	return m
+}
+
+// qrRank counts the diagonal entries of R that aren't negligible next
+// to the largest.
+func qrRank(R Matrix) int {
+	ins, outs := R.Shape()
+	largest := 0.0
+	for d := 0; d < ins && d < outs; d++ {
+		largest = math.Max(largest, math.Abs(R.Get(d, d)))
+	}
+	rank := 0
+	for d := 0; d < ins && d < outs; d++ {
+		if math.Abs(R.Get(d, d)) > 1e-10*largest {
+			rank++
+		}
+	}
+	return rank
+}
+
+// Predict returns X*Theta for new observations X.
+func (m *LinearModel) Predict(X Matrix) Vector {
+	return Apply(X, m.Theta)
+}
+
+// Sigma2 is the unbiased estimate of the noise variance, RSS/DF.
+func (m *LinearModel) Sigma2() float64 {
+	if m.DF <= 0 {
+		panic(fmt.Errorf("no residual degrees of freedom"))
+	}
+	return m.RSS / float64(m.DF)
+}
+
+// ANOVAResult compares the fits of two nested linear models.
+type ANOVAResult struct {
+	// RSS and DF of the smaller and larger models.
+	RSS1, RSS2 float64
+	DF1, DF2   int
+	// F is the ratio of the decrease in RSS per parameter added to the
+	// larger model's noise variance, and P the probability of one at
+	// least that large if the added parameters are really zero.
+	F, P float64
+}
+
+// ANOVA tests whether model2, which must have the features of model1
+// and more, fits the same observations significantly better, by the F
+// test on their residual sums of squares.
+func ANOVA(model1, model2 *LinearModel) ANOVAResult {
+	CheckSameShape(model1.Y, model2.Y)
+	if model1.DF <= model2.DF {
+		panic(fmt.Errorf("model1 with %d degrees of freedom isn't nested in model2 with %d", model1.DF, model2.DF))
+	}
+	if model2.DF <= 0 {
+		panic(fmt.Errorf("no residual degrees of freedom"))
+	}
+	r := ANOVAResult{RSS1: model1.RSS, RSS2: model2.RSS, DF1: model1.DF, DF2: model2.DF}
+	d1, d2 := float64(r.DF1-r.DF2), float64(r.DF2)
+	if r.RSS2 == 0 {
+		r.F, r.P = math.Inf(1), 0
+		return r
+	}
+	r.F = math.Max(0, r.RSS1-r.RSS2) / d1 / (r.RSS2 / d2)
+	r.P = fSurvival(r.F, d1, d2)
+	return r
+}
+
+// Leverage returns the diagonal of the hat matrix X*Inverse(Dual(X)*X)*
+// Dual(X), how much each observation pulls the fit towards itself. It
+// comes from the QR factors as the squared lengths of the rows of the
+// thin Q, without forming the hat matrix.
+func (m *LinearModel) Leverage() Vector {
+	_, n := m.X.Shape()
+	E := NewArrayMatrix(m.Rank, n)
+	for d := 0; d < m.Rank; d++ {
+		E.Set(d, d, 1)
+	}
+	m.qr.ApplyQ(E)
+	h := NewVector(n)
+	for o := 0; o < n; o++ {
+		sum := 0.0
+		for i := 0; i < m.Rank; i++ {
+			sum += E.Get(i, o) * E.Get(i, o)
+		}
+		h.Set(0, o, sum)
+	}
+	return h
+}
+
+// StudentizedResiduals returns each residual divided by its standard
+// error, estimating the noise from the fit without that observation,
+// so that each one is t distributed with DF-1 degrees of freedom if the
+// model is right.
+func (m *LinearModel) StudentizedResiduals() Vector {
+	if m.DF <= 1 {
+		panic(fmt.Errorf("too few residual degrees of freedom"))
+	}
+	h := m.Leverage()
+	_, n := h.Shape()
+	t := NewVector(n)
+	for o := 0; o < n; o++ {
+		r, ho := m.Residuals.Get(0, o), h.Get(0, o)
+		// Leaving an observation out reduces the RSS by r^2/(1-h).
+		s2 := (m.RSS - r*r/(1-ho)) / float64(m.DF-1)
+		t.Set(0, o, r/math.Sqrt(s2*(1-ho)))
+	}
+	return t
+}
+
+// CooksDistance returns how far the fitted values move when each
+// observation is left out, scaled by the number of parameters and the
+// noise variance. Values near 1 or more are usually worth a look.
+func (m *LinearModel) CooksDistance() Vector {
+	h := m.Leverage()
+	_, n := h.Shape()
+	s2 := m.Sigma2()
+	d := NewVector(n)
+	for o := 0; o < n; o++ {
+		r, ho := m.Residuals.Get(0, o), h.Get(0, o)
+		d.Set(0, o, r*r*ho/(float64(m.Rank)*s2*(1-ho)*(1-ho)))
+	}
+	return d
+}
+
+// Prediction is the fitted value and intervals at each observation of
+// new data.
+type Prediction struct {
+	Fit Vector
+	// ConfLo and ConfHi bound the mean response, and PredLo and PredHi
+	// a new observation, which also has its own noise.
+	ConfLo, ConfHi Vector
+	PredLo, PredHi Vector
+}
+
+// PredictWithInterval returns the predictions for new observations X
+// with confidence and prediction intervals at the given level (like
+// 0.95), from Student's t with DF degrees of freedom. The standard error
+// of the mean at x is sqrt(Sigma2*Dual(x)*Inverse(Dual(R)*R)*x), which
+// comes from one triangular solve with the R factor per observation.
+func (m *LinearModel) PredictWithInterval(X Matrix, level float64) Prediction {
+	if level <= 0 || level >= 1 {
+		panic(fmt.Errorf("confidence level %v isn't in (0, 1)", level))
+	}
+	CheckSameIns(m.X, X)
+	p, n := X.Shape()
+	s2 := m.Sigma2()
+	q := studentTQuantile((1+level)/2, float64(m.DF))
+	Rt := Dual(Slice(m.qr.R(), 0, p, 0, p))
+
+	pr := Prediction{
+		Fit:    m.Predict(X),
+		ConfLo: NewVector(n),
+		ConfHi: NewVector(n),
+		PredLo: NewVector(n),
+		PredHi: NewVector(n),
+	}
+	for o := 0; o < n; o++ {
+		z := FindInputLowerTriangular(Rt, Dual(Slice(X, 0, p, o, o+1)))
+		v := DotProduct(z, Dual(z))
+		fit := pr.Fit.Get(0, o)
+		conf := q * math.Sqrt(s2*v)
+		pred := q * math.Sqrt(s2*(1+v))
+		pr.ConfLo.Set(0, o, fit-conf)
+		pr.ConfHi.Set(0, o, fit+conf)
+		pr.PredLo.Set(0, o, fit-pred)
+		pr.PredHi.Set(0, o, fit+pred)
+	}
+	return pr
+}
+
+// LeaveOneOutResiduals returns, for each observation, the residual of
+// the prediction for it by the model fit to every other observation.
+// That's r/(1-h) for residual r and leverage h, so it takes no refits.
+func (m *LinearModel) LeaveOneOutResiduals() Vector {
+	h := m.Leverage()
+	_, n := h.Shape()
+	e := NewVector(n)
+	for o := 0; o < n; o++ {
+		e.Set(0, o, m.Residuals.Get(0, o)/(1-h.Get(0, o)))
+	}
+	return e
+}
+
+// PRESS is the predicted residual sum of squares, the sum of the
+// squared leave-one-out residuals. Divided by the number of
+// observations it's the leave-one-out cross-validation error, for
+// comparing models by how well they predict rather than fit.
+func (m *LinearModel) PRESS() float64 {
+	e := frobeniusNorm(m.LeaveOneOutResiduals())
+	return e * e
+}
+
+// DurbinWatson returns the Durbin-Watson statistic of the residuals in
+// order of observation, which is near 2 when neighboring residuals are
+// uncorrelated, towards 0 when they're positively correlated and
+// towards 4 when negatively.
+func (m *LinearModel) DurbinWatson() float64 {
+	_, n := m.Residuals.Shape()
+	diff := 0.0
+	for o := 1; o < n; o++ {
+		d := m.Residuals.Get(0, o) - m.Residuals.Get(0, o-1)
+		diff += d * d
+	}
+	return diff / m.RSS
+}
+
+// ResidualACF returns the autocorrelation of the residuals in order of
+// observation at each lag from 0 up to maxLag.
+func (m *LinearModel) ResidualACF(maxLag int) Vector {
+	_, n := m.Residuals.Shape()
+	if maxLag < 0 || maxLag >= n {
+		panic(fmt.Errorf("lag %d isn't in [0, %d)", maxLag, n))
+	}
+	mean := 0.0
+	for o := 0; o < n; o++ {
+		mean += m.Residuals.Get(0, o)
+	}
+	mean /= float64(n)
+	e := make([]float64, n)
+	variance := 0.0
+	for o := range e {
+		e[o] = m.Residuals.Get(0, o) - mean
+		variance += e[o] * e[o]
+	}
+	acf := NewVector(maxLag + 1)
+	for k := 0; k <= maxLag; k++ {
+		sum := 0.0
+		for o := 0; o+k < n; o++ {
+			sum += e[o] * e[o+k]
+		}
+		acf.Set(0, k, sum/variance)
+	}
+	return acf
+}
@@ -0,0 +1,226 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitLinearModel(t *testing.T) {
+	X := MatrixFromSlice([]float64{
+		1, 0,
+		1, 1,
+		1, 2,
+		1, 3,
+	}, 2, 4, 2)
+	y := MatrixFromSlice([]float64{1, 2, 2, 4}, 1, 4, 1)
+
+	m := FitLinearModel(X, y)
+
+	ExpectFloat(0.9, m.Theta.Get(0, 1), t)
+	ExpectFloat(0.9, m.Theta.Get(0, 0), t)
+	ExpectInt(2, m.Rank, t)
+	ExpectInt(2, m.DF, t)
+	ExpectFloat(0.7, m.RSS, t)
+	ExpectFloat(-0.7, m.Residuals.Get(0, 2), t)
+	ExpectFloat(m.RSS/2, m.Sigma2(), t)
+	ExpectMatrix(MatrixFromSlice([]float64{0.9 + 0.9*5}, 1, 1, 1), m.Predict(MatrixFromSlice([]float64{1, 5}, 2, 1, 2)), t)
+}
+
+func TestFitLinearModelCollinear(t *testing.T) {
+	// The second feature is twice the first.
+	X := MatrixFromSlice([]float64{
+		1, 2,
+		2, 4,
+		3, 6,
+	}, 2, 3, 2)
+	y := MatrixFromSlice([]float64{1, 2, 3}, 1, 3, 1)
+	expectPanic(t, func() { FitLinearModel(X, y) })
+}
+
+func TestANOVA(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	n := 50
+	X := NewArrayMatrix(3, n)
+	y := NewVector(n)
+	for o := 0; o < n; o++ {
+		x1, x2 := r.NormFloat64(), r.NormFloat64()
+		X.Set(0, o, 1)
+		X.Set(1, o, x1)
+		X.Set(2, o, x2)
+		y.Set(0, o, 1+2*x1+0.1*r.NormFloat64())
+	}
+	small := FitLinearModel(Slice(X, 0, 2, 0, n), y)
+	big := FitLinearModel(X, y)
+	intercept := FitLinearModel(Slice(X, 0, 1, 0, n), y)
+
+	// x2 does nothing so adding it is not significant, but x1 is.
+	useless := ANOVA(small, big)
+	ExpectInt(48, useless.DF1, t)
+	ExpectInt(47, useless.DF2, t)
+	expectF := (useless.RSS1 - useless.RSS2) / (useless.RSS2 / 47)
+	ExpectFloat(expectF, useless.F, t)
+	if useless.P < 0.01 {
+		t.Errorf("expected x2 not to be significant but got p = %v", useless.P)
+	}
+	useful := ANOVA(intercept, small)
+	if useful.P > 1e-10 || math.IsNaN(useful.P) {
+		t.Errorf("expected x1 to be significant but got p = %v", useful.P)
+	}
+}
+
+func TestInfluence(t *testing.T) {
+	n := 8
+	X := NewArrayMatrix(2, n)
+	y := NewVector(n)
+	for o := 0; o < n; o++ {
+		X.Set(0, o, 1)
+		X.Set(1, o, float64(o))
+		y.Set(0, o, float64(o)+0.1*float64(o%3))
+	}
+	// An outlier far out in x.
+	X.Set(1, n-1, 20)
+	y.Set(0, n-1, 5)
+	m := FitLinearModel(X, y)
+
+	// The leverages are the diagonal of the hat matrix and sum to the
+	// number of parameters.
+	H := Apply(X, Apply(FactorCholesky(Compose(X, Dual(X))).Inverse(), Dual(X)))
+	h := m.Leverage()
+	sum := 0.0
+	for o := 0; o < n; o++ {
+		ExpectFloat(H.Get(o, o), h.Get(0, o), t)
+		sum += h.Get(0, o)
+	}
+	ExpectFloat(2, sum, t)
+
+	// Refitting without an observation gives the same studentized
+	// residual and Cook's distance.
+	student := m.StudentizedResiduals()
+	cooks := m.CooksDistance()
+	for _, leave := range []int{2, n - 1} {
+		Xl := NewArrayMatrix(2, n-1)
+		yl := NewVector(n - 1)
+		for o, k := 0, 0; o < n; o++ {
+			if o == leave {
+				continue
+			}
+			CopyInto(Slice(X, 0, 2, o, o+1), Slice(Xl, 0, 2, k, k+1))
+			yl.Set(0, k, y.Get(0, o))
+			k++
+		}
+		ml := FitLinearModel(Xl, yl)
+		xo := Slice(X, 0, 2, leave, leave+1)
+		predicted := Apply(xo, ml.Theta).Get(0, 0)
+		se := math.Sqrt(ml.Sigma2() * (1 + DotProduct(Apply(FactorCholesky(Compose(Xl, Dual(Xl))).Inverse(), Dual(xo)), xo)))
+		ExpectFloat((y.Get(0, leave)-predicted)/se, student.Get(0, leave), t)
+
+		moved := frobeniusDistance(Apply(X, m.Theta), Apply(X, ml.Theta))
+		ExpectFloat(moved*moved/(2*m.Sigma2()), cooks.Get(0, leave), t)
+	}
+	if cooks.Get(0, n-1) < 1 {
+		t.Errorf("expected the outlier to be influential but got %v", cooks.Get(0, n-1))
+	}
+}
+
+func TestPredictWithInterval(t *testing.T) {
+	X := MatrixFromSlice([]float64{
+		1, 0,
+		1, 1,
+		1, 2,
+		1, 3,
+	}, 2, 4, 2)
+	y := MatrixFromSlice([]float64{1, 2, 2, 4}, 1, 4, 1)
+	m := FitLinearModel(X, y)
+	x := MatrixFromSlice([]float64{1, 1.5, 1, 5}, 2, 2, 2)
+
+	pr := m.PredictWithInterval(x, 0.95)
+
+	// For simple regression the standard error of the mean at x0 is
+	// s*sqrt(1/n + (x0 - mean)^2/Sxx), with n = 4, mean 1.5, Sxx = 5,
+	// s^2 = 0.35 and t = 4.302653 for 2 degrees of freedom.
+	q := studentTQuantile(0.975, 2)
+	if math.Abs(q-4.302653) > 1e-5 {
+		t.Errorf("expected 4.302653 but got %v", q)
+	}
+	for o, x0 := range []float64{1.5, 5} {
+		v := 0.25 + (x0-1.5)*(x0-1.5)/5
+		fit := 0.9 + 0.9*x0
+		ExpectFloat(fit, pr.Fit.Get(0, o), t)
+		ExpectFloat(fit-q*math.Sqrt(0.35*v), pr.ConfLo.Get(0, o), t)
+		ExpectFloat(fit+q*math.Sqrt(0.35*v), pr.ConfHi.Get(0, o), t)
+		ExpectFloat(fit-q*math.Sqrt(0.35*(1+v)), pr.PredLo.Get(0, o), t)
+		ExpectFloat(fit+q*math.Sqrt(0.35*(1+v)), pr.PredHi.Get(0, o), t)
+	}
+}
+
+func TestPRESS(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	n := 12
+	X := NewArrayMatrix(3, n)
+	y := NewVector(n)
+	for o := 0; o < n; o++ {
+		x := float64(o) / 4
+		X.Set(0, o, 1)
+		X.Set(1, o, x)
+		X.Set(2, o, x*x)
+		y.Set(0, o, 1+x-0.5*x*x+0.2*r.NormFloat64())
+	}
+	m := FitLinearModel(X, y)
+
+	// Refit without each observation in turn.
+	press := 0.0
+	e := m.LeaveOneOutResiduals()
+	for leave := 0; leave < n; leave++ {
+		var rows []int
+		for o := 0; o < n; o++ {
+			if o != leave {
+				rows = append(rows, o)
+			}
+		}
+		ml := FitLinearModel(Gather(X, rows), Gather(y, rows))
+		residual := y.Get(0, leave) - Apply(Slice(X, 0, 3, leave, leave+1), ml.Theta).Get(0, 0)
+		ExpectFloat(residual, e.Get(0, leave), t)
+		press += residual * residual
+	}
+	ExpectFloat(press, m.PRESS(), t)
+	if m.PRESS() < m.RSS {
+		t.Errorf("expected PRESS %v to be at least the RSS %v", m.PRESS(), m.RSS)
+	}
+}
+
+func TestResidualAutocorrelation(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	n := 400
+	X := NewArrayMatrix(2, n)
+	white, ar := NewVector(n), NewVector(n)
+	noise := 0.0
+	for o := 0; o < n; o++ {
+		x := float64(o) / float64(n)
+		X.Set(0, o, 1)
+		X.Set(1, o, x)
+		white.Set(0, o, 2*x+r.NormFloat64())
+		// AR(1) noise with coefficient 0.8.
+		noise = 0.8*noise + r.NormFloat64()
+		ar.Set(0, o, 2*x+noise)
+	}
+
+	independent := FitLinearModel(X, white)
+	correlated := FitLinearModel(X, ar)
+
+	if dw := independent.DurbinWatson(); math.Abs(dw-2) > 0.3 {
+		t.Errorf("expected a statistic near 2 but got %v", dw)
+	}
+	// The statistic is about 2*(1 - rho).
+	if dw := correlated.DurbinWatson(); dw > 0.7 {
+		t.Errorf("expected a statistic near 0.4 but got %v", dw)
+	}
+	acf := correlated.ResidualACF(2)
+	ExpectFloat(1, acf.Get(0, 0), t)
+	if acf.Get(0, 1) < 0.6 || acf.Get(0, 2) > acf.Get(0, 1) {
+		t.Errorf("expected decaying autocorrelation from about 0.8 but got %v, %v", acf.Get(0, 1), acf.Get(0, 2))
+	}
+	if a := independent.ResidualACF(1).Get(0, 1); math.Abs(a) > 0.15 {
+		t.Errorf("expected little autocorrelation but got %v", a)
+	}
+}
@@ -0,0 +1,47 @@
+package linear
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+var logger *slog.Logger
+
+// SetLogger makes the package log to l: how long each operation took
+// at debug level, whether iterative solvers converged at info level,
+// and warnings like tiny pivots that threaten accuracy. A nil l, the
+// default, turns logging off. Like the counters, the logger is global
+// and shouldn't be changed while other goroutines are calling into the
+// package.
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
+
+// logOp starts timing an operation for beginOp.
+func logOp(name string) func() {
+	start := time.Now()
+	return func() {
+		logger.LogAttrs(context.Background(), slog.LevelDebug, "operation",
+			slog.String("op", name), slog.Duration("duration", time.Since(start)))
+	}
+}
+
+// logEvent logs msg and its attributes if there's a logger.
+func logEvent(level slog.Level, msg string, attrs ...slog.Attr) {
+	if logger == nil {
+		return
+	}
+	logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// logConvergence logs how an iterative solver finished.
+func logConvergence(solver string, converged bool, stats Stats) {
+	level, msg := slog.LevelInfo, "converged"
+	if !converged {
+		level, msg = slog.LevelWarn, "did not converge"
+	}
+	logEvent(level, msg, slog.String("solver", solver),
+		slog.Int("iterations", stats.Iterations), slog.Float64("residual", stats.Residual),
+		slog.Duration("duration", stats.Duration))
+}
@@ -0,0 +1,60 @@
+package linear
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func captureLog(level slog.Level) *bytes.Buffer {
+	var b bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: level})))
+	return &b
+}
+
+func expectLogged(b *bytes.Buffer, want string, t *testing.T) {
+	t.Helper()
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("expected %q in the log but got:\n%s", want, b.String())
+	}
+}
+
+func TestLogOperations(t *testing.T) {
+	b := captureLog(slog.LevelDebug)
+	defer SetLogger(nil)
+
+	FactorQR(factorTestMatrix())
+
+	expectLogged(b, "msg=operation op=QR duration=", t)
+}
+
+func TestLogSmallPivot(t *testing.T) {
+	b := captureLog(slog.LevelWarn)
+	defer SetLogger(nil)
+
+	A := MatrixFromSlice([]float64{
+		1, 1,
+		1, 1 + 1e-14,
+	}, 2, 2, 2)
+	FactorLU(A)
+
+	expectLogged(b, `level=WARN msg="small pivot" op=LU step=1`, t)
+	if strings.Contains(b.String(), "duration") {
+		t.Error("expected debug messages to be filtered out")
+	}
+}
+
+func TestLogConvergence(t *testing.T) {
+	b := captureLog(slog.LevelInfo)
+	defer SetLogger(nil)
+
+	A := factorTestMatrix()
+	rhs := NewVector(3)
+	rhs.Set(0, 0, 1)
+	GMRES(MatrixOperator(A), rhs, 1e-12, 10)
+	GMRES(MatrixOperator(A), rhs, 1e-12, 1)
+
+	expectLogged(b, "level=INFO msg=converged solver=GMRES iterations=3", t)
+	expectLogged(b, `level=WARN msg="did not converge" solver=GMRES iterations=1`, t)
+}
@@ -0,0 +1,213 @@
+package linear
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrInfeasible means no x satisfies the constraints.
+	ErrInfeasible = errors.New("infeasible")
+	// ErrUnbounded means the objective can decrease without limit.
+	ErrUnbounded = errors.New("unbounded")
+)
+
+// lpTolerance is how close to zero a reduced cost, pivot or
+// infeasibility has to be to count as zero.
+const lpTolerance = 1e-9
+
+// LinearProgram finds the x that minimizes Dual(c)*x subject to
+// A*x <= b and x >= 0, by the revised simplex method: each step factors
+// the current basis with FactorLU and solves with it rather than
+// updating a tableau. Constraints with a negative b need a first phase
+// to find a feasible starting point. It returns ErrInfeasible or
+// ErrUnbounded when there is no minimum.
+func LinearProgram(c Vector, A Matrix, b Vector) (x Vector, value float64, err error) {
+	CheckVector(c)
+	CheckVector(b)
+	CheckSameOuts(A, b)
+	n, m := A.Shape()
+	if _, dim := c.Shape(); dim != n {
+		panic(fmt.Errorf("expected %d costs but got %d", n, dim))
+	}
+
+	// Standard form: [A I R]*(x, slacks, artificials) = b, with the rows
+	// where b < 0 negated so that the right hand side is non-negative,
+	// and an artificial variable for each of those since its slack can't
+	// start in the basis.
+	var artificialRows []int
+	for o := 0; o < m; o++ {
+		if b.Get(0, o) < 0 {
+			artificialRows = append(artificialRows, o)
+		}
+	}
+	cols := n + m + len(artificialRows)
+	M := NewArrayMatrixColMajor(cols, m)
+	rhs := NewVector(m)
+	basis := make([]int, m)
+	for o := 0; o < m; o++ {
+		sign := 1.0
+		if b.Get(0, o) < 0 {
+			sign = -1
+		}
+		for i := 0; i < n; i++ {
+			M.Set(i, o, sign*A.Get(i, o))
+		}
+		M.Set(n+o, o, sign)
+		rhs.Set(0, o, sign*b.Get(0, o))
+		basis[o] = n + o
+	}
+	for k, o := range artificialRows {
+		M.Set(n+m+k, o, 1)
+		basis[o] = n + m + k
+	}
+
+	lp := &simplex{M: M, rhs: rhs, basis: basis}
+	if len(artificialRows) > 0 {
+		// Phase one minimizes the sum of the artificial variables.
+		cost := make([]float64, cols)
+		for k := range artificialRows {
+			cost[n+m+k] = 1
+		}
+		lp.allowed = cols
+		if lp.run(cost) != nil {
+			panic(fmt.Errorf("phase one can't be unbounded"))
+		}
+		xB := lp.basic()
+		for r, j := range lp.basis {
+			if j >= n+m && xB.Get(0, r) > lpTolerance*(1+L2Norm(rhs)) {
+				return nil, 0, ErrInfeasible
+			}
+		}
+	}
+
+	// Phase two never lets an artificial variable back in, and the ones
+	// still in the basis (at zero) are swapped out where possible, since
+	// otherwise they could grow again. Any left are on redundant rows.
+	lp.allowed = n + m
+	lp.removeArtificial()
+	cost := make([]float64, cols)
+	for i := 0; i < n; i++ {
+		cost[i] = c.Get(0, i)
+	}
+	if err := lp.run(cost); err != nil {
+		return nil, 0, err
+	}
+	xB := lp.basic()
+	x = NewVector(n)
+	for r, j := range lp.basis {
+		if j < n {
+			x.Set(0, j, xB.Get(0, r))
+		}
+	}
+	return x, DotProduct(x, Dual(c)), nil
+}
+
+// simplex is a linear program M*x = rhs, x >= 0 in standard form with
+// a feasible basis.
+type simplex struct {
+	M     Matrix
+	rhs   Vector
+	basis []int
+	// Only the first allowed columns may enter the basis.
+	allowed int
+}
+
+// basisMatrix returns the columns of M in the basis.
+func (s *simplex) basisMatrix() Matrix {
+	_, m := s.M.Shape()
+	B := NewArrayMatrixColMajor(m, m)
+	for r, j := range s.basis {
+		CopyInto(Slice(s.M, j, j+1, 0, m), Slice(B, r, r+1, 0, m))
+	}
+	return B
+}
+
+// basic returns the values of the basic variables.
+func (s *simplex) basic() Vector {
+	return FactorLU(s.basisMatrix()).SolveVec(s.rhs)
+}
+
+// run pivots until no allowed column has a negative reduced cost,
+// using Bland's rule (lowest index first) so that it can't cycle.
+func (s *simplex) run(cost []float64) error {
+	_, m := s.M.Shape()
+	for {
+		B := s.basisMatrix()
+		lu := FactorLU(B)
+		xB := lu.SolveVec(s.rhs)
+
+		// The prices y solve Dual(B)*y = the basic costs.
+		cB := NewVector(m)
+		for r, j := range s.basis {
+			cB.Set(0, r, cost[j])
+		}
+		y := FactorLU(Copy(Dual(B))).SolveVec(cB)
+
+		entering := -1
+		for j := 0; j < s.allowed && entering < 0; j++ {
+			if s.inBasis(j) {
+				continue
+			}
+			reduced := cost[j] - DotProduct(Slice(s.M, j, j+1, 0, m), Dual(y))
+			if reduced < -lpTolerance {
+				entering = j
+			}
+		}
+		if entering < 0 {
+			return nil
+		}
+
+		// Moving along the entering column changes the basic variables
+		// by -t*d, and the first to hit zero leaves.
+		d := lu.SolveVec(Slice(s.M, entering, entering+1, 0, m))
+		leaving := -1
+		best := math.Inf(1)
+		for r := 0; r < m; r++ {
+			if dr := d.Get(0, r); dr > lpTolerance {
+				t := xB.Get(0, r) / dr
+				if t < best-lpTolerance || (t <= best+lpTolerance && leaving >= 0 && s.basis[r] < s.basis[leaving]) {
+					best, leaving = t, r
+				}
+			}
+		}
+		if leaving < 0 {
+			return ErrUnbounded
+		}
+		s.basis[leaving] = entering
+	}
+}
+
+// removeArtificial replaces the variables of the basis past the
+// allowed columns with allowed ones, which are at zero so the basis
+// stays feasible, by pivoting on a non-zero of their row of
+// inverse(B)*M.
+func (s *simplex) removeArtificial() {
+	_, m := s.M.Shape()
+	for r, j := range s.basis {
+		if j < s.allowed {
+			continue
+		}
+		// Row r of inverse(B) solves Dual(B)*z = e_r.
+		z := FactorLU(Copy(Dual(s.basisMatrix()))).SolveVec(BasisVector(m, r))
+		for k := 0; k < s.allowed; k++ {
+			if s.inBasis(k) {
+				continue
+			}
+			if math.Abs(DotProduct(Slice(s.M, k, k+1, 0, m), Dual(z))) > lpTolerance {
+				s.basis[r] = k
+				break
+			}
+		}
+	}
+}
+
+func (s *simplex) inBasis(j int) bool {
+	for _, b := range s.basis {
+		if b == j {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestLinearProgram(t *testing.T) {
+	// Maximize x + y subject to x + 2y <= 4 and 3x + y <= 6.
+	c := NewVector(2)
+	c.Set(0, 0, -1)
+	c.Set(0, 1, -1)
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 3)
+	A.Set(1, 1, 1)
+	b := NewVector(2)
+	b.Set(0, 0, 4)
+	b.Set(0, 1, 6)
+
+	x, value, err := LinearProgram(c, A, b)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectFloat(1.6, x.Get(0, 0), t)
+	ExpectFloat(1.2, x.Get(0, 1), t)
+	ExpectFloat(-2.8, value, t)
+}
+
+func TestLinearProgramPhaseOne(t *testing.T) {
+	// Minimize x + 2y subject to x + y >= 1 and x <= 3.
+	c := NewVector(2)
+	c.Set(0, 0, 1)
+	c.Set(0, 1, 2)
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, -1)
+	A.Set(1, 0, -1)
+	A.Set(0, 1, 1)
+	b := NewVector(2)
+	b.Set(0, 0, -1)
+	b.Set(0, 1, 3)
+
+	x, value, err := LinearProgram(c, A, b)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectFloat(1, x.Get(0, 0), t)
+	ExpectFloat(0, x.Get(0, 1), t)
+	ExpectFloat(1, value, t)
+}
+
+func TestLinearProgramNoMinimum(t *testing.T) {
+	c := NewVector(1)
+	c.Set(0, 0, -1)
+	A := NewArrayMatrix(1, 1)
+	A.Set(0, 0, -1)
+	b := NewVector(1)
+	b.Set(0, 0, 1)
+
+	if _, _, err := LinearProgram(c, A, b); err != ErrUnbounded {
+		t.Errorf("expected ErrUnbounded but got %v", err)
+	}
+
+	// x <= -1 with x >= 0.
+	A.Set(0, 0, 1)
+	b.Set(0, 0, -1)
+
+	if _, _, err := LinearProgram(c, A, b); err != ErrInfeasible {
+		t.Errorf("expected ErrInfeasible but got %v", err)
+	}
+}
+
+func TestLinearProgramLeastAbsoluteDeviations(t *testing.T) {
+	// Fit y = a + b*t minimizing the sum of |residuals|, with one wild
+	// outlier that least squares would chase. Variables are a+, a-, b+,
+	// b- and the positive and negative parts of each residual.
+	ts := []float64{0, 1, 2, 3, 4}
+	ys := []float64{1, 3, 5, 100, 9}
+	n := 4 + 2*len(ts)
+	c := NewVector(n)
+	for k := range ts {
+		c.Set(0, 4+2*k, 1)
+		c.Set(0, 5+2*k, 1)
+	}
+	// Each equality a + b*t + e+ - e- = y is two inequalities.
+	A := NewArrayMatrix(n, 2*len(ts))
+	b := NewVector(2 * len(ts))
+	for k, tk := range ts {
+		for s, sign := range []float64{1, -1} {
+			o := 2*k + s
+			A.Set(0, o, sign)
+			A.Set(1, o, -sign)
+			A.Set(2, o, sign*tk)
+			A.Set(3, o, -sign*tk)
+			A.Set(4+2*k, o, sign)
+			A.Set(5+2*k, o, -sign)
+			b.Set(0, o, sign*ys[k])
+		}
+	}
+
+	x, value, err := LinearProgram(c, A, b)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectFloat(1, x.Get(0, 0)-x.Get(0, 1), t)
+	ExpectFloat(2, x.Get(0, 2)-x.Get(0, 3), t)
+	ExpectFloat(93, value, t)
+}
@@ -0,0 +1,282 @@
+package linear
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Data types and array classes of MATLAB level 5 MAT-files.
+const (
+	matInt8       = 1
+	matUint8      = 2
+	matInt16      = 3
+	matUint16     = 4
+	matInt32      = 5
+	matUint32     = 6
+	matSingle     = 7
+	matDouble     = 9
+	matInt64      = 12
+	matUint64     = 13
+	matMatrix     = 14
+	matCompressed = 15
+
+	matDoubleClass = 6
+	matUint64Class = 15
+)
+
+var matName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,62}$`)
+
+// ReadMAT reads the variables of a MATLAB level 5 MAT-file that are
+// real numeric 2-d arrays, by name, with MATLAB's rows as outputs.
+// Compressed variables are supported. Other variables, like cells,
+// structs, strings and sparse arrays, are skipped; complex arrays are
+// an error rather than losing their imaginary parts.
+func ReadMAT(r io.Reader) (map[string]Matrix, error) {
+	var header [128]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch string(header[126:]) {
+	case "IM":
+		order = binary.LittleEndian
+	case "MI":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a level 5 MAT-file")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]Matrix{}
+	d := &matDecoder{data, order}
+	for len(d.data) > 0 {
+		typ, body, err := d.element()
+		if err != nil {
+			return nil, err
+		}
+		if typ == matCompressed {
+			zr, err := zlib.NewReader(bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			inflated, err := io.ReadAll(zr)
+			if err != nil {
+				return nil, err
+			}
+			if typ, body, err = (&matDecoder{inflated, order}).element(); err != nil {
+				return nil, err
+			}
+		}
+		if typ != matMatrix {
+			continue
+		}
+		name, A, err := (&matDecoder{body, order}).matrix()
+		if err != nil {
+			return nil, err
+		}
+		if A != nil {
+			vars[name] = A
+		}
+	}
+	return vars, nil
+}
+
+type matDecoder struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// element reads the next data element, in either the full form (an 8
+// byte tag) or small form (type and size packed in 4 bytes, with up to
+// 4 bytes of data).
+func (d *matDecoder) element() (typ int, body []byte, err error) {
+	if len(d.data) < 8 {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	tag := d.order.Uint32(d.data)
+	if size := int(tag >> 16); size != 0 {
+		if size > 4 {
+			return 0, nil, fmt.Errorf("small data element of %d bytes", size)
+		}
+		body = d.data[4 : 4+size]
+		d.data = d.data[8:]
+		return int(tag & 0xffff), body, nil
+	}
+	n := uint64(d.order.Uint32(d.data[4:]))
+	if n > uint64(len(d.data)-8) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	body = d.data[8 : 8+n]
+	d.data = d.data[8+n:]
+	// Elements are padded to 8 bytes, except compressed ones.
+	if pad := int((8 - n%8) % 8); int(tag) != matCompressed && pad <= len(d.data) {
+		d.data = d.data[pad:]
+	}
+	return int(tag), body, nil
+}
+
+// matrix reads the contents of a matMatrix element, returning a nil
+// Matrix for arrays that aren't numeric.
+func (d *matDecoder) matrix() (string, Matrix, error) {
+	typ, flags, err := d.element()
+	if err != nil {
+		return "", nil, err
+	}
+	if typ != matUint32 || len(flags) != 8 {
+		return "", nil, fmt.Errorf("bad array flags")
+	}
+	class := int(flags[firstByte(d.order, 0)])
+	complex := flags[firstByte(d.order, 1)]&0x08 != 0
+
+	typ, dimBytes, err := d.element()
+	if err != nil {
+		return "", nil, err
+	}
+	dims, err := matValues(typ, dimBytes, d.order)
+	if err != nil {
+		return "", nil, err
+	}
+	_, nameBytes, err := d.element()
+	if err != nil {
+		return "", nil, err
+	}
+	name := string(nameBytes)
+	if class < matDoubleClass || class > matUint64Class {
+		return name, nil, nil
+	}
+	if complex {
+		return "", nil, fmt.Errorf("%s: complex arrays aren't supported", name)
+	}
+	if len(dims) != 2 {
+		return "", nil, fmt.Errorf("%s: can't read a %d-d array as a matrix", name, len(dims))
+	}
+	for _, n := range dims {
+		if n < 0 || n != math.Trunc(n) || n >= math.MaxInt64 {
+			return "", nil, fmt.Errorf("%s: bad dimensions %v", name, dims)
+		}
+	}
+	size, ok := entryCount(uint64(dims[0]), uint64(dims[1]))
+	if !ok {
+		return "", nil, fmt.Errorf("%s: %v by %v is too big", name, dims[0], dims[1])
+	}
+	rows, cols := int(dims[0]), int(dims[1])
+
+	typ, realBytes, err := d.element()
+	if err != nil {
+		return "", nil, err
+	}
+	values, err := matValues(typ, realBytes, d.order)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if len(values) != size {
+		return "", nil, fmt.Errorf("%s: %d values for %d by %d", name, len(values), rows, cols)
+	}
+	// MATLAB stores arrays column by column.
+	return name, &arrayMatrix{array: values, ins: cols, outs: rows, inStride: rows, outStride: 1}, nil
+}
+
+// firstByte returns the index of byte k (from least significant) of a
+// uint32 stored in the given byte order.
+func firstByte(order binary.ByteOrder, k int) int {
+	if order == binary.BigEndian {
+		return 3 - k
+	}
+	return k
+}
+
+// matValues decodes numeric data of any MAT-file type as float64s.
+func matValues(typ int, b []byte, order binary.ByteOrder) ([]float64, error) {
+	sizes := map[int]int{
+		matInt8: 1, matUint8: 1, matInt16: 2, matUint16: 2, matInt32: 4, matUint32: 4,
+		matSingle: 4, matDouble: 8, matInt64: 8, matUint64: 8,
+	}
+	size, ok := sizes[typ]
+	if !ok {
+		return nil, fmt.Errorf("unsupported data type %d", typ)
+	}
+	if len(b)%size != 0 {
+		return nil, fmt.Errorf("%d bytes of %d byte values", len(b), size)
+	}
+	values := make([]float64, len(b)/size)
+	for k := range values {
+		e := b[k*size:]
+		switch typ {
+		case matDouble:
+			values[k] = math.Float64frombits(order.Uint64(e))
+		case matSingle:
+			values[k] = float64(math.Float32frombits(order.Uint32(e)))
+		default:
+			values[k] = npyInt(e[:size], order, typ == matInt8 || typ == matInt16 || typ == matInt32 || typ == matInt64)
+		}
+	}
+	return values, nil
+}
+
+// WriteMAT writes the variables as double arrays in an uncompressed
+// MATLAB level 5 MAT-file, which MATLAB, Octave and SciPy can load.
+// Names must be valid MATLAB identifiers.
+func WriteMAT(w io.Writer, vars map[string]Matrix) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if !matName.MatchString(name) {
+			return fmt.Errorf("%q isn't a valid MATLAB variable name", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	text := "MATLAB 5.0 MAT-file, written by github.com/ornerylawn/linear"
+	buf := []byte(text + strings.Repeat(" ", 116-len(text)))
+	buf = append(buf, make([]byte, 8)...)
+	buf = binary.LittleEndian.AppendUint16(buf, 0x0100)
+	buf = append(buf, 'I', 'M')
+
+	for _, name := range names {
+		A := vars[name]
+		ins, outs := A.Shape()
+		if uint64(8*ins*outs) > math.MaxUint32-256 {
+			return fmt.Errorf("%s is too big for a MAT-file", name)
+		}
+		var body []byte
+		body = appendMATElement(body, matUint32, []byte{matDoubleClass, 0, 0, 0, 0, 0, 0, 0})
+		var dims []byte
+		dims = binary.LittleEndian.AppendUint32(dims, uint32(outs))
+		dims = binary.LittleEndian.AppendUint32(dims, uint32(ins))
+		body = appendMATElement(body, matInt32, dims)
+		body = appendMATElement(body, matInt8, []byte(name))
+		values := make([]byte, 0, 8*ins*outs)
+		for i := 0; i < ins; i++ {
+			for o := 0; o < outs; o++ {
+				values = binary.LittleEndian.AppendUint64(values, math.Float64bits(A.Get(i, o)))
+			}
+		}
+		body = appendMATElement(body, matDouble, values)
+		buf = appendMATElement(buf, matMatrix, body)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// appendMATElement appends a little endian data element, in the small
+// form when it fits.
+func appendMATElement(buf []byte, typ int, data []byte) []byte {
+	if len(data) <= 4 && typ != matMatrix {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(data))<<16|uint32(typ))
+		buf = append(buf, data...)
+		return append(buf, make([]byte, 4-len(data))...)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(typ))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+	return append(buf, make([]byte, (8-len(data)%8)%8)...)
+}
@@ -0,0 +1,148 @@
+package linear
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// matFile builds a MAT-file from elements in the given byte order.
+func matFile(order binary.ByteOrder, elements ...[]byte) []byte {
+	b := make([]byte, 126)
+	if order == binary.BigEndian {
+		b = append(b, 'M', 'I')
+	} else {
+		b = append(b, 'I', 'M')
+	}
+	for _, e := range elements {
+		b = append(b, e...)
+	}
+	return b
+}
+
+func matElement(order binary.ByteOrder, typ int, data []byte) []byte {
+	b := make([]byte, 8, 8+len(data)+7)
+	order.PutUint32(b, uint32(typ))
+	order.PutUint32(b[4:], uint32(len(data)))
+	b = append(b, data...)
+	return append(b, make([]byte, (8-len(data)%8)%8)...)
+}
+
+func matUint32s(order binary.ByteOrder, xs ...uint32) []byte {
+	b := make([]byte, 4*len(xs))
+	for k, x := range xs {
+		order.PutUint32(b[4*k:], x)
+	}
+	return b
+}
+
+func matArray(order binary.ByteOrder, class, flags byte, rows, cols int, name string, real []byte) []byte {
+	return matArrayDims(order, class, flags, matElement(order, matInt32, matUint32s(order, uint32(rows), uint32(cols))), name, real)
+}
+
+// matArrayDims is matArray with the dimensions given as an element, so
+// they can be of any type.
+func matArrayDims(order binary.ByteOrder, class, flags byte, dims []byte, name string, real []byte) []byte {
+	var body []byte
+	// The second word of the array flags is unused.
+	body = append(body, matElement(order, matUint32, matUint32s(order, uint32(flags)<<8|uint32(class), 0))...)
+	body = append(body, dims...)
+	body = append(body, matElement(order, matInt8, []byte(name))...)
+	body = append(body, real...)
+	return matElement(order, matMatrix, body)
+}
+
+func TestWriteReadMAT(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, math.Inf(1)}, 3, 2, 3)
+	v := MatrixFromSlice([]float64{0.5}, 1, 1, 1)
+	var b bytes.Buffer
+	if err := WriteMAT(&b, map[string]Matrix{"A": A, "long_name_x": v}); err != nil {
+		t.Fatal(err)
+	}
+	vars, err := ReadMAT(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectInt(2, len(vars), t)
+	ExpectMatrix(A, vars["A"], t)
+	ExpectMatrix(v, vars["long_name_x"], t)
+
+	if err := WriteMAT(&b, map[string]Matrix{"2x": v}); err == nil {
+		t.Errorf("expected an error for an invalid name")
+	}
+}
+
+func TestReadMATBigEndianIntegers(t *testing.T) {
+	order := binary.BigEndian
+	var data []byte
+	for _, x := range []int32{1, 4, 2, 5, 3, -6} {
+		data = append(data, matUint32s(order, uint32(x))...)
+	}
+	// A 2 by 3 int32 array, column by column, with a small element for
+	// the name.
+	const int32Class = 12
+	e := matArray(order, int32Class, 0, 2, 3, "", matElement(order, matInt32, data))
+	name := append(matUint32s(order, 1<<16|matInt8), 'M', 0, 0, 0)
+	// Swap the empty name element for the small one.
+	e = bytes.Replace(e, matElement(order, matInt8, nil), name, 1)
+	order.PutUint32(e[4:], uint32(len(e)-8))
+
+	vars, err := ReadMAT(bytes.NewReader(matFile(order, e)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{1, 2, 3, 4, 5, -6}, 3, 2, 3), vars["M"], t)
+}
+
+func TestReadMATCompressedAndSkipped(t *testing.T) {
+	order := binary.LittleEndian
+	var values []byte
+	for _, x := range []float64{7, 8} {
+		values = binary.LittleEndian.AppendUint64(values, math.Float64bits(x))
+	}
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(matArray(order, matDoubleClass, 0, 1, 2, "row", matElement(order, matDouble, values)))
+	zw.Close()
+	compressed := append(matUint32s(order, matCompressed, uint32(z.Len())), z.Bytes()...)
+
+	const charClass = 4
+	text := matArray(order, charClass, 0, 1, 2, "s", matElement(order, matUint16, []byte{'h', 0, 'i', 0}))
+
+	vars, err := ReadMAT(bytes.NewReader(matFile(order, text, compressed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectInt(1, len(vars), t)
+	ExpectMatrix(MatrixFromSlice([]float64{7, 8}, 2, 1, 2), vars["row"], t)
+
+	const complexFlag = 0x08
+	complexArray := matArray(order, matDoubleClass, complexFlag, 1, 1, "z", matElement(order, matDouble, values[:8]))
+	if _, err := ReadMAT(bytes.NewReader(matFile(order, complexArray))); err == nil {
+		t.Errorf("expected an error for a complex array")
+	}
+	if _, err := ReadMAT(bytes.NewReader(make([]byte, 128))); err == nil {
+		t.Errorf("expected an error for a missing endian indicator")
+	}
+}
+
+func TestReadMATBadDimensions(t *testing.T) {
+	order := binary.LittleEndian
+	var huge, fractional []byte
+	huge = order.AppendUint64(huge, 1<<32)
+	huge = order.AppendUint64(huge, 1<<32)
+	fractional = order.AppendUint64(fractional, math.Float64bits(1.5))
+	fractional = order.AppendUint64(fractional, math.Float64bits(2))
+	for _, dims := range [][]byte{
+		// The number of entries wraps to 0, matching no data.
+		matElement(order, matUint64, huge),
+		matElement(order, matDouble, fractional),
+	} {
+		e := matArrayDims(order, matDoubleClass, 0, dims, "A", matElement(order, matDouble, nil))
+		if _, err := ReadMAT(bytes.NewReader(matFile(order, e))); err == nil {
+			t.Errorf("expected an error for dimensions % x", dims)
+		}
+	}
+}
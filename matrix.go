@@ -24,22 +24,121 @@ type Matrix interface {
 }
 
 type arrayMatrix struct {
-	array     []float64
-	ins, outs int
+	array               []float64
+	ins, outs           int
+	inStride, outStride int
 }
 
-// NewArrayMatrix makes a new array-based Matrix with the given shape.
+// NewArrayMatrix makes a new array-based Matrix with the given shape,
+// with the entries of each output (row) next to each other in memory.
 func NewArrayMatrix(ins, outs int) Matrix {
+	countAlloc(ins * outs)
 	return &arrayMatrix{
-		array: make([]float64, outs*ins),
-		ins:   ins,
-		outs:  outs,
+		array:     make([]float64, outs*ins),
+		ins:       ins,
+		outs:      outs,
+		inStride:  1,
+		outStride: ins,
 	}
 }
 
-func (m *arrayMatrix) Shape() (ins, outs int)         { return m.ins, m.outs }
-func (m *arrayMatrix) Get(in, out int) float64        { return m.array[out*m.ins+in] }
-func (m *arrayMatrix) Set(in, out int, value float64) { m.array[out*m.ins+in] = value }
+// NewArrayMatrixColMajor makes a new array-based Matrix with the given
+// shape, with the entries of each input (column) next to each other in
+// memory. It's a better fit for algorithms that walk down columns.
+func NewArrayMatrixColMajor(ins, outs int) Matrix {
+	countAlloc(ins * outs)
+	return &arrayMatrix{
+		array:     make([]float64, outs*ins),
+		ins:       ins,
+		outs:      outs,
+		inStride:  outs,
+		outStride: 1,
+	}
+}
+
+// MatrixFromSlice makes an array-based Matrix that reads and writes
+// the given data in place instead of copying it, with each output
+// (row) starting rowStride entries after the previous one.
+func MatrixFromSlice(data []float64, ins, outs, rowStride int) Matrix {
+	if ins < 0 || outs < 0 || rowStride < ins {
+		panic(fmt.Errorf("invalid shape (%d, %d) with row stride %d", ins, outs, rowStride))
+	}
+	if outs > 0 && ins > 0 && len(data) < (outs-1)*rowStride+ins {
+		panic(fmt.Errorf("%d entries is too few for shape (%d, %d) with row stride %d", len(data), ins, outs, rowStride))
+	}
+	return &arrayMatrix{
+		array:     data,
+		ins:       ins,
+		outs:      outs,
+		inStride:  1,
+		outStride: rowStride,
+	}
+}
+
+func (m *arrayMatrix) Shape() (ins, outs int) { return m.ins, m.outs }
+func (m *arrayMatrix) Get(in, out int) float64 {
+	return m.array[out*m.outStride+in*m.inStride]
+}
+func (m *arrayMatrix) Set(in, out int, value float64) {
+	m.array[out*m.outStride+in*m.inStride] = value
+}
+
+// colMajor returns true if walking down an input (column) is
+// contiguous in memory.
+func (m *arrayMatrix) colMajor() bool { return m.outStride == 1 && m.inStride != 1 }
+
+// dual returns a view of the same array with the roles of inputs and
+// outputs swapped.
+func (m *arrayMatrix) dual() *arrayMatrix {
+	return &arrayMatrix{m.array, m.outs, m.ins, m.outStride, m.inStride}
+}
+
+// arrayBacked is implemented by matrices from elsewhere in the package
+// that store their entries in an arrayMatrix.
+type arrayBacked interface {
+	backingArray() (*arrayMatrix, bool)
+}
+
+// asArrayMatrix sees through Dual to an underlying arrayMatrix so that
+// kernels can work on the array directly.
+func asArrayMatrix(A Matrix) (*arrayMatrix, bool) {
+	switch a := A.(type) {
+	case *arrayMatrix:
+		return a, true
+	case *dualMatrix:
+		if m, ok := asArrayMatrix(a.A); ok {
+			return m.dual(), true
+		}
+	case arrayBacked:
+		return a.backingArray()
+	}
+	return nil, false
+}
+
+// readOnlyArray is implemented by array-backed matrices whose array may
+// be read by kernels but not written, like a read-only map.
+type readOnlyArray interface {
+	readOnlyArray() bool
+}
+
+// asWritableArrayMatrix is asArrayMatrix for the destination of a
+// kernel, which writes to the array.
+func asWritableArrayMatrix(A Matrix) (*arrayMatrix, bool) {
+	if isReadOnlyArray(A) {
+		return nil, false
+	}
+	return asArrayMatrix(A)
+}
+
+func isReadOnlyArray(A Matrix) bool {
+	switch a := A.(type) {
+	case *dualMatrix:
+		return isReadOnlyArray(a.A)
+	case readOnlyArray:
+		return a.readOnlyArray()
+	}
+	return false
+}
 
 type sliceMatrix struct {
 	A                        Matrix
@@ -67,6 +166,31 @@ func (s *sliceMatrix) checkBounds(in, out int) {
 	}
 }
 
+type gatherMatrix struct {
+	A    Matrix
+	outs []int
+}
+
+// Gather returns a Matrix backed by another one whose outputs (rows)
+// are the given outputs of A, in that order and possibly repeated, as
+// for resampling observations.
+func Gather(A Matrix, outs []int) Matrix {
+	_, aOuts := A.Shape()
+	for _, o := range outs {
+		if o < 0 || o >= aOuts {
+			panic(fmt.Errorf("output %d is out of bounds %d", o, aOuts))
+		}
+	}
+	return &gatherMatrix{A, outs}
+}
+
+func (g *gatherMatrix) Shape() (ins, outs int) {
+	ins, _ = g.A.Shape()
+	return ins, len(g.outs)
+}
+func (g *gatherMatrix) Get(in, out int) float64        { return g.A.Get(in, g.outs[out]) }
+func (g *gatherMatrix) Set(in, out int, value float64) { g.A.Set(in, g.outs[out], value) }
+
 type dualMatrix struct {
 	A Matrix
 }
@@ -97,6 +221,25 @@ func IsZero(A Matrix) bool {
 	return true
 }
 
+// IsZeroRelative is like IsZero but treats entries as zero when their
+// magnitude is at most tol times scale, so that the decision doesn't
+// depend on the units the entries happen to be in.
+func IsZeroRelative(A Matrix, scale, tol float64) bool {
+	return MaxAbs(A) <= tol*scale
+}
+
+// MaxAbs returns the largest magnitude of any entry of A.
+func MaxAbs(A Matrix) float64 {
+	ins, outs := A.Shape()
+	m := 0.0
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			m = math.Max(m, math.Abs(A.Get(i, o)))
+		}
+	}
+	return m
+}
+
 // CopyInto copies the entries from one matrix to another.
 func CopyInto(src, dst Matrix) {
 	ins, outs := src.Shape()
@@ -154,6 +297,19 @@ func ComposeInto(A, B, dst Matrix) {
 	if aOuts != bIns {
 		panic(fmt.Errorf("dimension mismatch %d vs %d", aOuts, bIns))
 	}
+	checkSpaces(A, B, dst)
+	defer beginOp("Multiply")()
+	if composeSparse(A, B, dst) {
+		return
+	}
+	countFlops(2 * aIns * aOuts * bOuts)
+	a, aok := asArrayMatrix(A)
+	b, bok := asArrayMatrix(B)
+	d, dok := asWritableArrayMatrix(dst)
+	if aok && bok && dok {
+		composeArrays(a, b, d)
+		return
+	}
 	for o := 0; o < bOuts; o++ {
 		for i := 0; i < aIns; i++ {
 			dot := 0.0
@@ -165,13 +321,124 @@ func ComposeInto(A, B, dst Matrix) {
 	}
 }
 
-// Compose returns "A then B" (aka B*A).
+// composeArrays is ComposeInto for arrays, ordering the loops so that
+// the innermost one walks contiguously through dst.
+func composeArrays(a, b, d *arrayMatrix) {
+	// Blocked by the gemm microkernel when rows are contiguous, or
+	// columns, which are the rows of the dual product.
+	if gemmArrays(a, b, d) || gemmArrays(b.dual(), a.dual(), d.dual()) {
+		return
+	}
+	if d.colMajor() {
+		// Build each input (column) of dst as a linear combination of
+		// the columns of b. Columns don't share any of dst so they can be
+		// built in parallel without changing the result.
+		parallelFor(a.ins, a.outs*b.outs, func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				col := d.array[i*d.inStride:]
+				for o := 0; o < b.outs; o++ {
+					col[o*d.outStride] = 0
+				}
+				for k := 0; k < a.outs; k++ {
+					s := a.array[k*a.outStride+i*a.inStride]
+					if s == 0 {
+						continue
+					}
+					bk := b.array[k*b.inStride:]
+					if d.outStride == 1 && b.outStride == 1 {
+						axpy(s, bk[:b.outs], col[:b.outs])
+						continue
+					}
+					for o := 0; o < b.outs; o++ {
+						col[o*d.outStride] += s * bk[o*b.outStride]
+					}
+				}
+			}
+		})
+		return
+	}
+	// Build each output (row) of dst as a linear combination of the
+	// rows of a.
+	parallelFor(b.outs, a.ins*a.outs, func(lo, hi int) {
+		for o := lo; o < hi; o++ {
+			row := d.array[o*d.outStride:]
+			for i := 0; i < a.ins; i++ {
+				row[i*d.inStride] = 0
+			}
+			for k := 0; k < a.outs; k++ {
+				s := b.array[o*b.outStride+k*b.inStride]
+				if s == 0 {
+					continue
+				}
+				ak := a.array[k*a.outStride:]
+				if d.inStride == 1 && a.inStride == 1 {
+					axpy(s, ak[:a.ins], row[:a.ins])
+					continue
+				}
+				for i := 0; i < a.ins; i++ {
+					row[i*d.inStride] += s * ak[i*a.inStride]
+				}
+			}
+		}
+	})
+}
+
+// gemmArrays is composeArrays in 4 by 8 blocks of dst with gemm4x8,
+// for when the outputs (rows) of a and dst are contiguous. It returns
+// false, doing nothing, otherwise or when dst is too small to block.
+func gemmArrays(a, b, d *arrayMatrix) bool {
+	m, n, k := b.outs, a.ins, a.outs
+	if a.inStride != 1 || d.inStride != 1 || m < 4 || n < 8 || k == 0 {
+		return false
+	}
+	at := func(o, i int) float64 {
+		sum := 0.0
+		for p := 0; p < k; p++ {
+			sum += b.array[o*b.outStride+p*b.inStride] * a.array[p*a.outStride+i]
+		}
+		return sum
+	}
+	blocks := m / 4
+	parallelFor(blocks, 4*k*n, func(lo, hi int) {
+		// Pack the 4 rows of b so the microkernel reads them in order.
+		lp := make([]float64, 4*k)
+		for blk := lo; blk < hi; blk++ {
+			o0 := 4 * blk
+			for p := 0; p < k; p++ {
+				for r := 0; r < 4; r++ {
+					lp[4*p+r] = b.array[(o0+r)*b.outStride+p*b.inStride]
+				}
+			}
+			i := 0
+			for ; i+8 <= n; i += 8 {
+				gemm4x8(k, lp, a.array[i:], a.outStride, d.array[o0*d.outStride+i:], d.outStride)
+			}
+			for ; i < n; i++ {
+				for r := 0; r < 4; r++ {
+					d.array[(o0+r)*d.outStride+i] = at(o0+r, i)
+				}
+			}
+		}
+	})
+	for o := 4 * blocks; o < m; o++ {
+		for i := 0; i < n; i++ {
+			d.array[o*d.outStride+i] = at(o, i)
+		}
+	}
+	return true
+}
+
+// Compose returns "A then B" (aka B*A). If both are sparse then so is
+// the result.
 func Compose(A, B Matrix) Matrix {
+	if C, ok := multiplySparseSparse(A, B); ok {
+		return C
+	}
 	aIns, _ := A.Shape()
 	_, bOuts := B.Shape()
 	dst := NewArrayMatrix(aIns, bOuts)
 	ComposeInto(A, B, dst)
-	return dst
+	return tagComposition(A, B, dst)
 }
 
 // ApplyInto writes A*X into dst.
@@ -179,13 +446,16 @@ func ApplyInto(A, X, dst Matrix) {
 	ComposeInto(X, A, dst)
 }
 
-// Apply returns A*X.
+// Apply returns A*X. If both are sparse then so is the result.
 func Apply(A, X Matrix) Matrix {
+	if C, ok := multiplySparseSparse(X, A); ok {
+		return C
+	}
 	xIns, _ := X.Shape()
 	_, aOuts := A.Shape()
 	dst := NewArrayMatrix(xIns, aOuts)
 	ApplyInto(A, X, dst)
-	return dst
+	return tagComposition(X, A, dst)
 }
 
 func CheckScalar(f Matrix) {
@@ -254,6 +524,12 @@ func DotProduct(v, c Matrix) float64 {
 	CheckVector(v)
 	CheckCovector(c)
 	_, dim := v.Shape()
+	countFlops(2 * dim)
+	if a, ok := asArrayMatrix(v); ok && a.outStride == 1 {
+		if b, ok := asArrayMatrix(c); ok && b.inStride == 1 {
+			return dot(a.array[:dim], b.array[:dim])
+		}
+	}
 	dot := 0.0
 	for d := 0; d < dim; d++ {
 		dot += v.Get(0, d) * c.Get(d, 0)
@@ -269,25 +545,45 @@ func BasisVector(dim int, index int) Matrix {
 	return e
 }
 
-// L2Norm returns the euclidean length of the vector.
+// L2Norm returns the euclidean length of the vector. Like LAPACK's
+// dnrm2 it keeps a running scale, the largest magnitude seen so far,
+// and sums squares relative to it, so that the result doesn't overflow
+// or underflow unless the length itself does.
 func L2Norm(v Matrix) float64 {
 	CheckVector(v)
 	_, outs := v.Shape()
-	sumOfSquares := 0.0
+	scale := 0.0
+	sumOfSquares := 1.0
 	for o := 0; o < outs; o++ {
 		f := v.Get(0, o)
-		sumOfSquares += f * f
+		if f == 0 {
+			continue
+		}
+		a := math.Abs(f)
+		if scale < a {
+			r := scale / a
+			sumOfSquares = 1 + sumOfSquares*r*r
+			scale = a
+		} else {
+			r := a / scale
+			sumOfSquares += r * r
+		}
 	}
-	return math.Sqrt(sumOfSquares)
+	return scale * math.Sqrt(sumOfSquares)
 }
 
 // NormalizeInto writes into dst a vector in the same direction as src
-// but with unit length, by dividing out the L2 norm.
+// but with unit length, by dividing out the L2 norm. It panics if src
+// is zero or has an infinite or NaN entry, since then there is no
+// direction.
 func NormalizeInto(src, dst Matrix) {
 	CheckVector(src)
 	CheckVector(dst)
 	CheckSameShape(src, dst)
 	mag := L2Norm(src)
+	if mag == 0 || math.IsInf(mag, 0) || math.IsNaN(mag) {
+		panic(fmt.Errorf("can't normalize a vector with length %f", mag))
+	}
 	_, dim := dst.Shape()
 	for d := 0; d < dim; d++ {
 		dst.Set(0, d, src.Get(0, d)/mag)
@@ -1,6 +1,8 @@
 package linear
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -22,6 +24,54 @@ func TestMatrix(t *testing.T) {
 	ExpectFloat(34, A.Get(1, 2), t)
 }
 
+func TestMatrixColMajor(t *testing.T) {
+	A := NewArrayMatrixColMajor(2, 3)
+
+	ins, outs := A.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(3, outs, t)
+
+	A.Set(1, 2, 34)
+	A.Set(0, 1, 12)
+
+	ExpectFloat(34, A.Get(1, 2), t)
+	ExpectFloat(12, A.Get(0, 1), t)
+	ExpectFloat(0, A.Get(1, 1), t)
+
+	// Entries of a column are contiguous.
+	ExpectFloat(12, A.(*arrayMatrix).array[1], t)
+	ExpectFloat(34, A.(*arrayMatrix).array[5], t)
+}
+
+func TestMatrixFromSlice(t *testing.T) {
+	data := []float64{
+		1, 2, -1,
+		3, 4, -1,
+		5, 6,
+	}
+
+	A := MatrixFromSlice(data, 2, 3, 3)
+
+	ins, outs := A.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(3, outs, t)
+	ExpectFloat(1, A.Get(0, 0), t)
+	ExpectFloat(2, A.Get(1, 0), t)
+	ExpectFloat(3, A.Get(0, 1), t)
+	ExpectFloat(4, A.Get(1, 1), t)
+	ExpectFloat(5, A.Get(0, 2), t)
+	ExpectFloat(6, A.Get(1, 2), t)
+
+	A.Set(1, 1, 7)
+
+	ExpectFloat(7, data[4], t)
+
+	B := Compose(Identity(2), A)
+
+	ExpectFloat(7, B.Get(1, 1), t)
+	ExpectFloat(6, B.Get(1, 2), t)
+}
+
 func TestSlice(t *testing.T) {
 	A := NewArrayMatrix(2, 3)
 	A.Set(0, 0, 1)
@@ -48,6 +98,27 @@ func TestSlice(t *testing.T) {
 	ExpectFloat(8, A.Get(1, 2), t)
 }
 
+func TestGather(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		1, 2,
+		3, 4,
+		5, 6,
+	}, 2, 3, 2)
+
+	G := Gather(A, []int{2, 0, 2, 1})
+
+	ExpectMatrix(MatrixFromSlice([]float64{
+		5, 6,
+		1, 2,
+		5, 6,
+		3, 4,
+	}, 2, 4, 2), G, t)
+
+	G.Set(0, 1, 9)
+
+	ExpectFloat(9, A.Get(0, 0), t)
+}
+
 func TestDual(t *testing.T) {
 	A := NewArrayMatrix(2, 3)
 	A.Set(0, 0, 1)
@@ -181,6 +252,28 @@ func TestComposeInto(t *testing.T) {
 	ExpectFloat(9, C.Get(2, 2), t)
 }
 
+func TestComposeIntoColMajor(t *testing.T) {
+	A := NewArrayMatrix(2, 3)
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 3)
+	A.Set(1, 1, 4)
+	A.Set(0, 2, 5)
+	A.Set(1, 2, 6)
+
+	B := NewArrayMatrixColMajor(3, 2)
+	CopyInto(Dual(A), B)
+
+	for _, dst := range []Matrix{NewArrayMatrix(2, 2), NewArrayMatrixColMajor(2, 2)} {
+		ComposeInto(A, B, dst)
+
+		ExpectFloat(35, dst.Get(0, 0), t)
+		ExpectFloat(44, dst.Get(1, 0), t)
+		ExpectFloat(44, dst.Get(0, 1), t)
+		ExpectFloat(56, dst.Get(1, 1), t)
+	}
+}
+
 func TestCompose(t *testing.T) {
 	A := NewArrayMatrix(2, 3)
 	A.Set(0, 0, 2)
@@ -294,6 +387,29 @@ func TestL2Norm(t *testing.T) {
 	ExpectFloat(5, h, t)
 }
 
+func TestL2NormExtremes(t *testing.T) {
+	for _, scale := range []float64{1e200, 1e300, 1e-200, 1e-300} {
+		v := NewArrayMatrix(1, 3)
+		v.Set(0, 0, 3*scale)
+		v.Set(0, 1, 0)
+		v.Set(0, 2, -4*scale)
+
+		h := L2Norm(v)
+
+		if math.Abs(h/scale-5) > 1e-12 {
+			t.Errorf("expected %g but got %g", 5*scale, h)
+		}
+	}
+
+	v := NewArrayMatrix(1, 2)
+	v.Set(0, 0, math.Inf(-1))
+	v.Set(0, 1, 1)
+
+	if !math.IsInf(L2Norm(v), 1) {
+		t.Errorf("expected +Inf but got %g", L2Norm(v))
+	}
+}
+
 func TestNormalizeInto(t *testing.T) {
 	v := NewArrayMatrix(1, 2)
 	v.Set(0, 0, 3)
@@ -306,6 +422,30 @@ func TestNormalizeInto(t *testing.T) {
 	ExpectFloat(4/5., u.Get(0, 1), t)
 }
 
+func TestNormalizeIntoExtremes(t *testing.T) {
+	for _, scale := range []float64{1e300, 1e-300} {
+		v := NewArrayMatrix(1, 2)
+		v.Set(0, 0, 3*scale)
+		v.Set(0, 1, 4*scale)
+
+		u := NewArrayMatrix(1, 2)
+		NormalizeInto(v, u)
+
+		ExpectFloat(3/5., u.Get(0, 0), t)
+		ExpectFloat(4/5., u.Get(0, 1), t)
+	}
+}
+
+func TestNormalizeIntoZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected normalizing a zero vector to panic")
+		}
+	}()
+	v := NewArrayMatrix(1, 2)
+	NormalizeInto(v, v)
+}
+
 func TestNormalize(t *testing.T) {
 	v := NewArrayMatrix(1, 2)
 	v.Set(0, 0, 3)
@@ -318,3 +458,21 @@ func TestNormalize(t *testing.T) {
 	ExpectFloat(3/5., v.Get(0, 0), t)
 	ExpectFloat(4/5., v.Get(0, 1), t)
 }
+
+func BenchmarkCompose(b *testing.B) {
+	dim := 128
+	A := NewArrayMatrix(dim, dim)
+	B := NewArrayMatrix(dim, dim)
+	for o := 0; o < dim; o++ {
+		for i := 0; i < dim; i++ {
+			A.Set(i, o, rand.Float64())
+			B.Set(i, o, rand.Float64())
+		}
+	}
+	dst := NewArrayMatrix(dim, dim)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ComposeInto(A, B, dst)
+	}
+}
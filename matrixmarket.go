@@ -0,0 +1,203 @@
+package linear
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// matrixMarketMaxDim caps the rows and columns a Matrix Market header
+// may claim, since a sparse matrix takes memory per row even when the
+// file has no entries.
+const matrixMarketMaxDim = 1 << 28
+
+// ReadMatrixMarket reads a matrix in the Matrix Market exchange
+// format. Coordinate files become a *CSR and array files an array
+// Matrix. Real, integer and pattern fields are supported, with general,
+// symmetric or skew-symmetric storage. The file's rows are outputs and
+// its columns are inputs.
+func ReadMatrixMarket(r io.Reader) (Matrix, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	line := 0
+	next := func() ([]string, error) {
+		for s.Scan() {
+			line++
+			text := strings.TrimSpace(s.Text())
+			if text == "" || strings.HasPrefix(text, "%") {
+				continue
+			}
+			return strings.Fields(text), nil
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	line++
+	banner := strings.Fields(strings.ToLower(s.Text()))
+	if len(banner) != 5 || banner[0] != "%%matrixmarket" || banner[1] != "matrix" {
+		return nil, fmt.Errorf("line 1: not a Matrix Market matrix header")
+	}
+	format, field, symmetry := banner[2], banner[3], banner[4]
+	if format != "coordinate" && format != "array" {
+		return nil, fmt.Errorf("line 1: unknown format %q", format)
+	}
+	if field != "real" && field != "integer" && (field != "pattern" || format != "coordinate") {
+		return nil, fmt.Errorf("line 1: unsupported field %q", field)
+	}
+	if symmetry != "general" && symmetry != "symmetric" && symmetry != "skew-symmetric" {
+		return nil, fmt.Errorf("line 1: unsupported symmetry %q", symmetry)
+	}
+
+	fields, err := next()
+	if err != nil {
+		return nil, err
+	}
+	size, err := parseInts(fields, line)
+	if err != nil {
+		return nil, err
+	}
+	if format == "coordinate" && len(size) != 3 || format == "array" && len(size) != 2 {
+		return nil, fmt.Errorf("line %d: bad size line", line)
+	}
+	rows, cols := size[0], size[1]
+	if rows < 0 || cols < 0 || symmetry != "general" && rows != cols {
+		return nil, fmt.Errorf("line %d: bad shape %d by %d for %s", line, rows, cols, symmetry)
+	}
+	if _, ok := entryCount(uint64(rows), uint64(cols)); !ok || rows > matrixMarketMaxDim || cols > matrixMarketMaxDim {
+		return nil, fmt.Errorf("line %d: shape %d by %d is too big", line, rows, cols)
+	}
+	sign := 1.0
+	if symmetry == "skew-symmetric" {
+		sign = -1
+	}
+
+	if format == "array" {
+		// Entries are column by column, only on and below the diagonal
+		// (or strictly below for skew-symmetric) unless general. They're
+		// collected as they're read, so the matrix isn't allocated
+		// until the file has shown it has that many.
+		first := func(c int) int {
+			switch symmetry {
+			case "symmetric":
+				return c
+			case "skew-symmetric":
+				return c + 1
+			}
+			return 0
+		}
+		var values []float64
+		for c := 0; c < cols; c++ {
+			for o := first(c); o < rows; o++ {
+				fields, err := next()
+				if err != nil {
+					return nil, err
+				}
+				if len(fields) != 1 {
+					return nil, fmt.Errorf("line %d: expected 1 value but got %d", line, len(fields))
+				}
+				v, err := strconv.ParseFloat(fields[0], 64)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %v", line, err)
+				}
+				values = append(values, v)
+			}
+		}
+		A := NewArrayMatrix(cols, rows)
+		k := 0
+		for c := 0; c < cols; c++ {
+			for o := first(c); o < rows; o++ {
+				A.Set(c, o, values[k])
+				if symmetry != "general" && o != c {
+					A.Set(o, c, sign*values[k])
+				}
+				k++
+			}
+		}
+		return A, nil
+	}
+
+	nonZeros := size[2]
+	if nonZeros < 0 {
+		return nil, fmt.Errorf("line %d: bad number of entries %d", line, nonZeros)
+	}
+	var entries []SparseEntry
+	for k := 0; k < nonZeros; k++ {
+		fields, err := next()
+		if err != nil {
+			return nil, err
+		}
+		want := 3
+		if field == "pattern" {
+			want = 2
+		}
+		if len(fields) != want {
+			return nil, fmt.Errorf("line %d: expected %d fields but got %d", line, want, len(fields))
+		}
+		at, err := parseInts(fields[:2], line)
+		if err != nil {
+			return nil, err
+		}
+		o, c := at[0]-1, at[1]-1
+		if o < 0 || o >= rows || c < 0 || c >= cols {
+			return nil, fmt.Errorf("line %d: (%d, %d) is out of bounds", line, at[0], at[1])
+		}
+		v := 1.0
+		if field != "pattern" {
+			if v, err = strconv.ParseFloat(fields[2], 64); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		}
+		entries = append(entries, SparseEntry{c, o, v})
+		if symmetry != "general" && o != c {
+			entries = append(entries, SparseEntry{o, c, sign * v})
+		}
+	}
+	return NewCSRFromEntries(cols, rows, entries), nil
+}
+
+func parseInts(fields []string, line int) ([]int, error) {
+	ints := make([]int, len(fields))
+	for k, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		ints[k] = n
+	}
+	return ints, nil
+}
+
+// WriteMatrixMarket writes A in the Matrix Market exchange format, as
+// a general real coordinate file if it's a *CSR and an array file
+// otherwise.
+func WriteMatrixMarket(w io.Writer, A Matrix) error {
+	bw := bufio.NewWriter(w)
+	ins, outs := A.Shape()
+	if m, ok := A.(*CSR); ok {
+		fmt.Fprintf(bw, "%%%%MatrixMarket matrix coordinate real general\n%d %d %d\n", outs, ins, m.NonZeros())
+		for o := 0; o < outs; o++ {
+			for j := m.rowStart[o]; j < m.rowStart[o+1]; j++ {
+				fmt.Fprintf(bw, "%d %d %s\n", o+1, m.cols[j]+1, strconv.FormatFloat(m.values[j], 'g', -1, 64))
+			}
+		}
+		return bw.Flush()
+	}
+	fmt.Fprintf(bw, "%%%%MatrixMarket matrix array real general\n%d %d\n", outs, ins)
+	for i := 0; i < ins; i++ {
+		for o := 0; o < outs; o++ {
+			fmt.Fprintf(bw, "%s\n", strconv.FormatFloat(A.Get(i, o), 'g', -1, 64))
+		}
+	}
+	return bw.Flush()
+}
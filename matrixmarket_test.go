@@ -0,0 +1,90 @@
+package linear
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadMatrixMarketCoordinate(t *testing.T) {
+	A, err := ReadMatrixMarket(strings.NewReader(`%%MatrixMarket matrix coordinate real symmetric
+% a comment
+3 3 3
+1 1 2.5
+3 1 -1
+
+2 2 4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := A.(*CSR); !ok {
+		t.Errorf("expected a *CSR but got %T", A)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{
+		2.5, 0, -1,
+		0, 4, 0,
+		-1, 0, 0,
+	}, 3, 3, 3), A, t)
+
+	P, err := ReadMatrixMarket(strings.NewReader("%%MatrixMarket matrix coordinate pattern general\n2 3 2\n1 3\n2 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{0, 0, 1, 1, 0, 0}, 3, 2, 3), P, t)
+}
+
+func TestReadMatrixMarketArray(t *testing.T) {
+	// Column by column.
+	A, err := ReadMatrixMarket(strings.NewReader("%%MatrixMarket matrix array real general\n2 3\n1\n4\n2\n5\n3\n6\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6}, 3, 2, 3), A, t)
+
+	S, err := ReadMatrixMarket(strings.NewReader("%%MatrixMarket matrix array real skew-symmetric\n3 3\n1\n2\n3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{
+		0, -1, -2,
+		1, 0, -3,
+		2, 3, 0,
+	}, 3, 3, 3), S, t)
+}
+
+func TestReadMatrixMarketErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"not a header\n1 1\n1\n",
+		"%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n",
+		"%%MatrixMarket matrix array real general\n2 2\n1\nx\n3\n4\n",
+		"%%MatrixMarket matrix array real symmetric\n2 3\n1\n",
+		// Shapes too big to allocate, with no entries to back them.
+		"%%MatrixMarket matrix array real general\n3000000000 3000000000\n1\n",
+		"%%MatrixMarket matrix coordinate real general\n4000000000000 1 0\n",
+		"%%MatrixMarket matrix array real general\n100000 100000\n1\n",
+	} {
+		if _, err := ReadMatrixMarket(strings.NewReader(text)); err == nil {
+			t.Errorf("expected an error reading %q", text)
+		}
+	}
+}
+
+func TestWriteMatrixMarket(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, 0.125}, 3, 2, 3)
+	sparse := NewCSRFromEntries(3, 2, []SparseEntry{{2, 0, 7}, {0, 1, -1}})
+	for _, M := range []Matrix{A, sparse} {
+		var b bytes.Buffer
+		if err := WriteMatrixMarket(&b, M); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadMatrixMarket(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ExpectMatrix(M, got, t)
+	}
+}
@@ -0,0 +1,153 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// SolveMixedPrecision finds x such that A*x = b for a square
+// nonsingular A. The LU factorization, which is the expensive part,
+// runs in float32 on an equilibrated copy of A, and then iterative
+// refinement with float64 residuals recovers float64 accuracy. It
+// stops once a correction changes x by less than tol relative to its
+// size, and returns the number of refinement steps taken. It panics if
+// that hasn't happened within maxIter steps, which means A is too
+// badly conditioned for float32.
+func SolveMixedPrecision(A Matrix, b Vector, tol float64, maxIter int) (x Vector, iterations int) {
+	ins, outs := A.Shape()
+	if ins != outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", ins, outs))
+	}
+	CheckVector(b)
+	CheckSameOuts(A, b)
+	n := ins
+
+	// Scale the rows and then the columns so their largest entries are
+	// 1, which keeps float32 from overflowing or losing small entries.
+	r := make([]float64, n)
+	c := make([]float64, n)
+	for o := 0; o < n; o++ {
+		max := 0.0
+		for i := 0; i < n; i++ {
+			max = math.Max(max, math.Abs(A.Get(i, o)))
+		}
+		CheckNotCloseToZero(max)
+		r[o] = 1 / max
+	}
+	for i := 0; i < n; i++ {
+		max := 0.0
+		for o := 0; o < n; o++ {
+			max = math.Max(max, math.Abs(r[o]*A.Get(i, o)))
+		}
+		CheckNotCloseToZero(max)
+		c[i] = 1 / max
+	}
+	scaled := func(i, o int) float64 { return r[o] * A.Get(i, o) * c[i] }
+
+	lu := make([]float32, n*n)
+	for o := 0; o < n; o++ {
+		for i := 0; i < n; i++ {
+			lu[o*n+i] = float32(scaled(i, o))
+		}
+	}
+	piv := decomposeLU32(lu, n)
+
+	// Solve the scaled system (R*A*C)*y = R*b, then x = C*y.
+	rb := make([]float64, n)
+	for o := 0; o < n; o++ {
+		rb[o] = r[o] * b.Get(0, o)
+	}
+	y := make([]float64, n)
+	residual := make([]float64, n)
+	copy(residual, rb)
+	for iterations = 0; iterations < maxIter; iterations++ {
+		d := solveLU32(lu, piv, residual)
+		dmax, ymax := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			y[i] += d[i]
+			dmax = math.Max(dmax, math.Abs(d[i]))
+			ymax = math.Max(ymax, math.Abs(y[i]))
+		}
+		for o := 0; o < n; o++ {
+			sum := rb[o]
+			for i := 0; i < n; i++ {
+				sum -= scaled(i, o) * y[i]
+			}
+			residual[o] = sum
+		}
+		if dmax <= tol*ymax {
+			x = NewVector(n)
+			for i := 0; i < n; i++ {
+				x.Set(0, i, c[i]*y[i])
+			}
+			return x, iterations + 1
+		}
+	}
+	panic(fmt.Errorf("iterative refinement didn't converge in %d steps", maxIter))
+}
+
+// decomposeLU32 factors the row-major n by n matrix a in place into
+// L*U with partial pivoting, returning the row permutation.
+func decomposeLU32(a []float32, n int) []int {
+	piv := make([]int, n)
+	for k := 0; k < n; k++ {
+		p := k
+		for o := k + 1; o < n; o++ {
+			if abs32(a[o*n+k]) > abs32(a[p*n+k]) {
+				p = o
+			}
+		}
+		piv[k] = p
+		if p != k {
+			for i := 0; i < n; i++ {
+				a[k*n+i], a[p*n+i] = a[p*n+i], a[k*n+i]
+			}
+		}
+		pivot := a[k*n+k]
+		CheckNotCloseToZero(float64(pivot))
+		for o := k + 1; o < n; o++ {
+			l := a[o*n+k] / pivot
+			a[o*n+k] = l
+			for i := k + 1; i < n; i++ {
+				a[o*n+i] -= l * a[k*n+i]
+			}
+		}
+	}
+	return piv
+}
+
+// solveLU32 solves with a factorization from decomposeLU32, rounding
+// the right hand side to float32.
+func solveLU32(lu []float32, piv []int, b []float64) []float64 {
+	n := len(piv)
+	x := make([]float32, n)
+	for i := range x {
+		x[i] = float32(b[i])
+	}
+	for k := 0; k < n; k++ {
+		x[k], x[piv[k]] = x[piv[k]], x[k]
+	}
+	for o := 0; o < n; o++ {
+		for i := 0; i < o; i++ {
+			x[o] -= lu[o*n+i] * x[i]
+		}
+	}
+	for o := n - 1; o >= 0; o-- {
+		for i := o + 1; i < n; i++ {
+			x[o] -= lu[o*n+i] * x[i]
+		}
+		x[o] /= lu[o*n+o]
+	}
+	y := make([]float64, n)
+	for i := range x {
+		y[i] = float64(x[i])
+	}
+	return y
+}
+
+func abs32(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,37 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSolveMixedPrecision(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	n := 20
+	A := NewArrayMatrix(n, n)
+	for o := 0; o < n; o++ {
+		for i := 0; i < n; i++ {
+			A.Set(i, o, rng.NormFloat64())
+		}
+		// Badly scaled rows are what equilibration is for.
+		A.Set(o, o, A.Get(o, o)+float64(n))
+		for i := 0; i < n; i++ {
+			A.Set(i, o, A.Get(i, o)*float64(int(1)<<uint(o)))
+		}
+	}
+
+	x := NewVector(n)
+	for d := 0; d < n; d++ {
+		x.Set(0, d, rng.NormFloat64())
+	}
+	b := Apply(A, x)
+
+	got, iterations := SolveMixedPrecision(A, b, 1e-15, 20)
+
+	if iterations < 2 {
+		t.Errorf("expected refinement to take more than one step")
+	}
+	for d := 0; d < n; d++ {
+		ExpectFloat(x.Get(0, d), got.Get(0, d), t)
+	}
+}
@@ -0,0 +1,126 @@
+//go:build unix
+
+package linear
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// MapMode says whether a memory-mapped matrix may be written to.
+type MapMode int
+
+const (
+	// MapReadOnly maps the file so that Set panics.
+	MapReadOnly MapMode = iota
+	// MapReadWrite maps the file so that Set writes through to it.
+	MapReadWrite
+)
+
+// MappedMatrix is a Matrix whose entries live in a memory-mapped file
+// as row-major float64s in native byte order, so the operating system
+// pages them in and out as needed rather than holding them all in RAM.
+type MappedMatrix struct {
+	*arrayMatrix
+	mode  MapMode
+	bytes []byte
+}
+
+// MapMatrix maps the file at path as a matrix of the given shape. The
+// file must be exactly big enough to hold the entries.
+func MapMatrix(path string, ins, outs int, mode MapMode) (*MappedMatrix, error) {
+	flag, prot := os.O_RDONLY, syscall.PROT_READ
+	if mode == MapReadWrite {
+		flag, prot = os.O_RDWR, syscall.PROT_READ|syscall.PROT_WRITE
+	}
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return mapFile(f, ins, outs, mode, prot)
+}
+
+// CreateMappedMatrix creates (or truncates) the file at path to hold a
+// zero matrix of the given shape and maps it for reading and writing.
+func CreateMappedMatrix(path string, ins, outs int) (*MappedMatrix, error) {
+	size, err := mappedSize(ins, outs)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := f.Truncate(int64(size)); err != nil {
+		return nil, err
+	}
+	return mapFile(f, ins, outs, MapReadWrite, syscall.PROT_READ|syscall.PROT_WRITE)
+}
+
+// mappedSize returns the number of bytes in a file of a matrix of the
+// given shape.
+func mappedSize(ins, outs int) (int, error) {
+	if ins < 0 || outs < 0 {
+		return 0, fmt.Errorf("invalid shape (%d, %d)", ins, outs)
+	}
+	size, ok := entryCount(uint64(ins), uint64(outs), 8)
+	if !ok {
+		return 0, fmt.Errorf("shape (%d, %d) is too big to map", ins, outs)
+	}
+	return size, nil
+}
+
+func mapFile(f *os.File, ins, outs int, mode MapMode, prot int) (*MappedMatrix, error) {
+	size, err := mappedSize(ins, outs)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() != int64(size) {
+		return nil, fmt.Errorf("%s has %d bytes but shape (%d, %d) needs %d", f.Name(), info.Size(), ins, outs, size)
+	}
+	m := &MappedMatrix{mode: mode}
+	var data []float64
+	if size > 0 {
+		m.bytes, err = syscall.Mmap(int(f.Fd()), 0, size, prot, syscall.MAP_SHARED)
+		if err != nil {
+			return nil, err
+		}
+		data = unsafe.Slice((*float64)(unsafe.Pointer(&m.bytes[0])), ins*outs)
+	}
+	m.arrayMatrix = MatrixFromSlice(data, ins, outs, ins).(*arrayMatrix)
+	return m, nil
+}
+
+// Set changes an entry, which is written through to the file. It
+// panics if the matrix was mapped read-only.
+func (m *MappedMatrix) Set(in, out int, value float64) {
+	if m.mode != MapReadWrite {
+		panic(fmt.Errorf("can't set (%d, %d) of a read-only mapped matrix", in, out))
+	}
+	m.arrayMatrix.Set(in, out, value)
+}
+
+// Close unmaps the file. The matrix must not be used afterwards.
+func (m *MappedMatrix) Close() error {
+	m.arrayMatrix = nil
+	if m.bytes == nil {
+		return nil
+	}
+	err := syscall.Munmap(m.bytes)
+	m.bytes = nil
+	return err
+}
+
+func (m *MappedMatrix) backingArray() (*arrayMatrix, bool) { return m.arrayMatrix, true }
+
+// readOnlyArray keeps kernels from writing to a read-only map through
+// the array, where Set would have panicked.
+func (m *MappedMatrix) readOnlyArray() bool { return m.mode != MapReadWrite }
@@ -0,0 +1,90 @@
+//go:build unix
+
+package linear
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedMatrix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "A.f64")
+
+	A, err := CreateMappedMatrix(path, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 3)
+	A.Set(1, 1, 4)
+	A.Set(0, 2, 5)
+	A.Set(1, 2, 6)
+	if err := A.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	B, err := MapMatrix(path, 2, 3, MapReadOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer B.Close()
+
+	ins, outs := B.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(3, outs, t)
+	ExpectFloat(4, B.Get(1, 1), t)
+	ExpectFloat(5, B.Get(0, 2), t)
+
+	C := Compose(B, Dual(B))
+
+	ExpectFloat(35, C.Get(0, 0), t)
+	ExpectFloat(44, C.Get(1, 0), t)
+	ExpectFloat(56, C.Get(1, 1), t)
+
+	// The array kernels can read a read-only map but not write to one.
+	if _, ok := asArrayMatrix(B); !ok {
+		t.Errorf("expected the read-only map to be array-backed")
+	}
+	expectPanic(t, func() { ComposeInto(Identity(2), Copy(B), B) })
+	ExpectFloat(1, B.Get(0, 0), t)
+	// Columns of its dual are contiguous, but on read-only pages.
+	columns := MatrixColumns(Dual(B))
+	columns[0][0] = 7
+	ExpectFloat(1, B.Get(0, 0), t)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected Set on read-only matrix to panic")
+		}
+	}()
+	B.Set(0, 0, 7)
+}
+
+func TestMapMatrixWrongSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "A.f64")
+
+	A, err := CreateMappedMatrix(path, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	A.Close()
+
+	if _, err := MapMatrix(path, 2, 3, MapReadOnly); err == nil {
+		t.Errorf("expected an error mapping 4 entries as shape (2, 3)")
+	}
+
+	// 2^61 entries of 8 bytes wrap around to an empty file.
+	empty := filepath.Join(t.TempDir(), "empty.f64")
+	E, err := CreateMappedMatrix(empty, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	E.Close()
+	if _, err := MapMatrix(empty, 1<<61, 1, MapReadOnly); err == nil {
+		t.Errorf("expected an error mapping an empty file as shape (2^61, 1)")
+	}
+	if _, err := CreateMappedMatrix(empty, 1<<61, 1); err == nil {
+		t.Errorf("expected an error creating shape (2^61, 1)")
+	}
+}
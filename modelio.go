@@ -0,0 +1,341 @@
+package linear
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// modelFormatVersion is the version of the saved model formats below.
+// Loading rejects versions it doesn't know rather than guessing.
+const modelFormatVersion = 1
+
+// savedModel is the form every model is saved in: a kind, a version,
+// and named matrices and numbers.
+type savedModel struct {
+	Kind     string                `json:"kind"`
+	Version  int                   `json:"version"`
+	Matrices map[string]JSONMatrix `json:"matrices"`
+	Numbers  map[string]float64    `json:"numbers,omitempty"`
+}
+
+func (s *savedModel) check(kind string) error {
+	if s.Kind != kind {
+		return fmt.Errorf("expected a saved %s but got %q", kind, s.Kind)
+	}
+	if s.Version != modelFormatVersion {
+		return fmt.Errorf("unsupported %s version %d", kind, s.Version)
+	}
+	return nil
+}
+
+func (s *savedModel) matrix(name string) (Matrix, error) {
+	m, ok := s.Matrices[name]
+	if !ok || m.Matrix == nil {
+		return nil, fmt.Errorf("saved %s is missing %s", s.Kind, name)
+	}
+	return m.Matrix, nil
+}
+
+func (s *savedModel) number(name string) (float64, error) {
+	f, ok := s.Numbers[name]
+	if !ok {
+		return 0, fmt.Errorf("saved %s is missing %s", s.Kind, name)
+	}
+	return f, nil
+}
+
+// The binary form is a magic string and version, the kind, then the
+// counts of matrices and numbers followed by each name and value, all
+// little endian. Names are written in sorted order so that saving the
+// same model twice gives the same bytes.
+var modelMagic = []byte("LINM")
+
+func (s *savedModel) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	b.Write(modelMagic)
+	binary.Write(&b, binary.LittleEndian, uint32(s.Version))
+	writeString(&b, s.Kind)
+	binary.Write(&b, binary.LittleEndian, uint32(len(s.Matrices)))
+	for _, name := range sortedMatrixNames(s.Matrices) {
+		writeString(&b, name)
+		if err := WriteMatrix(&b, s.Matrices[name].Matrix); err != nil {
+			return nil, err
+		}
+	}
+	binary.Write(&b, binary.LittleEndian, uint32(len(s.Numbers)))
+	for _, name := range sortedNumberNames(s.Numbers) {
+		writeString(&b, name)
+		binary.Write(&b, binary.LittleEndian, math.Float64bits(s.Numbers[name]))
+	}
+	return b.Bytes(), nil
+}
+
+func (s *savedModel) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	magic := make([]byte, len(modelMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, modelMagic) {
+		return fmt.Errorf("not a saved model")
+	}
+	var version, n uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return err
+	}
+	s.Version = int(version)
+	if s.Version != modelFormatVersion {
+		return fmt.Errorf("unsupported model version %d", s.Version)
+	}
+	var err error
+	if s.Kind, err = readString(r); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return err
+	}
+	s.Matrices = map[string]JSONMatrix{}
+	for k := uint32(0); k < n; k++ {
+		name, err := readString(r)
+		if err != nil {
+			return err
+		}
+		m, err := ReadMatrix(r)
+		if err != nil {
+			return err
+		}
+		s.Matrices[name] = JSONMatrix{m}
+	}
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return err
+	}
+	s.Numbers = map[string]float64{}
+	for k := uint32(0); k < n; k++ {
+		name, err := readString(r)
+		if err != nil {
+			return err
+		}
+		var bits uint64
+		if err := binary.Read(r, binary.LittleEndian, &bits); err != nil {
+			return err
+		}
+		s.Numbers[name] = math.Float64frombits(bits)
+	}
+	return nil
+}
+
+func sortedMatrixNames(m map[string]JSONMatrix) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedNumberNames(m map[string]float64) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeString(b *bytes.Buffer, s string) {
+	binary.Write(b, binary.LittleEndian, uint32(len(s)))
+	b.WriteString(s)
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return "", err
+	}
+	if int64(n) > int64(r.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// LinearModel is saved with its data, so that the diagnostics still
+// work after loading; the QR factors are recomputed from X.
+
+func (m *LinearModel) saved() *savedModel {
+	return &savedModel{
+		Kind:    "LinearModel",
+		Version: modelFormatVersion,
+		Matrices: map[string]JSONMatrix{
+			"X": {m.X}, "Y": {m.Y}, "Theta": {m.Theta},
+		},
+		Numbers: map[string]float64{
+			"RSS": m.RSS, "Rank": float64(m.Rank), "DF": float64(m.DF),
+		},
+	}
+}
+
+func (m *LinearModel) load(s *savedModel) error {
+	if err := s.check("LinearModel"); err != nil {
+		return err
+	}
+	var err error
+	var l LinearModel
+	if l.X, err = s.matrix("X"); err != nil {
+		return err
+	}
+	if l.Y, err = s.matrix("Y"); err != nil {
+		return err
+	}
+	if l.Theta, err = s.matrix("Theta"); err != nil {
+		return err
+	}
+	ins, outs := l.X.Shape()
+	if yIns, yOuts := l.Y.Shape(); yIns != 1 || yOuts != outs {
+		return fmt.Errorf("saved Y has shape (%d, %d) for %d observations", yIns, yOuts, outs)
+	}
+	if tIns, tOuts := l.Theta.Shape(); tIns != 1 || tOuts != ins {
+		return fmt.Errorf("saved Theta has shape (%d, %d) for %d features", tIns, tOuts, ins)
+	}
+	var rss, rank, df float64
+	if rss, err = s.number("RSS"); err != nil {
+		return err
+	}
+	if rank, err = s.number("Rank"); err != nil {
+		return err
+	}
+	if df, err = s.number("DF"); err != nil {
+		return err
+	}
+	// FitLinearModel only makes models of full column rank.
+	if rank != float64(ins) || df != float64(outs-ins) {
+		return fmt.Errorf("saved Rank %v and DF %v don't fit %d features and %d observations", rank, df, ins, outs)
+	}
+	l.RSS, l.Rank, l.DF = rss, int(rank), int(df)
+	l.qr = FactorQR(l.X)
+	l.Residuals = Apply(l.X, l.Theta)
+	addScaledInto(l.Y, l.Residuals, -1, l.Residuals)
+	*m = l
+	return nil
+}
+
+func (m *LinearModel) MarshalJSON() ([]byte, error) { return json.Marshal(m.saved()) }
+
+func (m *LinearModel) UnmarshalJSON(data []byte) error {
+	var s savedModel
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return m.load(&s)
+}
+
+func (m *LinearModel) MarshalBinary() ([]byte, error) { return m.saved().MarshalBinary() }
+
+func (m *LinearModel) UnmarshalBinary(data []byte) error {
+	var s savedModel
+	if err := s.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	return m.load(&s)
+}
+
+func (m *SoftmaxModel) saved() *savedModel {
+	return &savedModel{
+		Kind:     "SoftmaxModel",
+		Version:  modelFormatVersion,
+		Matrices: map[string]JSONMatrix{"Weights": {m.Weights}, "Bias": {m.Bias}},
+	}
+}
+
+func (m *SoftmaxModel) load(s *savedModel) error {
+	if err := s.check("SoftmaxModel"); err != nil {
+		return err
+	}
+	W, err := s.matrix("Weights")
+	if err != nil {
+		return err
+	}
+	b, err := s.matrix("Bias")
+	if err != nil {
+		return err
+	}
+	classes, _ := W.Shape()
+	if bIns, bOuts := b.Shape(); bIns != classes || bOuts != 1 {
+		return fmt.Errorf("saved Bias has shape (%d, %d) for %d classes", bIns, bOuts, classes)
+	}
+	m.Weights, m.Bias = W, b
+	return nil
+}
+
+func (m *SoftmaxModel) MarshalJSON() ([]byte, error) { return json.Marshal(m.saved()) }
+
+func (m *SoftmaxModel) UnmarshalJSON(data []byte) error {
+	var s savedModel
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return m.load(&s)
+}
+
+func (m *SoftmaxModel) MarshalBinary() ([]byte, error) { return m.saved().MarshalBinary() }
+
+func (m *SoftmaxModel) UnmarshalBinary(data []byte) error {
+	var s savedModel
+	if err := s.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	return m.load(&s)
+}
+
+func (s *SVM) saved() *savedModel {
+	return &savedModel{
+		Kind:     "SVM",
+		Version:  modelFormatVersion,
+		Matrices: map[string]JSONMatrix{"Weights": {s.Weights}},
+		Numbers:  map[string]float64{"Bias": s.Bias},
+	}
+}
+
+func (s *SVM) load(saved *savedModel) error {
+	if err := saved.check("SVM"); err != nil {
+		return err
+	}
+	w, err := saved.matrix("Weights")
+	if err != nil {
+		return err
+	}
+	if ins, _ := w.Shape(); ins != 1 {
+		return fmt.Errorf("saved Weights isn't a vector")
+	}
+	bias, err := saved.number("Bias")
+	if err != nil {
+		return err
+	}
+	s.Weights, s.Bias = w, bias
+	return nil
+}
+
+func (s *SVM) MarshalJSON() ([]byte, error) { return json.Marshal(s.saved()) }
+
+func (s *SVM) UnmarshalJSON(data []byte) error {
+	var saved savedModel
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	return s.load(&saved)
+}
+
+func (s *SVM) MarshalBinary() ([]byte, error) { return s.saved().MarshalBinary() }
+
+func (s *SVM) UnmarshalBinary(data []byte) error {
+	var saved savedModel
+	if err := saved.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	return s.load(&saved)
+}
@@ -0,0 +1,113 @@
+package linear
+
+import (
+	"encoding"
+	"encoding/json"
+	"testing"
+)
+
+func TestSaveLoadLinearModel(t *testing.T) {
+	X := MatrixFromSlice([]float64{
+		1, 0,
+		1, 1,
+		1, 2,
+		1, 3,
+	}, 2, 4, 2)
+	y := MatrixFromSlice([]float64{1, 2, 2, 4}, 1, 4, 1)
+	m := FitLinearModel(X, y)
+
+	check := func(loaded *LinearModel) {
+		ExpectMatrix(m.Theta, loaded.Theta, t)
+		ExpectFloat(m.RSS, loaded.RSS, t)
+		ExpectInt(m.DF, loaded.DF, t)
+		ExpectMatrix(m.Leverage(), loaded.Leverage(), t)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON LinearModel
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	check(&fromJSON)
+
+	data, err = m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromBinary LinearModel
+	if err := fromBinary.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	check(&fromBinary)
+}
+
+func TestLoadLinearModelBadRank(t *testing.T) {
+	// FitLinearModel on one feature has rank 1 and three observations
+	// leave 2 degrees of freedom.
+	bad := &LinearModel{
+		X:     MatrixFromSlice([]float64{1, 2, 3}, 1, 3, 1),
+		Y:     MatrixFromSlice([]float64{1, 2, 3}, 1, 3, 1),
+		Theta: NewVector(1),
+		Rank:  3,
+		DF:    -2,
+	}
+	data, err := json.Marshal(bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded LinearModel
+	if err := json.Unmarshal(data, &loaded); err == nil {
+		t.Errorf("expected an error for rank 3 with 1 feature")
+	}
+}
+
+func TestSaveLoadClassifiers(t *testing.T) {
+	softmax := &SoftmaxModel{
+		Weights: MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6}, 3, 2, 3),
+		Bias:    MatrixFromSlice([]float64{0.1, 0.2, 0.3}, 3, 1, 3),
+	}
+	svm := &SVM{Weights: MatrixFromSlice([]float64{1, -1}, 1, 2, 1), Bias: 0.5}
+	loadedSoftmax, loadedSVM := &SoftmaxModel{}, &SVM{}
+
+	for _, test := range []struct {
+		saved, loaded interface {
+			encoding.BinaryMarshaler
+			encoding.BinaryUnmarshaler
+		}
+	}{
+		{softmax, loadedSoftmax},
+		{svm, loadedSVM},
+	} {
+		data, err := test.saved.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := test.loaded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ExpectMatrix(softmax.Weights, loadedSoftmax.Weights, t)
+	ExpectMatrix(softmax.Bias, loadedSoftmax.Bias, t)
+	ExpectMatrix(svm.Weights, loadedSVM.Weights, t)
+	ExpectFloat(0.5, loadedSVM.Bias, t)
+
+	data, _ := json.Marshal(softmax)
+	var s SoftmaxModel
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(softmax.Weights, s.Weights, t)
+	ExpectMatrix(softmax.Bias, s.Bias, t)
+
+	// A model of the wrong kind or an unknown version is rejected.
+	if err := json.Unmarshal(data, &SVM{}); err == nil {
+		t.Errorf("expected an error loading a SoftmaxModel as an SVM")
+	}
+	if err := json.Unmarshal([]byte(`{"kind":"SVM","version":99}`), &SVM{}); err == nil {
+		t.Errorf("expected an error for an unknown version")
+	}
+}
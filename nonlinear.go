@@ -0,0 +1,108 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// NumericalJacobian forms the Jacobian of f at x by forward
+// differences, one column (input) per evaluation, as JacobianOperator
+// does for a single direction.
+func NumericalJacobian(f func(Vector) Vector, x Vector, eps float64) Matrix {
+	J := JacobianOperator(f, x, eps)
+	ins, outs := J.Shape()
+	M := NewArrayMatrixColMajor(ins, outs)
+	for i := 0; i < ins; i++ {
+		CopyInto(J.ApplyVec(BasisVector(ins, i)), Slice(M, i, i+1, 0, outs))
+	}
+	return M
+}
+
+// GaussNewton finds the theta that minimizes the sum of the squares of
+// residual(theta), starting from theta0, by repeatedly linearizing the
+// residual and solving that least squares problem with QR. jacobian
+// returns the Jacobian of the residual (with a column for each
+// parameter); if it's nil then NumericalJacobian is used. It stops when
+// a step changes theta by less than tol relative to its length, or
+// after maxIter steps, returning how many it took.
+func GaussNewton(residual func(theta Vector) Vector, jacobian func(theta Vector) Matrix, theta0 Vector, tol float64, maxIter int) (theta Vector, iterations int) {
+	jacobian = jacobianOrNumerical(residual, jacobian)
+	theta = Copy(theta0)
+	for iterations = 0; iterations < maxIter; {
+		r := residual(theta)
+		step := FactorQR(jacobian(theta)).SolveVec(negated(r))
+		addScaledInto(theta, step, 1, theta)
+		iterations++
+		if L2Norm(step) <= tol*(L2Norm(theta)+tol) {
+			break
+		}
+	}
+	return theta, iterations
+}
+
+// LevenbergMarquardt is GaussNewton with each step damped by lambda,
+// solving the least squares problem for [J; math.Sqrt(lambda)*I] with QR.
+// lambda shrinks after a step that reduces the sum of squares and grows
+// (rejecting the step) after one that doesn't, so it behaves like
+// gradient descent far from the minimum and like Gauss-Newton close to
+// it.
+func LevenbergMarquardt(residual func(theta Vector) Vector, jacobian func(theta Vector) Matrix, theta0 Vector, tol float64, maxIter int) (theta Vector, iterations int) {
+	jacobian = jacobianOrNumerical(residual, jacobian)
+	theta = Copy(theta0)
+	_, n := theta.Shape()
+	r := residual(theta)
+	cost := DotProduct(r, Dual(r))
+	lambda := 1e-3
+	for iterations = 0; iterations < maxIter; iterations++ {
+		J := jacobian(theta)
+		_, m := J.Shape()
+		for {
+			augmented := NewArrayMatrixColMajor(n, m+n)
+			CopyInto(J, Slice(augmented, 0, n, 0, m))
+			rhs := NewVector(m + n)
+			CopyInto(negated(r), Slice(rhs, 0, 1, 0, m))
+			for d := 0; d < n; d++ {
+				augmented.Set(d, m+d, math.Sqrt(lambda))
+			}
+			step := FactorQR(augmented).SolveVec(rhs)
+			trial := NewVector(n)
+			addScaledInto(theta, step, 1, trial)
+			rt := residual(trial)
+			if trialCost := DotProduct(rt, Dual(rt)); trialCost < cost {
+				theta, r, cost = trial, rt, trialCost
+				lambda /= 10
+				if L2Norm(step) <= tol*(L2Norm(theta)+tol) {
+					return theta, iterations + 1
+				}
+				break
+			}
+			lambda *= 10
+			if lambda > 1e16 {
+				// No step makes progress, so this is a minimum as far
+				// as working precision can tell.
+				return theta, iterations + 1
+			}
+		}
+	}
+	return theta, iterations
+}
+
+func jacobianOrNumerical(residual func(Vector) Vector, jacobian func(Vector) Matrix) func(Vector) Matrix {
+	if jacobian != nil {
+		return func(theta Vector) Matrix {
+			J := jacobian(theta)
+			ins, _ := J.Shape()
+			if _, dim := theta.Shape(); ins != dim {
+				panic(fmt.Errorf("jacobian has %d ins for %d parameters", ins, dim))
+			}
+			return J
+		}
+	}
+	return func(theta Vector) Matrix {
+		return NumericalJacobian(residual, theta, 0)
+	}
+}
+
+func negated(v Matrix) Matrix {
+	return mapEntries(v, func(f float64) float64 { return -f })
+}
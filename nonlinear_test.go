@@ -0,0 +1,82 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+// expDecay returns the residuals of fitting a*exp(-b*t) to points on
+// exactly that curve with a=3, b=0.5.
+func expDecay() (residual func(Vector) Vector, jacobian func(Vector) Matrix) {
+	ts := []float64{0, 0.5, 1, 2, 3, 4, 6}
+	residual = func(theta Vector) Vector {
+		a, b := theta.Get(0, 0), theta.Get(0, 1)
+		r := NewVector(len(ts))
+		for o, t := range ts {
+			r.Set(0, o, a*math.Exp(-b*t)-3*math.Exp(-0.5*t))
+		}
+		return r
+	}
+	jacobian = func(theta Vector) Matrix {
+		a, b := theta.Get(0, 0), theta.Get(0, 1)
+		J := NewArrayMatrix(2, len(ts))
+		for o, t := range ts {
+			J.Set(0, o, math.Exp(-b*t))
+			J.Set(1, o, -a*t*math.Exp(-b*t))
+		}
+		return J
+	}
+	return residual, jacobian
+}
+
+func TestGaussNewton(t *testing.T) {
+	residual, jacobian := expDecay()
+	theta0 := NewVector(2)
+	theta0.Set(0, 0, 2)
+	theta0.Set(0, 1, 0.3)
+
+	theta, _ := GaussNewton(residual, jacobian, theta0, 1e-12, 50)
+
+	ExpectFloat(3, theta.Get(0, 0), t)
+	ExpectFloat(0.5, theta.Get(0, 1), t)
+
+	// And with a numerical Jacobian.
+	theta, _ = GaussNewton(residual, nil, theta0, 1e-12, 50)
+
+	ExpectFloat(3, theta.Get(0, 0), t)
+	ExpectFloat(0.5, theta.Get(0, 1), t)
+}
+
+func TestLevenbergMarquardt(t *testing.T) {
+	residual, jacobian := expDecay()
+	// Far enough away that undamped steps overshoot.
+	theta0 := NewVector(2)
+	theta0.Set(0, 0, 10)
+	theta0.Set(0, 1, 3)
+
+	theta, iterations := LevenbergMarquardt(residual, jacobian, theta0, 1e-12, 200)
+
+	if iterations >= 200 {
+		t.Errorf("didn't converge")
+	}
+	ExpectFloat(3, theta.Get(0, 0), t)
+	ExpectFloat(0.5, theta.Get(0, 1), t)
+}
+
+func TestNumericalJacobian(t *testing.T) {
+	residual, jacobian := expDecay()
+	theta := NewVector(2)
+	theta.Set(0, 0, 2)
+	theta.Set(0, 1, 0.7)
+
+	J := jacobian(theta)
+	N := NumericalJacobian(residual, theta, 0)
+
+	for o := 0; o < 7; o++ {
+		for i := 0; i < 2; i++ {
+			if math.Abs(J.Get(i, o)-N.Get(i, o)) > 1e-6 {
+				t.Errorf("(%d, %d) expected %v but got %v", i, o, J.Get(i, o), N.Get(i, o))
+			}
+		}
+	}
+}
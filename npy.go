@@ -0,0 +1,198 @@
+package linear
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	npyMagic = []byte("\x93NUMPY")
+
+	npyDescr   = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	npyFortran = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShape   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// ReadNPY reads a NumPy .npy array of floats or integers, of rank 1
+// (as a vector) or 2 (with a NumPy row as each output), in either byte
+// order and in C or Fortran order.
+func ReadNPY(r io.Reader) (Matrix, error) {
+	var pre [8]byte
+	if _, err := io.ReadFull(r, pre[:]); err != nil {
+		return nil, err
+	}
+	if string(pre[:6]) != string(npyMagic) {
+		return nil, fmt.Errorf("not an npy file")
+	}
+	var headerLen int
+	switch pre[6] {
+	case 1:
+		var n [2]byte
+		if _, err := io.ReadFull(r, n[:]); err != nil {
+			return nil, err
+		}
+		headerLen = int(binary.LittleEndian.Uint16(n[:]))
+	case 2, 3:
+		var n [4]byte
+		if _, err := io.ReadFull(r, n[:]); err != nil {
+			return nil, err
+		}
+		headerLen = int(binary.LittleEndian.Uint32(n[:]))
+	default:
+		return nil, fmt.Errorf("unsupported npy version %d.%d", pre[6], pre[7])
+	}
+	if headerLen > 1<<20 {
+		return nil, fmt.Errorf("npy header of %d bytes is too long", headerLen)
+	}
+	h := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, h); err != nil {
+		return nil, err
+	}
+	header := string(h)
+
+	descr := npyDescr.FindStringSubmatch(header)
+	fortran := npyFortran.FindStringSubmatch(header)
+	shape := npyShape.FindStringSubmatch(header)
+	if descr == nil || fortran == nil || shape == nil {
+		return nil, fmt.Errorf("bad npy header %q", header)
+	}
+	var dims []int
+	for _, d := range strings.Split(shape[1], ",") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("bad npy shape (%s)", shape[1])
+		}
+		dims = append(dims, n)
+	}
+	var ins, outs int
+	switch len(dims) {
+	case 1:
+		ins, outs = 1, dims[0]
+	case 2:
+		ins, outs = dims[1], dims[0]
+	default:
+		return nil, fmt.Errorf("can't read a rank %d npy array as a matrix", len(dims))
+	}
+
+	if _, ok := entryCount(uint64(ins), uint64(outs)); !ok {
+		return nil, fmt.Errorf("npy shape (%s) is too big", shape[1])
+	}
+
+	dtype := descr[1]
+	var order binary.ByteOrder = binary.LittleEndian
+	if dtype != "" {
+		switch dtype[0] {
+		case '>':
+			order = binary.BigEndian
+			dtype = dtype[1:]
+		case '<', '=', '|':
+			dtype = dtype[1:]
+		}
+	}
+	if len(dtype) < 2 {
+		return nil, fmt.Errorf("unsupported npy dtype %q", descr[1])
+	}
+	size, err := strconv.Atoi(dtype[1:])
+	if err != nil || size != 1 && size != 2 && size != 4 && size != 8 {
+		return nil, fmt.Errorf("unsupported npy dtype %q", descr[1])
+	}
+	var decode func([]byte) float64
+	switch {
+	case dtype[0] == 'f' && size == 8:
+		decode = func(b []byte) float64 { return math.Float64frombits(order.Uint64(b)) }
+	case dtype[0] == 'f' && size == 4:
+		decode = func(b []byte) float64 { return float64(math.Float32frombits(order.Uint32(b))) }
+	case dtype[0] == 'i' || dtype[0] == 'u':
+		signed := dtype[0] == 'i'
+		decode = func(b []byte) float64 { return npyInt(b, order, signed) }
+	default:
+		return nil, fmt.Errorf("unsupported npy dtype %q", descr[1])
+	}
+
+	// Read in chunks so a corrupt shape can't allocate more than the
+	// data that's actually there.
+	data := make([]float64, 0)
+	buf := make([]byte, size*1024)
+	for remaining := ins * outs; remaining > 0; {
+		n := remaining
+		if n > 1024 {
+			n = 1024
+		}
+		if _, err := io.ReadFull(r, buf[:size*n]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		for k := 0; k < n; k++ {
+			data = append(data, decode(buf[size*k:size*(k+1)]))
+		}
+		remaining -= n
+	}
+	if fortran[1] == "True" {
+		return &arrayMatrix{array: data, ins: ins, outs: outs, inStride: outs, outStride: 1}, nil
+	}
+	return MatrixFromSlice(data, ins, outs, ins), nil
+}
+
+func npyInt(b []byte, order binary.ByteOrder, signed bool) float64 {
+	switch len(b) {
+	case 1:
+		if signed {
+			return float64(int8(b[0]))
+		}
+		return float64(b[0])
+	case 2:
+		if signed {
+			return float64(int16(order.Uint16(b)))
+		}
+		return float64(order.Uint16(b))
+	case 4:
+		if signed {
+			return float64(int32(order.Uint32(b)))
+		}
+		return float64(order.Uint32(b))
+	}
+	if signed {
+		return float64(int64(order.Uint64(b)))
+	}
+	return float64(order.Uint64(b))
+}
+
+// WriteNPY writes A as a NumPy .npy array of little endian float64s,
+// with shape (outs, ins), or (outs,) if A is a vector.
+func WriteNPY(w io.Writer, A Matrix) error {
+	ins, outs := A.Shape()
+	shape := fmt.Sprintf("(%d, %d)", outs, ins)
+	if ins == 1 {
+		shape = fmt.Sprintf("(%d,)", outs)
+	}
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': %s, }", shape)
+	// The magic, version, length, header and newline are padded to a
+	// multiple of 64 bytes so the data is aligned.
+	pad := 63 - (len(npyMagic)+4+len(header))%64
+	header += strings.Repeat(" ", pad) + "\n"
+	if len(header) > math.MaxUint16 {
+		return fmt.Errorf("npy header is too long")
+	}
+	buf := make([]byte, 0, len(npyMagic)+4+len(header)+8*ins*outs)
+	buf = append(buf, npyMagic...)
+	buf = append(buf, 1, 0)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(header)))
+	buf = append(buf, header...)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(A.Get(i, o)))
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
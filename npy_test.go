@@ -0,0 +1,81 @@
+package linear
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func npyFile(header string, data []byte) []byte {
+	b := append([]byte("\x93NUMPY\x01\x00"), byte(len(header)), byte(len(header)>>8))
+	return append(append(b, header...), data...)
+}
+
+func TestWriteReadNPY(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, math.Inf(-1)}, 3, 2, 3)
+	var b bytes.Buffer
+	if err := WriteNPY(&b, A); err != nil {
+		t.Fatal(err)
+	}
+	// The data starts 64 byte aligned.
+	ExpectInt(0, (b.Len()-6*8)%64, t)
+	got, err := ReadNPY(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(A, got, t)
+
+	v := MatrixFromSlice([]float64{1, 2}, 1, 2, 1)
+	b.Reset()
+	if err := WriteNPY(&b, v); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte("'shape': (2,)")) {
+		t.Errorf("expected a rank 1 shape in %q", b.String())
+	}
+	got, err = ReadNPY(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(v, got, t)
+}
+
+func TestReadNPYTypes(t *testing.T) {
+	// Fortran order int32s, column by column.
+	var data []byte
+	for _, x := range []int32{1, 4, 2, 5, 3, -6} {
+		data = binary.LittleEndian.AppendUint32(data, uint32(x))
+	}
+	A, err := ReadNPY(bytes.NewReader(npyFile("{'descr': '<i4', 'fortran_order': True, 'shape': (2, 3), }\n", data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{1, 2, 3, 4, 5, -6}, 3, 2, 3), A, t)
+
+	// Big endian float32s.
+	data = nil
+	for _, x := range []float32{0.5, -2} {
+		data = binary.BigEndian.AppendUint32(data, math.Float32bits(x))
+	}
+	A, err = ReadNPY(bytes.NewReader(npyFile("{'descr': '>f4', 'fortran_order': False, 'shape': (2,), }\n", data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{0.5, -2}, 1, 2, 1), A, t)
+
+	for _, header := range []string{
+		"{'descr': '<c16', 'fortran_order': False, 'shape': (1,), }\n",
+		"{'descr': '<f8', 'fortran_order': False, 'shape': (1, 1, 1), }\n",
+		"{'descr': '<f8', 'shape': (1,), }\n",
+		"{'descr': '<f8', 'fortran_order': False, 'shape': (4,), }\n",
+		"{'descr': '', 'fortran_order': False, 'shape': (1,), }\n",
+		"{'descr': '<', 'fortran_order': False, 'shape': (1,), }\n",
+		"{'descr': '<f', 'fortran_order': False, 'shape': (1,), }\n",
+		"{'descr': '<f8', 'fortran_order': False, 'shape': (4294967296, 4294967296), }\n",
+	} {
+		if _, err := ReadNPY(bytes.NewReader(npyFile(header, make([]byte, 16)))); err == nil {
+			t.Errorf("expected an error for %q", header)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package linear
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ONNXTensor is a float tensor read from an ONNX model, with its
+// entries in row-major order (the last dimension varies fastest).
+type ONNXTensor struct {
+	Name string
+	Dims []int
+	Data []float64
+}
+
+// ONNX TensorProto data types.
+const (
+	onnxFloat  = 1
+	onnxDouble = 11
+)
+
+// ReadONNXInitializers reads the float and double initializers (the
+// stored weights) of the graph of an ONNX model. Tensors of other types
+// and everything else about the model are skipped.
+func ReadONNXInitializers(r io.Reader) ([]ONNXTensor, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var tensors []ONNXTensor
+	// ModelProto.graph is field 7 and GraphProto.initializer field 5.
+	model := &wireDecoder{data}
+	for !model.done() {
+		num, typ, err := model.field()
+		if err != nil {
+			return nil, err
+		}
+		if num != 7 || typ != wireBytes {
+			if err := model.skip(typ); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		b, err := model.bytes()
+		if err != nil {
+			return nil, err
+		}
+		graph := &wireDecoder{b}
+		for !graph.done() {
+			num, typ, err := graph.field()
+			if err != nil {
+				return nil, err
+			}
+			if num != 5 || typ != wireBytes {
+				if err := graph.skip(typ); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			b, err := graph.bytes()
+			if err != nil {
+				return nil, err
+			}
+			t, ok, err := decodeONNXTensor(b)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				tensors = append(tensors, t)
+			}
+		}
+	}
+	return tensors, nil
+}
+
+// decodeONNXTensor decodes a TensorProto, returning false if it isn't
+// float or double.
+func decodeONNXTensor(b []byte) (ONNXTensor, bool, error) {
+	var t ONNXTensor
+	var dims []int64
+	var raw []byte
+	dataType := 0
+	d := &wireDecoder{b}
+	for !d.done() {
+		num, typ, err := d.field()
+		if err != nil {
+			return t, false, err
+		}
+		switch {
+		case num == 1:
+			dims, err = d.int64s(typ, dims)
+		case num == 2 && typ == wireVarint:
+			var v uint64
+			v, err = d.varint()
+			dataType = int(v)
+		case num == 4:
+			t.Data, err = d.floats(typ, t.Data)
+		case num == 8 && typ == wireBytes:
+			var name []byte
+			name, err = d.bytes()
+			t.Name = string(name)
+		case num == 9 && typ == wireBytes:
+			raw, err = d.bytes()
+		case num == 10:
+			t.Data, err = d.doubles(typ, t.Data)
+		default:
+			err = d.skip(typ)
+		}
+		if err != nil {
+			return t, false, err
+		}
+	}
+	if dataType != onnxFloat && dataType != onnxDouble {
+		return t, false, nil
+	}
+
+	udims := make([]uint64, len(dims))
+	for k, n := range dims {
+		if n < 0 {
+			return t, false, fmt.Errorf("tensor %q has negative dimension %d", t.Name, n)
+		}
+		t.Dims = append(t.Dims, int(n))
+		udims[k] = uint64(n)
+	}
+	size, ok := entryCount(udims...)
+	if !ok {
+		return t, false, fmt.Errorf("tensor %q has too many entries %v", t.Name, dims)
+	}
+	// raw_data, when present, holds the entries little endian instead.
+	if raw != nil {
+		width := 4
+		if dataType == onnxDouble {
+			width = 8
+		}
+		if len(raw)%width != 0 || len(raw)/width != size {
+			return t, false, fmt.Errorf("tensor %q has %d bytes of data for %d entries", t.Name, len(raw), size)
+		}
+		t.Data = make([]float64, size)
+		for k := range t.Data {
+			if width == 4 {
+				t.Data[k] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[4*k:])))
+			} else {
+				t.Data[k] = math.Float64frombits(binary.LittleEndian.Uint64(raw[8*k:]))
+			}
+		}
+	}
+	if len(t.Data) != size {
+		return t, false, fmt.Errorf("tensor %q has %d entries for dimensions %v", t.Name, len(t.Data), t.Dims)
+	}
+	return t, true, nil
+}
+
+// Matrix returns a tensor of rank 2 as a Matrix with an output (row)
+// for each entry of the first dimension, the layout of a dense layer's
+// weights, or a tensor of rank 1 as a vector.
+func (t ONNXTensor) Matrix() (Matrix, error) {
+	switch len(t.Dims) {
+	case 1:
+		return MatrixFromSlice(t.Data, 1, t.Dims[0], 1), nil
+	case 2:
+		return MatrixFromSlice(t.Data, t.Dims[1], t.Dims[0], t.Dims[1]), nil
+	}
+	return nil, fmt.Errorf("tensor %q has rank %d, not 1 or 2", t.Name, len(t.Dims))
+}
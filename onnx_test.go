@@ -0,0 +1,87 @@
+package linear
+
+import (
+	"bytes"
+	"testing"
+)
+
+// onnxModel encodes a ModelProto whose graph has the given
+// initializers, each already an encoded TensorProto.
+func onnxModel(initializers ...[]byte) []byte {
+	var graph wireEncoder
+	graph.bytes(1, []byte("node"))
+	for _, t := range initializers {
+		graph.bytes(5, t)
+	}
+	var model wireEncoder
+	model.varint(1, 7)
+	model.bytes(7, graph.data)
+	return model.data
+}
+
+func TestReadONNXInitializers(t *testing.T) {
+	var weights wireEncoder
+	weights.packedInt64s(1, []int64{2, 3})
+	weights.varint(2, onnxFloat)
+	weights.packedFloats(4, []float64{1, 2, 3, 4, 5, 6})
+	weights.bytes(8, []byte("dense/W"))
+
+	var bias wireEncoder
+	bias.varint(1, 2)
+	bias.varint(2, onnxDouble)
+	bias.bytes(8, []byte("dense/b"))
+	bias.bytes(9, []byte{0, 0, 0, 0, 0, 0, 0xe0, 0x3f, 0, 0, 0, 0, 0, 0, 0xf0, 0xbf})
+
+	var ints wireEncoder
+	ints.varint(1, 1)
+	ints.varint(2, 7)
+	ints.bytes(8, []byte("shape"))
+
+	tensors, err := ReadONNXInitializers(bytes.NewReader(onnxModel(weights.data, bias.data, ints.data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ExpectInt(2, len(tensors), t)
+	if tensors[0].Name != "dense/W" || tensors[1].Name != "dense/b" {
+		t.Errorf("unexpected names %q and %q", tensors[0].Name, tensors[1].Name)
+	}
+	W, err := tensors[0].Matrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6}, 3, 2, 3), W, t)
+	b, err := tensors[1].Matrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{0.5, -1}, 1, 2, 1), b, t)
+}
+
+func TestReadONNXInitializersErrors(t *testing.T) {
+	var short wireEncoder
+	short.packedInt64s(1, []int64{2, 2})
+	short.varint(2, onnxFloat)
+	short.packedFloats(4, []float64{1, 2, 3})
+	if _, err := ReadONNXInitializers(bytes.NewReader(onnxModel(short.data))); err == nil {
+		t.Errorf("expected an error for too few entries")
+	}
+
+	// Dimensions whose product wraps to 0 don't match no data.
+	var huge wireEncoder
+	huge.packedInt64s(1, []int64{1 << 32, 1 << 32})
+	huge.varint(2, onnxFloat)
+	if _, err := ReadONNXInitializers(bytes.NewReader(onnxModel(huge.data))); err == nil {
+		t.Errorf("expected an error for overflowing dimensions")
+	}
+
+	model := onnxModel(short.data)
+	if _, err := ReadONNXInitializers(bytes.NewReader(model[:len(model)-3])); err == nil {
+		t.Errorf("expected an error for a truncated model")
+	}
+
+	rank3 := ONNXTensor{"t", []int{1, 1, 1}, []float64{1}}
+	if _, err := rank3.Matrix(); err == nil {
+		t.Errorf("expected an error for rank 3")
+	}
+}
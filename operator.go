@@ -0,0 +1,112 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// LinearOperator is a linear map known only by what it does to
+// vectors, so that it needn't be stored as a Matrix at all. Iterative
+// solvers only ever need this much.
+type LinearOperator interface {
+	Shape() (ins, outs int)
+	ApplyVec(x Vector) Vector
+}
+
+// matrixOperator is a Matrix seen as a LinearOperator.
+type matrixOperator struct {
+	A Matrix
+}
+
+// MatrixOperator returns A as a LinearOperator, which applies it with
+// Apply and so with whatever kernel suits how A is stored.
+func MatrixOperator(A Matrix) LinearOperator {
+	return matrixOperator{A}
+}
+
+func (m matrixOperator) Shape() (ins, outs int)   { return m.A.Shape() }
+func (m matrixOperator) ApplyVec(x Vector) Vector { return Apply(m.A, x) }
+
+// ApplyOperator returns A*B for a LinearOperator A, applying it to B a
+// column (input) at a time.
+func ApplyOperator(A LinearOperator, B Matrix) Matrix {
+	ins, outs := A.Shape()
+	cols, rows := B.Shape()
+	if rows != ins {
+		panic(fmt.Errorf("not composable (%d, %d) operator vs (%d, %d)", ins, outs, cols, rows))
+	}
+	AB := NewArrayMatrixColMajor(cols, outs)
+	for c := 0; c < cols; c++ {
+		CopyInto(A.ApplyVec(Slice(B, c, c+1, 0, rows)), Slice(AB, c, c+1, 0, outs))
+	}
+	return AB
+}
+
+// OperatorFunc is a LinearOperator from a function, which is trusted
+// to be linear.
+type OperatorFunc struct {
+	Ins, Outs int
+	F         func(x Vector) Vector
+}
+
+func (f OperatorFunc) Shape() (ins, outs int)   { return f.Ins, f.Outs }
+func (f OperatorFunc) ApplyVec(x Vector) Vector { return f.F(x) }
+
+// jacobianOperator approximates J*v for the Jacobian J of f at x.
+type jacobianOperator struct {
+	f         func(Vector) Vector
+	x, fx     Vector
+	xnorm     float64
+	eps       float64
+	ins, outs int
+}
+
+// JacobianOperator returns an approximation of the Jacobian of f at x
+// that is never formed: J*v is estimated by the forward difference
+// (f(x + h*v) - f(x)) / h, with h scaled by eps and the sizes of x and
+// v, which costs one evaluation of f. An eps of 0 means the square root
+// of the machine epsilon, which balances truncation against rounding
+// for smooth f.
+func JacobianOperator(f func(Vector) Vector, x Vector, eps float64) LinearOperator {
+	CheckVector(x)
+	if eps == 0 {
+		eps = math.Sqrt(0x1p-52)
+	}
+	if eps < 0 {
+		panic(fmt.Errorf("negative step %g", eps))
+	}
+	fx := f(x)
+	CheckVector(fx)
+	_, ins := x.Shape()
+	_, outs := fx.Shape()
+	return &jacobianOperator{
+		f:     f,
+		x:     x,
+		fx:    fx,
+		xnorm: L2Norm(x),
+		eps:   eps,
+		ins:   ins,
+		outs:  outs,
+	}
+}
+
+func (j *jacobianOperator) Shape() (ins, outs int) { return j.ins, j.outs }
+
+func (j *jacobianOperator) ApplyVec(v Vector) Vector {
+	CheckVector(v)
+	CheckSameShape(v, j.x)
+	Jv := NewVector(j.outs)
+	vnorm := L2Norm(v)
+	if vnorm == 0 {
+		return Jv
+	}
+	h := j.eps * (1 + j.xnorm) / vnorm
+	xh := NewVector(j.ins)
+	addScaledInto(j.x, v, h, xh)
+	fxh := j.f(xh)
+	CheckSameShape(fxh, j.fx)
+	for o := 0; o < j.outs; o++ {
+		Jv.Set(0, o, (fxh.Get(0, o)-j.fx.Get(0, o))/h)
+	}
+	return Jv
+}
@@ -0,0 +1,55 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJacobianOperator(t *testing.T) {
+	// f(x, y) = (x*y, sin(x) + y^2)
+	f := func(v Vector) Vector {
+		x, y := v.Get(0, 0), v.Get(0, 1)
+		out := NewVector(2)
+		out.Set(0, 0, x*y)
+		out.Set(0, 1, math.Sin(x)+y*y)
+		return out
+	}
+	x := NewVector(2)
+	x.Set(0, 0, 0.5)
+	x.Set(0, 1, 2)
+	J := JacobianOperator(f, x, 0)
+
+	ins, outs := J.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(2, outs, t)
+
+	v := NewVector(2)
+	v.Set(0, 0, 1)
+	v.Set(0, 1, -3)
+	Jv := J.ApplyVec(v)
+
+	// J = [[y, x], [cos(x), 2y]]
+	expect0 := 2*1 + 0.5*-3
+	expect1 := math.Cos(0.5)*1 + 4*-3
+	if math.Abs(Jv.Get(0, 0)-expect0) > 1e-6 || math.Abs(Jv.Get(0, 1)-expect1) > 1e-6 {
+		t.Errorf("expected (%v, %v) but got (%v, %v)", expect0, expect1, Jv.Get(0, 0), Jv.Get(0, 1))
+	}
+	ExpectFloat(0, J.ApplyVec(NewVector(2)).Get(0, 1), t)
+}
+
+func TestMatrixOperator(t *testing.T) {
+	A := factorTestMatrix()
+	x := NewVector(3)
+	x.Set(0, 0, 1)
+	x.Set(0, 1, 2)
+	x.Set(0, 2, 3)
+
+	ExpectMatrix(Apply(A, x), MatrixOperator(A).ApplyVec(x), t)
+}
+
+func TestApplyOperator(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6}, 3, 2, 3)
+	B := MatrixFromSlice([]float64{1, 0, 2, 1, 0, -1}, 2, 3, 2)
+	ExpectMatrix(Apply(A, B), ApplyOperator(MatrixOperator(A), B), t)
+	expectPanic(t, func() { ApplyOperator(MatrixOperator(A), A) })
+}
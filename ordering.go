@@ -0,0 +1,467 @@
+package linear
+
+import (
+	"fmt"
+	"sort"
+)
+
+// symmetricAdjacency returns the neighbours of each row of a square
+// sparse matrix, treating an entry at (i, o) or (o, i) as an edge
+// between i and o, and ignoring the diagonal.
+func symmetricAdjacency(A *CSR) []map[int]bool {
+	if A.ins != A.outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", A.ins, A.outs))
+	}
+	adj := make([]map[int]bool, A.outs)
+	for o := range adj {
+		adj[o] = map[int]bool{}
+	}
+	for o := 0; o < A.outs; o++ {
+		for j := A.rowStart[o]; j < A.rowStart[o+1]; j++ {
+			if i := A.cols[j]; i != o {
+				adj[o][i] = true
+				adj[i][o] = true
+			}
+		}
+	}
+	return adj
+}
+
+// ApproximateMinimumDegreeOrder returns an elimination order for the
+// symmetric sparse A by approximate minimum degree (AMD, after Amestoy,
+// Davis and Duff), which tends to keep the fill of a Cholesky factor
+// small.
+// Rather than forming the elimination graph, which fills in as rows are
+// eliminated, it keeps the quotient graph: each eliminated row becomes
+// an element standing for the clique of its neighbours, and elements
+// inside a newer one are absorbed into it. Degrees are upper bounds
+// computed from the elements instead of exact counts, rows whose
+// neighbourhoods become the same are merged and eliminated together,
+// and rows wait in buckets by degree, so the whole ordering costs about
+// as much as the non-zeros of the factor rather than of its square.
+// perm[k] is the row eliminated at step k.
+func ApproximateMinimumDegreeOrder(A *CSR) []int {
+	g := newQuotientGraph(A)
+	for g.eliminated < g.n {
+		g.eliminate(g.popMinDegree())
+	}
+	return g.perm
+}
+
+// quotientGraph is the state of AMD. Every node starts as a variable
+// (a row not yet eliminated) and becomes an element when it's
+// eliminated. Lists are pruned lazily, so they may mention dead nodes,
+// which are skipped.
+type quotientGraph struct {
+	n int
+	// vars is the variables adjacent to each variable, from A, and for
+	// an element the variables in its clique. elems is the elements
+	// adjacent to each variable.
+	vars, elems [][]int
+	// nv is how many rows a variable stands for, 0 once it's merged
+	// into another or eliminated. members are those rows.
+	nv      []int
+	members [][]int
+	isElem  []bool
+	// absorbed elements are inside a newer element.
+	absorbed []bool
+	degree   []int
+	// Variables are kept in doubly linked lists by degree.
+	head, next, prev []int
+	minDegree        int
+	// w is the weight of each element outside the current pivot's
+	// clique, valid where stamp is the current pivot's.
+	w, stamp   []int
+	mark       []int
+	eliminated int
+	perm       []int
+}
+
+func newQuotientGraph(A *CSR) *quotientGraph {
+	if A.ins != A.outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", A.ins, A.outs))
+	}
+	n := A.outs
+	g := &quotientGraph{
+		n:        n,
+		vars:     symmetricNeighbours(A),
+		elems:    make([][]int, n),
+		nv:       make([]int, n),
+		members:  make([][]int, n),
+		isElem:   make([]bool, n),
+		absorbed: make([]bool, n),
+		degree:   make([]int, n),
+		head:     make([]int, n+1),
+		next:     make([]int, n),
+		prev:     make([]int, n),
+		w:        make([]int, n),
+		stamp:    make([]int, n),
+		mark:     make([]int, n),
+		perm:     make([]int, 0, n),
+	}
+	for d := range g.head {
+		g.head[d] = -1
+	}
+	for i := 0; i < n; i++ {
+		g.nv[i] = 1
+		g.members[i] = []int{i}
+		g.stamp[i], g.mark[i] = -1, -1
+		g.insert(i, len(g.vars[i]))
+	}
+	return g
+}
+
+// symmetricNeighbours is symmetricAdjacency as sorted lists.
+func symmetricNeighbours(A *CSR) [][]int {
+	n := A.outs
+	adj := make([][]int, n)
+	for o := 0; o < n; o++ {
+		for j := A.rowStart[o]; j < A.rowStart[o+1]; j++ {
+			if i := A.cols[j]; i != o {
+				adj[o] = append(adj[o], i)
+				adj[i] = append(adj[i], o)
+			}
+		}
+	}
+	// Drop duplicates, from entries stored on both sides.
+	for o := range adj {
+		sort.Ints(adj[o])
+		unique := adj[o][:0]
+		for k, i := range adj[o] {
+			if k == 0 || i != adj[o][k-1] {
+				unique = append(unique, i)
+			}
+		}
+		adj[o] = unique
+	}
+	return adj
+}
+
+func (g *quotientGraph) insert(i, degree int) {
+	g.degree[i] = degree
+	g.prev[i], g.next[i] = -1, g.head[degree]
+	if g.next[i] >= 0 {
+		g.prev[g.next[i]] = i
+	}
+	g.head[degree] = i
+	if degree < g.minDegree {
+		g.minDegree = degree
+	}
+}
+
+func (g *quotientGraph) remove(i int) {
+	if g.prev[i] >= 0 {
+		g.next[g.prev[i]] = g.next[i]
+	} else {
+		g.head[g.degree[i]] = g.next[i]
+	}
+	if g.next[i] >= 0 {
+		g.prev[g.next[i]] = g.prev[i]
+	}
+}
+
+func (g *quotientGraph) popMinDegree() int {
+	for g.head[g.minDegree] < 0 {
+		g.minDegree++
+	}
+	p := g.head[g.minDegree]
+	g.remove(p)
+	return p
+}
+
+func (g *quotientGraph) alive(i int) bool { return g.nv[i] > 0 }
+
+// eliminate turns the variable p into an element and updates the
+// variables of its clique.
+func (g *quotientGraph) eliminate(p int) {
+	g.perm = append(g.perm, g.members[p]...)
+	g.eliminated += g.nv[p]
+	g.nv[p] = 0
+	g.isElem[p] = true
+
+	// The clique is p's variables and those of its elements, which it
+	// absorbs.
+	var clique []int
+	cliqueWeight := 0
+	add := func(i int) {
+		if g.alive(i) && g.mark[i] != p {
+			g.mark[i] = p
+			clique = append(clique, i)
+			cliqueWeight += g.nv[i]
+		}
+	}
+	for _, i := range g.vars[p] {
+		add(i)
+	}
+	for _, e := range g.elems[p] {
+		if g.absorbed[e] {
+			continue
+		}
+		for _, i := range g.vars[e] {
+			add(i)
+		}
+		g.absorbed[e] = true
+		g.vars[e] = nil
+	}
+	g.vars[p], g.elems[p] = clique, nil
+
+	// How much of each other element adjacent to the clique is outside
+	// it.
+	for _, i := range clique {
+		g.remove(i)
+		for _, e := range g.elems[i] {
+			if e == p || g.absorbed[e] {
+				continue
+			}
+			if g.stamp[e] != p {
+				g.stamp[e] = p
+				g.w[e] = 0
+				live := g.vars[e][:0]
+				for _, j := range g.vars[e] {
+					if g.alive(j) {
+						live = append(live, j)
+						g.w[e] += g.nv[j]
+					}
+				}
+				g.vars[e] = live
+			}
+			g.w[e] -= g.nv[i]
+		}
+	}
+
+	// Prune each clique variable's lists, absorbing elements that are
+	// entirely inside the clique, and note its approximate degree.
+	fromVars := make([]int, len(clique))
+	fromElems := make([]int, len(clique))
+	for k, i := range clique {
+		elems := []int{p}
+		for _, e := range g.elems[i] {
+			if e == p || g.absorbed[e] {
+				continue
+			}
+			if g.w[e] == 0 {
+				g.absorbed[e] = true
+				g.vars[e] = nil
+				continue
+			}
+			elems = append(elems, e)
+			fromElems[k] += g.w[e]
+		}
+		g.elems[i] = elems
+		vars := g.vars[i][:0]
+		for _, j := range g.vars[i] {
+			if g.alive(j) && g.mark[j] != p {
+				vars = append(vars, j)
+				fromVars[k] += g.nv[j]
+			}
+		}
+		g.vars[i] = vars
+	}
+
+	g.mergeIndistinguishable(clique)
+
+	remaining := g.n - g.eliminated
+	for k, i := range clique {
+		if !g.alive(i) {
+			continue
+		}
+		outside := cliqueWeight - g.nv[i]
+		d := fromVars[k] + outside + fromElems[k]
+		if bound := g.degree[i] + outside; bound < d {
+			d = bound
+		}
+		if bound := remaining - g.nv[i]; bound < d {
+			d = bound
+		}
+		g.insert(i, d)
+	}
+}
+
+// mergeIndistinguishable merges variables of the clique that have the
+// same elements and variables, which will be eliminated together
+// anyway, into one supervariable. Candidates are found by hashing their
+// lists.
+func (g *quotientGraph) mergeIndistinguishable(clique []int) {
+	buckets := map[int][]int{}
+	for _, i := range clique {
+		h := 0
+		for _, e := range g.elems[i] {
+			h += e
+		}
+		for _, j := range g.vars[i] {
+			h += j
+		}
+		buckets[h] = append(buckets[h], i)
+	}
+	for _, candidates := range buckets {
+		for a, i := range candidates {
+			if !g.alive(i) {
+				continue
+			}
+			for _, j := range candidates[a+1:] {
+				if g.alive(j) && sameSet(g.elems[i], g.elems[j]) && sameSet(g.vars[i], g.vars[j]) {
+					g.nv[i] += g.nv[j]
+					g.members[i] = append(g.members[i], g.members[j]...)
+					g.nv[j], g.members[j] = 0, nil
+					g.vars[j], g.elems[j] = nil, nil
+				}
+			}
+		}
+	}
+}
+
+// sameSet reports whether the lists, which have no duplicates, have the
+// same entries.
+func sameSet(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sa := append([]int(nil), a...)
+	sb := append([]int(nil), b...)
+	sort.Ints(sa)
+	sort.Ints(sb)
+	for k := range sa {
+		if sa[k] != sb[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// InversePermutation returns pinv such that pinv[perm[k]] = k.
+func InversePermutation(perm []int) []int {
+	pinv := make([]int, len(perm))
+	for k, p := range perm {
+		pinv[p] = k
+	}
+	return pinv
+}
+
+// PermuteSymmetric returns P*A*Dual(P) where row (and column) k of the
+// result is row (and column) perm[k] of A.
+func PermuteSymmetric(A *CSR, perm []int) *CSR {
+	pinv := InversePermutation(perm)
+	entries := make([]SparseEntry, 0, A.NonZeros())
+	for o := 0; o < A.outs; o++ {
+		for j := A.rowStart[o]; j < A.rowStart[o+1]; j++ {
+			entries = append(entries, SparseEntry{pinv[A.cols[j]], pinv[o], A.values[j]})
+		}
+	}
+	return NewCSRFromEntries(A.ins, A.outs, entries)
+}
+
+// ReverseCuthillMcKee returns an order for the symmetric sparse A that
+// keeps the non-zeros close to the diagonal, which is what banded
+// solvers want. Each connected part is walked breadth first from a row
+// far from the rest, visiting neighbours of low degree first, and the
+// whole order is reversed at the end (which doesn't change the
+// bandwidth but reduces fill). perm[k] is the row that goes k-th.
+func ReverseCuthillMcKee(A *CSR) []int {
+	adj := symmetricAdjacency(A)
+	n := len(adj)
+	neighbours := make([][]int, n)
+	for i := range adj {
+		for j := range adj[i] {
+			neighbours[i] = append(neighbours[i], j)
+		}
+		// By degree, then index so that the order is deterministic.
+		sort.Slice(neighbours[i], func(a, b int) bool {
+			na, nb := neighbours[i][a], neighbours[i][b]
+			if len(adj[na]) != len(adj[nb]) {
+				return len(adj[na]) < len(adj[nb])
+			}
+			return na < nb
+		})
+	}
+
+	visited := make([]bool, n)
+	perm := make([]int, 0, n)
+	for len(perm) < n {
+		start := -1
+		for i := 0; i < n; i++ {
+			if !visited[i] && (start < 0 || len(adj[i]) < len(adj[start])) {
+				start = i
+			}
+		}
+		start = peripheralRow(neighbours, start)
+		visited[start] = true
+		perm = append(perm, start)
+		for q := len(perm) - 1; q < len(perm); q++ {
+			for _, j := range neighbours[perm[q]] {
+				if !visited[j] {
+					visited[j] = true
+					perm = append(perm, j)
+				}
+			}
+		}
+	}
+	for a, b := 0, n-1; a < b; a, b = a+1, b-1 {
+		perm[a], perm[b] = perm[b], perm[a]
+	}
+	return perm
+}
+
+// peripheralRow starts from row i and repeatedly moves to the furthest
+// row (by breadth first levels) until that stops getting further away,
+// which finds a row near the edge of its connected part.
+func peripheralRow(neighbours [][]int, i int) int {
+	depth := -1
+	for {
+		levels := breadthFirstLevels(neighbours, i)
+		last := levels[len(levels)-1]
+		if len(levels) <= depth {
+			return i
+		}
+		depth = len(levels)
+		// Of the furthest rows, the one with the fewest neighbours.
+		next := last[0]
+		for _, j := range last {
+			if len(neighbours[j]) < len(neighbours[next]) {
+				next = j
+			}
+		}
+		if next == i {
+			return i
+		}
+		i = next
+	}
+}
+
+// breadthFirstLevels returns the rows at each distance from row i.
+func breadthFirstLevels(neighbours [][]int, i int) [][]int {
+	seen := map[int]bool{i: true}
+	levels := [][]int{{i}}
+	for {
+		var next []int
+		for _, a := range levels[len(levels)-1] {
+			for _, b := range neighbours[a] {
+				if !seen[b] {
+					seen[b] = true
+					next = append(next, b)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return levels
+		}
+		levels = append(levels, next)
+	}
+}
+
+// Bandwidth returns the largest distance from the diagonal of any
+// non-zero of A.
+func Bandwidth(A *CSR) int {
+	b := 0
+	for o := 0; o < A.outs; o++ {
+		for j := A.rowStart[o]; j < A.rowStart[o+1]; j++ {
+			d := A.cols[j] - o
+			if d < 0 {
+				d = -d
+			}
+			if A.values[j] != 0 && d > b {
+				b = d
+			}
+		}
+	}
+	return b
+}
@@ -0,0 +1,72 @@
+package linear
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func expectPermutation(perm []int, n int, t *testing.T) {
+	ExpectInt(n, len(perm), t)
+	sorted := append([]int(nil), perm...)
+	sort.Ints(sorted)
+	for k, p := range sorted {
+		ExpectInt(k, p, t)
+	}
+}
+
+func TestReverseCuthillMcKee(t *testing.T) {
+	// A path whose rows have been shuffled, so the bandwidth is large
+	// until it's put back in order.
+	n := 20
+	r := rand.New(rand.NewSource(1))
+	shuffle := r.Perm(n)
+	var entries []SparseEntry
+	for k := 0; k < n; k++ {
+		entries = append(entries, SparseEntry{shuffle[k], shuffle[k], 2})
+		if k > 0 {
+			entries = append(entries,
+				SparseEntry{shuffle[k-1], shuffle[k], -1},
+				SparseEntry{shuffle[k], shuffle[k-1], -1})
+		}
+	}
+	A := NewCSRFromEntries(n, n, entries)
+
+	perm := ReverseCuthillMcKee(A)
+
+	expectPermutation(perm, n, t)
+	ExpectInt(1, Bandwidth(PermuteSymmetric(A, perm)), t)
+}
+
+func TestMinimumDegreeOrder(t *testing.T) {
+	A := arrowMatrix(6)
+
+	perm := ApproximateMinimumDegreeOrder(A)
+
+	expectPermutation(perm, 6, t)
+	inv := InversePermutation(perm)
+	for k, p := range perm {
+		ExpectInt(k, inv[p], t)
+	}
+}
+
+func TestApproximateMinimumDegreeOrderGrid(t *testing.T) {
+	// A grid the size of a modest PDE problem orders quickly, and with
+	// much less fill than the natural order's band of about n*width.
+	width := 150
+	n := width * width
+	A := gridLaplacian(width, width)
+
+	start := time.Now()
+	perm := ApproximateMinimumDegreeOrder(A)
+	elapsed := time.Since(start)
+
+	expectPermutation(perm, n, t)
+	if elapsed > 2*time.Second {
+		t.Errorf("expected ordering to take well under 2s but took %v", elapsed)
+	}
+	if nz := AnalyzeSparseCholesky(A).NonZeros(); nz > n*width/4 {
+		t.Errorf("expected at most %d entries in the factor but got %d", n*width/4, nz)
+	}
+}
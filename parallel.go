@@ -0,0 +1,123 @@
+package linear
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Parallel kernels split their work across this many goroutines. It
+// starts out at 1 so that nothing runs in parallel unless asked for.
+var parallelism = 1
+
+// In deterministic mode reductions are split into fixed-size chunks
+// whose partial results are combined in a fixed order, so results are
+// bitwise identical however many goroutines there are.
+var deterministic = false
+
+// deterministicChunk is the number of entries per chunk of a
+// deterministic reduction. It must not depend on the parallelism.
+const deterministicChunk = 4096
+
+// parallelGrain is the least amount of work (in multiply-adds) worth
+// handing to another goroutine.
+const parallelGrain = 1 << 15
+
+// SetParallelism sets how many goroutines the kernels may use. A value
+// less than 1 means runtime.GOMAXPROCS(0). It must not be called while
+// other calls into the package are running.
+func SetParallelism(n int) {
+	if n < 1 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	parallelism = n
+}
+
+// Parallelism returns how many goroutines the kernels may use.
+func Parallelism() int { return parallelism }
+
+// SetDeterministic turns deterministic mode on or off. In deterministic
+// mode every result is bitwise identical from run to run and for any
+// parallelism, at some cost in speed. (Results may still differ between
+// CPUs that have different kernels.) It must not be called while other
+// calls into the package are running.
+func SetDeterministic(on bool) { deterministic = on }
+
+// Deterministic reports whether deterministic mode is on.
+func Deterministic() bool { return deterministic }
+
+// parallelFor calls work on consecutive ranges covering [0, n), in
+// parallel if there is enough of it. cost is the work per index.
+func parallelFor(n, cost int, work func(lo, hi int)) {
+	p := parallelism
+	if p > n {
+		p = n
+	}
+	if p <= 1 || n*cost < 2*parallelGrain {
+		work(0, n)
+		return
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < p; w++ {
+		lo, hi := w*n/p, (w+1)*n/p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			work(lo, hi)
+		}()
+	}
+	wg.Wait()
+}
+
+// parallelDot is dotKernel split across goroutines.
+func parallelDot(x, y []float64) float64 {
+	n := len(x)
+	if deterministic {
+		chunks := (n + deterministicChunk - 1) / deterministicChunk
+		if chunks <= 1 {
+			return dotKernel(x, y)
+		}
+		partial := make([]float64, chunks)
+		parallelFor(chunks, deterministicChunk, func(lo, hi int) {
+			for c := lo; c < hi; c++ {
+				start := c * deterministicChunk
+				end := start + deterministicChunk
+				if end > n {
+					end = n
+				}
+				partial[c] = dotKernel(x[start:end], y[start:end])
+			}
+		})
+		return pairwiseSum(partial)
+	}
+
+	p := parallelism
+	if p <= 1 || n < 2*parallelGrain {
+		return dotKernel(x, y)
+	}
+	// Partial sums are added up in whatever order they finish.
+	sums := make(chan float64, p)
+	for w := 0; w < p; w++ {
+		lo, hi := w*n/p, (w+1)*n/p
+		go func() {
+			sums <- dotKernel(x[lo:hi], y[lo:hi])
+		}()
+	}
+	sum := 0.0
+	for w := 0; w < p; w++ {
+		sum += <-sums
+	}
+	return sum
+}
+
+// pairwiseSum adds up xs as a balanced tree, which depends only on
+// len(xs).
+func pairwiseSum(xs []float64) float64 {
+	switch len(xs) {
+	case 0:
+		return 0
+	case 1:
+		return xs[0]
+	}
+	mid := len(xs) / 2
+	return pairwiseSum(xs[:mid]) + pairwiseSum(xs[mid:])
+}
@@ -0,0 +1,73 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDeterministicDot(t *testing.T) {
+	defer SetParallelism(Parallelism())
+	defer SetDeterministic(Deterministic())
+
+	n := 4*parallelGrain + 17
+	r := rand.New(rand.NewSource(1))
+	x := NewArrayMatrix(1, n)
+	y := NewArrayMatrix(n, 1)
+	for d := 0; d < n; d++ {
+		x.Set(0, d, r.NormFloat64())
+		y.Set(d, 0, r.NormFloat64())
+	}
+
+	SetDeterministic(true)
+	SetParallelism(1)
+	serial := DotProduct(x, y)
+	for _, p := range []int{2, 3, 8} {
+		SetParallelism(p)
+		for run := 0; run < 4; run++ {
+			if got := DotProduct(x, y); math.Float64bits(got) != math.Float64bits(serial) {
+				t.Errorf("parallelism %d gave %v but serial gave %v", p, got, serial)
+			}
+		}
+	}
+
+	SetDeterministic(false)
+	SetParallelism(4)
+	if got := DotProduct(x, y); math.Abs(got-serial) > 1e-9*float64(n) {
+		t.Errorf("expected about %v but got %v", serial, got)
+	}
+}
+
+func TestParallelCompose(t *testing.T) {
+	defer SetParallelism(Parallelism())
+
+	r := rand.New(rand.NewSource(2))
+	A := NewArrayMatrix(64, 80)
+	B := NewArrayMatrixColMajor(80, 96)
+	for _, M := range []Matrix{A, B} {
+		ins, outs := M.Shape()
+		for o := 0; o < outs; o++ {
+			for i := 0; i < ins; i++ {
+				M.Set(i, o, r.NormFloat64())
+			}
+		}
+	}
+
+	SetParallelism(1)
+	serial := Compose(A, B)
+	serialCol := NewArrayMatrixColMajor(64, 96)
+	ComposeInto(A, B, serialCol)
+
+	SetParallelism(4)
+	parallel := Compose(A, B)
+	parallelCol := NewArrayMatrixColMajor(64, 96)
+	ComposeInto(A, B, parallelCol)
+
+	for o := 0; o < 96; o++ {
+		for i := 0; i < 64; i++ {
+			if parallel.Get(i, o) != serial.Get(i, o) || parallelCol.Get(i, o) != serialCol.Get(i, o) {
+				t.Fatalf("entry (%d, %d) differs in parallel", i, o)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// PrincipalComponents finds the k orthogonal directions in feature
+// space along which the observations (outputs) of X vary the most. It
+// returns the mean observation as a covector and the directions as
+// the columns of W, ordered by decreasing variance.
+func PrincipalComponents(X Matrix, k int) (mean, W Matrix) {
+	ins, outs := X.Shape()
+	if k < 0 || k > ins || k > outs {
+		panic(fmt.Errorf("can't find %d components of (%d, %d)", k, ins, outs))
+	}
+
+	mean = ColumnMeans(X)
+
+	// The right singular vectors of the centered data are the
+	// eigenvectors of its covariance.
+	_, _, V := DecomposeSVD(center(X, mean))
+	W = Copy(Slice(V, 0, k, 0, ins))
+	return mean, W
+}
+
+// PrincipalComponentScores maps the observations of X onto the
+// principal directions, after removing the mean.
+func PrincipalComponentScores(X, mean, W Matrix) Matrix {
+	CheckCovector(mean)
+	CheckSameIns(X, mean)
+	return Apply(center(X, mean), W)
+}
+
+// PCR is principal component regression: it regresses y onto the
+// scores of the first k principal components of X by ordinary least
+// squares, then maps the coefficients back to the original features so
+// that X*theta + intercept approximates y.
+func PCR(X, y Matrix, k int) (theta Matrix, intercept float64) {
+	CheckVector(y)
+	CheckSameOuts(X, y)
+	_, outs := y.Shape()
+
+	mean, W := PrincipalComponents(X, k)
+	Z := PrincipalComponentScores(X, mean, W)
+
+	// Centering y too means the intercept doesn't have to be part of
+	// the least squares problem.
+	ymean := 0.0
+	for o := 0; o < outs; o++ {
+		ymean += y.Get(0, o)
+	}
+	ymean /= float64(outs)
+	yc := NewArrayMatrix(1, outs)
+	for o := 0; o < outs; o++ {
+		yc.Set(0, o, y.Get(0, o)-ymean)
+	}
+
+	gamma := OrdinaryLeastSquares(Z, yc)
+	theta = Apply(W, gamma)
+	intercept = ymean - DotProduct(theta, mean)
+	return theta, intercept
+}
+
+// center returns a copy of X with the covector c subtracted from each
+// output.
+func center(X, c Matrix) Matrix {
+	ins, outs := X.Shape()
+	Xc := NewArrayMatrix(ins, outs)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			Xc.Set(i, o, X.Get(i, o)-c.Get(i, 0))
+		}
+	}
+	return Xc
+}
@@ -0,0 +1,68 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPrincipalComponents(t *testing.T) {
+	X := NewArrayMatrix(2, 4)
+	X.Set(0, 0, 1)
+	X.Set(1, 0, 1)
+	X.Set(0, 1, 2)
+	X.Set(1, 1, 2)
+	X.Set(0, 2, 3)
+	X.Set(1, 2, 3)
+	X.Set(0, 3, 2)
+	X.Set(1, 3, 2)
+
+	mean, W := PrincipalComponents(X, 1)
+
+	ExpectFloat(2, mean.Get(0, 0), t)
+	ExpectFloat(2, mean.Get(1, 0), t)
+
+	wIns, wOuts := W.Shape()
+	ExpectInt(1, wIns, t)
+	ExpectInt(2, wOuts, t)
+	ExpectFloat(1/math.Sqrt(2), math.Abs(W.Get(0, 0)), t)
+	ExpectFloat(1/math.Sqrt(2), math.Abs(W.Get(0, 1)), t)
+
+	Z := PrincipalComponentScores(X, mean, W)
+
+	zIns, zOuts := Z.Shape()
+	ExpectInt(1, zIns, t)
+	ExpectInt(4, zOuts, t)
+	ExpectFloat(math.Sqrt(2), math.Abs(Z.Get(0, 0)), t)
+	ExpectFloat(0, Z.Get(0, 1), t)
+	ExpectFloat(math.Sqrt(2), math.Abs(Z.Get(0, 2)), t)
+}
+
+func TestPCR(t *testing.T) {
+	X := NewArrayMatrix(2, 5)
+	X.Set(0, 0, 0)
+	X.Set(1, 0, 0)
+	X.Set(0, 1, 1)
+	X.Set(1, 1, 0)
+	X.Set(0, 2, 0)
+	X.Set(1, 2, 1)
+	X.Set(0, 3, 1)
+	X.Set(1, 3, 1)
+	X.Set(0, 4, 2)
+	X.Set(1, 4, 1)
+
+	// y = 2*x0 - x1 + 3
+	y := NewArrayMatrix(1, 5)
+	y.Set(0, 0, 3)
+	y.Set(0, 1, 5)
+	y.Set(0, 2, 2)
+	y.Set(0, 3, 4)
+	y.Set(0, 4, 6)
+
+	theta, intercept := PCR(X, y, 2)
+
+	_, dim := theta.Shape()
+	ExpectInt(2, dim, t)
+	ExpectFloat(2, theta.Get(0, 0), t)
+	ExpectFloat(-1, theta.Get(0, 1), t)
+	ExpectFloat(3, intercept, t)
+}
@@ -0,0 +1,194 @@
+package linear
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// WriteHeatmap draws A as a PNG with a cellSize pixel square for each
+// entry, outputs (rows) going down. Positive entries are red and
+// negative entries blue, fading to white at zero, scaled by the
+// largest magnitude in A so the structure of a decomposition stands
+// out. NaNs are gray.
+func WriteHeatmap(w io.Writer, A Matrix, cellSize int) error {
+	if cellSize < 1 {
+		return fmt.Errorf("cell size %d is less than 1", cellSize)
+	}
+	ins, outs := A.Shape()
+	img := image.NewRGBA(image.Rect(0, 0, ins*cellSize, outs*cellSize))
+	scale := 0.0
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			if f := math.Abs(A.Get(i, o)); f > scale && !math.IsInf(f, 0) {
+				scale = f
+			}
+		}
+	}
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			c := heatColor(A.Get(i, o), scale)
+			for y := o * cellSize; y < (o+1)*cellSize; y++ {
+				for x := i * cellSize; x < (i+1)*cellSize; x++ {
+					img.SetRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+func heatColor(f, scale float64) color.RGBA {
+	if math.IsNaN(f) {
+		return color.RGBA{128, 128, 128, 255}
+	}
+	t := 0.0
+	if scale > 0 {
+		t = math.Max(-1, math.Min(1, f/scale))
+	}
+	// Fade the other two channels away from white.
+	fade := uint8(math.Round(255 * (1 - math.Abs(t))))
+	if t < 0 {
+		return color.RGBA{fade, fade, 255, 255}
+	}
+	return color.RGBA{255, fade, fade, 255}
+}
+
+// WriteConvergencePlot draws residuals against iteration as a width by
+// height PNG, with the residuals on a log scale and a gray line at
+// each power of ten, for seeing how fast a solver converges. Residuals
+// that aren't positive are drawn at the bottom.
+func WriteConvergencePlot(w io.Writer, residuals []float64, width, height int) error {
+	const margin = 4
+	if width <= 2*margin || height <= 2*margin {
+		return fmt.Errorf("plot size (%d, %d) is too small", width, height)
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, r := range residuals {
+		if r > 0 && !math.IsInf(r, 0) {
+			lo = math.Min(lo, math.Log10(r))
+			hi = math.Max(hi, math.Log10(r))
+		}
+	}
+	if lo > hi {
+		lo, hi = 0, 1
+	}
+	lo, hi = math.Floor(lo), math.Ceil(hi)
+	if lo == hi {
+		hi++
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+		}
+	}
+	left, right, top, bottom := margin, width-1-margin, margin, height-1-margin
+	toY := func(r float64) int {
+		if !(r > 0) {
+			return bottom
+		}
+		t := (math.Log10(r) - lo) / (hi - lo)
+		return bottom - int(math.Round(math.Min(t, 1)*float64(bottom-top)))
+	}
+	gray := color.RGBA{220, 220, 220, 255}
+	for e := lo; e <= hi; e++ {
+		y := toY(math.Pow(10, e))
+		drawLine(img, left, y, right, y, gray)
+	}
+	black := color.RGBA{0, 0, 0, 255}
+	drawLine(img, left, top, left, bottom, black)
+	drawLine(img, left, bottom, right, bottom, black)
+
+	blue := color.RGBA{31, 119, 180, 255}
+	toX := func(k int) int {
+		if len(residuals) == 1 {
+			return left
+		}
+		return left + k*(right-left)/(len(residuals)-1)
+	}
+	for k := range residuals {
+		if k == 0 {
+			img.SetRGBA(toX(0), toY(residuals[0]), blue)
+			continue
+		}
+		drawLine(img, toX(k-1), toY(residuals[k-1]), toX(k), toY(residuals[k]), blue)
+	}
+	return png.Encode(w, img)
+}
+
+// drawLine sets the pixels from (x0, y0) to (x1, y1).
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := x1-x0, y1-y0
+	steps := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+	for s := 0; s <= steps; s++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(s) / float64(steps)
+		}
+		x := x0 + int(math.Round(t*float64(dx)))
+		y := y0 + int(math.Round(t*float64(dy)))
+		img.SetRGBA(x, y, c)
+	}
+}
+
+// spyWidth is the most characters Spy draws across.
+const spyWidth = 64
+
+// Spy writes the pattern of non-zero entries of A as text, with a *
+// for a non-zero and a . for a zero, followed by the number of
+// non-zeros. Matrices wider than 64 inputs are drawn with each
+// character standing for a square block of entries, marked if any of
+// them is non-zero. A *CSR only has its stored entries visited.
+func Spy(A Matrix, w io.Writer) error {
+	ins, outs := A.Shape()
+	block := (ins + spyWidth - 1) / spyWidth
+	if block < 1 {
+		block = 1
+	}
+	cols, rows := (ins+block-1)/block, (outs+block-1)/block
+	marked := make([][]bool, rows)
+	for r := range marked {
+		marked[r] = make([]bool, cols)
+	}
+	nonZeros := 0
+	if m, ok := A.(*CSR); ok {
+		for o := 0; o < outs; o++ {
+			for j := m.rowStart[o]; j < m.rowStart[o+1]; j++ {
+				if m.values[j] != 0 {
+					marked[o/block][m.cols[j]/block] = true
+					nonZeros++
+				}
+			}
+		}
+	} else {
+		for o := 0; o < outs; o++ {
+			for i := 0; i < ins; i++ {
+				if A.Get(i, o) != 0 {
+					marked[o/block][i/block] = true
+					nonZeros++
+				}
+			}
+		}
+	}
+
+	line := make([]byte, cols+1)
+	line[cols] = '\n'
+	for _, row := range marked {
+		for c, m := range row {
+			line[c] = '.'
+			if m {
+				line[c] = '*'
+			}
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "nz = %d\n", nonZeros)
+	return err
+}
@@ -0,0 +1,93 @@
+package linear
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func decodePNG(t *testing.T, b *bytes.Buffer) image.Image {
+	t.Helper()
+	img, err := png.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func expectColor(expect color.RGBA, got color.Color, t *testing.T) {
+	t.Helper()
+	if c := color.RGBAModel.Convert(got).(color.RGBA); c != expect {
+		t.Errorf("expected %v but got %v", expect, c)
+	}
+}
+
+func TestWriteHeatmap(t *testing.T) {
+	A := MatrixFromSlice([]float64{2, 0, -2, -1, math.NaN(), 1}, 3, 2, 3)
+	var b bytes.Buffer
+	if err := WriteHeatmap(&b, A, 4); err != nil {
+		t.Fatal(err)
+	}
+	img := decodePNG(t, &b)
+	ExpectInt(12, img.Bounds().Dx(), t)
+	ExpectInt(8, img.Bounds().Dy(), t)
+
+	expectColor(color.RGBA{255, 0, 0, 255}, img.At(0, 0), t)
+	expectColor(color.RGBA{255, 255, 255, 255}, img.At(7, 3), t)
+	expectColor(color.RGBA{0, 0, 255, 255}, img.At(11, 0), t)
+	expectColor(color.RGBA{128, 128, 255, 255}, img.At(0, 4), t)
+	expectColor(color.RGBA{128, 128, 128, 255}, img.At(5, 5), t)
+
+	if err := WriteHeatmap(&b, A, 0); err == nil {
+		t.Errorf("expected an error for a zero cell size")
+	}
+}
+
+func TestWriteConvergencePlot(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteConvergencePlot(&b, []float64{1, 1e-1, 1e-2, 1e-3, 0}, 109, 57); err != nil {
+		t.Fatal(err)
+	}
+	img := decodePNG(t, &b)
+	ExpectInt(109, img.Bounds().Dx(), t)
+	ExpectInt(57, img.Bounds().Dy(), t)
+
+	// The first residual is at the top left corner of the axes and each
+	// power of ten is a third of the axis lower.
+	blue := color.RGBA{31, 119, 180, 255}
+	expectColor(blue, img.At(4, 4), t)
+	expectColor(blue, img.At(4+25, 4+16), t)
+	expectColor(blue, img.At(4+75, 4+48), t)
+	// The zero is drawn on the bottom axis.
+	expectColor(blue, img.At(104, 52), t)
+	expectColor(color.RGBA{0, 0, 0, 255}, img.At(4, 30), t)
+	expectColor(color.RGBA{220, 220, 220, 255}, img.At(60, 4+32), t)
+	expectColor(color.RGBA{255, 255, 255, 255}, img.At(60, 10), t)
+
+	if err := WriteConvergencePlot(&b, nil, 8, 8); err == nil {
+		t.Errorf("expected an error for a tiny plot")
+	}
+}
+
+func TestSpy(t *testing.T) {
+	A := NewCSRFromEntries(4, 3, []SparseEntry{{0, 0, 1}, {3, 0, 2}, {1, 1, -1}, {2, 2, 0}})
+	var b bytes.Buffer
+	if err := Spy(A, &b); err != nil {
+		t.Fatal(err)
+	}
+	expectString("*..*\n.*..\n....\nnz = 3\n", b.String(), t)
+
+	// Blocks of 2 by 2 for 65 to 128 inputs.
+	D := NewArrayMatrix(100, 4)
+	D.Set(99, 0, 1)
+	D.Set(0, 3, 1)
+	b.Reset()
+	if err := Spy(D, &b); err != nil {
+		t.Fatal(err)
+	}
+	dots := "................................................."
+	expectString(dots+"*\n*"+dots+"\nnz = 2\n", b.String(), t)
+}
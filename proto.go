@@ -0,0 +1,60 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// MarshalMatrixProto encodes A as the Matrix message of matrix.proto.
+func MarshalMatrixProto(A Matrix) []byte {
+	ins, outs := A.Shape()
+	data := make([]float64, 0, ins*outs)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			data = append(data, A.Get(i, o))
+		}
+	}
+	var e wireEncoder
+	// Zero values are left out, as proto3 does.
+	if ins != 0 {
+		e.varint(1, uint64(ins))
+	}
+	if outs != 0 {
+		e.varint(2, uint64(outs))
+	}
+	if len(data) != 0 {
+		e.packedDoubles(3, data)
+	}
+	return e.data
+}
+
+// UnmarshalMatrixProto decodes a Matrix message of matrix.proto, as
+// written by MarshalMatrixProto or by generated code in any language.
+func UnmarshalMatrixProto(b []byte) (Matrix, error) {
+	var ins, outs uint64
+	var data []float64
+	d := &wireDecoder{b}
+	for !d.done() {
+		num, typ, err := d.field()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case num == 1 && typ == wireVarint:
+			ins, err = d.varint()
+		case num == 2 && typ == wireVarint:
+			outs, err = d.varint()
+		case num == 3:
+			data, err = d.doubles(typ, data)
+		default:
+			// Unknown fields are skipped, so the schema can grow.
+			err = d.skip(typ)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if size, ok := entryCount(ins, outs); !ok || ins >= 1<<32 || outs >= 1<<32 || size != len(data) {
+		return nil, fmt.Errorf("%d entries for shape (%d, %d)", len(data), ins, outs)
+	}
+	return MatrixFromSlice(data, int(ins), int(outs), int(ins)), nil
+}
@@ -0,0 +1,65 @@
+package linear
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestMatrixProto(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2, 3, 4, 5, 6}, 3, 2, 3)
+
+	b := MarshalMatrixProto(A)
+
+	B, err := UnmarshalMatrixProto(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(A, B, t)
+
+	// The same message as protoc's encoding: ins, outs, then the
+	// packed doubles.
+	expect := []byte{0x08, 3, 0x10, 2, 0x1a, 48}
+	for k, c := range expect {
+		if b[k] != c {
+			t.Fatalf("expected prefix % x but got % x", expect, b[:len(expect)])
+		}
+	}
+}
+
+func TestMatrixProtoUnpackedAndUnknown(t *testing.T) {
+	// Older encoders may write repeated doubles unpacked, and newer
+	// schemas may add fields.
+	var e wireEncoder
+	e.varint(2, 2)
+	e.varint(1, 1)
+	e.bytes(9, []byte("future"))
+	for _, f := range []float64{7, 8} {
+		e.tag(3, wireFixed64)
+		e.data = binary.LittleEndian.AppendUint64(e.data, math.Float64bits(f))
+	}
+
+	A, err := UnmarshalMatrixProto(e.data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{7, 8}, 1, 2, 1), A, t)
+
+	if _, err := UnmarshalMatrixProto(MarshalMatrixProto(A)[:5]); err == nil {
+		t.Errorf("expected an error for a truncated message")
+	}
+	empty, err := UnmarshalMatrixProto(MarshalMatrixProto(NewArrayMatrix(0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, outs := empty.Shape()
+	ExpectInt(0, ins+outs, t)
+
+	// A shape whose number of entries wraps to 0 doesn't match no data.
+	var huge wireEncoder
+	huge.varint(1, 1<<32)
+	huge.varint(2, 1<<32)
+	if _, err := UnmarshalMatrixProto(huge.data); err == nil {
+		t.Errorf("expected an error for an overflowing shape")
+	}
+}
@@ -0,0 +1,130 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// QP solves convex quadratic programs
+//
+//	minimize Dual(x)*P*x/2 + Dual(q)*x subject to lower <= C*x <= upper
+//
+// for a symmetric positive semidefinite P, by ADMM split the way OSQP
+// does it: x is kept apart from z = C*x, the only linear system is with
+// P + Sigma*I + Rho*Dual(C)*C, which is factored once, and z is
+// projected onto the bounds. Bounds may be infinite, and equality
+// constraints have lower equal to upper.
+type QP struct {
+	// Rho is the penalty on C*x disagreeing with z.
+	Rho float64
+	// Sigma is a small regularization that keeps the system positive
+	// definite even if P is only semidefinite.
+	Sigma float64
+	// MaxIter is how many iterations to run before giving up.
+	MaxIter int
+	// AbsTol and RelTol decide when the primal and dual residuals are
+	// small enough to stop.
+	AbsTol, RelTol float64
+}
+
+// qpRelaxation mixes each new C*x with the previous z, which OSQP finds
+// speeds up convergence.
+const qpRelaxation = 1.6
+
+// Solve returns the minimizer and how many iterations it took.
+func (s *QP) Solve(P Matrix, q Vector, C Matrix, lower, upper Vector) (x Vector, iterations int) {
+	checkSquare(P)
+	CheckVector(q)
+	CheckVector(lower)
+	CheckVector(upper)
+	n, m := C.Shape()
+	CheckSameOuts(P, q)
+	CheckSameOuts(C, lower)
+	CheckSameOuts(C, upper)
+	if dim, _ := P.Shape(); dim != n {
+		panic(fmt.Errorf("P is %d by %d but C has %d ins", dim, dim, n))
+	}
+	for o := 0; o < m; o++ {
+		if lower.Get(0, o) > upper.Get(0, o) {
+			panic(fmt.Errorf("lower bound %g is above upper bound %g at %d", lower.Get(0, o), upper.Get(0, o), o))
+		}
+	}
+
+	rho, sigma := s.Rho, s.Sigma
+	K := Copy(Apply(Dual(C), C))
+	for o := 0; o < n; o++ {
+		for i := 0; i < n; i++ {
+			K.Set(i, o, P.Get(i, o)+rho*K.Get(i, o))
+		}
+		K.Set(o, o, K.Get(o, o)+sigma)
+	}
+	kkt := FactorCholesky(K)
+
+	x = NewVector(n)
+	z := NewVector(m)
+	y := NewVector(m)
+	for iterations = 1; iterations <= s.MaxIter; iterations++ {
+		// x~ minimizes the quadratic plus the penalties for straying
+		// from the previous x and from z.
+		rhs := NewVector(n)
+		w := NewVector(m)
+		addScaledInto(y, z, -rho, w)
+		CWt := Apply(Dual(C), w)
+		for d := 0; d < n; d++ {
+			rhs.Set(0, d, sigma*x.Get(0, d)-q.Get(0, d)-CWt.Get(0, d))
+		}
+		xt := kkt.SolveVec(rhs)
+		zt := Apply(C, xt)
+
+		for d := 0; d < n; d++ {
+			x.Set(0, d, qpRelaxation*xt.Get(0, d)+(1-qpRelaxation)*x.Get(0, d))
+		}
+		zPrev := Copy(z)
+		for o := 0; o < m; o++ {
+			relaxed := qpRelaxation*zt.Get(0, o) + (1-qpRelaxation)*zPrev.Get(0, o)
+			zo := math.Min(upper.Get(0, o), math.Max(lower.Get(0, o), relaxed+y.Get(0, o)/rho))
+			z.Set(0, o, zo)
+			y.Set(0, o, y.Get(0, o)+rho*(relaxed-zo))
+		}
+
+		// Primal: C*x against z. Dual: the gradient of the Lagrangian.
+		Cx := Apply(C, x)
+		Px := Apply(P, x)
+		Cty := Apply(Dual(C), y)
+		primal := maxAbsDifference(Cx, z)
+		grad := NewVector(n)
+		for d := 0; d < n; d++ {
+			grad.Set(0, d, Px.Get(0, d)+q.Get(0, d)+Cty.Get(0, d))
+		}
+		dual := MaxAbs(grad)
+		primalTol := s.AbsTol + s.RelTol*math.Max(MaxAbs(Cx), MaxAbs(z))
+		dualTol := s.AbsTol + s.RelTol*math.Max(MaxAbs(Px), math.Max(MaxAbs(Cty), MaxAbs(q)))
+		if primal <= primalTol && dual <= dualTol {
+			break
+		}
+	}
+	if iterations > s.MaxIter {
+		iterations = s.MaxIter
+	}
+	return x, iterations
+}
+
+// QuadraticProgram solves the quadratic program with default settings
+// that suit well scaled problems.
+func QuadraticProgram(P Matrix, q Vector, C Matrix, lower, upper Vector) Vector {
+	s := &QP{Rho: 0.1, Sigma: 1e-6, MaxIter: 10000, AbsTol: 1e-9, RelTol: 1e-9}
+	x, _ := s.Solve(P, q, C, lower, upper)
+	return x
+}
+
+func maxAbsDifference(A, B Matrix) float64 {
+	CheckSameShape(A, B)
+	ins, outs := A.Shape()
+	m := 0.0
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			m = math.Max(m, math.Abs(A.Get(i, o)-B.Get(i, o)))
+		}
+	}
+	return m
+}
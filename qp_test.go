@@ -0,0 +1,60 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func expectClose(expect, got, tol float64, t *testing.T) {
+	t.Helper()
+	if math.Abs(got-expect) > tol {
+		t.Errorf("expected %v but got %v", expect, got)
+	}
+}
+
+func TestQuadraticProgramBox(t *testing.T) {
+	// The closest point to a in the unit box is a clamped.
+	P := Identity(3)
+	q := NewVector(3)
+	q.Set(0, 0, -2)
+	q.Set(0, 1, 0.5)
+	q.Set(0, 2, -0.25)
+	lower := NewVector(3)
+	upper := NewVector(3)
+	for d := 0; d < 3; d++ {
+		upper.Set(0, d, 1)
+	}
+
+	x := QuadraticProgram(P, q, Identity(3), lower, upper)
+
+	expectClose(1, x.Get(0, 0), 1e-6, t)
+	expectClose(0, x.Get(0, 1), 1e-6, t)
+	expectClose(0.25, x.Get(0, 2), 1e-6, t)
+}
+
+func TestQuadraticProgramPortfolio(t *testing.T) {
+	// Minimum variance weights that sum to 1, with no short selling,
+	// for uncorrelated assets with variances 1, 4 and 100.
+	P := NewArrayMatrix(3, 3)
+	P.Set(0, 0, 1)
+	P.Set(1, 1, 4)
+	P.Set(2, 2, 100)
+	q := NewVector(3)
+	C := NewArrayMatrix(3, 4)
+	lower := NewVector(4)
+	upper := NewVector(4)
+	for i := 0; i < 3; i++ {
+		C.Set(i, 0, 1)
+		C.Set(i, i+1, 1)
+		upper.Set(0, i+1, math.Inf(1))
+	}
+	lower.Set(0, 0, 1)
+	upper.Set(0, 0, 1)
+
+	x := QuadraticProgram(P, q, C, lower, upper)
+
+	// Weights inversely proportional to variance: 100:25:1.
+	expectClose(100.0/126, x.Get(0, 0), 1e-6, t)
+	expectClose(25.0/126, x.Get(0, 1), 1e-6, t)
+	expectClose(1.0/126, x.Get(0, 2), 1e-6, t)
+}
@@ -0,0 +1,143 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// These update A = Q*R from DecomposeQR (with the full, square Q) when
+// a row or column is added to or taken away from A, with Givens
+// rotations that cost O(outs*ins) rather than refactoring. Together
+// they make rolling window least squares cheap: append each new
+// observation as a row of [X y] and remove the oldest, and the R of
+// [X y] solves the window's regression.
+
+// QRInsertRow returns the factors of A with row (a covector) inserted
+// before row k, given the factors of A.
+func QRInsertRow(Q, R Matrix, k int, row Matrix) (Q2, R2 Matrix) {
+	defer beginOp("QRUpdate")()
+	ins, outs := checkQRPair(Q, R)
+	CheckCovector(row)
+	CheckSameIns(row, R)
+	if k < 0 || k > outs {
+		panic(fmt.Errorf("can't insert row %d into %d rows", k, outs))
+	}
+
+	// With the new row on top, [row; A] = diag(1, Q)*[row; R], where
+	// [row; R] is upper Hessenberg and takes a rotation per column to
+	// make triangular again.
+	R2 = NewArrayMatrix(ins, outs+1)
+	CopyInto(row, Slice(R2, 0, ins, 0, 1))
+	CopyInto(R, Slice(R2, 0, ins, 1, outs+1))
+	Qa := NewArrayMatrix(outs+1, outs+1)
+	Qa.Set(0, 0, 1)
+	CopyInto(Q, Slice(Qa, 1, outs+1, 1, outs+1))
+	for j := 0; j < ins && j < outs; j++ {
+		g := NewGivens(R2.Get(j, j), R2.Get(j, j+1), j, j+1)
+		g.ApplyLeft(Slice(R2, j, ins, 0, outs+1))
+		R2.Set(j, j+1, 0)
+		g.Dual().ApplyRight(Qa)
+	}
+
+	// Then moving the row into place only permutes the rows of Q.
+	perm := make([]int, outs+1)
+	for o := range perm {
+		switch {
+		case o < k:
+			perm[o] = o + 1
+		case o == k:
+			perm[o] = 0
+		default:
+			perm[o] = o
+		}
+	}
+	return Copy(Gather(Qa, perm)), R2
+}
+
+// QRRemoveRow returns the factors of A without row k, given the factors
+// of A.
+func QRRemoveRow(Q, R Matrix, k int) (Q2, R2 Matrix) {
+	defer beginOp("QRUpdate")()
+	ins, outs := checkQRPair(Q, R)
+	if k < 0 || k >= outs {
+		panic(fmt.Errorf("can't remove row %d of %d rows", k, outs))
+	}
+
+	// Rotate row k of Q into e1 from the bottom up, keeping Q*R the
+	// same. Then the rest of column 0 of Q is zero and row 0 of R is
+	// row k of A, so both drop out.
+	Q1 := Copy(Q)
+	R1 := Copy(R)
+	for j := outs - 2; j >= 0; j-- {
+		g := NewGivens(Q1.Get(j, k), -Q1.Get(j+1, k), j, j+1)
+		g.ApplyRight(Q1)
+		g.Dual().ApplyLeft(R1)
+	}
+	rows := make([]int, 0, outs-1)
+	for o := 0; o < outs; o++ {
+		if o != k {
+			rows = append(rows, o)
+		}
+	}
+	Q2 = Copy(Slice(Gather(Q1, rows), 1, outs, 0, outs-1))
+	R2 = Copy(Slice(R1, 0, ins, 1, outs))
+	return Q2, R2
+}
+
+// QRInsertColumn returns the factors of A with col (a vector) inserted
+// before column k, given the factors of A.
+func QRInsertColumn(Q, R Matrix, k int, col Matrix) (Q2, R2 Matrix) {
+	defer beginOp("QRUpdate")()
+	ins, outs := checkQRPair(Q, R)
+	CheckVector(col)
+	CheckSameOuts(col, R)
+	if k < 0 || k > ins {
+		panic(fmt.Errorf("can't insert column %d into %d columns", k, ins))
+	}
+
+	// Dual(Q)*col goes in as the new column of R, and rotations from
+	// the bottom up zero it below the diagonal, only filling in the
+	// diagonal of the columns after it.
+	R2 = NewArrayMatrix(ins+1, outs)
+	CopyInto(Slice(R, 0, k, 0, outs), Slice(R2, 0, k, 0, outs))
+	CopyInto(Apply(Dual(Q), col), Slice(R2, k, k+1, 0, outs))
+	CopyInto(Slice(R, k, ins, 0, outs), Slice(R2, k+1, ins+1, 0, outs))
+	Q2 = Copy(Q)
+	for o := outs - 1; o > k; o-- {
+		g := NewGivens(R2.Get(k, o-1), R2.Get(k, o), o-1, o)
+		g.ApplyLeft(Slice(R2, k, ins+1, 0, outs))
+		R2.Set(k, o, 0)
+		g.Dual().ApplyRight(Q2)
+	}
+	return Q2, R2
+}
+
+// QRRemoveColumn returns the factors of A without column k, given the
+// factors of A.
+func QRRemoveColumn(Q, R Matrix, k int) (Q2, R2 Matrix) {
+	defer beginOp("QRUpdate")()
+	ins, outs := checkQRPair(Q, R)
+	if k < 0 || k >= ins {
+		panic(fmt.Errorf("can't remove column %d of %d columns", k, ins))
+	}
+
+	// Without column k, R is upper Hessenberg from there on.
+	R2 = NewArrayMatrix(ins-1, outs)
+	CopyInto(Slice(R, 0, k, 0, outs), Slice(R2, 0, k, 0, outs))
+	CopyInto(Slice(R, k+1, ins, 0, outs), Slice(R2, k, ins-1, 0, outs))
+	Q2 = Copy(Q)
+	for j := k; j < ins-1 && j+1 < outs; j++ {
+		g := NewGivens(R2.Get(j, j), R2.Get(j, j+1), j, j+1)
+		g.ApplyLeft(Slice(R2, j, ins-1, 0, outs))
+		R2.Set(j, j+1, 0)
+		g.Dual().ApplyRight(Q2)
+	}
+	return Q2, R2
+}
+
+// checkQRPair checks that Q is square and matches R, returning R's
+// shape.
+func checkQRPair(Q, R Matrix) (ins, outs int) {
+	checkSquare(Q)
+	CheckSameOuts(Q, R)
+	return R.Shape()
+}
@@ -0,0 +1,108 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomMatrix(rng *rand.Rand, ins, outs int) Matrix {
+	A := NewArrayMatrix(ins, outs)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			A.Set(i, o, rng.NormFloat64())
+		}
+	}
+	return A
+}
+
+// expectQR checks that Q*R is A with Q orthogonal and R upper
+// triangular.
+func expectQR(t *testing.T, A, Q, R Matrix) {
+	t.Helper()
+	ins, outs := A.Shape()
+	ExpectMatrix(Identity(outs), Compose(Q, Dual(Q)), t)
+	ExpectMatrix(A, Apply(Q, R), t)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < o && i < ins; i++ {
+			ExpectFloat(0, R.Get(i, o), t)
+		}
+	}
+}
+
+func TestQRInsertRemoveRow(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	A := randomMatrix(rng, 3, 5)
+	Q, R := DecomposeQR(A)
+	row := randomMatrix(rng, 3, 1)
+
+	for _, k := range []int{0, 2, 5} {
+		Q2, R2 := QRInsertRow(Q, R, k, row)
+		rows := []Matrix{}
+		for o := 0; o < 5; o++ {
+			if o == k {
+				rows = append(rows, row)
+			}
+			rows = append(rows, Slice(A, 0, 3, o, o+1))
+		}
+		if k == 5 {
+			rows = append(rows, row)
+		}
+		A2 := NewArrayMatrix(3, 6)
+		for o, r := range rows {
+			CopyInto(r, Slice(A2, 0, 3, o, o+1))
+		}
+		expectQR(t, A2, Q2, R2)
+
+		Q3, R3 := QRRemoveRow(Q2, R2, k)
+		expectQR(t, A, Q3, R3)
+	}
+	expectPanic(t, func() { QRRemoveRow(Q, R, 5) })
+}
+
+func TestQRInsertRemoveColumn(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	A := randomMatrix(rng, 3, 5)
+	Q, R := DecomposeQR(A)
+	col := randomMatrix(rng, 1, 5)
+
+	for _, k := range []int{0, 1, 3} {
+		Q2, R2 := QRInsertColumn(Q, R, k, col)
+		A2 := NewArrayMatrix(4, 5)
+		CopyInto(Slice(A, 0, k, 0, 5), Slice(A2, 0, k, 0, 5))
+		CopyInto(col, Slice(A2, k, k+1, 0, 5))
+		CopyInto(Slice(A, k, 3, 0, 5), Slice(A2, k+1, 4, 0, 5))
+		expectQR(t, A2, Q2, R2)
+
+		Q3, R3 := QRRemoveColumn(Q2, R2, k)
+		expectQR(t, A, Q3, R3)
+	}
+}
+
+func TestQRRollingWindow(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	// Observations of [x 1 y] with y = 2x + 1, in a window of 4.
+	observation := func() Matrix {
+		x := rng.NormFloat64()
+		return MatrixFromSlice([]float64{x, 1, 2*x + 1 + 0.01*rng.NormFloat64()}, 3, 1, 3)
+	}
+	window := []Matrix{observation(), observation(), observation(), observation()}
+	A := NewArrayMatrix(3, 4)
+	for o, r := range window {
+		CopyInto(r, Slice(A, 0, 3, o, o+1))
+	}
+	Q, R := DecomposeQR(A)
+	for step := 0; step < 20; step++ {
+		next := observation()
+		Q, R = QRInsertRow(Q, R, 4, next)
+		Q, R = QRRemoveRow(Q, R, 0)
+		window = append(window[1:], next)
+	}
+	for o, r := range window {
+		CopyInto(r, Slice(A, 0, 3, o, o+1))
+	}
+	expectQR(t, A, Q, R)
+
+	// The top of R solves the window's regression.
+	theta := FindInputUpperTriangular(Slice(R, 0, 2, 0, 2), Slice(R, 2, 3, 0, 2))
+	ExpectMatrix(OrdinaryLeastSquares(Slice(A, 0, 2, 0, 4), Slice(A, 2, 3, 0, 4)), theta, t)
+}
@@ -0,0 +1,107 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// Int8Matrix is a Matrix quantized to int8 with a scale and zero point
+// for each output (row): entry q in row o stands for
+// Scales[o]*(q - ZeroPoints[o]). Each row's range is mapped onto the
+// 256 levels, so rows of very different sizes don't cost each other
+// accuracy, and zero is always exact.
+type Int8Matrix struct {
+	ins, outs  int
+	data       []int8
+	Scales     []float64
+	ZeroPoints []int8
+}
+
+// Quantize rounds A to an Int8Matrix, choosing each row's scale and
+// zero point from its smallest and largest entries. Entries are off by
+// at most half their row's scale.
+func Quantize(A Matrix) *Int8Matrix {
+	ins, outs := A.Shape()
+	q := &Int8Matrix{
+		ins:        ins,
+		outs:       outs,
+		data:       make([]int8, ins*outs),
+		Scales:     make([]float64, outs),
+		ZeroPoints: make([]int8, outs),
+	}
+	for o := 0; o < outs; o++ {
+		lo, hi := 0.0, 0.0
+		for i := 0; i < ins; i++ {
+			f := A.Get(i, o)
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				panic(fmt.Errorf("can't quantize %v at (%d, %d)", f, i, o))
+			}
+			lo, hi = math.Min(lo, f), math.Max(hi, f)
+		}
+		scale := (hi - lo) / 255
+		if scale == 0 {
+			scale = 1
+		}
+		q.Scales[o] = scale
+		q.ZeroPoints[o] = int8(clampInt8(math.Round(-128 - lo/scale)))
+		for i := 0; i < ins; i++ {
+			q.Set(i, o, A.Get(i, o))
+		}
+	}
+	return q
+}
+
+func clampInt8(f float64) float64 {
+	return math.Max(-128, math.Min(127, f))
+}
+
+func (q *Int8Matrix) Shape() (ins, outs int) { return q.ins, q.outs }
+
+func (q *Int8Matrix) Get(in, out int) float64 {
+	return q.Scales[out] * float64(int(q.data[q.offset(in, out)])-int(q.ZeroPoints[out]))
+}
+
+// Set quantizes value with the row's scale and zero point, clamping it
+// to the row's range.
+func (q *Int8Matrix) Set(in, out int, value float64) {
+	level := math.Round(value/q.Scales[out]) + float64(q.ZeroPoints[out])
+	q.data[q.offset(in, out)] = int8(clampInt8(level))
+}
+
+// Levels returns the quantized entries, output (row) by output.
+func (q *Int8Matrix) Levels() []int8 { return q.data }
+
+func (q *Int8Matrix) offset(in, out int) int {
+	if in < 0 || in >= q.ins || out < 0 || out >= q.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds (%d, %d)", in, out, q.ins, q.outs))
+	}
+	return out*q.ins + in
+}
+
+// QuantizedProduct returns A*Dual(B) for quantized A and B, like
+// X*Dual(W) for a batch of inputs X and a layer's weights W with a row
+// for each output. Since each entry pairs a row of A with a row of B,
+// the products of their levels are summed exactly in integers and only
+// then scaled by the two rows' scales, as int8 inference hardware does.
+func QuantizedProduct(A, B *Int8Matrix) Matrix {
+	if A.ins != B.ins {
+		panic(fmt.Errorf("rows of %d and %d entries", A.ins, B.ins))
+	}
+	k := A.ins
+	countFlops(2 * A.outs * B.outs * k)
+	C := NewArrayMatrix(B.outs, A.outs)
+	for o := 0; o < A.outs; o++ {
+		a := A.data[o*k : (o+1)*k]
+		za := int64(A.ZeroPoints[o])
+		for p := 0; p < B.outs; p++ {
+			b := B.data[p*k : (p+1)*k]
+			zb := int64(B.ZeroPoints[p])
+			var sum int64
+			for j, qa := range a {
+				sum += (int64(qa) - za) * (int64(b[j]) - zb)
+			}
+			C.Set(p, o, A.Scales[o]*B.Scales[p]*float64(sum))
+		}
+	}
+	return C
+}
@@ -0,0 +1,55 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestQuantize(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		-1, 0, 0.5, 3,
+		100, 200, 50, 0,
+		0, 0, 0, 0,
+	}, 4, 3, 4)
+	q := Quantize(A)
+	for o := 0; o < 3; o++ {
+		for i := 0; i < 4; i++ {
+			if err := math.Abs(q.Get(i, o) - A.Get(i, o)); err > q.Scales[o]/2+1e-12 {
+				t.Errorf("(%d, %d) is off by %v with scale %v", i, o, err, q.Scales[o])
+			}
+		}
+	}
+	// Zero is always exact.
+	ExpectFloat(0, q.Get(1, 0), t)
+	ExpectFloat(0, q.Get(3, 1), t)
+	ExpectFloat(4.0/255, q.Scales[0], t)
+	ExpectInt(12, len(q.Levels()), t)
+
+	// Set clamps to the row's range.
+	q.Set(0, 0, 1000)
+	ExpectFloat(q.Scales[0]*float64(127-int(q.ZeroPoints[0])), q.Get(0, 0), t)
+	expectPanic(t, func() { Quantize(MatrixFromSlice([]float64{math.Inf(1)}, 1, 1, 1)) })
+}
+
+func TestQuantizedProduct(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	X := randomMatrix(rng, 16, 5)
+	W := randomMatrix(rng, 16, 3)
+	qX, qW := Quantize(X), Quantize(W)
+
+	got := QuantizedProduct(qX, qW)
+
+	// Exactly the product of the dequantized matrices, and close to the
+	// real one.
+	ExpectMatrix(Apply(Copy(qX), Dual(Copy(qW))), got, t)
+	exact := Apply(X, Dual(W))
+	for o := 0; o < 5; o++ {
+		for i := 0; i < 3; i++ {
+			if math.Abs(got.Get(i, o)-exact.Get(i, o)) > 0.1 {
+				t.Errorf("expected %f but got %f", exact.Get(i, o), got.Get(i, o))
+			}
+		}
+	}
+	expectPanic(t, func() { QuantizedProduct(qX, Quantize(NewArrayMatrix(4, 2))) })
+}
@@ -0,0 +1,165 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// Quaternion is W + X*i + Y*j + Z*k. Unit quaternions represent
+// rotations in 3 dimensions, with q and -q the same rotation.
+type Quaternion struct {
+	W, X, Y, Z float64
+}
+
+// QuaternionFromAxisAngle returns the unit quaternion that rotates by
+// angle radians around axis, counterclockwise looking down the axis.
+func QuaternionFromAxisAngle(axis Vector, angle float64) Quaternion {
+	checkFixedVector(axis, 3)
+	n := math.Sqrt(DotProduct(axis, Dual(axis)))
+	if n == 0 {
+		panic(fmt.Errorf("can't rotate around a zero axis"))
+	}
+	s := math.Sin(angle/2) / n
+	return Quaternion{math.Cos(angle / 2), axis.Get(0, 0) * s, axis.Get(0, 1) * s, axis.Get(0, 2) * s}
+}
+
+// QuaternionFromRotationMatrix returns the unit quaternion for the 3x3
+// rotation matrix R, with a non-negative W.
+func QuaternionFromRotationMatrix(R Matrix) Quaternion {
+	ins, outs := R.Shape()
+	if ins != 3 || outs != 3 {
+		panic(fmt.Errorf("expected a 3x3 rotation but got (%d, %d)", ins, outs))
+	}
+	r := func(row, col int) float64 { return R.Get(col, row) }
+	// Solving for the largest component first avoids dividing by one
+	// that's close to zero.
+	var q Quaternion
+	switch trace := r(0, 0) + r(1, 1) + r(2, 2); {
+	case trace > 0:
+		s := 2 * math.Sqrt(1+trace)
+		q = Quaternion{s / 4, (r(2, 1) - r(1, 2)) / s, (r(0, 2) - r(2, 0)) / s, (r(1, 0) - r(0, 1)) / s}
+	case r(0, 0) > r(1, 1) && r(0, 0) > r(2, 2):
+		s := 2 * math.Sqrt(1+r(0, 0)-r(1, 1)-r(2, 2))
+		q = Quaternion{(r(2, 1) - r(1, 2)) / s, s / 4, (r(0, 1) + r(1, 0)) / s, (r(0, 2) + r(2, 0)) / s}
+	case r(1, 1) > r(2, 2):
+		s := 2 * math.Sqrt(1+r(1, 1)-r(0, 0)-r(2, 2))
+		q = Quaternion{(r(0, 2) - r(2, 0)) / s, (r(0, 1) + r(1, 0)) / s, s / 4, (r(1, 2) + r(2, 1)) / s}
+	default:
+		s := 2 * math.Sqrt(1+r(2, 2)-r(0, 0)-r(1, 1))
+		q = Quaternion{(r(1, 0) - r(0, 1)) / s, (r(0, 2) + r(2, 0)) / s, (r(1, 2) + r(2, 1)) / s, s / 4}
+	}
+	if q.W < 0 {
+		q = q.Scale(-1)
+	}
+	return q.Normalize()
+}
+
+// Norm returns the length of q as a 4 dimensional vector.
+func (q Quaternion) Norm() float64 {
+	return math.Sqrt(q.W*q.W + q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+}
+
+// Normalize returns q scaled to unit length.
+func (q Quaternion) Normalize() Quaternion {
+	n := q.Norm()
+	if n == 0 {
+		panic(fmt.Errorf("can't normalize a zero quaternion"))
+	}
+	return q.Scale(1 / n)
+}
+
+// Scale returns q with every component multiplied by s.
+func (q Quaternion) Scale(s float64) Quaternion {
+	return Quaternion{q.W * s, q.X * s, q.Y * s, q.Z * s}
+}
+
+// Conjugate returns q with the imaginary components negated, which for
+// a unit quaternion is the inverse rotation.
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{q.W, -q.X, -q.Y, -q.Z}
+}
+
+// Multiply returns q*p, the rotation p followed by q.
+func (q Quaternion) Multiply(p Quaternion) Quaternion {
+	return Quaternion{
+		q.W*p.W - q.X*p.X - q.Y*p.Y - q.Z*p.Z,
+		q.W*p.X + q.X*p.W + q.Y*p.Z - q.Z*p.Y,
+		q.W*p.Y - q.X*p.Z + q.Y*p.W + q.Z*p.X,
+		q.W*p.Z + q.X*p.Y - q.Y*p.X + q.Z*p.W,
+	}
+}
+
+// Dot returns the dot product of q and p as 4 dimensional vectors.
+func (q Quaternion) Dot(p Quaternion) float64 {
+	return q.W*p.W + q.X*p.X + q.Y*p.Y + q.Z*p.Z
+}
+
+// Rotate returns v rotated by the unit quaternion q.
+func (q Quaternion) Rotate(v Vector) Vector {
+	checkFixedVector(v, 3)
+	p := q.Multiply(Quaternion{0, v.Get(0, 0), v.Get(0, 1), v.Get(0, 2)}).Multiply(q.Conjugate())
+	w := NewVector(3)
+	w.Set(0, 0, p.X)
+	w.Set(0, 1, p.Y)
+	w.Set(0, 2, p.Z)
+	return w
+}
+
+// RotationMatrix returns the 3x3 rotation matrix for the unit
+// quaternion q, so that Apply(R, v) is q.Rotate(v).
+func (q Quaternion) RotationMatrix() Matrix {
+	w, x, y, z := q.W, q.X, q.Y, q.Z
+	return Matrix3{
+		{1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y)},
+		{2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x)},
+		{2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y)},
+	}.Matrix()
+}
+
+// AxisAngle returns the unit axis and the angle, in [0, pi], of the
+// rotation by the unit quaternion q. The identity rotation has no
+// particular axis, so it's given as the first basis vector.
+func (q Quaternion) AxisAngle() (axis Vector, angle float64) {
+	if q.W < 0 {
+		q = q.Scale(-1)
+	}
+	axis = NewVector(3)
+	s := math.Sqrt(q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+	if s == 0 {
+		axis.Set(0, 0, 1)
+		return axis, 0
+	}
+	axis.Set(0, 0, q.X/s)
+	axis.Set(0, 1, q.Y/s)
+	axis.Set(0, 2, q.Z/s)
+	return axis, 2 * math.Atan2(s, q.W)
+}
+
+// Slerp spherically interpolates between the unit quaternions p and q,
+// returning p at t = 0 and q at t = 1 and rotating at a constant rate
+// in between, the short way around.
+func Slerp(p, q Quaternion, t float64) Quaternion {
+	d := p.Dot(q)
+	if d < 0 {
+		q, d = q.Scale(-1), -d
+	}
+	// Nearly parallel quaternions would divide by a tiny sine, but a
+	// straight line is just as good there.
+	if d > 0.9995 {
+		return Quaternion{
+			p.W + t*(q.W-p.W),
+			p.X + t*(q.X-p.X),
+			p.Y + t*(q.Y-p.Y),
+			p.Z + t*(q.Z-p.Z),
+		}.Normalize()
+	}
+	theta := math.Acos(d)
+	a := math.Sin((1-t)*theta) / math.Sin(theta)
+	b := math.Sin(t*theta) / math.Sin(theta)
+	return Quaternion{
+		a*p.W + b*q.W,
+		a*p.X + b*q.X,
+		a*p.Y + b*q.Y,
+		a*p.Z + b*q.Z,
+	}
+}
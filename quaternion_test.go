@@ -0,0 +1,70 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func vector3(x, y, z float64) Vector {
+	v := NewVector(3)
+	v.Set(0, 0, x)
+	v.Set(0, 1, y)
+	v.Set(0, 2, z)
+	return v
+}
+
+func TestQuaternionRotation(t *testing.T) {
+	// A quarter turn around z takes x to y.
+	q := QuaternionFromAxisAngle(vector3(0, 0, 2), math.Pi/2)
+
+	ExpectFloat(1, q.Norm(), t)
+	ExpectMatrix(vector3(0, 1, 0), q.Rotate(vector3(1, 0, 0)), t)
+	ExpectMatrix(MatrixFromSlice([]float64{
+		0, -1, 0,
+		1, 0, 0,
+		0, 0, 1,
+	}, 3, 3, 3), q.RotationMatrix(), t)
+}
+
+func TestQuaternionRoundTrips(t *testing.T) {
+	axis := vector3(1, -2, 0.5)
+	n := math.Sqrt(5.25)
+	unit := vector3(1/n, -2/n, 0.5/n)
+	for _, angle := range []float64{0.1, 1, 2, 3, math.Pi} {
+		q := QuaternionFromAxisAngle(axis, angle)
+		R := q.RotationMatrix()
+
+		p := QuaternionFromRotationMatrix(R)
+		ExpectFloat(1, math.Abs(p.Dot(q)), t)
+		v := vector3(0.3, 0.7, -1.1)
+		ExpectMatrix(q.Rotate(v), Apply(R, v), t)
+
+		a, theta := q.AxisAngle()
+		ExpectFloat(angle, theta, t)
+		ExpectMatrix(unit, a, t)
+	}
+}
+
+func TestQuaternionMultiply(t *testing.T) {
+	p := QuaternionFromAxisAngle(vector3(1, 0, 0), 0.4)
+	q := QuaternionFromAxisAngle(vector3(0, 1, 0), 1.3)
+	v := vector3(1, 2, 3)
+
+	ExpectMatrix(q.Rotate(p.Rotate(v)), q.Multiply(p).Rotate(v), t)
+	ExpectMatrix(v, p.Conjugate().Rotate(p.Rotate(v)), t)
+}
+
+func TestSlerp(t *testing.T) {
+	z := vector3(0, 0, 1)
+	p := QuaternionFromAxisAngle(z, 0.2)
+	q := QuaternionFromAxisAngle(z, 1.4)
+
+	for _, s := range []float64{0, 0.25, 0.5, 1} {
+		_, angle := Slerp(p, q, s).AxisAngle()
+		ExpectFloat(0.2+s*1.2, angle, t)
+	}
+	// The negation of q is the same rotation, and slerp still goes the
+	// short way.
+	_, angle := Slerp(p, q.Scale(-1), 0.5).AxisAngle()
+	ExpectFloat(0.8, angle, t)
+}
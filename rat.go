@@ -0,0 +1,192 @@
+package linear
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RatMatrix is a Matrix of exact rational numbers. As a Matrix it
+// reads entries as the nearest float64 and sets them to exactly the
+// float64 given, so it can be filled from and compared with ordinary
+// matrices, but GetRat and SetRat work with the exact values.
+type RatMatrix struct {
+	ins, outs int
+	// entries are by output (row), then input.
+	entries []*big.Rat
+}
+
+// NewRatMatrix makes a new zero RatMatrix with the given shape.
+func NewRatMatrix(ins, outs int) *RatMatrix {
+	if ins < 0 || outs < 0 {
+		panic(fmt.Errorf("invalid shape (%d, %d)", ins, outs))
+	}
+	m := &RatMatrix{ins: ins, outs: outs, entries: make([]*big.Rat, ins*outs)}
+	for j := range m.entries {
+		m.entries[j] = new(big.Rat)
+	}
+	return m
+}
+
+// RatMatrixFrom makes a RatMatrix with exactly the entries of A.
+func RatMatrixFrom(A Matrix) *RatMatrix {
+	ins, outs := A.Shape()
+	m := NewRatMatrix(ins, outs)
+	CopyInto(A, m)
+	return m
+}
+
+func (m *RatMatrix) Shape() (ins, outs int) { return m.ins, m.outs }
+
+func (m *RatMatrix) Get(in, out int) float64 {
+	f, _ := m.GetRat(in, out).Float64()
+	return f
+}
+
+func (m *RatMatrix) Set(in, out int, value float64) {
+	m.checkBounds(in, out)
+	if m.entries[out*m.ins+in].SetFloat64(value) == nil {
+		panic(fmt.Errorf("%g isn't a rational number", value))
+	}
+}
+
+// GetRat returns the exact entry, which must not be modified.
+func (m *RatMatrix) GetRat(in, out int) *big.Rat {
+	m.checkBounds(in, out)
+	return m.entries[out*m.ins+in]
+}
+
+// SetRat sets the entry to a copy of value.
+func (m *RatMatrix) SetRat(in, out int, value *big.Rat) {
+	m.checkBounds(in, out)
+	m.entries[out*m.ins+in].Set(value)
+}
+
+func (m *RatMatrix) checkBounds(in, out int) {
+	if in < 0 || in >= m.ins || out < 0 || out >= m.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds for shape (%d, %d)", in, out, m.ins, m.outs))
+	}
+}
+
+// Copy returns a RatMatrix with the same exact entries.
+func (m *RatMatrix) Copy() *RatMatrix {
+	c := NewRatMatrix(m.ins, m.outs)
+	for j, r := range m.entries {
+		c.entries[j].Set(r)
+	}
+	return c
+}
+
+// RREF returns the reduced row echelon form of A, computed exactly by
+// Gauss-Jordan elimination, and the column (input) of the pivot in each
+// non-zero row, so len(pivots) is the rank of A.
+func RREF(A *RatMatrix) (R *RatMatrix, pivots []int) {
+	R = A.Copy()
+	t := new(big.Rat)
+	row := 0
+	for col := 0; col < R.ins && row < R.outs; col++ {
+		// Any non-zero pivot will do since there's no rounding.
+		p := row
+		for p < R.outs && R.GetRat(col, p).Sign() == 0 {
+			p++
+		}
+		if p == R.outs {
+			continue
+		}
+		R.swapRows(row, p)
+
+		inv := new(big.Rat).Inv(R.GetRat(col, row))
+		for i := col; i < R.ins; i++ {
+			e := R.GetRat(i, row)
+			e.Mul(e, inv)
+		}
+		for o := 0; o < R.outs; o++ {
+			f := R.GetRat(col, o)
+			if o == row || f.Sign() == 0 {
+				continue
+			}
+			f = new(big.Rat).Set(f)
+			for i := col; i < R.ins; i++ {
+				e := R.GetRat(i, o)
+				e.Sub(e, t.Mul(f, R.GetRat(i, row)))
+			}
+		}
+		pivots = append(pivots, col)
+		row++
+	}
+	return R, pivots
+}
+
+// SolveRat finds the exact x such that A*x = B for a square invertible
+// A, with a column of x for each column (input) of B. It panics if A is
+// singular.
+func SolveRat(A, B *RatMatrix) *RatMatrix {
+	if A.ins != A.outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", A.ins, A.outs))
+	}
+	if B.outs != A.outs {
+		panic(fmt.Errorf("dimension mismatch %d vs %d", A.outs, B.outs))
+	}
+	// Eliminating on [A | B] leaves [I | x].
+	n := A.ins
+	AB := NewRatMatrix(n+B.ins, n)
+	for o := 0; o < n; o++ {
+		for i := 0; i < n; i++ {
+			AB.SetRat(i, o, A.GetRat(i, o))
+		}
+		for i := 0; i < B.ins; i++ {
+			AB.SetRat(n+i, o, B.GetRat(i, o))
+		}
+	}
+	R, pivots := RREF(AB)
+	if len(pivots) < n || pivots[n-1] != n-1 {
+		panic(fmt.Errorf("singular matrix"))
+	}
+	x := NewRatMatrix(B.ins, n)
+	for o := 0; o < n; o++ {
+		for i := 0; i < B.ins; i++ {
+			x.SetRat(i, o, R.GetRat(n+i, o))
+		}
+	}
+	return x
+}
+
+// DetRat returns the exact determinant of the square A.
+func DetRat(A *RatMatrix) *big.Rat {
+	if A.ins != A.outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", A.ins, A.outs))
+	}
+	U := A.Copy()
+	det := big.NewRat(1, 1)
+	t := new(big.Rat)
+	for col := 0; col < U.ins; col++ {
+		p := col
+		for p < U.outs && U.GetRat(col, p).Sign() == 0 {
+			p++
+		}
+		if p == U.outs {
+			return new(big.Rat)
+		}
+		if p != col {
+			U.swapRows(col, p)
+			det.Neg(det)
+		}
+		pivot := U.GetRat(col, col)
+		det.Mul(det, pivot)
+		for o := col + 1; o < U.outs; o++ {
+			f := new(big.Rat).Quo(U.GetRat(col, o), pivot)
+			for i := col; i < U.ins; i++ {
+				e := U.GetRat(i, o)
+				e.Sub(e, t.Mul(f, U.GetRat(i, col)))
+			}
+		}
+	}
+	return det
+}
+
+func (m *RatMatrix) swapRows(a, b int) {
+	ra := m.entries[a*m.ins : (a+1)*m.ins]
+	rb := m.entries[b*m.ins : (b+1)*m.ins]
+	for i := range ra {
+		ra[i], rb[i] = rb[i], ra[i]
+	}
+}
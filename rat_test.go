@@ -0,0 +1,69 @@
+package linear
+
+import (
+	"math/big"
+	"testing"
+)
+
+func expectRat(expect string, got *big.Rat, t *testing.T) {
+	e, ok := new(big.Rat).SetString(expect)
+	if !ok {
+		panic("bad rational " + expect)
+	}
+	if e.Cmp(got) != 0 {
+		t.Errorf("expected %s but got %s", e.RatString(), got.RatString())
+	}
+}
+
+func TestRREF(t *testing.T) {
+	A := RatMatrixFrom(MatrixFromSlice([]float64{
+		1, 2, 1, 4,
+		2, 4, 0, 2,
+		3, 6, 1, 6,
+	}, 4, 3, 4))
+
+	R, pivots := RREF(A)
+
+	ExpectInt(2, len(pivots), t)
+	ExpectInt(0, pivots[0], t)
+	ExpectInt(2, pivots[1], t)
+	ExpectMatrix(MatrixFromSlice([]float64{
+		1, 2, 0, 1,
+		0, 0, 1, 3,
+		0, 0, 0, 0,
+	}, 4, 3, 4), R, t)
+}
+
+func TestSolveRat(t *testing.T) {
+	// The Hilbert matrix is badly conditioned, but exact arithmetic
+	// doesn't care.
+	n := 6
+	H := NewRatMatrix(n, n)
+	b := NewRatMatrix(1, n)
+	for o := 0; o < n; o++ {
+		sum := new(big.Rat)
+		for i := 0; i < n; i++ {
+			h := big.NewRat(1, int64(i+o+1))
+			H.SetRat(i, o, h)
+			sum.Add(sum, h)
+		}
+		b.SetRat(0, o, sum)
+	}
+
+	x := SolveRat(H, b)
+
+	for o := 0; o < n; o++ {
+		expectRat("1", x.GetRat(0, o), t)
+	}
+	expectRat("1/186313420339200000", DetRat(H), t)
+}
+
+func TestDetRat(t *testing.T) {
+	A := RatMatrixFrom(MatrixFromSlice([]float64{
+		0, 2, 1,
+		1, 0.5, 0,
+		3, 0, 4,
+	}, 3, 3, 3))
+
+	expectRat("-19/2", DetRat(A), t)
+}
@@ -0,0 +1,90 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// EstimateRigidTransform finds the rotation R, translation t and scale
+// that best map the points src onto the points dst in the least
+// squares sense, so that dst is approximately scale*R*src + t. Each
+// point is an observation (output), with a column (input) per
+// dimension, and src and dst must list corresponding points in the same
+// order. This is the Umeyama algorithm: R comes from the SVD of the
+// covariance between the centered point sets, with the sign of the last
+// singular direction flipped when needed so that R is a rotation and
+// not a reflection.
+func EstimateRigidTransform(src, dst Matrix) (R Matrix, t Vector, scale float64) {
+	CheckSameShape(src, dst)
+	dim, n := src.Shape()
+	if n == 0 {
+		panic(fmt.Errorf("can't register no points"))
+	}
+
+	srcMean, dstMean := ColumnMeans(src), ColumnMeans(dst)
+	S, D := center(src, srcMean), center(dst, dstMean)
+	C := Apply(Dual(D), S)
+	U, sigma, V := DecomposeSVD(C)
+	U = completeOrthonormal(U, sigma)
+
+	// Flipping the smallest singular direction costs the least when
+	// U*Dual(V) would otherwise be a reflection.
+	signs := make([]float64, dim)
+	for d := range signs {
+		signs[d] = 1
+	}
+	if FactorLU(U).Det()*FactorLU(V).Det() < 0 {
+		signs[dim-1] = -1
+	}
+	US := Copy(U)
+	trace := 0.0
+	for i := 0; i < dim; i++ {
+		trace += sigma.Get(0, i) * signs[i]
+		for o := 0; o < dim; o++ {
+			US.Set(i, o, US.Get(i, o)*signs[i])
+		}
+	}
+	R = Apply(US, Dual(V))
+
+	variance := frobeniusNorm(S)
+	variance *= variance
+	if variance == 0 {
+		panic(fmt.Errorf("can't estimate a transform from coincident points"))
+	}
+	scale = trace / variance
+
+	t = Apply(R, Dual(srcMean))
+	for d := 0; d < dim; d++ {
+		t.Set(0, d, dstMean.Get(d, 0)-scale*t.Get(0, d))
+	}
+	return R, t, scale
+}
+
+// completeOrthonormal replaces the columns of the square U that
+// DecomposeSVD left without a direction, because their singular values
+// are zero (or too small to trust), with ones orthonormal to the rest.
+func completeOrthonormal(U, sigma Matrix) Matrix {
+	dim, _ := U.Shape()
+	U = Copy(U)
+	tol := 1e-12 * sigma.Get(0, 0)
+	for i := 0; i < dim; i++ {
+		if sigma.Get(0, i) > tol {
+			continue
+		}
+		// Try each basis vector until one has a part orthogonal to the
+		// earlier columns.
+		for e := 0; e < dim; e++ {
+			u := BasisVector(dim, e)
+			for j := 0; j < i; j++ {
+				uj := Slice(U, j, j+1, 0, dim)
+				addScaledInto(u, uj, -DotProduct(uj, Dual(u)), u)
+			}
+			if mag := L2Norm(u); mag > 0.5 {
+				for o := 0; o < dim; o++ {
+					U.Set(i, o, u.Get(0, o)/mag)
+				}
+				break
+			}
+		}
+	}
+	return U
+}
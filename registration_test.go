@@ -0,0 +1,59 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEstimateRigidTransform(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	n := 10
+	src := NewArrayMatrix(3, n)
+	for o := 0; o < n; o++ {
+		for i := 0; i < 3; i++ {
+			src.Set(i, o, r.NormFloat64())
+		}
+	}
+	rotation := QuaternionFromAxisAngle(vector3(1, 2, 3), 2.1).RotationMatrix()
+	translation := vector3(1, -2, 0.5)
+	dst := transformPoints(src, rotation, translation, 1.7)
+
+	R, tr, scale := EstimateRigidTransform(src, dst)
+
+	ExpectMatrix(rotation, R, t)
+	ExpectMatrix(translation, tr, t)
+	ExpectFloat(1.7, scale, t)
+}
+
+func TestEstimateRigidTransformPlanar(t *testing.T) {
+	// Points in a plane leave a singular direction, and the rotation
+	// must still not be a reflection.
+	src := MatrixFromSlice([]float64{
+		0, 0, 0,
+		1, 0, 0,
+		0, 1, 0,
+		1, 1, 0,
+		2, 0.5, 0,
+	}, 3, 5, 3)
+	rotation := RotationFromEuler(0.3, -0.2, 1.1, ZYX)
+	dst := transformPoints(src, rotation, vector3(0, 0, 4), 1)
+
+	R, tr, scale := EstimateRigidTransform(src, dst)
+
+	ExpectMatrix(rotation, R, t)
+	ExpectMatrix(vector3(0, 0, 4), tr, t)
+	ExpectFloat(1, scale, t)
+	ExpectFloat(1, FactorLU(R).Det(), t)
+}
+
+// transformPoints maps each observation x of X to scale*R*x + t.
+func transformPoints(X, R Matrix, t Vector, scale float64) Matrix {
+	dim, n := X.Shape()
+	Y := Apply(X, Dual(R))
+	for o := 0; o < n; o++ {
+		for i := 0; i < dim; i++ {
+			Y.Set(i, o, scale*Y.Get(i, o)+t.Get(0, i))
+		}
+	}
+	return Y
+}
@@ -0,0 +1,124 @@
+package linear
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FormatOptions controls how ToLaTeX and ToMarkdownTable write
+// entries.
+type FormatOptions struct {
+	// Precision is the number of digits after the decimal point, or 0
+	// for the fewest digits that give back the exact float64.
+	Precision int
+	// Environment is the LaTeX matrix environment, like "pmatrix" or
+	// "vmatrix". It defaults to "bmatrix".
+	Environment string
+}
+
+func (opts FormatOptions) format(f float64) string {
+	if opts.Precision > 0 {
+		return strconv.FormatFloat(f, 'f', opts.Precision, 64)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// ToLaTeX writes A as a LaTeX matrix, one output (row) per line, for
+// use in math mode.
+func ToLaTeX(A Matrix, opts FormatOptions) string {
+	env := opts.Environment
+	if env == "" {
+		env = "bmatrix"
+	}
+	ins, outs := A.Shape()
+	var b strings.Builder
+	b.WriteString("\\begin{" + env + "}\n")
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			if i > 0 {
+				b.WriteString(" & ")
+			}
+			b.WriteString(latexNumber(A.Get(i, o), opts))
+		}
+		if o < outs-1 {
+			b.WriteString(" \\\\")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\\end{" + env + "}")
+	return b.String()
+}
+
+// latexNumber writes exponents as powers of ten rather than 1e-10.
+func latexNumber(f float64, opts FormatOptions) string {
+	switch {
+	case math.IsNaN(f):
+		return "\\text{NaN}"
+	case math.IsInf(f, 1):
+		return "\\infty"
+	case math.IsInf(f, -1):
+		return "-\\infty"
+	}
+	s := opts.format(f)
+	mantissa, exponent, ok := strings.Cut(s, "e")
+	if !ok {
+		return s
+	}
+	exponent = strings.TrimPrefix(exponent, "+")
+	if neg := strings.HasPrefix(exponent, "-"); neg {
+		exponent = "-" + strings.TrimLeft(exponent[1:], "0")
+	} else {
+		exponent = strings.TrimLeft(exponent, "0")
+	}
+	return mantissa + " \\times 10^{" + exponent + "}"
+}
+
+// ToMarkdownTable writes A as a Markdown table with a row for each
+// output. A *LabeledMatrix gets its labels as the header and first
+// column; otherwise the inputs are numbered from 0.
+func ToMarkdownTable(A Matrix, opts FormatOptions) string {
+	var inLabels, outLabels []string
+	if l, ok := A.(*LabeledMatrix); ok {
+		inLabels, outLabels = l.InLabels, l.OutLabels
+	}
+	ins, outs := A.Shape()
+	if inLabels == nil {
+		inLabels = make([]string, ins)
+		for i := range inLabels {
+			inLabels[i] = strconv.Itoa(i)
+		}
+	}
+
+	var b strings.Builder
+	row := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" " + strings.ReplaceAll(cell, "|", "\\|") + " |")
+		}
+		b.WriteString("\n")
+	}
+	var header, rule []string
+	if outLabels != nil {
+		header = append(header, "")
+		rule = append(rule, "---")
+	}
+	header = append(header, inLabels...)
+	for i := 0; i < ins; i++ {
+		// Numbers line up best on the right.
+		rule = append(rule, "---:")
+	}
+	row(header)
+	row(rule)
+	for o := 0; o < outs; o++ {
+		var cells []string
+		if outLabels != nil {
+			cells = append(cells, outLabels[o])
+		}
+		for i := 0; i < ins; i++ {
+			cells = append(cells, opts.format(A.Get(i, o)))
+		}
+		row(cells)
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func expectString(expect, got string, t *testing.T) {
+	t.Helper()
+	if got != expect {
+		t.Errorf("expected\n%s\nbut got\n%s", expect, got)
+	}
+}
+
+func TestToLaTeX(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, -2.5, 3, 1e-12, math.Inf(1), 2e21}, 3, 2, 3)
+
+	expectString(`\begin{bmatrix}
+1 & -2.5 & 3 \\
+1 \times 10^{-12} & \infty & 2 \times 10^{21}
+\end{bmatrix}`, ToLaTeX(A, FormatOptions{}), t)
+
+	B := MatrixFromSlice([]float64{1.0 / 3, 2}, 2, 1, 2)
+	expectString("\\begin{pmatrix}\n0.333 & 2.000\n\\end{pmatrix}", ToLaTeX(B, FormatOptions{Precision: 3, Environment: "pmatrix"}), t)
+}
+
+func TestToMarkdownTable(t *testing.T) {
+	A := MatrixFromSlice([]float64{1, 2.25, -3, 4}, 2, 2, 2)
+
+	expectString(`| 0 | 1 |
+| ---: | ---: |
+| 1 | 2.25 |
+| -3 | 4 |
+`, ToMarkdownTable(A, FormatOptions{}), t)
+
+	L := Label(A, []string{"x", "a|b"}, []string{"first", "second"})
+	expectString(`|  | x | a\|b |
+| --- | ---: | ---: |
+| first | 1.0 | 2.2 |
+| second | -3.0 | 4.0 |
+`, ToMarkdownTable(L, FormatOptions{Precision: 1}), t)
+}
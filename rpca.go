@@ -0,0 +1,83 @@
+package linear
+
+import (
+	"math"
+)
+
+// SingularValueThreshold is the proximal operator of lambda times the
+// nuclear norm (the sum of the singular values), which shrinks each
+// singular value towards zero by lambda/rho. It's the low rank
+// counterpart to SoftThreshold.
+func SingularValueThreshold(lambda float64) ProximalOperator {
+	return func(v Matrix, rho float64) Matrix {
+		U, sigma, V := DecomposeSVD(v)
+		_, k := sigma.Shape()
+		_, outs := U.Shape()
+		US := Copy(U)
+		for i := 0; i < k; i++ {
+			s := softThreshold(sigma.Get(0, i), lambda/rho)
+			for o := 0; o < outs; o++ {
+				US.Set(i, o, US.Get(i, o)*s)
+			}
+		}
+		return Apply(US, Dual(V))
+	}
+}
+
+// RobustPCA splits A into a low rank L plus a sparse S, so that gross
+// errors in a few entries end up in S instead of skewing the principal
+// components of L. It minimizes the nuclear norm of L plus lambda times
+// the L1 norm of S by the inexact augmented Lagrangian method, which is
+// ADMM with the penalty growing each iteration. A lambda of zero means
+// 1/sqrt(max(ins, outs)), which is usually right.
+func RobustPCA(A Matrix, lambda float64) (L, S Matrix) {
+	ins, outs := A.Shape()
+	if lambda <= 0 {
+		lambda = 1 / math.Sqrt(math.Max(float64(ins), float64(outs)))
+	}
+
+	_, sigma, _ := DecomposeSVD(A)
+	norm2 := sigma.Get(0, 0)
+	normInf := 0.0
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			normInf = math.Max(normInf, math.Abs(A.Get(i, o)))
+		}
+	}
+	normF := frobeniusNorm(A)
+	if normF == 0 {
+		return NewArrayMatrix(ins, outs), NewArrayMatrix(ins, outs)
+	}
+
+	// Start the multiplier Y on the boundary of the dual norm ball.
+	Y := Copy(A)
+	scale := 1 / math.Max(norm2, normInf/lambda)
+	addScaledInto(Y, Y, scale-1, Y)
+	mu := 1.25 / norm2
+	muMax := mu * 1e7
+
+	svt := SingularValueThreshold(1)
+	shrink := SoftThreshold(lambda)
+	S = NewArrayMatrix(ins, outs)
+	V := NewArrayMatrix(ins, outs)
+	for iter := 0; iter < 1000; iter++ {
+		// L and S each minimize the Lagrangian with the other fixed.
+		addScaledInto(A, S, -1, V)
+		addScaledInto(V, Y, 1/mu, V)
+		L = svt(V, mu)
+
+		addScaledInto(A, L, -1, V)
+		addScaledInto(V, Y, 1/mu, V)
+		S = shrink(V, mu)
+
+		// V is now what's left of A, which Y accumulates.
+		addScaledInto(A, L, -1, V)
+		addScaledInto(V, S, -1, V)
+		addScaledInto(Y, V, mu, Y)
+		if frobeniusNorm(V)/normF < 1e-9 {
+			break
+		}
+		mu = math.Min(mu*1.5, muMax)
+	}
+	return L, S
+}
@@ -0,0 +1,49 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSingularValueThreshold(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 3)
+	A.Set(1, 1, 1)
+
+	B := SingularValueThreshold(2)(A, 1)
+
+	ExpectFloat(1, B.Get(0, 0), t)
+	ExpectFloat(0, B.Get(1, 0), t)
+	ExpectFloat(0, B.Get(0, 1), t)
+	ExpectFloat(0, B.Get(1, 1), t)
+}
+
+func TestRobustPCA(t *testing.T) {
+	// A rank one matrix with a couple of corrupted entries.
+	n := 10
+	A := NewArrayMatrix(n, n)
+	for o := 0; o < n; o++ {
+		for i := 0; i < n; i++ {
+			A.Set(i, o, float64((i+1)*(o%3+1)))
+		}
+	}
+	clean := Copy(A)
+	A.Set(2, 7, A.Get(2, 7)+50)
+	A.Set(8, 1, A.Get(8, 1)-30)
+
+	L, S := RobustPCA(A, 0)
+
+	for o := 0; o < n; o++ {
+		for i := 0; i < n; i++ {
+			if math.Abs(L.Get(i, o)-clean.Get(i, o)) > 1e-4 {
+				t.Errorf("L(%d, %d) expected %f but got %f", i, o, clean.Get(i, o), L.Get(i, o))
+			}
+		}
+	}
+	if math.Abs(S.Get(2, 7)-50) > 1e-4 {
+		t.Errorf("expected 50 but got %f", S.Get(2, 7))
+	}
+	if math.Abs(S.Get(8, 1)+30) > 1e-4 {
+		t.Errorf("expected -30 but got %f", S.Get(8, 1))
+	}
+}
@@ -0,0 +1,151 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// schurTolerance is how small a subdiagonal entry of the Hessenberg
+// matrix has to be, relative to the diagonal entries beside it, for QR
+// iteration to treat it as zero and split the problem there.
+const schurTolerance = 0x1p-52
+
+// DecomposeSchur decomposes a square A into Q*T*Dual(Q) for an
+// orthogonal Q and a real Schur form T: upper triangular except for 2
+// by 2 blocks on the diagonal, one for each pair of complex conjugate
+// eigenvalues. The real eigenvalues are the rest of the diagonal, and
+// each block is made to have equal diagonal entries (as LAPACK does),
+// so it holds a +- i*sqrt(-b*c) for a block [a b; c a]. The leading
+// columns of Q span the invariant subspaces of the leading eigenvalues,
+// as long as no block is split. It reduces A with ReduceHessenberg and
+// then does Francis double shift QR iteration, which keeps everything
+// real. It panics if the iteration doesn't converge.
+func DecomposeSchur(A Matrix) (Q, T Matrix) {
+	defer beginOp("Schur")()
+	T, Q = ReduceHessenberg(A)
+	n, _ := T.Shape()
+	norm := MaxAbs(T)
+	iterations := 0
+	for hi := n - 1; hi >= 0; {
+		// The active block runs from the lowest negligible subdiagonal
+		// entry down to hi; below it is already in Schur form.
+		lo := hi
+		for ; lo > 0; lo-- {
+			scale := math.Abs(T.Get(lo-1, lo-1)) + math.Abs(T.Get(lo, lo))
+			if scale == 0 {
+				scale = norm
+			}
+			if math.Abs(T.Get(lo-1, lo)) <= schurTolerance*scale {
+				T.Set(lo-1, lo, 0)
+				break
+			}
+		}
+		switch {
+		case lo == hi:
+			hi--
+			iterations = 0
+		case lo == hi-1:
+			splitSchurBlock(T, Q, lo)
+			hi -= 2
+			iterations = 0
+		default:
+			iterations++
+			if iterations > 30*n {
+				panic(fmt.Errorf("QR iteration didn't converge for rows %d to %d", lo, hi))
+			}
+			francisStep(T, Q, lo, hi, iterations%10 == 0)
+		}
+	}
+	return Q, T
+}
+
+// francisStep does one double shift QR step on rows and columns lo to
+// hi of the Hessenberg T, accumulating the transform into Q. The shifts
+// are the eigenvalues of the trailing 2 by 2 block, and only their sum
+// and product are used, so a complex pair stays real. The step chases a
+// bulge down the subdiagonal with 3 by 3 reflections.
+func francisStep(T, Q Matrix, lo, hi int, exceptional bool) {
+	_, n := T.Shape()
+	// row o, column i
+	t := func(o, i int) float64 { return T.Get(i, o) }
+	a, b, c, d := t(hi-1, hi-1), t(hi-1, hi), t(hi, hi-1), t(hi, hi)
+	sum, product := a+d, a*d-b*c
+	if exceptional {
+		// Every so often shift by something ad hoc instead, to break
+		// out of cycles that the usual shifts can fall into (as LAPACK
+		// does).
+		e := math.Abs(t(hi, hi-1)) + math.Abs(t(hi-1, hi-2))
+		h := 0.75*e + d
+		sum, product = 2*h, h*h+0.4375*e*e
+	}
+
+	// The first column of (T - s1*I)*(T - s2*I), which only has three
+	// entries since T is Hessenberg.
+	x := t(lo, lo)*t(lo, lo) + t(lo, lo+1)*t(lo+1, lo) - sum*t(lo, lo) + product
+	y := t(lo+1, lo) * (t(lo, lo) + t(lo+1, lo+1) - sum)
+	z := t(lo+1, lo) * t(lo+2, lo+1)
+	for k := lo; k < hi-1; k++ {
+		v, beta := HouseholderVector(vectorFromSlice([]float64{x, y, z}), BasisVector(3, 0))
+		first := lo
+		if k > lo {
+			first = k - 1
+		}
+		last := k + 4
+		if last > hi+1 {
+			last = hi + 1
+		}
+		ApplyHouseholderLeft(v, beta, Slice(T, first, n, k, k+3))
+		ApplyHouseholderRight(v, beta, Slice(T, k, k+3, 0, last))
+		ApplyHouseholderRight(v, beta, Slice(Q, k, k+3, 0, n))
+		if k > lo {
+			T.Set(k-1, k+1, 0)
+			T.Set(k-1, k+2, 0)
+		}
+		x, y = t(k+1, k), t(k+2, k)
+		if k < hi-2 {
+			z = t(k+3, k)
+		}
+	}
+	// The bulge is down to one entry, which a rotation removes.
+	g := NewGivens(x, y, hi-1, hi)
+	g.ApplyLeft(Slice(T, hi-2, n, 0, n))
+	g.Dual().ApplyRight(Slice(T, 0, n, 0, hi+1))
+	g.Dual().ApplyRight(Q)
+	T.Set(hi-2, hi, 0)
+}
+
+// splitSchurBlock rotates the 2 by 2 block of T at row and column p,
+// accumulating the rotation into Q. A block with real eigenvalues is
+// made upper triangular by rotating an eigenvector to the front, and
+// one with complex eigenvalues is given equal diagonal entries.
+func splitSchurBlock(T, Q Matrix, p int) {
+	_, n := T.Shape()
+	a, b := T.Get(p, p), T.Get(p+1, p)
+	c, d := T.Get(p, p+1), T.Get(p+1, p+1)
+	half := (a - d) / 2
+	disc := half*half + b*c
+	var g Givens
+	if disc >= 0 {
+		// Taking the root with the sign of half keeps lambda - d from
+		// cancelling, and (lambda - d, c) is an eigenvector.
+		lambda := (a+d)/2 + math.Copysign(math.Sqrt(disc), half)
+		if lambda-d == 0 && c == 0 {
+			return
+		}
+		g = NewGivens(lambda-d, c, p, p+1)
+	} else {
+		theta := math.Atan2(d-a, b+c) / 2
+		g = Givens{math.Cos(theta), math.Sin(theta), p, p + 1}
+	}
+	g.ApplyLeft(Slice(T, p, n, 0, n))
+	g.Dual().ApplyRight(Slice(T, 0, n, 0, p+2))
+	g.Dual().ApplyRight(Q)
+	if disc >= 0 {
+		T.Set(p, p+1, 0)
+	} else {
+		// The rotation makes the diagonal equal up to rounding.
+		m := (T.Get(p, p) + T.Get(p+1, p+1)) / 2
+		T.Set(p, p, m)
+		T.Set(p+1, p+1, m)
+	}
+}
@@ -0,0 +1,108 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// expectSchur checks that A = Q*T*Dual(Q) with Q orthogonal and T in
+// real Schur form, returning the real parts of the eigenvalues from T in
+// order.
+func expectSchur(A, Q, T Matrix, t *testing.T) []float64 {
+	t.Helper()
+	n, _ := A.Shape()
+	ExpectMatrix(Identity(n), Apply(Dual(Q), Q), t)
+	ExpectMatrix(A, Apply(Q, Apply(T, Dual(Q))), t)
+	var eigenvalues []float64
+	for o := 0; o < n; o++ {
+		for i := 0; i+1 < o; i++ {
+			ExpectFloat(0, T.Get(i, o), t)
+		}
+		if o+1 < n && T.Get(o, o+1) != 0 {
+			// A block, which has equal diagonals and complex eigenvalues.
+			ExpectFloat(T.Get(o, o), T.Get(o+1, o+1), t)
+			if T.Get(o+1, o)*T.Get(o, o+1) >= 0 {
+				t.Errorf("block at %d has real eigenvalues", o)
+			}
+			if o+2 < n {
+				ExpectFloat(0, T.Get(o+1, o+2), t)
+			}
+		}
+		eigenvalues = append(eigenvalues, T.Get(o, o))
+	}
+	return eigenvalues
+}
+
+func TestDecomposeSchurReal(t *testing.T) {
+	// The companion matrix of (x - 1)(x - 2)(x - 3)(x + 4).
+	A := MatrixFromSlice([]float64{
+		0, 0, 0, 24,
+		1, 0, 0, -38,
+		0, 1, 0, 13,
+		0, 0, 1, 2,
+	}, 4, 4, 4)
+	Q, T := DecomposeSchur(A)
+
+	eigenvalues := expectSchur(A, Q, T, t)
+	sort.Float64s(eigenvalues)
+	ExpectMatrix(vectorFromSlice([]float64{-4, 1, 2, 3}), vectorFromSlice(eigenvalues), t)
+	// All real, so T is triangular.
+	CheckUpperTriangular(T)
+}
+
+func TestDecomposeSchurComplex(t *testing.T) {
+	// A rotation by 90 degrees scaled by 2, and 5, disguised by an
+	// orthogonal change of basis.
+	B := MatrixFromSlice([]float64{
+		1, -2, 3,
+		2, 1, 4,
+		0, 0, 5,
+	}, 3, 3, 3)
+	U, _ := DecomposeQR(randomMatrix(rand.New(rand.NewSource(2)), 3, 3))
+	A := Apply(U, Apply(B, Dual(U)))
+	Q, T := DecomposeSchur(A)
+
+	eigenvalues := expectSchur(A, Q, T, t)
+	sort.Float64s(eigenvalues)
+	ExpectMatrix(vectorFromSlice([]float64{1, 1, 5}), vectorFromSlice(eigenvalues), t)
+	// The block holds 1 +- 2i.
+	for o := 0; o < 2; o++ {
+		if b, c := T.Get(o+1, o), T.Get(o, o+1); c != 0 {
+			ExpectFloat(2, math.Sqrt(-b*c), t)
+		}
+	}
+}
+
+func TestDecomposeSchurRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for _, n := range []int{1, 2, 5, 12} {
+		A := randomMatrix(rng, n, n)
+		Q, T := DecomposeSchur(A)
+
+		expectSchur(A, Q, T, t)
+		// The trace is the sum of the eigenvalues.
+		trace, sum := 0.0, 0.0
+		for d := 0; d < n; d++ {
+			trace += A.Get(d, d)
+			sum += T.Get(d, d)
+		}
+		ExpectFloat(trace, sum, t)
+	}
+}
+
+func TestDecomposeSchurSymmetric(t *testing.T) {
+	A := MatrixFromSlice([]float64{
+		4, 1, 0, 2,
+		1, 3, 1, 0,
+		0, 1, 2, 1,
+		2, 0, 1, 1,
+	}, 4, 4, 4)
+	Q, T := DecomposeSchur(A)
+
+	expectSchur(A, Q, T, t)
+	// Symmetric, so T is diagonal.
+	ExpectFloat(0, frobeniusNorm(Slice(T, 1, 4, 0, 1))+frobeniusNorm(Slice(T, 2, 4, 1, 2))+math.Abs(T.Get(3, 2)), t)
+	expectPanic(t, func() { DecomposeSchur(NewArrayMatrix(2, 3)) })
+}
@@ -0,0 +1,146 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// GaussianSketch returns a random m by n map with independent normal
+// entries of variance 1/m, so that |S*x| is close to |x| for any fixed
+// x. It's the simplest sketch to reason about but costs m*n to apply.
+func GaussianSketch(n, m int, rng *rand.Rand) LinearOperator {
+	checkSketchShape(n, m)
+	S := NewArrayMatrix(n, m)
+	scale := 1 / math.Sqrt(float64(m))
+	for o := 0; o < m; o++ {
+		for i := 0; i < n; i++ {
+			S.Set(i, o, scale*rng.NormFloat64())
+		}
+	}
+	return MatrixOperator(S)
+}
+
+// countSketch sends each input to one random output with a random sign.
+type countSketch struct {
+	n, m    int
+	buckets []int
+	signs   []float64
+}
+
+// CountSketch returns a random m by n map with a single ±1 in each
+// column, which costs only n to apply (or the number of non-zeros for
+// sparse data). It needs a larger m than the other sketches for the
+// same accuracy, around the square of the number of columns being
+// preserved.
+func CountSketch(n, m int, rng *rand.Rand) LinearOperator {
+	checkSketchShape(n, m)
+	s := &countSketch{n: n, m: m, buckets: make([]int, n), signs: make([]float64, n)}
+	for i := 0; i < n; i++ {
+		s.buckets[i] = rng.Intn(m)
+		s.signs[i] = float64(2*rng.Intn(2) - 1)
+	}
+	return s
+}
+
+func (s *countSketch) Shape() (ins, outs int) { return s.n, s.m }
+
+func (s *countSketch) ApplyVec(x Vector) Vector {
+	CheckVector(x)
+	checkOperatorInput(s, x)
+	y := NewVector(s.m)
+	for i := 0; i < s.n; i++ {
+		b := s.buckets[i]
+		y.Set(0, b, y.Get(0, b)+s.signs[i]*x.Get(0, i))
+	}
+	return y
+}
+
+// srht is sqrt(1/m)*P*H*D with D random signs, H the Hadamard matrix of
+// size padded (n rounded up to a power of two) and P picking m rows.
+type srht struct {
+	n, m, padded int
+	signs        []float64
+	rows         []int
+}
+
+// SRHT returns a subsampled randomized Hadamard transform, an m by n
+// map that flips the sign of random inputs, mixes them all together
+// with a fast Walsh-Hadamard transform and keeps m of the results. It
+// costs n*log(n) to apply and needs m only a little more than the
+// number of columns being preserved. m can be at most n rounded up to
+// a power of two.
+func SRHT(n, m int, rng *rand.Rand) LinearOperator {
+	checkSketchShape(n, m)
+	padded := 1
+	for padded < n {
+		padded *= 2
+	}
+	if m > padded {
+		panic(fmt.Errorf("can't sketch %d inputs to %d outputs", n, m))
+	}
+	s := &srht{n: n, m: m, padded: padded, signs: make([]float64, n)}
+	for i := range s.signs {
+		s.signs[i] = float64(2*rng.Intn(2) - 1)
+	}
+	s.rows = rng.Perm(padded)[:m]
+	return s
+}
+
+func (s *srht) Shape() (ins, outs int) { return s.n, s.m }
+
+func (s *srht) ApplyVec(x Vector) Vector {
+	CheckVector(x)
+	checkOperatorInput(s, x)
+	buf := make([]float64, s.padded)
+	for i := 0; i < s.n; i++ {
+		buf[i] = s.signs[i] * x.Get(0, i)
+	}
+	walshHadamard(buf)
+	// The unnormalized transform scales lengths by sqrt(padded), and
+	// keeping m of padded entries by sqrt(m/padded).
+	scale := 1 / math.Sqrt(float64(s.m))
+	y := NewVector(s.m)
+	for k, r := range s.rows {
+		y.Set(0, k, scale*buf[r])
+	}
+	return y
+}
+
+// walshHadamard replaces x with H*x in place, where H is the
+// unnormalized Hadamard matrix of size len(x), a power of two.
+func walshHadamard(x []float64) {
+	for h := 1; h < len(x); h *= 2 {
+		for i := 0; i < len(x); i += 2 * h {
+			for j := i; j < i+h; j++ {
+				a, b := x[j], x[j+h]
+				x[j], x[j+h] = a+b, a-b
+			}
+		}
+	}
+}
+
+func checkSketchShape(n, m int) {
+	if n < 1 || m < 1 {
+		panic(fmt.Errorf("can't sketch %d inputs to %d outputs", n, m))
+	}
+}
+
+func checkOperatorInput(A LinearOperator, x Vector) {
+	ins, _ := A.Shape()
+	if _, dim := x.Shape(); dim != ins {
+		panic(fmt.Errorf("operator takes %d inputs but got %d", ins, dim))
+	}
+}
+
+// SketchedLeastSquares approximately solves the least squares problem
+// of OrdinaryLeastSquares by solving the much smaller problem
+// S*X*theta = S*y instead, for a sketch S that maps X's n observations
+// (outputs) to far fewer. With S from GaussianSketch or SRHT and a
+// sketch size of a few times the number of parameters, the residual is
+// within a small factor of the best possible with high probability.
+func SketchedLeastSquares(X, y Matrix, S LinearOperator) Matrix {
+	defer beginOp("LeastSquares")()
+	CheckSameOuts(X, y)
+	return FactorQR(ApplyOperator(S, X)).Solve(ApplyOperator(S, y))
+}
@@ -0,0 +1,73 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSketchesPreserveLengths(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	x := NewVector(300)
+	for i := 0; i < 300; i++ {
+		x.Set(0, i, rng.NormFloat64())
+	}
+	length := L2Norm(x)
+	for name, S := range map[string]LinearOperator{
+		"gaussian":    GaussianSketch(300, 2000, rng),
+		"countsketch": CountSketch(300, 2000, rng),
+		"srht":        SRHT(300, 400, rng),
+	} {
+		if got := L2Norm(S.ApplyVec(x)); math.Abs(got-length) > 0.1*length {
+			t.Errorf("%s: expected a length near %f but got %f", name, length, got)
+		}
+	}
+}
+
+func TestSRHTIsOrthogonalWhenSquare(t *testing.T) {
+	S := ApplyOperator(SRHT(8, 8, rand.New(rand.NewSource(2))), Identity(8))
+	ExpectMatrix(Identity(8), Compose(S, Dual(S)), t)
+}
+
+func TestCountSketchColumns(t *testing.T) {
+	S := ApplyOperator(CountSketch(20, 5, rand.New(rand.NewSource(3))), Identity(20))
+	for i := 0; i < 20; i++ {
+		nonZeros := 0
+		for o := 0; o < 5; o++ {
+			if f := S.Get(i, o); f != 0 {
+				ExpectFloat(1, math.Abs(f), t)
+				nonZeros++
+			}
+		}
+		ExpectInt(1, nonZeros, t)
+	}
+}
+
+func TestSketchedLeastSquares(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	n, p := 2000, 3
+	X := NewArrayMatrix(p, n)
+	y := NewVector(n)
+	for o := 0; o < n; o++ {
+		X.Set(0, o, 1)
+		for i := 1; i < p; i++ {
+			X.Set(i, o, rng.NormFloat64())
+		}
+		y.Set(0, o, 1+2*X.Get(1, o)-X.Get(2, o)+0.1*rng.NormFloat64())
+	}
+	best := OrdinaryLeastSquares(X, y)
+	rss := func(theta Matrix) float64 {
+		r := NewVector(n)
+		addScaledInto(Apply(X, theta), y, -1, r)
+		return DotProduct(r, Dual(r))
+	}
+	for name, S := range map[string]LinearOperator{
+		"gaussian": GaussianSketch(n, 60, rng),
+		"srht":     SRHT(n, 60, rng),
+	} {
+		theta := SketchedLeastSquares(X, y, S)
+		if ratio := rss(theta) / rss(best); ratio > 1.5 {
+			t.Errorf("%s: residual is %f times the best", name, ratio)
+		}
+	}
+}
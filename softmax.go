@@ -0,0 +1,129 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// SoftmaxModel is a multinomial logistic regression model: the
+// probability of each class for an observation is the softmax of its
+// scores, a score per class from Weights plus Bias.
+type SoftmaxModel struct {
+	// Weights has a column (input) per class and an output per
+	// feature, like the parameters from OrdinaryLeastSquares with a
+	// response per class.
+	Weights Matrix
+	// Bias has an entry per class.
+	Bias Covector
+}
+
+// SoftmaxRegression fits a multinomial logistic regression to the
+// observations (outputs) of X with labels y from 0 up to classes, by
+// minimizing the mean cross entropy plus lambda/2 times the sum of the
+// squares of the weights (but not the bias) with L-BFGS.
+func SoftmaxRegression(X Matrix, y []int, classes int, lambda float64) *SoftmaxModel {
+	features, n := X.Shape()
+	if len(y) != n {
+		panic(fmt.Errorf("%d labels for %d observations", len(y), n))
+	}
+	for o, label := range y {
+		if label < 0 || label >= classes {
+			panic(fmt.Errorf("label %d at %d isn't in [0, %d)", label, o, classes))
+		}
+	}
+
+	// The parameters are the weights, a feature at a time, and then the
+	// biases, all in one vector.
+	unpack := func(theta Vector) *SoftmaxModel {
+		W := NewArrayMatrix(classes, features)
+		b := NewCovector(classes)
+		for f := 0; f < features; f++ {
+			for c := 0; c < classes; c++ {
+				W.Set(c, f, theta.Get(0, f*classes+c))
+			}
+		}
+		for c := 0; c < classes; c++ {
+			b.Set(c, 0, theta.Get(0, features*classes+c))
+		}
+		return &SoftmaxModel{W, b}
+	}
+	objective := func(theta Vector) (float64, Vector) {
+		m := unpack(theta)
+		P := m.PredictProba(X)
+		loss := 0.0
+		// Residual is P minus the one-hot labels, divided by n.
+		R := NewArrayMatrix(classes, n)
+		for o := 0; o < n; o++ {
+			loss -= math.Log(math.Max(P.Get(y[o], o), 1e-300))
+			for c := 0; c < classes; c++ {
+				r := P.Get(c, o)
+				if c == y[o] {
+					r--
+				}
+				R.Set(c, o, r/float64(n))
+			}
+		}
+		loss /= float64(n)
+		gW := Apply(Dual(X), R)
+		g := NewVector((features + 1) * classes)
+		for f := 0; f < features; f++ {
+			for c := 0; c < classes; c++ {
+				w := m.Weights.Get(c, f)
+				loss += lambda / 2 * w * w
+				g.Set(0, f*classes+c, gW.Get(c, f)+lambda*w)
+			}
+		}
+		for c := 0; c < classes; c++ {
+			sum := 0.0
+			for o := 0; o < n; o++ {
+				sum += R.Get(c, o)
+			}
+			g.Set(0, features*classes+c, sum)
+		}
+		return loss, g
+	}
+
+	l := &LBFGS{Memory: 10, MaxIter: 1000, GradTol: 1e-8}
+	theta, _ := l.Minimize(objective, NewVector((features+1)*classes))
+	return unpack(theta)
+}
+
+// PredictProba returns the probability of each class (input) for each
+// observation (output) of X.
+func (m *SoftmaxModel) PredictProba(X Matrix) Matrix {
+	S := Apply(X, m.Weights)
+	classes, n := S.Shape()
+	for o := 0; o < n; o++ {
+		// Subtracting the largest score keeps exp from overflowing.
+		top := math.Inf(-1)
+		for c := 0; c < classes; c++ {
+			S.Set(c, o, S.Get(c, o)+m.Bias.Get(c, 0))
+			top = math.Max(top, S.Get(c, o))
+		}
+		sum := 0.0
+		for c := 0; c < classes; c++ {
+			e := math.Exp(S.Get(c, o) - top)
+			S.Set(c, o, e)
+			sum += e
+		}
+		for c := 0; c < classes; c++ {
+			S.Set(c, o, S.Get(c, o)/sum)
+		}
+	}
+	return S
+}
+
+// Predict returns the most probable class for each observation of X.
+func (m *SoftmaxModel) Predict(X Matrix) []int {
+	P := m.PredictProba(X)
+	classes, n := P.Shape()
+	labels := make([]int, n)
+	for o := range labels {
+		for c := 1; c < classes; c++ {
+			if P.Get(c, o) > P.Get(labels[o], o) {
+				labels[o] = c
+			}
+		}
+	}
+	return labels
+}
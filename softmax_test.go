@@ -0,0 +1,56 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSoftmaxRegression(t *testing.T) {
+	// Three clouds around different centers.
+	centers := [][2]float64{{0, 3}, {-3, -2}, {3, -2}}
+	r := rand.New(rand.NewSource(1))
+	n := 90
+	X := NewArrayMatrix(2, n)
+	y := make([]int, n)
+	for o := 0; o < n; o++ {
+		y[o] = o % 3
+		X.Set(0, o, centers[y[o]][0]+r.NormFloat64()*0.5)
+		X.Set(1, o, centers[y[o]][1]+r.NormFloat64()*0.5)
+	}
+
+	m := SoftmaxRegression(X, y, 3, 0.01)
+
+	predicted := m.Predict(X)
+	for o := range y {
+		ExpectInt(y[o], predicted[o], t)
+	}
+	P := m.PredictProba(X)
+	for o := 0; o < n; o++ {
+		sum := 0.0
+		for c := 0; c < 3; c++ {
+			sum += P.Get(c, o)
+		}
+		ExpectFloat(1, sum, t)
+	}
+}
+
+func TestSoftmaxRegressionGradient(t *testing.T) {
+	// With heavy regularization on one feature, the weights shrink to
+	// near zero and the probabilities come from the bias alone, which
+	// matches the class frequencies.
+	X := NewArrayMatrix(1, 4)
+	for o := 0; o < 4; o++ {
+		X.Set(0, o, float64(o))
+	}
+	y := []int{0, 1, 1, 1}
+
+	m := SoftmaxRegression(X, y, 2, 1e6)
+
+	P := m.PredictProba(X)
+	for o := 0; o < 4; o++ {
+		if math.Abs(P.Get(1, o)-0.75) > 1e-3 {
+			t.Errorf("expected probability 0.75 but got %v", P.Get(1, o))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package linear
+
+import "time"
+
+// Stats is how a solve went.
+type Stats struct {
+	// Iterations is how many iterations an iterative solver did, or 0
+	// for a direct one.
+	Iterations int
+	// Residual is the length of A*x - b (for several right hand sides
+	// the Frobenius norm), as the solver tracks it for an iterative
+	// one. For L-BFGS it's the length of the gradient.
+	Residual float64
+	// Flops estimates the additions and multiplications of this solve
+	// from the sizes involved, so unlike the counters it's right with
+	// other solves running on other goroutines. Applying a
+	// MatrixOperator is counted as 2 per stored entry, but other
+	// operators and objectives aren't counted.
+	Flops int64
+	// Duration is the wall time spent.
+	Duration time.Duration
+}
+
+// measure starts adding wall time to stats, until the returned
+// function is called.
+func measure(stats *Stats) func() {
+	start := time.Now()
+	return func() {
+		stats.Duration += time.Since(start)
+	}
+}
+
+// operatorFlops estimates the cost of A.ApplyVec.
+func operatorFlops(A LinearOperator) int64 {
+	m, ok := A.(matrixOperator)
+	if !ok {
+		return 0
+	}
+	if sparse, ok := m.A.(interface{ NonZeros() int }); ok {
+		return 2 * int64(sparse.NonZeros())
+	}
+	ins, outs := m.A.Shape()
+	return 2 * int64(ins) * int64(outs)
+}
+
+// SolveWithStats finds X such that A*X = B for a square nonsingular A
+// by LU, like Inverse(A) applied to B but without forming it, and
+// returns how it went. The residual is computed afterwards and isn't
+// in the flops or duration.
+func SolveWithStats(A, B Matrix) (X Matrix, stats Stats) {
+	end := measure(&stats)
+	X = FactorLU(A).Solve(B)
+	end()
+	n, _ := A.Shape()
+	cols, _ := B.Shape()
+	stats.Flops = int64(2*n*n*n/3 + 2*n*n*cols)
+	stats.Residual = frobeniusDistance(Apply(A, X), B)
+	return X, stats
+}
+
+// OrdinaryLeastSquaresWithStats is OrdinaryLeastSquares, also returning
+// how it went. The residual is that of the fit, X*theta_hat - y.
+func OrdinaryLeastSquaresWithStats(X, y Matrix) (theta Matrix, stats Stats) {
+	end := measure(&stats)
+	theta = OrdinaryLeastSquares(X, y)
+	end()
+	// Householder QR, then applying Dual(Q) and back substitution.
+	n, m := X.Shape()
+	cols, _ := y.Shape()
+	stats.Flops = int64(2*m*n*n - 2*n*n*n/3 + (4*m*n+n*n)*cols)
+	stats.Residual = frobeniusDistance(Apply(X, theta), y)
+	return theta, stats
+}
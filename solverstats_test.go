@@ -0,0 +1,100 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestSolveWithStats(t *testing.T) {
+	A := factorTestMatrix()
+	b := NewVector(3)
+	b.Set(0, 0, 5)
+	b.Set(0, 1, -2)
+	b.Set(0, 2, 9)
+
+	x, stats := SolveWithStats(A, b)
+
+	ExpectMatrix(FactorLU(A).Solve(b), x, t)
+	ExpectInt(0, stats.Iterations, t)
+	ExpectFloat(0, stats.Residual, t)
+	// The factorization then the substitutions.
+	ExpectInt(2*3*3*3/3+2*3*3, int(stats.Flops), t)
+	if stats.Duration <= 0 {
+		t.Errorf("expected a duration but got %v", stats.Duration)
+	}
+}
+
+func TestOrdinaryLeastSquaresWithStats(t *testing.T) {
+	X := MatrixFromSlice([]float64{
+		1, 0,
+		1, 1,
+		1, 2,
+	}, 2, 3, 2)
+	y := MatrixFromSlice([]float64{0, 1, 1}, 1, 3, 1)
+
+	theta, stats := OrdinaryLeastSquaresWithStats(X, y)
+
+	ExpectMatrix(OrdinaryLeastSquares(X, y), theta, t)
+	// The fit is 1/6 + x/2, off by 1/6, 1/3 and 1/6.
+	ExpectFloat(1/6.0*2.449489742783178, stats.Residual, t)
+	if stats.Flops <= 0 {
+		t.Errorf("expected flops but got %d", stats.Flops)
+	}
+}
+
+func TestIterativeStats(t *testing.T) {
+	A, b := checkpointSystem()
+
+	x, stats := ConjugateGradientWithStats(MatrixOperator(A), b, 1e-12, 100)
+	want, iterations := ConjugateGradient(MatrixOperator(A), b, 1e-12, 100)
+	ExpectMatrix(want, x, t)
+	ExpectInt(iterations, stats.Iterations, t)
+	if stats.Residual > 1e-12*L2Norm(b) || stats.Flops <= 0 {
+		t.Errorf("unexpected %+v", stats)
+	}
+
+	_, stats = GMRESWithStats(MatrixOperator(A), b, 1e-10, 3)
+	ExpectInt(3, stats.Iterations, t)
+	if stats.Residual <= 1e-10*L2Norm(b) {
+		t.Errorf("expected 3 iterations not to converge but got %+v", stats)
+	}
+
+	// The flops so far survive a snapshot.
+	s := NewCGSolver(MatrixOperator(A), b, 1e-12)
+	s.Run(5)
+	data, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed := NewCGSolver(MatrixOperator(A), b, 1e-12)
+	if err := resumed.Restore(data); err != nil {
+		t.Fatal(err)
+	}
+	ExpectInt(int(s.Stats().Flops), int(resumed.Stats().Flops), t)
+
+	l := &LBFGS{Memory: 5, MaxIter: 200, GradTol: 1e-10}
+	x0 := NewVector(2)
+	_, stats = l.MinimizeWithStats(rosenbrock, x0)
+	if stats.Iterations == 0 || stats.Residual > 1e-10 {
+		t.Errorf("unexpected %+v", stats)
+	}
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	A, b := checkpointSystem()
+	_, alone := ConjugateGradientWithStats(MatrixOperator(A), b, 1e-12, 100)
+
+	// Solves on other goroutines don't add to each other's flops.
+	results := make(chan Stats)
+	for k := 0; k < 4; k++ {
+		go func() {
+			_, stats := ConjugateGradientWithStats(MatrixOperator(A), b, 1e-12, 100)
+			results <- stats
+		}()
+	}
+	for k := 0; k < 4; k++ {
+		ExpectInt(int(alone.Flops), int((<-results).Flops), t)
+	}
+	// One sparse matrix-vector product per iteration, plus the vector
+	// work.
+	ExpectInt(alone.Iterations*(2*A.(*CSR).NonZeros()+10*30), int(alone.Flops), t)
+}
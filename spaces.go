@@ -0,0 +1,102 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// Space names a vector space, so that maps between spaces of the same
+// dimension can't be confused. A Space with no Name matches any space
+// of its dimension.
+type Space struct {
+	Name string
+	Dim  int
+}
+
+func (s Space) String() string {
+	if s.Name == "" {
+		return fmt.Sprintf("R^%d", s.Dim)
+	}
+	return fmt.Sprintf("%s(%d)", s.Name, s.Dim)
+}
+
+// matches reports whether a map into s can be followed by a map out of
+// t.
+func (s Space) matches(t Space) bool {
+	return s.Dim == t.Dim && (s.Name == "" || t.Name == "" || s.Name == t.Name)
+}
+
+// TaggedMatrix is a Matrix that maps from the space In to the space
+// Out. Compose, Apply and their Into variants check that tagged
+// matrices are composed between matching spaces, and tag their results,
+// so composing in the wrong order panics even when the dimensions
+// happen to agree. Dual of a TaggedMatrix maps Out to In.
+type TaggedMatrix struct {
+	A       Matrix
+	In, Out Space
+}
+
+// Tag returns A viewed as a map from in to out.
+func Tag(A Matrix, in, out Space) *TaggedMatrix {
+	ins, outs := A.Shape()
+	if in.Dim != ins || out.Dim != outs {
+		panic(fmt.Errorf("can't tag shape (%d, %d) as %v to %v", ins, outs, in, out))
+	}
+	return &TaggedMatrix{A, in, out}
+}
+
+func (m *TaggedMatrix) Shape() (ins, outs int)             { return m.A.Shape() }
+func (m *TaggedMatrix) Get(in, out int) float64            { return m.A.Get(in, out) }
+func (m *TaggedMatrix) Set(in, out int, value float64)     { m.A.Set(in, out, value) }
+func (m *TaggedMatrix) backingArray() (*arrayMatrix, bool) { return asArrayMatrix(m.A) }
+func (m *TaggedMatrix) readOnlyArray() bool                { return isReadOnlyArray(m.A) }
+
+// spacesOf returns the spaces of A if it's tagged, seeing through Dual.
+func spacesOf(A Matrix) (in, out Space, ok bool) {
+	switch a := A.(type) {
+	case *TaggedMatrix:
+		return a.In, a.Out, true
+	case *dualMatrix:
+		if in, out, ok := spacesOf(a.A); ok {
+			return out, in, true
+		}
+	}
+	return Space{}, Space{}, false
+}
+
+// checkSpaces panics if A is tagged with an output space that B, if
+// tagged, doesn't take as input, or if dst's tags don't match.
+func checkSpaces(A, B, dst Matrix) {
+	aIn, aOut, aok := spacesOf(A)
+	bIn, bOut, bok := spacesOf(B)
+	if aok && bok && !aOut.matches(bIn) {
+		panic(fmt.Errorf("space mismatch %v vs %v", aOut, bIn))
+	}
+	dIn, dOut, dok := spacesOf(dst)
+	if !dok {
+		return
+	}
+	if aok && !aIn.matches(dIn) {
+		panic(fmt.Errorf("space mismatch %v vs %v", aIn, dIn))
+	}
+	if bok && !bOut.matches(dOut) {
+		panic(fmt.Errorf("space mismatch %v vs %v", bOut, dOut))
+	}
+}
+
+// tagComposition tags C, the result of "A then B", with the input
+// space of A and output space of B when either is tagged.
+func tagComposition(A, B, C Matrix) Matrix {
+	aIn, _, aok := spacesOf(A)
+	_, bOut, bok := spacesOf(B)
+	if !aok && !bok {
+		return C
+	}
+	ins, outs := C.Shape()
+	if !aok {
+		aIn = Space{Dim: ins}
+	}
+	if !bok {
+		bOut = Space{Dim: outs}
+	}
+	return Tag(C, aIn, bOut)
+}
@@ -0,0 +1,56 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestTaggedCompose(t *testing.T) {
+	meters := Space{"meters", 2}
+	pixels := Space{"pixels", 2}
+	// Both maps are 2x2, so only the tags catch the wrong order.
+	project := Tag(MatrixFromSlice([]float64{100, 0, 0, 100}, 2, 2, 2), meters, pixels)
+	flip := Tag(MatrixFromSlice([]float64{-1, 0, 0, 1}, 2, 2, 2), pixels, pixels)
+
+	C := Compose(project, flip)
+	in, out, ok := spacesOf(C)
+	if !ok || in != meters || out != pixels {
+		t.Errorf("expected meters to pixels but got %v to %v", in, out)
+	}
+	ExpectFloat(-100, C.Get(0, 0), t)
+
+	expectPanic(t, func() { Compose(flip, project) })
+	// Dual maps pixels back to meters, so that order is fine.
+	Compose(flip, Dual(project))
+}
+
+func TestTaggedApply(t *testing.T) {
+	features := Space{"features", 2}
+	observations := Space{"observations", 3}
+	X := Tag(MatrixFromSlice([]float64{
+		1, 2,
+		3, 4,
+		5, 6,
+	}, 2, 3, 2), features, observations)
+	theta := MatrixFromSlice([]float64{1, 1}, 1, 2, 1)
+
+	// Untagged matrices match any space of the right dimension.
+	y := Apply(X, theta)
+	_, out, _ := spacesOf(y)
+	if out != observations {
+		t.Errorf("expected observations but got %v", out)
+	}
+	ExpectFloat(11, y.Get(0, 2), t)
+
+	expectPanic(t, func() { Apply(X, Tag(NewVector(2), Space{Dim: 1}, Space{"weights", 2})) })
+	expectPanic(t, func() { Tag(theta, features, features) })
+}
+
+func expectPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	f()
+}
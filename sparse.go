@@ -0,0 +1,116 @@
+package linear
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CSR is a sparse Matrix in compressed sparse row form: only the
+// non-zero entries are stored, output (row) by output, each in order of
+// input.
+type CSR struct {
+	ins, outs int
+	// The entries of output o are at rowStart[o] up to rowStart[o+1]
+	// in cols and values.
+	rowStart []int
+	cols     []int
+	values   []float64
+}
+
+// SparseEntry is an entry of a sparse matrix.
+type SparseEntry struct {
+	In, Out int
+	Value   float64
+}
+
+// NewCSR makes a new zero sparse Matrix with the given shape.
+func NewCSR(ins, outs int) *CSR {
+	return &CSR{ins: ins, outs: outs, rowStart: make([]int, outs+1)}
+}
+
+// NewCSRFromEntries makes a sparse Matrix with the given entries, in
+// any order. Entries at the same position are added together.
+func NewCSRFromEntries(ins, outs int, entries []SparseEntry) *CSR {
+	sorted := append([]SparseEntry(nil), entries...)
+	sort.Slice(sorted, func(a, b int) bool {
+		if sorted[a].Out != sorted[b].Out {
+			return sorted[a].Out < sorted[b].Out
+		}
+		return sorted[a].In < sorted[b].In
+	})
+	m := NewCSR(ins, outs)
+	for j, e := range sorted {
+		m.checkBounds(e.In, e.Out)
+		if j > 0 && sorted[j-1].In == e.In && sorted[j-1].Out == e.Out {
+			m.values[len(m.values)-1] += e.Value
+			continue
+		}
+		m.cols = append(m.cols, e.In)
+		m.values = append(m.values, e.Value)
+		m.rowStart[e.Out+1]++
+	}
+	for o := 0; o < outs; o++ {
+		m.rowStart[o+1] += m.rowStart[o]
+	}
+	return m
+}
+
+func (m *CSR) Shape() (ins, outs int) { return m.ins, m.outs }
+
+func (m *CSR) Get(in, out int) float64 {
+	if j, ok := m.find(in, out); ok {
+		return m.values[j]
+	}
+	return 0
+}
+
+// Set changes an entry. Making a new entry non-zero has to shift all
+// the entries after it, so it's best to build with NewCSRFromEntries.
+func (m *CSR) Set(in, out int, value float64) {
+	j, ok := m.find(in, out)
+	if ok {
+		m.values[j] = value
+		return
+	}
+	if value == 0 {
+		return
+	}
+	m.cols = append(m.cols, 0)
+	copy(m.cols[j+1:], m.cols[j:])
+	m.cols[j] = in
+	m.values = append(m.values, 0)
+	copy(m.values[j+1:], m.values[j:])
+	m.values[j] = value
+	for o := out + 1; o <= m.outs; o++ {
+		m.rowStart[o]++
+	}
+}
+
+// NonZeros returns the number of stored entries.
+func (m *CSR) NonZeros() int { return len(m.values) }
+
+// find returns where the entry is stored, or where it would go.
+func (m *CSR) find(in, out int) (int, bool) {
+	m.checkBounds(in, out)
+	lo, hi := m.rowStart[out], m.rowStart[out+1]
+	j := lo + sort.SearchInts(m.cols[lo:hi], in)
+	return j, j < hi && m.cols[j] == in
+}
+
+func (m *CSR) checkBounds(in, out int) {
+	if in < 0 || in >= m.ins || out < 0 || out >= m.outs {
+		panic(fmt.Errorf("(%d, %d) is out of bounds (%d, %d)", in, out, m.ins, m.outs))
+	}
+}
+
+// transposeCSR returns Dual(A) stored as its own CSR, so that the
+// columns of A can be walked as rows.
+func transposeCSR(A *CSR) *CSR {
+	entries := make([]SparseEntry, 0, A.NonZeros())
+	for o := 0; o < A.outs; o++ {
+		for j := A.rowStart[o]; j < A.rowStart[o+1]; j++ {
+			entries = append(entries, SparseEntry{o, A.cols[j], A.values[j]})
+		}
+	}
+	return NewCSRFromEntries(A.outs, A.ins, entries)
+}
@@ -0,0 +1,36 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestCSR(t *testing.T) {
+	A := NewCSRFromEntries(3, 2, []SparseEntry{
+		{2, 1, 5},
+		{0, 0, 1},
+		{1, 1, 2},
+		{2, 1, 1},
+	})
+
+	ins, outs := A.Shape()
+	ExpectInt(3, ins, t)
+	ExpectInt(2, outs, t)
+	ExpectInt(3, A.NonZeros(), t)
+	ExpectFloat(1, A.Get(0, 0), t)
+	ExpectFloat(0, A.Get(1, 0), t)
+	ExpectFloat(2, A.Get(1, 1), t)
+	ExpectFloat(6, A.Get(2, 1), t)
+
+	A.Set(2, 0, 7)
+	A.Set(1, 0, 0)
+	A.Set(1, 1, 3)
+
+	ExpectInt(4, A.NonZeros(), t)
+	ExpectFloat(7, A.Get(2, 0), t)
+	ExpectFloat(3, A.Get(1, 1), t)
+	ExpectFloat(6, A.Get(2, 1), t)
+
+	B := Copy(A)
+	ExpectFloat(7, B.Get(2, 0), t)
+	ExpectFloat(0, B.Get(0, 1), t)
+}
@@ -0,0 +1,261 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// SparseCholeskyAnalysis is the part of a sparse Cholesky factorization
+// that only depends on where the non-zeros of A are: the fill-reducing
+// order, the elimination tree and how many entries each column of L
+// will have. It can be reused to factor other matrices with the same
+// pattern.
+type SparseCholeskyAnalysis struct {
+	n int
+	// perm[k] is the row of A that is row k of the permuted matrix.
+	perm, pinv []int
+	// parent[j] is the parent of column j in the elimination tree,
+	// or -1 for a root.
+	parent []int
+	// The entries of column j of L are at colStart[j] up to
+	// colStart[j+1].
+	colStart []int
+}
+
+// AnalyzeSparseCholesky orders the symmetric sparse A to reduce fill and
+// works out the pattern of its Cholesky factor. Both triangles of A
+// must be stored.
+func AnalyzeSparseCholesky(A *CSR) *SparseCholeskyAnalysis {
+	perm := ApproximateMinimumDegreeOrder(A)
+	C := PermuteSymmetric(A, perm)
+	n := C.outs
+	s := &SparseCholeskyAnalysis{
+		n:      n,
+		perm:   perm,
+		pinv:   InversePermutation(perm),
+		parent: eliminationTree(C),
+	}
+
+	// Row k of L has an entry in each column that row k reaches in the
+	// elimination tree, plus the diagonal.
+	counts := make([]int, n)
+	stack := make([]int, n)
+	mark := make([]bool, n)
+	for k := 0; k < n; k++ {
+		top := s.rowPattern(C, k, stack, mark)
+		for _, j := range stack[top:] {
+			counts[j]++
+		}
+		counts[k]++
+	}
+	s.colStart = make([]int, n+1)
+	for j := 0; j < n; j++ {
+		s.colStart[j+1] = s.colStart[j] + counts[j]
+	}
+	return s
+}
+
+// NonZeros returns the number of entries the factor will have.
+func (s *SparseCholeskyAnalysis) NonZeros() int { return s.colStart[s.n] }
+
+// eliminationTree finds the parent of each column of the Cholesky
+// factor of the symmetric C, with path compression through ancestor.
+func eliminationTree(C *CSR) []int {
+	n := C.outs
+	parent := make([]int, n)
+	ancestor := make([]int, n)
+	for k := 0; k < n; k++ {
+		parent[k] = -1
+		ancestor[k] = -1
+		for j := C.rowStart[k]; j < C.rowStart[k+1]; j++ {
+			for i := C.cols[j]; i != -1 && i < k; {
+				next := ancestor[i]
+				ancestor[i] = k
+				if next == -1 {
+					parent[i] = k
+				}
+				i = next
+			}
+		}
+	}
+	return parent
+}
+
+// rowPattern finds the columns of the non-zeros to the left of the
+// diagonal in row k of L by walking up the elimination tree from each
+// non-zero of row k of C. They go in stack[top:] in an order where every
+// column comes before the columns that depend on it.
+func (s *SparseCholeskyAnalysis) rowPattern(C *CSR, k int, stack []int, mark []bool) (top int) {
+	top = s.n
+	mark[k] = true
+	path := make([]int, 0, 8)
+	for j := C.rowStart[k]; j < C.rowStart[k+1]; j++ {
+		i := C.cols[j]
+		if i > k {
+			continue
+		}
+		path = path[:0]
+		for ; !mark[i]; i = s.parent[i] {
+			path = append(path, i)
+			mark[i] = true
+		}
+		for len(path) > 0 {
+			top--
+			stack[top] = path[len(path)-1]
+			path = path[:len(path)-1]
+		}
+	}
+	for _, i := range stack[top:] {
+		mark[i] = false
+	}
+	mark[k] = false
+	return top
+}
+
+// SparseCholesky is P*A*Dual(P) = L*Dual(L) for a sparse symmetric
+// positive definite A, with L stored by column.
+type SparseCholesky struct {
+	*SparseCholeskyAnalysis
+	rows   []int
+	values []float64
+}
+
+// FactorSparseCholesky analyzes and factors the symmetric positive
+// definite sparse A. Both triangles of A must be stored. It panics if
+// A isn't positive definite.
+func FactorSparseCholesky(A *CSR) *SparseCholesky {
+	return AnalyzeSparseCholesky(A).Factor(A)
+}
+
+// Factor computes the Cholesky factor of A, which must have the same
+// pattern as the matrix that was analyzed, one row of L at a time.
+func (s *SparseCholeskyAnalysis) Factor(A *CSR) *SparseCholesky {
+	defer beginOp("Cholesky")()
+	if A.ins != s.n || A.outs != s.n {
+		panic(fmt.Errorf("analyzed (%d, %d) but got (%d, %d)", s.n, s.n, A.ins, A.outs))
+	}
+	C := PermuteSymmetric(A, s.perm)
+	n := s.n
+	f := &SparseCholesky{
+		SparseCholeskyAnalysis: s,
+		rows:                   make([]int, s.NonZeros()),
+		values:                 make([]float64, s.NonZeros()),
+	}
+	// next[j] is where the next entry of column j of L goes.
+	next := append([]int(nil), s.colStart[:n]...)
+	x := make([]float64, n)
+	stack := make([]int, n)
+	mark := make([]bool, n)
+	for k := 0; k < n; k++ {
+		top := s.rowPattern(C, k, stack, mark)
+		for j := C.rowStart[k]; j < C.rowStart[k+1]; j++ {
+			if i := C.cols[j]; i <= k {
+				x[i] = C.values[j]
+			}
+		}
+		d := x[k]
+		x[k] = 0
+
+		// Solve for row k of L against the columns before it, in an
+		// order where each one is finished before it's used.
+		for _, i := range stack[top:] {
+			lki := x[i] / f.values[s.colStart[i]]
+			x[i] = 0
+			for p := s.colStart[i] + 1; p < next[i]; p++ {
+				x[f.rows[p]] -= f.values[p] * lki
+			}
+			countFlops(2 * (next[i] - s.colStart[i]))
+			d -= lki * lki
+			f.rows[next[i]] = k
+			f.values[next[i]] = lki
+			next[i]++
+		}
+		if d <= 0 {
+			panic(fmt.Errorf("not positive definite at %d (%g)", s.perm[k], d))
+		}
+		f.rows[next[k]] = k
+		f.values[next[k]] = math.Sqrt(d)
+		next[k]++
+	}
+	return f
+}
+
+// L returns the lower triangular factor of the permuted matrix as a
+// sparse matrix.
+func (f *SparseCholesky) L() *CSR {
+	entries := make([]SparseEntry, 0, len(f.values))
+	for j := 0; j < f.n; j++ {
+		for p := f.colStart[j]; p < f.colStart[j+1]; p++ {
+			entries = append(entries, SparseEntry{j, f.rows[p], f.values[p]})
+		}
+	}
+	return NewCSRFromEntries(f.n, f.n, entries)
+}
+
+// Permutation returns the fill-reducing order: row k of the factored
+// matrix is row Permutation()[k] of A.
+func (f *SparseCholesky) Permutation() []int {
+	return append([]int(nil), f.perm...)
+}
+
+// SolveVec finds x such that A*x = b.
+func (f *SparseCholesky) SolveVec(b Vector) Vector {
+	defer beginOp("Solve")()
+	CheckVector(b)
+	_, dim := b.Shape()
+	if dim != f.n {
+		panic(fmt.Errorf("expected dimension %d but got %d", f.n, dim))
+	}
+	y := make([]float64, f.n)
+	for k := 0; k < f.n; k++ {
+		y[k] = b.Get(0, f.perm[k])
+	}
+	// Forward substitution with L, column by column because the
+	// diagonal is the first entry of each column.
+	for j := 0; j < f.n; j++ {
+		y[j] /= f.values[f.colStart[j]]
+		for p := f.colStart[j] + 1; p < f.colStart[j+1]; p++ {
+			y[f.rows[p]] -= f.values[p] * y[j]
+		}
+	}
+	// Back substitution with Dual(L), whose rows are the columns of L.
+	for j := f.n - 1; j >= 0; j-- {
+		for p := f.colStart[j] + 1; p < f.colStart[j+1]; p++ {
+			y[j] -= f.values[p] * y[f.rows[p]]
+		}
+		y[j] /= f.values[f.colStart[j]]
+	}
+	countFlops(4 * len(f.values))
+	x := NewVector(f.n)
+	for k := 0; k < f.n; k++ {
+		x.Set(0, f.perm[k], y[k])
+	}
+	return x
+}
+
+// Solve finds X such that A*X = B.
+func (f *SparseCholesky) Solve(B Matrix) Matrix {
+	cols, n := B.Shape()
+	if n != f.n {
+		panic(fmt.Errorf("expected %d outs but got %d", f.n, n))
+	}
+	X := NewArrayMatrix(cols, n)
+	for c := 0; c < cols; c++ {
+		CopyInto(f.SolveVec(Slice(B, c, c+1, 0, n)), Slice(X, c, c+1, 0, n))
+	}
+	return X
+}
+
+// Det returns the determinant of A.
+func (f *SparseCholesky) Det() float64 {
+	det := 1.0
+	for j := 0; j < f.n; j++ {
+		det *= f.values[f.colStart[j]]
+	}
+	return det * det
+}
+
+// Inverse returns the inverse of A, which is dense.
+func (f *SparseCholesky) Inverse() Matrix {
+	return f.Solve(Identity(f.n))
+}
@@ -0,0 +1,113 @@
+package linear
+
+import (
+	"testing"
+)
+
+// arrowMatrix is diagonally dominant with a dense first row and
+// column, which fills in completely unless the first row goes last.
+func arrowMatrix(n int) *CSR {
+	var entries []SparseEntry
+	for i := 0; i < n; i++ {
+		entries = append(entries, SparseEntry{i, i, float64(n + i)})
+		if i > 0 {
+			entries = append(entries, SparseEntry{i, 0, 1}, SparseEntry{0, i, 1})
+		}
+	}
+	return NewCSRFromEntries(n, n, entries)
+}
+
+func TestSparseCholesky(t *testing.T) {
+	n := 8
+	A := arrowMatrix(n)
+
+	f := FactorSparseCholesky(A)
+
+	// Ordered well, the factor has no fill at all.
+	ExpectInt(2*n-1, f.NonZeros(), t)
+
+	b := NewVector(n)
+	for d := 0; d < n; d++ {
+		b.Set(0, d, float64(d*d)-3)
+	}
+	x := f.SolveVec(b)
+	expect := SolveCholesky(DecomposeCholesky(Copy(A)), b)
+	for d := 0; d < n; d++ {
+		ExpectFloat(expect.Get(0, d), x.Get(0, d), t)
+	}
+
+	dense := FactorCholesky(Copy(A))
+	if d := f.Det() / dense.Det(); d < 1-1e-12 || d > 1+1e-12 {
+		t.Errorf("expected determinant %v but got %v", dense.Det(), f.Det())
+	}
+}
+
+func TestSparseCholeskyReuseAnalysis(t *testing.T) {
+	D := Difference1D(6)
+	A := NewCSR(6, 6)
+	DtD := Apply(Dual(D), Copy(D))
+	for o := 0; o < 6; o++ {
+		for i := 0; i < 6; i++ {
+			if v := DtD.Get(i, o); v != 0 || i == o {
+				A.Set(i, o, v)
+			}
+		}
+	}
+	s := AnalyzeSparseCholesky(A)
+
+	// Same pattern, different values.
+	for _, shift := range []float64{1, 10} {
+		for d := 0; d < 6; d++ {
+			A.Set(d, d, DtD.Get(d, d)+shift)
+		}
+		L := s.Factor(A).L()
+		P := Apply(L, Dual(L))
+		C := PermuteSymmetric(A, s.perm)
+		for o := 0; o < 6; o++ {
+			for i := 0; i < 6; i++ {
+				ExpectFloat(C.Get(i, o), P.Get(i, o), t)
+			}
+		}
+	}
+}
+
+// gridLaplacian is the 5 point Laplacian of a width by height grid,
+// shifted to be positive definite.
+func gridLaplacian(width, height int) *CSR {
+	var entries []SparseEntry
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			o := y*width + x
+			entries = append(entries, SparseEntry{o, o, 4.01})
+			if x+1 < width {
+				entries = append(entries, SparseEntry{o + 1, o, -1}, SparseEntry{o, o + 1, -1})
+			}
+			if y+1 < height {
+				entries = append(entries, SparseEntry{o + width, o, -1}, SparseEntry{o, o + width, -1})
+			}
+		}
+	}
+	return NewCSRFromEntries(width*height, width*height, entries)
+}
+
+func TestSparseCholeskyGrid(t *testing.T) {
+	width := 40
+	n := width * width
+	A := gridLaplacian(width, width)
+
+	f := FactorSparseCholesky(A)
+
+	// In the natural order the factor fills the whole band, about
+	// n*width entries; a good ordering needs far fewer.
+	if nz := f.NonZeros(); nz > n*width/2 {
+		t.Errorf("expected at most %d entries in the factor but got %d", n*width/2, nz)
+	}
+	b := NewVector(n)
+	for d := 0; d < n; d++ {
+		b.Set(0, d, float64(d%7)-3)
+	}
+	x := f.SolveVec(b)
+	if r := frobeniusDistance(Apply(A, x), b); r > 1e-9*L2Norm(b) {
+		t.Errorf("expected a small residual but got %g", r)
+	}
+}
@@ -0,0 +1,176 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Densify returns a dense copy of A, with only the non-zeros read if A
+// is sparse.
+func Densify(A Matrix) Matrix {
+	entries, ok := sparseEntries(A)
+	if !ok {
+		return Copy(A)
+	}
+	ins, outs := A.Shape()
+	D := NewArrayMatrix(ins, outs)
+	for _, e := range entries {
+		D.Set(e.In, e.Out, e.Value)
+	}
+	return D
+}
+
+// Sparsify returns a sparse copy of A keeping only the entries whose
+// magnitude is more than tol.
+func Sparsify(A Matrix, tol float64) *CSR {
+	ins, outs := A.Shape()
+	entries, ok := sparseEntries(A)
+	if !ok {
+		for o := 0; o < outs; o++ {
+			for i := 0; i < ins; i++ {
+				entries = append(entries, SparseEntry{i, o, A.Get(i, o)})
+			}
+		}
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if math.Abs(e.Value) > tol {
+			kept = append(kept, e)
+		}
+	}
+	return NewCSRFromEntries(ins, outs, kept)
+}
+
+// Add returns A + B, which is sparse if both are.
+func Add(A, B Matrix) Matrix {
+	return AddScaled(A, 1, B)
+}
+
+// Sub returns A - B, which is sparse if both are.
+func Sub(A, B Matrix) Matrix {
+	return AddScaled(A, -1, B)
+}
+
+// AddScaled returns A + s*B, which is sparse if both are. If only one
+// is sparse, just its non-zeros are added to a dense copy of the other.
+func AddScaled(A Matrix, s float64, B Matrix) Matrix {
+	CheckSameShape(A, B)
+	ins, outs := A.Shape()
+	aEntries, aSparse := sparseEntries(A)
+	bEntries, bSparse := sparseEntries(B)
+	switch {
+	case aSparse && bSparse:
+		entries := append([]SparseEntry(nil), aEntries...)
+		for _, e := range bEntries {
+			entries = append(entries, SparseEntry{e.In, e.Out, s * e.Value})
+		}
+		return NewCSRFromEntries(ins, outs, entries)
+	case bSparse:
+		C := Copy(A)
+		for _, e := range bEntries {
+			C.Set(e.In, e.Out, C.Get(e.In, e.Out)+s*e.Value)
+		}
+		return C
+	case aSparse:
+		C := NewArrayMatrix(ins, outs)
+		addScaledInto(C, B, s, C)
+		for _, e := range aEntries {
+			C.Set(e.In, e.Out, C.Get(e.In, e.Out)+e.Value)
+		}
+		return C
+	}
+	C := NewArrayMatrix(ins, outs)
+	addScaledInto(A, B, s, C)
+	return C
+}
+
+// sparseEntries returns the stored entries of A if it's sparse (seeing
+// through Dual), in no particular order.
+func sparseEntries(A Matrix) ([]SparseEntry, bool) {
+	m, transposed, ok := asSparse(A)
+	if !ok {
+		return nil, false
+	}
+	entries := make([]SparseEntry, 0, m.NonZeros())
+	for o := 0; o < m.outs; o++ {
+		for j := m.rowStart[o]; j < m.rowStart[o+1]; j++ {
+			if transposed {
+				entries = append(entries, SparseEntry{o, m.cols[j], m.values[j]})
+			} else {
+				entries = append(entries, SparseEntry{m.cols[j], o, m.values[j]})
+			}
+		}
+	}
+	return entries, true
+}
+
+// csrOf returns A as a CSR, transposing it into a new one if it's only
+// available the other way round.
+func csrOf(A Matrix) (*CSR, bool) {
+	m, transposed, ok := asSparse(A)
+	if !ok {
+		return nil, false
+	}
+	if transposed {
+		return transposeCSR(m), true
+	}
+	return m, true
+}
+
+// multiplySparseSparse is composeSparseSparse as a "Multiply"
+// operation, for Compose and Apply, which don't go through ComposeInto
+// when both are sparse.
+func multiplySparseSparse(A, B Matrix) (*CSR, bool) {
+	_, _, aok := asSparse(A)
+	_, _, bok := asSparse(B)
+	if !aok || !bok {
+		return nil, false
+	}
+	defer beginOp("Multiply")()
+	return composeSparseSparse(A, B)
+}
+
+// composeSparseSparse returns "A then B" (aka B*A) as a CSR when both
+// are sparse, building each output (row) of the result from the rows
+// of A selected by that row of B (Gustavson's algorithm).
+func composeSparseSparse(A, B Matrix) (*CSR, bool) {
+	a, aok := csrOf(A)
+	b, bok := csrOf(B)
+	if !aok || !bok {
+		return nil, false
+	}
+	if a.outs != b.ins {
+		panic(fmt.Errorf("dimension mismatch %d vs %d", a.outs, b.ins))
+	}
+	C := NewCSR(a.ins, b.outs)
+	acc := make([]float64, a.ins)
+	used := make([]bool, a.ins)
+	var pattern []int
+	flops := 0
+	for o := 0; o < b.outs; o++ {
+		pattern = pattern[:0]
+		for j := b.rowStart[o]; j < b.rowStart[o+1]; j++ {
+			k, v := b.cols[j], b.values[j]
+			for p := a.rowStart[k]; p < a.rowStart[k+1]; p++ {
+				i := a.cols[p]
+				if !used[i] {
+					used[i] = true
+					pattern = append(pattern, i)
+				}
+				acc[i] += v * a.values[p]
+			}
+			flops += 2 * (a.rowStart[k+1] - a.rowStart[k])
+		}
+		sort.Ints(pattern)
+		for _, i := range pattern {
+			C.cols = append(C.cols, i)
+			C.values = append(C.values, acc[i])
+			acc[i] = 0
+			used[i] = false
+		}
+		C.rowStart[o+1] = len(C.cols)
+	}
+	countFlops(flops)
+	return C, true
+}
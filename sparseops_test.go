@@ -0,0 +1,55 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSparsifyDensify(t *testing.T) {
+	A := NewArrayMatrix(3, 2)
+	A.Set(0, 0, 1)
+	A.Set(2, 0, 1e-12)
+	A.Set(1, 1, -2)
+
+	S := Sparsify(A, 1e-9)
+
+	ExpectInt(2, S.NonZeros(), t)
+	ExpectFloat(-2, S.Get(1, 1), t)
+
+	D := Densify(Dual(S))
+
+	ExpectMatrix(Dual(S), D, t)
+}
+
+func TestAddSparseDense(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	S := NewCSRFromEntries(4, 3, randomSparse(r, 4, 3, 5))
+	T := NewCSRFromEntries(4, 3, randomSparse(r, 4, 3, 5))
+	D := Densify(T)
+
+	sparse := Sub(S, T)
+	if _, ok := sparse.(*CSR); !ok {
+		t.Errorf("expected sparse minus sparse to be sparse")
+	}
+	ExpectMatrix(AddScaled(Densify(S), -1, D), sparse, t)
+	ExpectMatrix(sparse, Sub(S, D), t)
+	ExpectMatrix(Add(D, S), Add(S, D), t)
+}
+
+func TestComposeSparseSparse(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	A := NewCSRFromEntries(4, 5, randomSparse(r, 4, 5, 8))
+	B := NewCSCFromEntries(5, 3, randomSparse(r, 5, 3, 6))
+
+	C := Compose(A, B)
+	if _, ok := C.(*CSR); !ok {
+		t.Errorf("expected sparse times sparse to be sparse")
+	}
+	ExpectMatrix(Compose(Densify(A), Densify(B)), C, t)
+
+	dst := NewArrayMatrix(4, 3)
+	ComposeInto(A, B, dst)
+	ExpectMatrix(C, dst, t)
+
+	ExpectMatrix(Apply(Densify(Dual(A)), Densify(A)), Apply(Dual(A), A), t)
+}
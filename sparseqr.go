@@ -0,0 +1,217 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// FindInputSparseLowerTriangular finds x such that L*x = b for a square
+// sparse lower triangular L, touching only its non-zeros.
+func FindInputSparseLowerTriangular(L *CSR, b Vector) Vector {
+	defer beginOp("Solve")()
+	x := copySparseRHS(L, b)
+	for o := 0; o < L.outs; o++ {
+		sum := x[o]
+		diag := 0.0
+		for j := L.rowStart[o]; j < L.rowStart[o+1]; j++ {
+			switch i := L.cols[j]; {
+			case i < o:
+				sum -= L.values[j] * x[i]
+			case i == o:
+				diag = L.values[j]
+			default:
+				panic(fmt.Errorf("not lower triangular at (%d, %d)", i, o))
+			}
+		}
+		if diag == 0 {
+			panic(fmt.Errorf("zero on the diagonal at %d", o))
+		}
+		x[o] = sum / diag
+	}
+	countFlops(2 * L.NonZeros())
+	return vectorFromSlice(x)
+}
+
+// FindInputSparseUpperTriangular finds x such that U*x = b for a square
+// sparse upper triangular U, touching only its non-zeros.
+func FindInputSparseUpperTriangular(U *CSR, b Vector) Vector {
+	defer beginOp("Solve")()
+	x := copySparseRHS(U, b)
+	for o := U.outs - 1; o >= 0; o-- {
+		sum := x[o]
+		diag := 0.0
+		for j := U.rowStart[o]; j < U.rowStart[o+1]; j++ {
+			switch i := U.cols[j]; {
+			case i > o:
+				sum -= U.values[j] * x[i]
+			case i == o:
+				diag = U.values[j]
+			default:
+				panic(fmt.Errorf("not upper triangular at (%d, %d)", i, o))
+			}
+		}
+		if diag == 0 {
+			panic(fmt.Errorf("zero on the diagonal at %d", o))
+		}
+		x[o] = sum / diag
+	}
+	countFlops(2 * U.NonZeros())
+	return vectorFromSlice(x)
+}
+
+func copySparseRHS(A *CSR, b Vector) []float64 {
+	CheckVector(b)
+	CheckSameOuts(A, b)
+	if A.ins != A.outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", A.ins, A.outs))
+	}
+	x := make([]float64, A.outs)
+	for o := range x {
+		x[o] = b.Get(0, o)
+	}
+	return x
+}
+
+func vectorFromSlice(x []float64) Vector {
+	v := NewVector(len(x))
+	for d, f := range x {
+		v.Set(0, d, f)
+	}
+	return v
+}
+
+// sparseRow is a row being rotated into R, with its entries in order of
+// input.
+type sparseRow struct {
+	cols   []int
+	values []float64
+}
+
+// givens is a rotation of the incoming row with row k of R.
+type givens struct {
+	k    int
+	c, s float64
+}
+
+// SparseQR is a QR factorization of a sparse A with at least as many
+// outputs as inputs, built by rotating the rows of A into R one at a
+// time with Givens rotations. Q isn't kept as a matrix; the rotations
+// are replayed on each right hand side instead.
+type SparseQR struct {
+	ins, outs int
+	r         *CSR
+	// rotations[o] rotates row o of A into R, and it finally lands in
+	// row placed[o] of R, or -1 if it was rotated away to nothing.
+	rotations [][]givens
+	placed    []int
+}
+
+// FactorSparseQR factors the sparse A, keeping R sparse.
+func FactorSparseQR(A *CSR) *SparseQR {
+	defer beginOp("QR")()
+	if A.outs < A.ins {
+		panic(fmt.Errorf("less matix outs (%d) than ins (%d)", A.outs, A.ins))
+	}
+	f := &SparseQR{
+		ins:       A.ins,
+		outs:      A.outs,
+		rotations: make([][]givens, A.outs),
+		placed:    make([]int, A.outs),
+	}
+	rows := make([]sparseRow, A.ins)
+	for o := 0; o < A.outs; o++ {
+		lo, hi := A.rowStart[o], A.rowStart[o+1]
+		row := sparseRow{
+			cols:   append([]int(nil), A.cols[lo:hi]...),
+			values: append([]float64(nil), A.values[lo:hi]...),
+		}
+		f.placed[o] = -1
+		for len(row.cols) > 0 {
+			k := row.cols[0]
+			if row.values[0] == 0 {
+				row.cols, row.values = row.cols[1:], row.values[1:]
+				continue
+			}
+			if len(rows[k].cols) == 0 {
+				rows[k] = row
+				f.placed[o] = k
+				break
+			}
+			// Rotate so that the leading entry of row cancels.
+			rk := rows[k]
+			h := math.Hypot(rk.values[0], row.values[0])
+			g := givens{k, rk.values[0] / h, row.values[0] / h}
+			f.rotations[o] = append(f.rotations[o], g)
+			rows[k], row = rotateSparseRows(rk, row, g.c, g.s)
+		}
+	}
+	var entries []SparseEntry
+	for k, row := range rows {
+		for j, i := range row.cols {
+			entries = append(entries, SparseEntry{i, k, row.values[j]})
+		}
+	}
+	f.r = NewCSRFromEntries(f.ins, f.ins, entries)
+	return f
+}
+
+// rotateSparseRows returns c*a + s*b and -s*a + c*b, dropping the
+// leading entry of the second since the rotation was chosen to zero it.
+func rotateSparseRows(a, b sparseRow, c, s float64) (sparseRow, sparseRow) {
+	var ra, rb sparseRow
+	countFlops(6 * (len(a.cols) + len(b.cols)))
+	for ia, ib := 0, 0; ia < len(a.cols) || ib < len(b.cols); {
+		var col int
+		var av, bv float64
+		switch {
+		case ib == len(b.cols) || (ia < len(a.cols) && a.cols[ia] < b.cols[ib]):
+			col, av = a.cols[ia], a.values[ia]
+			ia++
+		case ia == len(a.cols) || b.cols[ib] < a.cols[ia]:
+			col, bv = b.cols[ib], b.values[ib]
+			ib++
+		default:
+			col, av, bv = a.cols[ia], a.values[ia], b.values[ib]
+			ia++
+			ib++
+		}
+		ra.cols = append(ra.cols, col)
+		ra.values = append(ra.values, c*av+s*bv)
+		if len(ra.cols) > 1 {
+			rb.cols = append(rb.cols, col)
+			rb.values = append(rb.values, -s*av+c*bv)
+		}
+	}
+	return ra, rb
+}
+
+// R returns the upper triangular factor as a sparse matrix.
+func (f *SparseQR) R() *CSR { return f.r }
+
+// SolveVec finds the x that makes A*x closest to b. It panics if A
+// doesn't have full column rank.
+func (f *SparseQR) SolveVec(b Vector) Vector {
+	CheckVector(b)
+	if _, dim := b.Shape(); dim != f.outs {
+		panic(fmt.Errorf("expected dimension %d but got %d", f.outs, dim))
+	}
+	// Replay the rotations on b to get the top of Dual(Q)*b. Whatever
+	// is left of rows that were rotated away is the residual.
+	qtb := make([]float64, f.ins)
+	for o := 0; o < f.outs; o++ {
+		t := b.Get(0, o)
+		for _, g := range f.rotations[o] {
+			qtb[g.k], t = g.c*qtb[g.k]+g.s*t, -g.s*qtb[g.k]+g.c*t
+		}
+		if k := f.placed[o]; k >= 0 {
+			qtb[k] = t
+		}
+	}
+	return FindInputSparseUpperTriangular(f.r, vectorFromSlice(qtb))
+}
+
+// SparseLeastSquares finds the x that makes A*x closest to b for a
+// sparse A, without ever making anything dense except x.
+func SparseLeastSquares(A *CSR, b Vector) Vector {
+	return FactorSparseQR(A).SolveVec(b)
+}
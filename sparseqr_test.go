@@ -0,0 +1,73 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestFindInputSparseTriangular(t *testing.T) {
+	L := NewCSRFromEntries(3, 3, []SparseEntry{
+		{0, 0, 2}, {0, 1, 1}, {1, 1, 4}, {0, 2, -1}, {2, 2, 5},
+	})
+	b := NewVector(3)
+	b.Set(0, 0, 2)
+	b.Set(0, 1, 9)
+	b.Set(0, 2, 14)
+
+	x := FindInputSparseLowerTriangular(L, b)
+
+	ExpectFloat(1, x.Get(0, 0), t)
+	ExpectFloat(2, x.Get(0, 1), t)
+	ExpectFloat(3, x.Get(0, 2), t)
+
+	U := transposeCSR(L)
+	y := FindInputSparseUpperTriangular(U, b)
+	Uy := Apply(U, y)
+	for o := 0; o < 3; o++ {
+		ExpectFloat(b.Get(0, o), Uy.Get(0, o), t)
+	}
+}
+
+func TestSparseLeastSquares(t *testing.T) {
+	X := NewCSRFromEntries(2, 3, []SparseEntry{
+		{0, 0, 1}, {0, 1, 1}, {1, 1, 2}, {0, 2, -2}, {1, 2, 1},
+	})
+	y := NewVector(3)
+	y.Set(0, 0, 6)
+	y.Set(0, 1, 0)
+	y.Set(0, 2, -15)
+
+	theta := SparseLeastSquares(X, y)
+
+	ExpectFloat(6, theta.Get(0, 0), t)
+	ExpectFloat(-3, theta.Get(0, 1), t)
+}
+
+func TestSparseQR(t *testing.T) {
+	D := Difference1D(6)
+	// Stack the identity under the differences so it has full rank.
+	var entries []SparseEntry
+	for o := 0; o < 5; o++ {
+		for i := 0; i < 6; i++ {
+			if v := D.Get(i, o); v != 0 {
+				entries = append(entries, SparseEntry{i, o, v})
+			}
+		}
+	}
+	for i := 0; i < 6; i++ {
+		entries = append(entries, SparseEntry{i, 5 + i, 1})
+	}
+	A := NewCSRFromEntries(6, 11, entries)
+
+	f := FactorSparseQR(A)
+	R := f.R()
+	if f.R() != R {
+		t.Errorf("expected R to be built once")
+	}
+
+	// Dual(R)*R = Dual(A)*A since Q is orthogonal, and the band
+	// structure means R stays bidiagonal.
+	ExpectInt(11, R.NonZeros(), t)
+	RtR := Apply(Dual(R), Copy(R))
+	AtA := Apply(Dual(A), Copy(A))
+	ExpectMatrix(AtA, RtR, t)
+}
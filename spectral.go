@@ -0,0 +1,71 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// SpectralNorm estimates the largest singular value of A, the most A
+// can stretch a vector, by power iteration on Dual(A)*A. It stops when
+// an iteration changes the estimate by less than tol relative to it.
+func SpectralNorm(A Matrix, tol float64) float64 {
+	ins, _ := A.Shape()
+	return math.Sqrt(powerIteration(ins, tol, func(v Vector) Vector {
+		return Apply(Dual(A), Apply(A, v))
+	}))
+}
+
+// ConditionEstimate estimates the ratio of the largest to the smallest
+// singular value of A, which has at least as many outputs as inputs,
+// by power iteration for the largest and inverse iteration with the QR
+// decomposition for the smallest. It's infinite if A is rank
+// deficient.
+func ConditionEstimate(A Matrix, tol float64) float64 {
+	ins, outs := A.Shape()
+	if outs < ins {
+		panic(fmt.Errorf("less matix outs (%d) than ins (%d)", outs, ins))
+	}
+	_, R := DecomposeQR(A)
+	R = Slice(R, 0, ins, 0, ins)
+	for d := 0; d < ins; d++ {
+		if math.Abs(R.Get(d, d)) < 1e-14*math.Abs(R.Get(0, 0)) {
+			return math.Inf(1)
+		}
+	}
+
+	// Dual(A)*A = Dual(R)*R, so its inverse is applied by solving with
+	// Dual(R) and then R.
+	largestInverse := powerIteration(ins, tol, func(v Vector) Vector {
+		return FindInputUpperTriangular(R, FindInputLowerTriangular(Dual(R), v))
+	})
+	return SpectralNorm(A, tol) * math.Sqrt(largestInverse)
+}
+
+// powerIteration estimates the largest eigenvalue of the symmetric
+// positive semidefinite map applied by f.
+func powerIteration(dim int, tol float64, f func(v Vector) Vector) float64 {
+	rng := rand.New(rand.NewSource(1))
+	v := NewVector(dim)
+	for d := 0; d < dim; d++ {
+		v.Set(0, d, rng.NormFloat64())
+	}
+	Normalize(v)
+
+	lambda := 0.0
+	for iter := 0; iter < 10000; iter++ {
+		w := f(v)
+		next := L2Norm(w)
+		if next == 0 {
+			return 0
+		}
+		for d := 0; d < dim; d++ {
+			v.Set(0, d, w.Get(0, d)/next)
+		}
+		if math.Abs(next-lambda) <= tol*next {
+			return next
+		}
+		lambda = next
+	}
+	return lambda
+}
@@ -0,0 +1,43 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpectralNorm(t *testing.T) {
+	A := NewArrayMatrix(3, 2)
+	A.Set(0, 0, 3)
+	A.Set(1, 0, 2)
+	A.Set(2, 0, 2)
+	A.Set(0, 1, 2)
+	A.Set(1, 1, 3)
+	A.Set(2, 1, -2)
+
+	norm := SpectralNorm(A, 1e-14)
+
+	ExpectFloat(5, norm, t)
+	ExpectFloat(5, SpectralNorm(Dual(A), 1e-14), t)
+}
+
+func TestConditionEstimate(t *testing.T) {
+	A := NewArrayMatrix(2, 3)
+	A.Set(0, 0, 3)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 2)
+	A.Set(1, 1, 3)
+	A.Set(0, 2, 2)
+	A.Set(1, 2, -2)
+
+	ExpectFloat(5.0/3.0, ConditionEstimate(A, 1e-14), t)
+
+	B := NewArrayMatrix(2, 2)
+	B.Set(0, 0, 1)
+	B.Set(1, 0, 2)
+	B.Set(0, 1, 2)
+	B.Set(1, 1, 4)
+
+	if !math.IsInf(ConditionEstimate(B, 1e-14), 1) {
+		t.Errorf("expected a singular matrix to have infinite condition")
+	}
+}
@@ -0,0 +1,129 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// CSC is a sparse Matrix in compressed sparse column form: only the
+// non-zero entries are stored, input (column) by input, each in order
+// of output. It's stored as the CSR of its dual, so applying it walks
+// that CSR the transposed way.
+type CSC struct {
+	t *CSR
+}
+
+// NewCSC makes a new zero sparse column Matrix with the given shape.
+func NewCSC(ins, outs int) *CSC {
+	return &CSC{NewCSR(outs, ins)}
+}
+
+// NewCSCFromEntries makes a sparse column Matrix with the given
+// entries, in any order. Entries at the same position are added
+// together.
+func NewCSCFromEntries(ins, outs int, entries []SparseEntry) *CSC {
+	swapped := make([]SparseEntry, len(entries))
+	for j, e := range entries {
+		swapped[j] = SparseEntry{e.Out, e.In, e.Value}
+	}
+	return &CSC{NewCSRFromEntries(outs, ins, swapped)}
+}
+
+func (m *CSC) Shape() (ins, outs int)         { return m.t.outs, m.t.ins }
+func (m *CSC) Get(in, out int) float64        { return m.t.Get(out, in) }
+func (m *CSC) Set(in, out int, value float64) { m.t.Set(out, in, value) }
+
+// NonZeros returns the number of stored entries.
+func (m *CSC) NonZeros() int { return m.t.NonZeros() }
+
+// ToCSC returns the same matrix in compressed sparse column form.
+func (m *CSR) ToCSC() *CSC { return &CSC{transposeCSR(m)} }
+
+// ToCSR returns the same matrix in compressed sparse row form.
+func (m *CSC) ToCSR() *CSR { return transposeCSR(m.t) }
+
+// asSparse sees through Dual and CSC to the underlying CSR, reporting
+// whether A is its transpose.
+func asSparse(A Matrix) (m *CSR, transposed, ok bool) {
+	switch a := A.(type) {
+	case *CSR:
+		return a, false, true
+	case *CSC:
+		return a.t, true, true
+	case *dualMatrix:
+		if m, transposed, ok := asSparse(a.A); ok {
+			return m, !transposed, true
+		}
+	}
+	return nil, false, false
+}
+
+// composeSparse is ComposeInto for when either A or B is sparse, so
+// that the cost is proportional to the non-zeros.
+// It returns false if it doesn't apply.
+func composeSparse(A, B, dst Matrix) bool {
+	if bsrCompose(A, B, dst) || symmetricCompose(A, B, dst) {
+		return true
+	}
+	mb, tb, bSparse := asSparse(B)
+	ma, ta, aSparse := asSparse(A)
+	switch {
+	case aSparse && bSparse:
+		C, _ := composeSparseSparse(A, B)
+		CopyInto(Densify(C), dst)
+		return true
+	case bSparse && !aSparse:
+		cols, _ := A.Shape()
+		countFlops(2 * mb.NonZeros() * cols)
+		spmm(mb, tb, A, dst)
+		return true
+	case aSparse && !bSparse:
+		_, outs := B.Shape()
+		countFlops(2 * ma.NonZeros() * outs)
+		// B*S = Dual(Dual(S)*Dual(B)).
+		spmm(ma, !ta, Dual(B), Dual(dst))
+		return true
+	}
+	return false
+}
+
+// spmm writes S*X into dst, where S is m or, if transposed, Dual(m).
+func spmm(m *CSR, transposed bool, X, dst Matrix) {
+	addRow := rowAdder(X, dst)
+	for r := 0; r < m.outs; r++ {
+		for j := m.rowStart[r]; j < m.rowStart[r+1]; j++ {
+			if transposed {
+				// Entry (r, cols[j]) of Dual(m) scatters row r of X.
+				addRow(m.values[j], r, m.cols[j])
+			} else {
+				addRow(m.values[j], m.cols[j], r)
+			}
+		}
+	}
+}
+
+// rowAdder zeroes dst and returns a function that adds v times row k
+// of X to row o of dst, with axpy when the rows are contiguous.
+func rowAdder(X, dst Matrix) func(v float64, k, o int) {
+	cols, _ := X.Shape()
+	dCols, dOuts := dst.Shape()
+	if dCols != cols {
+		panic(fmt.Errorf("dimension mismatch %d vs %d", dCols, cols))
+	}
+	for o := 0; o < dOuts; o++ {
+		for c := 0; c < cols; c++ {
+			dst.Set(c, o, 0)
+		}
+	}
+	x, xok := asArrayMatrix(X)
+	d, dok := asWritableArrayMatrix(dst)
+	if xok && dok && x.inStride == 1 && d.inStride == 1 {
+		return func(v float64, k, o int) {
+			axpy(v, x.array[k*x.outStride:k*x.outStride+cols], d.array[o*d.outStride:o*d.outStride+cols])
+		}
+	}
+	return func(v float64, k, o int) {
+		for c := 0; c < cols; c++ {
+			dst.Set(c, o, dst.Get(c, o)+v*X.Get(c, k))
+		}
+	}
+}
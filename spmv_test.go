@@ -0,0 +1,57 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomSparse(r *rand.Rand, ins, outs, n int) []SparseEntry {
+	entries := make([]SparseEntry, n)
+	for j := range entries {
+		entries[j] = SparseEntry{r.Intn(ins), r.Intn(outs), r.NormFloat64()}
+	}
+	return entries
+}
+
+func TestSparseApply(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	entries := randomSparse(r, 5, 4, 9)
+	S := NewCSRFromEntries(5, 4, entries)
+	C := NewCSCFromEntries(5, 4, entries)
+	dense := Copy(S)
+
+	X := NewArrayMatrix(3, 5)
+	Y := NewArrayMatrixColMajor(3, 4)
+	for _, M := range []Matrix{X, Y} {
+		ins, outs := M.Shape()
+		for o := 0; o < outs; o++ {
+			for i := 0; i < ins; i++ {
+				M.Set(i, o, r.NormFloat64())
+			}
+		}
+	}
+
+	ExpectMatrix(Apply(dense, X), Apply(S, X), t)
+	ExpectMatrix(Apply(dense, X), Apply(C, X), t)
+	ExpectMatrix(Apply(Dual(dense), Y), Apply(Dual(S), Y), t)
+	ExpectMatrix(Apply(Dual(dense), Y), Apply(Dual(C), Y), t)
+
+	// And with the sparse matrix on the right.
+	Z := NewArrayMatrix(4, 2)
+	for o := 0; o < 2; o++ {
+		for i := 0; i < 4; i++ {
+			Z.Set(i, o, r.NormFloat64())
+		}
+	}
+	ExpectMatrix(Apply(Z, dense), Apply(Z, S), t)
+	ExpectMatrix(Apply(Z, dense), Apply(Z, C), t)
+}
+
+func TestCSCConversion(t *testing.T) {
+	S := NewCSRFromEntries(3, 2, []SparseEntry{{0, 0, 1}, {2, 0, 2}, {1, 1, 3}})
+	C := S.ToCSC()
+
+	ExpectInt(3, C.NonZeros(), t)
+	ExpectMatrix(S, C, t)
+	ExpectMatrix(S, C.ToCSR(), t)
+}
@@ -0,0 +1,169 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// RunningStats accumulates the mean and covariance of observations
+// (covectors) one at a time by Welford's algorithm, which updates the
+// mean and the sums of squared deviations from it instead of summing
+// raw squares, so it doesn't lose precision when the mean is large
+// compared to the spread.
+type RunningStats struct {
+	n    int
+	mean []float64
+	// comoment[a*dim+b] is the sum over observations of the deviation
+	// of feature a from the mean times that of feature b.
+	comoment []float64
+	delta    []float64
+}
+
+// NewRunningStats makes an empty accumulator for observations with the
+// given number of features.
+func NewRunningStats(dim int) *RunningStats {
+	return &RunningStats{
+		mean:     make([]float64, dim),
+		comoment: make([]float64, dim*dim),
+		delta:    make([]float64, dim),
+	}
+}
+
+// Add includes another observation.
+func (s *RunningStats) Add(x Covector) {
+	CheckCovector(x)
+	dim := len(s.mean)
+	if ins, _ := x.Shape(); ins != dim {
+		panic(fmt.Errorf("expected %d features but got %d", dim, ins))
+	}
+	s.n++
+	for a := 0; a < dim; a++ {
+		s.delta[a] = x.Get(a, 0) - s.mean[a]
+		s.mean[a] += s.delta[a] / float64(s.n)
+	}
+	// The deviation from the old mean times the deviation from the new
+	// one is exactly the increase in the comoment.
+	for a := 0; a < dim; a++ {
+		after := x.Get(a, 0) - s.mean[a]
+		for b := 0; b < dim; b++ {
+			s.comoment[b*dim+a] += s.delta[b] * after
+		}
+	}
+}
+
+// Count returns how many observations have been added.
+func (s *RunningStats) Count() int { return s.n }
+
+// Mean returns the mean of the observations.
+func (s *RunningStats) Mean() Covector {
+	dim := len(s.mean)
+	m := NewCovector(dim)
+	for a := 0; a < dim; a++ {
+		m.Set(a, 0, s.mean[a])
+	}
+	return m
+}
+
+// Variance returns the sample variance (dividing by one less than the
+// count) of each feature.
+func (s *RunningStats) Variance() Covector {
+	checkObservations(s.n)
+	dim := len(s.mean)
+	v := NewCovector(dim)
+	for a := 0; a < dim; a++ {
+		v.Set(a, 0, s.comoment[a*dim+a]/float64(s.n-1))
+	}
+	return v
+}
+
+// Covariance returns the sample covariance matrix of the features.
+func (s *RunningStats) Covariance() Matrix {
+	checkObservations(s.n)
+	dim := len(s.mean)
+	C := NewArrayMatrix(dim, dim)
+	for b := 0; b < dim; b++ {
+		for a := 0; a < dim; a++ {
+			C.Set(a, b, s.comoment[b*dim+a]/float64(s.n-1))
+		}
+	}
+	return C
+}
+
+// ColumnStats accumulates all of the observations (outputs) of X in a
+// single pass. ColumnMeans, ColumnVariances and Covariance make two
+// passes instead, which is a little more accurate when X is at hand.
+func ColumnStats(X Matrix) *RunningStats {
+	ins, outs := X.Shape()
+	s := NewRunningStats(ins)
+	for o := 0; o < outs; o++ {
+		s.Add(Slice(X, 0, ins, o, o+1))
+	}
+	return s
+}
+
+// ColumnMeans returns the mean of each column (input) of X.
+func ColumnMeans(X Matrix) Covector {
+	ins, outs := X.Shape()
+	if outs == 0 {
+		panic(fmt.Errorf("can't take the mean of no observations"))
+	}
+	mean := NewCovector(ins)
+	for i := 0; i < ins; i++ {
+		sum := 0.0
+		for o := 0; o < outs; o++ {
+			sum += X.Get(i, o)
+		}
+		m := sum / float64(outs)
+		// A second pass over the deviations corrects the rounding error
+		// of the first.
+		correction := 0.0
+		for o := 0; o < outs; o++ {
+			correction += X.Get(i, o) - m
+		}
+		mean.Set(i, 0, m+correction/float64(outs))
+	}
+	return mean
+}
+
+// ColumnVariances returns the sample variance of each column (input)
+// of X, by summing squared deviations from the mean rather than raw
+// squares.
+func ColumnVariances(X Matrix) Covector {
+	ins, outs := X.Shape()
+	checkObservations(outs)
+	mean := ColumnMeans(X)
+	variance := NewCovector(ins)
+	for i := 0; i < ins; i++ {
+		m := mean.Get(i, 0)
+		sum := 0.0
+		for o := 0; o < outs; o++ {
+			d := X.Get(i, o) - m
+			sum += d * d
+		}
+		variance.Set(i, 0, sum/float64(outs-1))
+	}
+	return variance
+}
+
+// Covariance returns the sample covariance between the columns
+// (inputs) of X.
+func Covariance(X Matrix) Matrix {
+	ins, outs := X.Shape()
+	checkObservations(outs)
+	Xc := center(X, ColumnMeans(X))
+	C := NewArrayMatrix(ins, ins)
+	for b := 0; b < ins; b++ {
+		xb := Slice(Xc, b, b+1, 0, outs)
+		for a := 0; a <= b; a++ {
+			c := DotProduct(Slice(Xc, a, a+1, 0, outs), Dual(xb)) / float64(outs-1)
+			C.Set(a, b, c)
+			C.Set(b, a, c)
+		}
+	}
+	return C
+}
+
+func checkObservations(n int) {
+	if n < 2 {
+		panic(fmt.Errorf("need at least 2 observations but have %d", n))
+	}
+}
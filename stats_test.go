@@ -0,0 +1,69 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestColumnStats(t *testing.T) {
+	X := NewArrayMatrix(2, 4)
+	X.Set(0, 0, 1)
+	X.Set(1, 0, 8)
+	X.Set(0, 1, 2)
+	X.Set(1, 1, 6)
+	X.Set(0, 2, 3)
+	X.Set(1, 2, 4)
+	X.Set(0, 3, 4)
+	X.Set(1, 3, 2)
+
+	mean := ColumnMeans(X)
+
+	ExpectFloat(2.5, mean.Get(0, 0), t)
+	ExpectFloat(5, mean.Get(1, 0), t)
+
+	variance := ColumnVariances(X)
+
+	ExpectFloat(5.0/3.0, variance.Get(0, 0), t)
+	ExpectFloat(20.0/3.0, variance.Get(1, 0), t)
+
+	C := Covariance(X)
+
+	ExpectFloat(5.0/3.0, C.Get(0, 0), t)
+	ExpectFloat(-10.0/3.0, C.Get(1, 0), t)
+	ExpectFloat(-10.0/3.0, C.Get(0, 1), t)
+	ExpectFloat(20.0/3.0, C.Get(1, 1), t)
+}
+
+func TestRunningStatsLargeMean(t *testing.T) {
+	// Summing raw squares would lose everything here.
+	s := NewRunningStats(1)
+	for _, f := range []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16} {
+		x := NewCovector(1)
+		x.Set(0, 0, f)
+		s.Add(x)
+	}
+
+	ExpectInt(4, s.Count(), t)
+	ExpectFloat(1e9+10, s.Mean().Get(0, 0), t)
+	ExpectFloat(30, s.Variance().Get(0, 0), t)
+}
+
+func TestColumnStatsMatchesTwoPass(t *testing.T) {
+	X := NewArrayMatrix(3, 5)
+	for o := 0; o < 5; o++ {
+		for i := 0; i < 3; i++ {
+			X.Set(i, o, float64((o*7+i*3)%5)+1e6*float64(i))
+		}
+	}
+
+	s := ColumnStats(X)
+	C := Covariance(X)
+	Cs := s.Covariance()
+	mean := ColumnMeans(X)
+
+	for b := 0; b < 3; b++ {
+		ExpectFloat(mean.Get(b, 0), s.Mean().Get(b, 0), t)
+		for a := 0; a < 3; a++ {
+			ExpectFloat(C.Get(a, b), Cs.Get(a, b), t)
+		}
+	}
+}
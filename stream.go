@@ -0,0 +1,140 @@
+package linear
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RowStream produces the observations (outputs) of a dataset one at a
+// time, so that online algorithms never need the whole design matrix
+// in memory.
+type RowStream interface {
+	// Next returns the next observation as a covector, or io.EOF when
+	// there are no more.
+	Next() (Matrix, error)
+}
+
+type matrixRowStream struct {
+	A Matrix
+	o int
+}
+
+// NewMatrixRowStream streams the outputs (rows) of A in order.
+func NewMatrixRowStream(A Matrix) RowStream {
+	return &matrixRowStream{A: A}
+}
+
+func (s *matrixRowStream) Next() (Matrix, error) {
+	ins, outs := s.A.Shape()
+	if s.o >= outs {
+		return nil, io.EOF
+	}
+	row := Copy(Slice(s.A, 0, ins, s.o, s.o+1))
+	s.o++
+	return row, nil
+}
+
+type csvRowStream struct {
+	r    *csv.Reader
+	skip bool
+}
+
+// NewCSVRowStream streams the records of a CSV file as rows, skipping
+// the first record if it's a header. Every record must have the same
+// number of numeric fields.
+func NewCSVRowStream(r io.Reader, header bool) RowStream {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+	return &csvRowStream{r: cr, skip: header}
+}
+
+func (s *csvRowStream) Next() (Matrix, error) {
+	if s.skip {
+		s.skip = false
+		if _, err := s.r.Read(); err != nil {
+			return nil, err
+		}
+	}
+	record, err := s.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := s.r.FieldPos(0)
+	return parseRow(record, line)
+}
+
+type textRowStream struct {
+	scanner *bufio.Scanner
+	line    int
+	ins     int
+}
+
+// NewTextRowStream streams whitespace separated numbers as rows, one
+// row per line. Blank lines and lines starting with # are skipped.
+func NewTextRowStream(r io.Reader) RowStream {
+	return &textRowStream{scanner: bufio.NewScanner(r), ins: -1}
+}
+
+func (s *textRowStream) Next() (Matrix, error) {
+	for s.scanner.Scan() {
+		s.line++
+		text := strings.TrimSpace(s.scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if s.ins >= 0 && len(fields) != s.ins {
+			return nil, fmt.Errorf("line %d: expected %d fields but got %d", s.line, s.ins, len(fields))
+		}
+		s.ins = len(fields)
+		return parseRow(fields, s.line)
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func parseRow(fields []string, line int) (Matrix, error) {
+	row := NewArrayMatrix(len(fields), 1)
+	for i, field := range fields {
+		f, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: field %d: %v", line, i+1, err)
+		}
+		row.Set(i, 0, f)
+	}
+	return row, nil
+}
+
+// CollectRows reads the rest of a stream into a matrix with one output
+// per row.
+func CollectRows(s RowStream) (Matrix, error) {
+	var data []float64
+	ins, outs := 0, 0
+	for {
+		row, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rowIns, _ := row.Shape()
+		if outs == 0 {
+			ins = rowIns
+		} else if rowIns != ins {
+			return nil, fmt.Errorf("row %d has %d inputs but previous rows have %d", outs, rowIns, ins)
+		}
+		for i := 0; i < ins; i++ {
+			data = append(data, row.Get(i, 0))
+		}
+		outs++
+	}
+	return MatrixFromSlice(data, ins, outs, ins), nil
+}
@@ -0,0 +1,80 @@
+package linear
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMatrixRowStream(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 1)
+	A.Set(1, 0, 2)
+	A.Set(0, 1, 3)
+	A.Set(1, 1, 4)
+
+	s := NewMatrixRowStream(A)
+
+	row, err := s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, outs := row.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(1, outs, t)
+	ExpectFloat(1, row.Get(0, 0), t)
+	ExpectFloat(2, row.Get(1, 0), t)
+
+	row, err = s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ExpectFloat(3, row.Get(0, 0), t)
+	ExpectFloat(4, row.Get(1, 0), t)
+
+	if _, err := s.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF but got %v", err)
+	}
+}
+
+func TestCSVRowStream(t *testing.T) {
+	s := NewCSVRowStream(strings.NewReader("x,y\n1, 2\n3,4.5\n"), true)
+
+	A, err := CollectRows(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ins, outs := A.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(2, outs, t)
+	ExpectFloat(1, A.Get(0, 0), t)
+	ExpectFloat(2, A.Get(1, 0), t)
+	ExpectFloat(3, A.Get(0, 1), t)
+	ExpectFloat(4.5, A.Get(1, 1), t)
+
+	s = NewCSVRowStream(strings.NewReader("1,2\n3,x\n"), false)
+	if _, err := CollectRows(s); err == nil {
+		t.Errorf("expected an error for a non-numeric field")
+	}
+}
+
+func TestTextRowStream(t *testing.T) {
+	s := NewTextRowStream(strings.NewReader("# comment\n1 2 3\n\n4\t5 6\n"))
+
+	A, err := CollectRows(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ins, outs := A.Shape()
+	ExpectInt(3, ins, t)
+	ExpectInt(2, outs, t)
+	ExpectFloat(3, A.Get(2, 0), t)
+	ExpectFloat(4, A.Get(0, 1), t)
+
+	s = NewTextRowStream(strings.NewReader("1 2\n3\n"))
+	if _, err := CollectRows(s); err == nil {
+		t.Errorf("expected an error for a ragged row")
+	}
+}
@@ -0,0 +1,58 @@
+package linear
+
+import (
+	"math"
+)
+
+// PrincipalAngles returns the angles between the subspaces spanned by
+// the columns (inputs) of U and of V, smallest first. Cosines of the
+// angles are the singular values of Dual(Qu)*Qv where Qu and Qv are
+// orthonormal bases for the subspaces, but small angles are found from
+// their sines instead since acos loses precision near 1.
+func PrincipalAngles(U, V Matrix) Vector {
+	CheckSameOuts(U, V)
+	Qu := orthonormalBasis(U)
+	Qv := orthonormalBasis(V)
+	C := Apply(Dual(Qu), Qv)
+	_, cosines, _ := DecomposeSVD(C)
+
+	// The sines are the singular values of what's left of Qv after
+	// projecting out the span of Qu.
+	S := Apply(Qu, C)
+	vIns, vOuts := Qv.Shape()
+	for o := 0; o < vOuts; o++ {
+		for i := 0; i < vIns; i++ {
+			S.Set(i, o, Qv.Get(i, o)-S.Get(i, o))
+		}
+	}
+	_, sines, _ := DecomposeSVD(S)
+	_, sDim := sines.Shape()
+
+	_, dim := cosines.Shape()
+	theta := NewVector(dim)
+	for d := 0; d < dim; d++ {
+		c := cosines.Get(0, d)
+		if c*c < 0.5 {
+			theta.Set(0, d, math.Acos(c))
+		} else {
+			theta.Set(0, d, math.Asin(math.Min(1, sines.Get(0, sDim-1-d))))
+		}
+	}
+	return theta
+}
+
+// GrassmannDistance returns the geodesic distance between the
+// subspaces spanned by the columns of U and of V, which is the L2 norm
+// of their principal angles.
+func GrassmannDistance(U, V Matrix) float64 {
+	return L2Norm(PrincipalAngles(U, V))
+}
+
+// orthonormalBasis returns the first columns of Q from the QR
+// decomposition of A, which span the same space as A if it has full
+// column rank.
+func orthonormalBasis(A Matrix) Matrix {
+	ins, outs := A.Shape()
+	Q, _ := DecomposeQR(A)
+	return Slice(Q, 0, ins, 0, outs)
+}
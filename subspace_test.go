@@ -0,0 +1,40 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPrincipalAngles(t *testing.T) {
+	// The xy plane.
+	U := NewArrayMatrix(2, 3)
+	U.Set(0, 0, 1)
+	U.Set(1, 1, 2)
+
+	// A plane containing the x axis tilted 45 degrees out of the xy
+	// plane.
+	V := NewArrayMatrix(2, 3)
+	V.Set(0, 0, 3)
+	V.Set(1, 1, 1)
+	V.Set(1, 2, 1)
+
+	theta := PrincipalAngles(U, V)
+
+	_, dim := theta.Shape()
+	ExpectInt(2, dim, t)
+	ExpectFloat(0, theta.Get(0, 0), t)
+	ExpectFloat(math.Pi/4, theta.Get(0, 1), t)
+	ExpectFloat(math.Pi/4, GrassmannDistance(U, V), t)
+}
+
+func TestPrincipalAnglesSameSubspace(t *testing.T) {
+	U := NewArrayMatrix(1, 2)
+	U.Set(0, 0, 1)
+	U.Set(0, 1, 1)
+
+	V := NewArrayMatrix(1, 2)
+	V.Set(0, 0, -2)
+	V.Set(0, 1, -2)
+
+	ExpectFloat(0, GrassmannDistance(U, V), t)
+}
@@ -0,0 +1,167 @@
+package linear
+
+import (
+	"math"
+)
+
+// DecomposeSVD decomposes A into U*S*Dual(V) where S is diagonal, by
+// rotating pairs of columns of A until they are all orthogonal to each
+// other (one-sided Jacobi). The singular values are returned as a
+// vector sigma in decreasing order, one per input or output of A
+// whichever is fewer. When A has at least as many outputs as inputs, U
+// has orthonormal columns for each non-zero singular value and V is
+// square and orthogonal. When A is wider the roles swap: U is square
+// and orthogonal and it's V that only has orthonormal columns.
+func DecomposeSVD(A Matrix) (U, sigma, V Matrix) {
+	ins, outs := A.Shape()
+	if outs < ins {
+		// Decompose the dual instead and swap the roles of U and V.
+		V, sigma, U = DecomposeSVD(Dual(A))
+		return U, sigma, V
+	}
+
+	U = Copy(A)
+	V = Identity(ins)
+	for sweep := 0; sweep < 64; sweep++ {
+		rotated := false
+		for p := 0; p < ins; p++ {
+			for q := p + 1; q < ins; q++ {
+				up := Slice(U, p, p+1, 0, outs)
+				uq := Slice(U, q, q+1, 0, outs)
+				alpha := DotProduct(up, Dual(up))
+				beta := DotProduct(uq, Dual(uq))
+				gamma := DotProduct(up, Dual(uq))
+				if gamma == 0 || math.Abs(gamma) <= 1e-15*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+
+				// Choose the rotation that zeroes the off-diagonal
+				// entry of the 2x2 Gram matrix of columns p and q.
+				zeta := (beta - alpha) / (2 * gamma)
+				t := 1 / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				if zeta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+				rotateColumns(U, p, q, c, s)
+				rotateColumns(V, p, q, c, s)
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+
+	// The columns are now orthogonal, so their lengths are the singular
+	// values and normalizing them gives the left singular vectors.
+	sigma = NewArrayMatrix(1, ins)
+	for i := 0; i < ins; i++ {
+		u := Slice(U, i, i+1, 0, outs)
+		mag := L2Norm(u)
+		sigma.Set(0, i, mag)
+		if mag != 0 {
+			for o := 0; o < outs; o++ {
+				u.Set(0, o, u.Get(0, o)/mag)
+			}
+		}
+	}
+
+	// Selection sort so that the singular values are decreasing.
+	for i := 0; i < ins; i++ {
+		max := i
+		for j := i + 1; j < ins; j++ {
+			if sigma.Get(0, j) > sigma.Get(0, max) {
+				max = j
+			}
+		}
+		if max != i {
+			s := sigma.Get(0, i)
+			sigma.Set(0, i, sigma.Get(0, max))
+			sigma.Set(0, max, s)
+			swapColumns(U, i, max)
+			swapColumns(V, i, max)
+		}
+	}
+
+	return U, sigma, V
+}
+
+// rotateColumns replaces columns p and q of A with c*p - s*q and
+// s*p + c*q respectively.
+func rotateColumns(A Matrix, p, q int, c, s float64) {
+	_, outs := A.Shape()
+	for o := 0; o < outs; o++ {
+		ap := A.Get(p, o)
+		aq := A.Get(q, o)
+		A.Set(p, o, c*ap-s*aq)
+		A.Set(q, o, s*ap+c*aq)
+	}
+}
+
+// swapColumns exchanges columns p and q of A.
+func swapColumns(A Matrix, p, q int) {
+	_, outs := A.Shape()
+	for o := 0; o < outs; o++ {
+		ap := A.Get(p, o)
+		A.Set(p, o, A.Get(q, o))
+		A.Set(q, o, ap)
+	}
+}
+
+// PseudoInverse returns the Moore-Penrose pseudoinverse of A, treating
+// singular values at most max(ins, outs) times machine epsilon of the
+// largest as zero, like PseudoInverseWithTolerance.
+func PseudoInverse(A Matrix) Matrix {
+	ins, outs := A.Shape()
+	return PseudoInverseWithTolerance(A, math.Max(float64(ins), float64(outs))*0x1p-52)
+}
+
+// PseudoInverseWithTolerance returns the Moore-Penrose pseudoinverse
+// of A from its SVD, V*Inverse(S)*Dual(U), inverting only the singular
+// values greater than tol times the largest. It maps from A's outputs
+// back to its inputs, so PseudoInverse(A)*b is the least squares
+// solution of A*x = b with the smallest norm, even when A is rank
+// deficient.
+func PseudoInverseWithTolerance(A Matrix, tol float64) Matrix {
+	ins, outs := A.Shape()
+	U, sigma, V := DecomposeSVD(A)
+	_, k := sigma.Shape()
+	P := NewArrayMatrix(outs, ins)
+	if k == 0 {
+		return P
+	}
+	cutoff := tol * sigma.Get(0, 0)
+	for r := 0; r < k; r++ {
+		s := sigma.Get(0, r)
+		if s <= cutoff || s == 0 {
+			// Singular values are decreasing.
+			break
+		}
+		for i := 0; i < ins; i++ {
+			v := V.Get(r, i) / s
+			for o := 0; o < outs; o++ {
+				P.Set(o, i, P.Get(o, i)+v*U.Get(r, o))
+			}
+		}
+	}
+	return P
+}
+
+// SolveHomogeneous returns the unit vector x that minimizes |A*x|,
+// which solves A*x = 0 when A has a null space. It's the right
+// singular vector for the smallest singular value, and is only unique
+// up to sign.
+func SolveHomogeneous(A Matrix) Vector {
+	ins, outs := A.Shape()
+	if outs < ins {
+		// Zero equations don't change the answer but make sure V has a
+		// column for every input.
+		padded := NewArrayMatrix(ins, ins)
+		CopyInto(A, Slice(padded, 0, ins, 0, outs))
+		A = padded
+	}
+	_, _, V := DecomposeSVD(A)
+	return Copy(Slice(V, ins-1, ins, 0, ins))
+}
@@ -0,0 +1,113 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecomposeSVDWide(t *testing.T) {
+	// For a wide A it's U that's square and V that isn't.
+	A := MatrixFromSlice([]float64{
+		3, 2, 2,
+		2, 3, -2,
+	}, 3, 2, 3)
+	U, _, V := DecomposeSVD(A)
+	ExpectMatrix(Identity(2), Apply(U, Dual(U)), t)
+	ins, outs := V.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(3, outs, t)
+	ExpectMatrix(Identity(2), Apply(Dual(V), V), t)
+}
+
+func TestDecomposeSVD(t *testing.T) {
+	A := NewArrayMatrix(3, 2)
+	A.Set(0, 0, 3)
+	A.Set(1, 0, 2)
+	A.Set(2, 0, 2)
+	A.Set(0, 1, 2)
+	A.Set(1, 1, 3)
+	A.Set(2, 1, -2)
+
+	for _, B := range []Matrix{A, Dual(A)} {
+		U, sigma, V := DecomposeSVD(B)
+
+		_, dim := sigma.Shape()
+		ExpectInt(2, dim, t)
+		ExpectFloat(5, sigma.Get(0, 0), t)
+		ExpectFloat(3, sigma.Get(0, 1), t)
+
+		// U*S*Dual(V) should be the same as B.
+		US := Copy(U)
+		uIns, uOuts := US.Shape()
+		for o := 0; o < uOuts; o++ {
+			for i := 0; i < uIns; i++ {
+				US.Set(i, o, US.Get(i, o)*sigma.Get(0, i))
+			}
+		}
+		C := Apply(US, Dual(V))
+
+		bIns, bOuts := B.Shape()
+		for o := 0; o < bOuts; o++ {
+			for i := 0; i < bIns; i++ {
+				ExpectFloat(B.Get(i, o), C.Get(i, o), t)
+			}
+		}
+
+		UTU := Apply(Dual(U), U)
+		for o := 0; o < 2; o++ {
+			for i := 0; i < 2; i++ {
+				if i == o {
+					ExpectFloat(1, UTU.Get(i, o), t)
+				} else {
+					ExpectFloat(0, UTU.Get(i, o), t)
+				}
+			}
+		}
+	}
+}
+
+func TestSolveHomogeneous(t *testing.T) {
+	// The null space of a rank 2 map from 3 dimensions is the cross
+	// product of its rows.
+	A := MatrixFromSlice([]float64{
+		1, 2, 3,
+		0, 1, 4,
+	}, 3, 2, 3)
+
+	x := SolveHomogeneous(A)
+
+	ExpectFloat(1, L2Norm(x), t)
+	ExpectMatrix(NewVector(2), Apply(A, x), t)
+	n := math.Sqrt(25 + 16 + 1)
+	if x.Get(0, 0) < 0 {
+		x = mapEntries(x, func(f float64) float64 { return -f })
+	}
+	ExpectMatrix(MatrixFromSlice([]float64{5 / n, -4 / n, 1 / n}, 1, 3, 1), x, t)
+}
+
+func TestPseudoInverse(t *testing.T) {
+	// Full column rank, where it gives the least squares solution.
+	X := MatrixFromSlice([]float64{1, 0, 1, 1, 1, 2}, 2, 3, 2)
+	y := MatrixFromSlice([]float64{1, 3, 5}, 1, 3, 1)
+	P := PseudoInverse(X)
+	ins, outs := P.Shape()
+	ExpectInt(3, ins, t)
+	ExpectInt(2, outs, t)
+	ExpectMatrix(OrdinaryLeastSquares(X, y), Apply(P, y), t)
+
+	// Rank deficient and wide, checking the Penrose conditions.
+	for _, A := range []Matrix{
+		MatrixFromSlice([]float64{1, 2, 2, 4, 3, 6}, 2, 3, 2),
+		MatrixFromSlice([]float64{1, 2, 3, 2, 4, 6}, 3, 2, 3),
+	} {
+		P := PseudoInverse(A)
+		ExpectMatrix(A, Apply(A, Apply(P, A)), t)
+		ExpectMatrix(P, Apply(P, Apply(A, P)), t)
+		AP := Apply(A, P)
+		ExpectMatrix(AP, Dual(AP), t)
+		PA := Apply(P, A)
+		ExpectMatrix(PA, Dual(PA), t)
+	}
+
+	ExpectMatrix(NewArrayMatrix(2, 3), PseudoInverse(NewArrayMatrix(3, 2)), t)
+}
@@ -0,0 +1,152 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// SVM is a linear classifier that labels an observation x by the sign
+// of Dual(Weights)*x + Bias.
+type SVM struct {
+	Weights Vector
+	Bias    float64
+}
+
+// LinearSVM trains a soft margin linear support vector machine on the
+// observations (outputs) of X with labels y, each -1 or +1. C trades
+// the margin against the hinge losses of the points inside it. It
+// solves the dual by coordinate descent, one observation at a time
+// (as LIBLINEAR does), which only ever needs an observation's dot
+// product with the weights, so a sparse X is cheap. The bias is
+// learned as the weight of an extra constant feature.
+func LinearSVM(X Matrix, y []int, C float64) *SVM {
+	features, n := X.Shape()
+	if len(y) != n {
+		panic(fmt.Errorf("%d labels for %d observations", len(y), n))
+	}
+	for o, label := range y {
+		if label != -1 && label != 1 {
+			panic(fmt.Errorf("label %d at %d isn't -1 or +1", label, o))
+		}
+	}
+	rows := observationRows(X)
+	w := make([]float64, features)
+	bias := 0.0
+	alpha := make([]float64, n)
+	diag := make([]float64, n)
+	for o := range diag {
+		diag[o] = rows.dot(o, rows.dense(o)) + 1
+	}
+
+	// The order is shuffled each sweep, from a fixed seed so training is
+	// repeatable.
+	r := rand.New(rand.NewSource(1))
+	order := make([]int, n)
+	for o := range order {
+		order[o] = o
+	}
+	for sweep := 0; sweep < 1000; sweep++ {
+		r.Shuffle(n, func(a, b int) { order[a], order[b] = order[b], order[a] })
+		maxPG, minPG := math.Inf(-1), math.Inf(1)
+		for _, o := range order {
+			yo := float64(y[o])
+			G := yo*(rows.dot(o, w)+bias) - 1
+			// The gradient projected onto the box 0 <= alpha <= C.
+			PG := G
+			if alpha[o] == 0 {
+				PG = math.Min(G, 0)
+			} else if alpha[o] == C {
+				PG = math.Max(G, 0)
+			}
+			maxPG = math.Max(maxPG, PG)
+			minPG = math.Min(minPG, PG)
+			if PG == 0 || diag[o] == 0 {
+				continue
+			}
+			prev := alpha[o]
+			alpha[o] = math.Min(math.Max(prev-G/diag[o], 0), C)
+			if step := (alpha[o] - prev) * yo; step != 0 {
+				rows.axpy(o, step, w)
+				bias += step
+			}
+		}
+		if maxPG-minPG < 1e-6 {
+			break
+		}
+	}
+	return &SVM{Weights: vectorFromSlice(w), Bias: bias}
+}
+
+// Decision returns Dual(Weights)*x + Bias for each observation x of X,
+// which is positive on the +1 side.
+func (s *SVM) Decision(X Matrix) Vector {
+	d := Apply(X, s.Weights)
+	_, n := d.Shape()
+	for o := 0; o < n; o++ {
+		d.Set(0, o, d.Get(0, o)+s.Bias)
+	}
+	return d
+}
+
+// Predict returns the label of each observation of X.
+func (s *SVM) Predict(X Matrix) []int {
+	d := s.Decision(X)
+	_, n := d.Shape()
+	labels := make([]int, n)
+	for o := range labels {
+		labels[o] = 1
+		if d.Get(0, o) < 0 {
+			labels[o] = -1
+		}
+	}
+	return labels
+}
+
+// rowAccess reads the observations (outputs) of a design matrix one at
+// a time, touching only the non-zeros if it's sparse.
+type rowAccess struct {
+	X Matrix
+	m *CSR
+}
+
+func observationRows(X Matrix) rowAccess {
+	m, _ := csrOf(X)
+	return rowAccess{X, m}
+}
+
+// dot returns row o of X dotted with w.
+func (r rowAccess) dot(o int, w []float64) float64 {
+	sum := 0.0
+	if r.m != nil {
+		for j := r.m.rowStart[o]; j < r.m.rowStart[o+1]; j++ {
+			sum += r.m.values[j] * w[r.m.cols[j]]
+		}
+		return sum
+	}
+	for i := range w {
+		sum += r.X.Get(i, o) * w[i]
+	}
+	return sum
+}
+
+// axpy adds s times row o of X to w.
+func (r rowAccess) axpy(o int, s float64, w []float64) {
+	if r.m != nil {
+		for j := r.m.rowStart[o]; j < r.m.rowStart[o+1]; j++ {
+			w[r.m.cols[j]] += s * r.m.values[j]
+		}
+		return
+	}
+	for i := range w {
+		w[i] += s * r.X.Get(i, o)
+	}
+}
+
+// dense returns a copy of row o of X.
+func (r rowAccess) dense(o int) []float64 {
+	ins, _ := r.X.Shape()
+	row := make([]float64, ins)
+	r.axpy(o, 1, row)
+	return row
+}
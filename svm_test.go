@@ -0,0 +1,43 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLinearSVM(t *testing.T) {
+	// Two separable clouds either side of the line x0 + x1 = 1.
+	r := rand.New(rand.NewSource(1))
+	n := 40
+	X := NewArrayMatrix(2, n)
+	y := make([]int, n)
+	for o := 0; o < n; o++ {
+		y[o] = 1 - 2*(o%2)
+		for {
+			a, b := r.Float64()*4-2, r.Float64()*4-2
+			if margin := float64(y[o]) * (a + b - 1); margin > 0.3 {
+				X.Set(0, o, a)
+				X.Set(1, o, b)
+				break
+			}
+		}
+	}
+
+	s := LinearSVM(X, y, 10)
+
+	predicted := s.Predict(X)
+	for o := range y {
+		ExpectInt(y[o], predicted[o], t)
+	}
+	// The boundary should be close to the true one.
+	w0, w1 := s.Weights.Get(0, 0), s.Weights.Get(0, 1)
+	if ratio := w0 / w1; ratio < 0.7 || ratio > 1.4 {
+		t.Errorf("expected weights in the ratio 1:1 but got %v:%v", w0, w1)
+	}
+
+	// The same data stored sparsely gives the same classifier.
+	sparse := LinearSVM(Sparsify(X, 0), y, 10)
+
+	ExpectFloat(s.Bias, sparse.Bias, t)
+	ExpectMatrix(s.Weights, sparse.Weights, t)
+}
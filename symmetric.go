@@ -0,0 +1,147 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// SymmetricCSR is a sparse symmetric Matrix that only stores the
+// entries on and above the diagonal (where in >= out), as a CSR. The
+// entries below the diagonal are read from their mirror images, which
+// halves the memory for things like graph Laplacians and covariances.
+type SymmetricCSR struct {
+	upper *CSR
+}
+
+// NewSymmetricCSR makes a new zero symmetric sparse Matrix.
+func NewSymmetricCSR(dim int) *SymmetricCSR {
+	return &SymmetricCSR{NewCSR(dim, dim)}
+}
+
+// NewSymmetricCSRFromEntries makes a symmetric sparse Matrix from
+// entries in either triangle; an entry below the diagonal is moved to
+// its mirror image. Entries at the same position are added together,
+// so a matrix should give each off-diagonal entry only once.
+func NewSymmetricCSRFromEntries(dim int, entries []SparseEntry) *SymmetricCSR {
+	upper := make([]SparseEntry, len(entries))
+	for j, e := range entries {
+		if e.In < e.Out {
+			e.In, e.Out = e.Out, e.In
+		}
+		upper[j] = e
+	}
+	return &SymmetricCSR{NewCSRFromEntries(dim, dim, upper)}
+}
+
+// SymmetricCSRFromCSR keeps the upper triangle of A, which is assumed
+// to be symmetric.
+func SymmetricCSRFromCSR(A *CSR) *SymmetricCSR {
+	if A.ins != A.outs {
+		panic(fmt.Errorf("not square shape=(%d, %d)", A.ins, A.outs))
+	}
+	var entries []SparseEntry
+	for o := 0; o < A.outs; o++ {
+		for j := A.rowStart[o]; j < A.rowStart[o+1]; j++ {
+			if A.cols[j] >= o {
+				entries = append(entries, SparseEntry{A.cols[j], o, A.values[j]})
+			}
+		}
+	}
+	return &SymmetricCSR{NewCSRFromEntries(A.ins, A.outs, entries)}
+}
+
+func (m *SymmetricCSR) Shape() (ins, outs int) { return m.upper.Shape() }
+
+func (m *SymmetricCSR) Get(in, out int) float64 {
+	if in < out {
+		in, out = out, in
+	}
+	return m.upper.Get(in, out)
+}
+
+// Set changes an entry and its mirror image together.
+func (m *SymmetricCSR) Set(in, out int, value float64) {
+	if in < out {
+		in, out = out, in
+	}
+	m.upper.Set(in, out, value)
+}
+
+// NonZeros returns the number of stored entries, counting each pair of
+// mirror images once.
+func (m *SymmetricCSR) NonZeros() int { return m.upper.NonZeros() }
+
+// ToCSR returns the full matrix, with both triangles stored, as a CSR.
+func (m *SymmetricCSR) ToCSR() *CSR {
+	u := m.upper
+	entries := make([]SparseEntry, 0, 2*u.NonZeros())
+	for o := 0; o < u.outs; o++ {
+		for j := u.rowStart[o]; j < u.rowStart[o+1]; j++ {
+			i := u.cols[j]
+			entries = append(entries, SparseEntry{i, o, u.values[j]})
+			if i != o {
+				entries = append(entries, SparseEntry{o, i, u.values[j]})
+			}
+		}
+	}
+	return NewCSRFromEntries(u.ins, u.outs, entries)
+}
+
+// asSymmetric sees through Dual, which doesn't change a symmetric
+// matrix, to a SymmetricCSR.
+func asSymmetric(A Matrix) (*SymmetricCSR, bool) {
+	switch a := A.(type) {
+	case *SymmetricCSR:
+		return a, true
+	case *dualMatrix:
+		return asSymmetric(a.A)
+	}
+	return nil, false
+}
+
+// symmetricCompose is ComposeInto for when one of A and B is a
+// SymmetricCSR and the other is dense. It returns false if it doesn't
+// apply.
+func symmetricCompose(A, B, dst Matrix) bool {
+	if m, ok := asSymmetric(B); ok && !isSparse(A) {
+		cols, _ := A.Shape()
+		countFlops(4 * m.NonZeros() * cols)
+		symmetricMM(m, A, dst)
+		return true
+	}
+	if m, ok := asSymmetric(A); ok && !isSparse(B) {
+		_, outs := B.Shape()
+		countFlops(4 * m.NonZeros() * outs)
+		// B*S = Dual(S*Dual(B)) since S is symmetric.
+		symmetricMM(m, Dual(B), Dual(dst))
+		return true
+	}
+	return false
+}
+
+// symmetricMM writes S*X into dst, using each stored entry of S for
+// both itself and its mirror image.
+func symmetricMM(m *SymmetricCSR, X, dst Matrix) {
+	addRow := rowAdder(X, dst)
+	u := m.upper
+	for o := 0; o < u.outs; o++ {
+		for j := u.rowStart[o]; j < u.rowStart[o+1]; j++ {
+			i, v := u.cols[j], u.values[j]
+			addRow(v, i, o)
+			if i != o {
+				addRow(v, o, i)
+			}
+		}
+	}
+}
+
+// isSparse reports whether A is stored in one of the sparse formats.
+func isSparse(A Matrix) bool {
+	if _, _, ok := asSparse(A); ok {
+		return true
+	}
+	if _, _, ok := asBSR(A); ok {
+		return true
+	}
+	_, ok := asSymmetric(A)
+	return ok
+}
@@ -0,0 +1,41 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSymmetricCSR(t *testing.T) {
+	D := Difference1D(5)
+	L := Apply(Dual(D), D).(*CSR)
+	S := SymmetricCSRFromCSR(L)
+
+	// The path Laplacian has 5 diagonal entries and 4 pairs.
+	ExpectInt(9, S.NonZeros(), t)
+	ExpectMatrix(L, S, t)
+	ExpectMatrix(L, S.ToCSR(), t)
+
+	S.Set(0, 3, 2)
+	ExpectFloat(2, S.Get(3, 0), t)
+
+	r := rand.New(rand.NewSource(1))
+	X := NewArrayMatrix(3, 5)
+	for o := 0; o < 5; o++ {
+		for i := 0; i < 3; i++ {
+			X.Set(i, o, r.NormFloat64())
+		}
+	}
+	dense := Densify(S)
+	ExpectMatrix(Apply(dense, X), Apply(S, X), t)
+	ExpectMatrix(Apply(Dual(X), dense), Apply(Dual(X), S), t)
+	ExpectMatrix(Apply(dense, X), Apply(Dual(S), X), t)
+}
+
+func TestNewSymmetricCSRFromEntries(t *testing.T) {
+	S := NewSymmetricCSRFromEntries(3, []SparseEntry{{0, 0, 4}, {0, 2, 1}, {1, 1, 3}})
+
+	ExpectFloat(1, S.Get(2, 0), t)
+	ExpectFloat(1, S.Get(0, 2), t)
+	ExpectFloat(0, S.Get(1, 0), t)
+	ExpectInt(3, S.NonZeros(), t)
+}
@@ -0,0 +1,230 @@
+package linear
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Table is a minimal data frame: named columns of the same length,
+// each either numeric or categorical, in the order they were added.
+type Table struct {
+	rows        int
+	names       []string
+	numeric     map[string][]float64
+	categorical map[string][]string
+}
+
+// NewTable makes an empty table.
+func NewTable() *Table {
+	return &Table{numeric: map[string][]float64{}, categorical: map[string][]string{}}
+}
+
+// AddNumeric adds a numeric column, which the table keeps (rather than
+// copies).
+func (t *Table) AddNumeric(name string, values []float64) {
+	t.addColumn(name, len(values))
+	t.numeric[name] = values
+}
+
+// AddCategorical adds a column of categories, which the table keeps
+// (rather than copies).
+func (t *Table) AddCategorical(name string, values []string) {
+	t.addColumn(name, len(values))
+	t.categorical[name] = values
+}
+
+func (t *Table) addColumn(name string, n int) {
+	if t.has(name) {
+		panic(fmt.Errorf("duplicate column %q", name))
+	}
+	if len(t.names) > 0 && n != t.rows {
+		panic(fmt.Errorf("column %q has %d rows but expected %d", name, n, t.rows))
+	}
+	t.rows = n
+	t.names = append(t.names, name)
+}
+
+func (t *Table) has(name string) bool {
+	_, numeric := t.numeric[name]
+	_, categorical := t.categorical[name]
+	return numeric || categorical
+}
+
+// Rows returns the number of rows.
+func (t *Table) Rows() int { return t.rows }
+
+// Columns returns the names of the columns in order.
+func (t *Table) Columns() []string { return append([]string(nil), t.names...) }
+
+// Numeric returns the numeric column name, and whether there is one.
+func (t *Table) Numeric(name string) ([]float64, bool) {
+	values, ok := t.numeric[name]
+	return values, ok
+}
+
+// Categorical returns the categorical column name, and whether there
+// is one.
+func (t *Table) Categorical(name string) ([]string, bool) {
+	values, ok := t.categorical[name]
+	return values, ok
+}
+
+// Levels returns the distinct values of the categorical column name in
+// sorted order.
+func (t *Table) Levels(name string) []string {
+	values, ok := t.categorical[name]
+	if !ok {
+		panic(fmt.Errorf("no categorical column %q", name))
+	}
+	seen := map[string]bool{}
+	var levels []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			levels = append(levels, v)
+		}
+	}
+	sort.Strings(levels)
+	return levels
+}
+
+// Formula specifies a linear model over the columns of a table.
+type Formula struct {
+	// Response is the numeric column to predict, or empty for none.
+	Response string
+	// Terms are the predictors. A term with one column is a main
+	// effect and one with several is their interaction, the product of
+	// their codings.
+	Terms [][]string
+	// Intercept adds a column of ones first.
+	Intercept bool
+}
+
+// DesignMatrix builds the design matrix for the formula, with an
+// observation (output) per row of the table and a labeled feature
+// (input) per column of the coding, along with the response if the
+// formula has one. A numeric column is coded as itself and a
+// categorical one as a dummy 0 or 1 column per level, leaving out the
+// first level (which the intercept covers) except for the first
+// categorical main effect of a model without an intercept.
+func (t *Table) DesignMatrix(f Formula) (X *LabeledMatrix, y Vector) {
+	var columns []designColumn
+	if f.Intercept {
+		ones := make([]float64, t.rows)
+		for o := range ones {
+			ones[o] = 1
+		}
+		columns = append(columns, designColumn{"(Intercept)", ones})
+	}
+
+	fullCoding := !f.Intercept
+	for _, term := range f.Terms {
+		if len(term) == 0 {
+			panic(fmt.Errorf("empty term"))
+		}
+		// Start with the product of nothing and multiply in the coding
+		// of each column of the term.
+		product := []designColumn{{"", nil}}
+		for _, name := range term {
+			full := false
+			if _, ok := t.categorical[name]; ok && len(term) == 1 && fullCoding {
+				full, fullCoding = true, false
+			}
+			var next []designColumn
+			for _, p := range product {
+				for _, c := range t.coding(name, full) {
+					values := c.values
+					if p.values != nil {
+						values = make([]float64, t.rows)
+						for o := range values {
+							values[o] = p.values[o] * c.values[o]
+						}
+					}
+					label := c.name
+					if p.name != "" {
+						label = p.name + ":" + c.name
+					}
+					next = append(next, designColumn{label, values})
+				}
+			}
+			product = next
+		}
+		columns = append(columns, product...)
+	}
+
+	labels := make([]string, len(columns))
+	A := NewArrayMatrix(len(columns), t.rows)
+	for i, c := range columns {
+		labels[i] = c.name
+		for o, v := range c.values {
+			A.Set(i, o, v)
+		}
+	}
+	X = Label(A, labels, nil)
+
+	if f.Response != "" {
+		values, ok := t.numeric[f.Response]
+		if !ok {
+			panic(fmt.Errorf("no numeric column %q for the response", f.Response))
+		}
+		y = NewVector(t.rows)
+		for o, v := range values {
+			y.Set(0, o, v)
+		}
+	}
+	return X, y
+}
+
+// designColumn is a named column of a design matrix.
+type designColumn struct {
+	name   string
+	values []float64
+}
+
+// coding returns the columns that code the table column name, with a
+// dummy for every level if full.
+func (t *Table) coding(name string, full bool) []designColumn {
+	if values, ok := t.numeric[name]; ok {
+		return []designColumn{{name, values}}
+	}
+	values, ok := t.categorical[name]
+	if !ok {
+		panic(fmt.Errorf("no column %q", name))
+	}
+	levels := t.Levels(name)
+	if !full {
+		levels = levels[1:]
+	}
+	columns := make([]designColumn, len(levels))
+	for l, level := range levels {
+		dummy := make([]float64, t.rows)
+		for o, v := range values {
+			if v == level {
+				dummy[o] = 1
+			}
+		}
+		columns[l] = designColumn{fmt.Sprintf("%s[%s]", name, level), dummy}
+	}
+	return columns
+}
+
+// String returns the formula in the usual notation, like
+// "y ~ x1 + x2 + x1:x2 - 1".
+func (f Formula) String() string {
+	var terms []string
+	for _, term := range f.Terms {
+		terms = append(terms, strings.Join(term, ":"))
+	}
+	rhs := strings.Join(terms, " + ")
+	if !f.Intercept {
+		if rhs == "" {
+			rhs = "0"
+		} else {
+			rhs += " - 1"
+		}
+	} else if rhs == "" {
+		rhs = "1"
+	}
+	return f.Response + " ~ " + rhs
+}
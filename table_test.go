@@ -0,0 +1,77 @@
+package linear
+
+import (
+	"testing"
+)
+
+func exampleTable() *Table {
+	t := NewTable()
+	t.AddNumeric("y", []float64{1, 3, 4, 8, 9, 12})
+	t.AddNumeric("x", []float64{0, 1, 2, 3, 4, 5})
+	t.AddCategorical("color", []string{"red", "blue", "red", "green", "blue", "green"})
+	return t
+}
+
+func expectLabels(expect []string, got []string, t *testing.T) {
+	t.Helper()
+	if len(expect) != len(got) {
+		t.Fatalf("expected labels %v but got %v", expect, got)
+	}
+	for i := range expect {
+		if expect[i] != got[i] {
+			t.Errorf("expected labels %v but got %v", expect, got)
+			return
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	table := exampleTable()
+
+	ExpectInt(6, table.Rows(), t)
+	expectLabels([]string{"y", "x", "color"}, table.Columns(), t)
+	expectLabels([]string{"blue", "green", "red"}, table.Levels("color"), t)
+	if _, ok := table.Numeric("color"); ok {
+		t.Errorf("expected color not to be numeric")
+	}
+}
+
+func TestDesignMatrix(t *testing.T) {
+	table := exampleTable()
+
+	X, y := table.DesignMatrix(Formula{
+		Response:  "y",
+		Terms:     [][]string{{"x"}, {"color"}, {"x", "color"}},
+		Intercept: true,
+	})
+
+	expectLabels([]string{
+		"(Intercept)", "x", "color[green]", "color[red]", "x:color[green]", "x:color[red]",
+	}, X.InLabels, t)
+	ExpectMatrix(MatrixFromSlice([]float64{
+		1, 0, 0, 1, 0, 0,
+		1, 1, 0, 0, 0, 0,
+		1, 2, 0, 1, 0, 2,
+		1, 3, 1, 0, 3, 0,
+		1, 4, 0, 0, 0, 0,
+		1, 5, 1, 0, 5, 0,
+	}, 6, 6, 6), X, t)
+	ExpectFloat(8, y.Get(0, 3), t)
+}
+
+func TestDesignMatrixNoIntercept(t *testing.T) {
+	table := exampleTable()
+
+	// Without an intercept the first factor gets every level.
+	X, y := table.DesignMatrix(Formula{Terms: [][]string{{"color"}, {"x"}}})
+
+	expectLabels([]string{"color[blue]", "color[green]", "color[red]", "x"}, X.InLabels, t)
+	if y != nil {
+		t.Errorf("expected no response")
+	}
+	ExpectFloat(1, X.Get(0, 1), t)
+	ExpectFloat(0, X.Get(0, 0), t)
+	if s := (Formula{"y", [][]string{{"a"}, {"a", "b"}}, false}).String(); s != "y ~ a + a:b - 1" {
+		t.Errorf("unexpected formula %q", s)
+	}
+}
@@ -0,0 +1,154 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// Tensor is a dense array with any number of indices (modes), for data
+// with more structure than rows and columns. Unfold flattens it into a
+// Matrix so the rest of the package can work on it.
+type Tensor struct {
+	dims    []int
+	strides []int
+	data    []float64
+}
+
+// NewTensor makes a new zero Tensor with the given size for each mode.
+func NewTensor(dims ...int) *Tensor {
+	size := 1
+	for _, d := range dims {
+		if d < 0 {
+			panic(fmt.Errorf("negative dimension in %v", dims))
+		}
+		size *= d
+	}
+	countAlloc(size)
+	return TensorFromSlice(make([]float64, size), dims...)
+}
+
+// TensorFromSlice makes a Tensor that reads and writes data in place,
+// with the last index varying fastest (like a Go array of arrays).
+func TensorFromSlice(data []float64, dims ...int) *Tensor {
+	t := &Tensor{dims: append([]int(nil), dims...), strides: make([]int, len(dims)), data: data}
+	size := 1
+	for k := len(dims) - 1; k >= 0; k-- {
+		t.strides[k] = size
+		size *= dims[k]
+	}
+	if len(data) != size {
+		panic(fmt.Errorf("%d entries for dimensions %v", len(data), dims))
+	}
+	return t
+}
+
+// Dims returns the size of each mode.
+func (t *Tensor) Dims() []int { return append([]int(nil), t.dims...) }
+
+// Rank returns the number of modes.
+func (t *Tensor) Rank() int { return len(t.dims) }
+
+func (t *Tensor) offset(index []int) int {
+	if len(index) != len(t.dims) {
+		panic(fmt.Errorf("%d indices for rank %d", len(index), len(t.dims)))
+	}
+	off := 0
+	for k, i := range index {
+		if i < 0 || i >= t.dims[k] {
+			panic(fmt.Errorf("%v is out of bounds %v", index, t.dims))
+		}
+		off += i * t.strides[k]
+	}
+	return off
+}
+
+// At returns the entry at the given index, one for each mode.
+func (t *Tensor) At(index ...int) float64 { return t.data[t.offset(index)] }
+
+// SetAt changes the entry at the given index.
+func (t *Tensor) SetAt(value float64, index ...int) { t.data[t.offset(index)] = value }
+
+// unfolding is a mode-n unfolding of a Tensor, reading and writing it
+// in place.
+type unfolding struct {
+	t    *Tensor
+	mode int
+	// inStrides are the offsets in t.data for a step in each of the
+	// other modes, in column order.
+	inDims, inStrides []int
+	ins               int
+}
+
+// Unfold returns the mode-n unfolding of t as a Matrix backed by it:
+// each fiber along that mode is a column (input), with an output (row)
+// for each index of the mode. The columns are in the order of Kolda and
+// Bader, with the earliest of the other modes varying fastest, which
+// is what the Kronecker product identities for Tucker and CP models
+// expect.
+func (t *Tensor) Unfold(mode int) Matrix {
+	if mode < 0 || mode >= len(t.dims) {
+		panic(fmt.Errorf("mode %d of a rank %d tensor", mode, len(t.dims)))
+	}
+	u := &unfolding{t: t, mode: mode, ins: 1}
+	for k, d := range t.dims {
+		if k == mode {
+			continue
+		}
+		u.inDims = append(u.inDims, d)
+		u.inStrides = append(u.inStrides, t.strides[k])
+		u.ins *= d
+	}
+	return u
+}
+
+func (u *unfolding) Shape() (ins, outs int) { return u.ins, u.t.dims[u.mode] }
+
+func (u *unfolding) offset(in, out int) int {
+	if in < 0 || in >= u.ins || out < 0 || out >= u.t.dims[u.mode] {
+		panic(fmt.Errorf("(%d, %d) is out of bounds (%d, %d)", in, out, u.ins, u.t.dims[u.mode]))
+	}
+	off := out * u.t.strides[u.mode]
+	for k, d := range u.inDims {
+		off += (in % d) * u.inStrides[k]
+		in /= d
+	}
+	return off
+}
+
+func (u *unfolding) Get(in, out int) float64        { return u.t.data[u.offset(in, out)] }
+func (u *unfolding) Set(in, out int, value float64) { u.t.data[u.offset(in, out)] = value }
+
+// Fold is the inverse of Unfold, copying a mode-n unfolding A back
+// into a new Tensor with the given dimensions.
+func Fold(A Matrix, mode int, dims ...int) *Tensor {
+	t := NewTensor(dims...)
+	U := t.Unfold(mode)
+	CheckSameShape(U, A)
+	CopyInto(A, U)
+	return t
+}
+
+// ModeProduct returns the n-mode product of t with A, which applies A
+// to every fiber along that mode: the result has A's outs in place of
+// that mode's size, which must be A's ins.
+func ModeProduct(t *Tensor, A Matrix, mode int) *Tensor {
+	U := t.Unfold(mode)
+	dims := t.Dims()
+	_, dims[mode] = A.Shape()
+	return Fold(Apply(A, U), mode, dims...)
+}
+
+// forEach calls f with every index of t, in storage order, and the
+// entry there.
+func (t *Tensor) forEach(f func(index []int, value float64)) {
+	index := make([]int, len(t.dims))
+	for _, v := range t.data {
+		f(index, v)
+		for k := len(index) - 1; k >= 0; k-- {
+			index[k]++
+			if index[k] < t.dims[k] {
+				break
+			}
+			index[k] = 0
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package linear
+
+import (
+	"testing"
+)
+
+// exampleTensor is the 3 by 4 by 2 tensor from Kolda and Bader, with
+// frontal slices 1..12 and 13..24 filled in column by column.
+func exampleTensor() *Tensor {
+	t := NewTensor(3, 4, 2)
+	v := 1.0
+	for k := 0; k < 2; k++ {
+		for j := 0; j < 4; j++ {
+			for i := 0; i < 3; i++ {
+				t.SetAt(v, i, j, k)
+				v++
+			}
+		}
+	}
+	return t
+}
+
+func TestTensorUnfold(t *testing.T) {
+	X := exampleTensor()
+	ExpectInt(3, X.Rank(), t)
+	ExpectFloat(24, X.At(2, 3, 1), t)
+
+	ExpectMatrix(MatrixFromSlice([]float64{
+		1, 4, 7, 10, 13, 16, 19, 22,
+		2, 5, 8, 11, 14, 17, 20, 23,
+		3, 6, 9, 12, 15, 18, 21, 24,
+	}, 8, 3, 8), X.Unfold(0), t)
+	ExpectMatrix(MatrixFromSlice([]float64{
+		1, 2, 3, 13, 14, 15,
+		4, 5, 6, 16, 17, 18,
+		7, 8, 9, 19, 20, 21,
+		10, 11, 12, 22, 23, 24,
+	}, 6, 4, 6), X.Unfold(1), t)
+	X3 := X.Unfold(2)
+	ExpectFloat(12, X3.Get(11, 0), t)
+	ExpectFloat(13, X3.Get(0, 1), t)
+
+	// Unfoldings are views.
+	X.Unfold(1).Set(4, 3, -1)
+	ExpectFloat(-1, X.At(1, 3, 1), t)
+}
+
+func TestTensorFold(t *testing.T) {
+	X := exampleTensor()
+	for mode := 0; mode < 3; mode++ {
+		Y := Fold(Copy(X.Unfold(mode)), mode, X.Dims()...)
+		ExpectMatrix(X.Unfold(0), Y.Unfold(0), t)
+	}
+	expectPanic(t, func() { Fold(X.Unfold(0), 1, X.Dims()...) })
+	expectPanic(t, func() { X.At(0, 0) })
+	expectPanic(t, func() { TensorFromSlice(make([]float64, 5), 2, 3) })
+}
@@ -0,0 +1,229 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Tucker is a tensor given as a small core tensor multiplied along
+// each mode by a factor matrix, with a row (output) for each index of
+// the mode and a column (input) for each index of the core.
+type Tucker struct {
+	Core    *Tensor
+	Factors []Matrix
+}
+
+// Tensor multiplies the core by the factors back out.
+func (d *Tucker) Tensor() *Tensor {
+	t := d.Core
+	for mode, U := range d.Factors {
+		t = ModeProduct(t, U, mode)
+	}
+	return t
+}
+
+// HOSVD computes the truncated higher order SVD of t: the factor for
+// each mode is the leading ranks[mode] left singular vectors of that
+// mode's unfolding, and the core is t multiplied by their duals. Ranks
+// as large as the dimensions reproduce t exactly; smaller ones give a
+// good, though not quite best, compression, which DecomposeTucker
+// improves on.
+func HOSVD(t *Tensor, ranks []int) *Tucker {
+	checkTuckerRanks(t, ranks)
+	d := &Tucker{Factors: make([]Matrix, t.Rank())}
+	for mode := range d.Factors {
+		d.Factors[mode] = leadingLeftSingularVectors(t.Unfold(mode), ranks[mode])
+	}
+	d.Core = tuckerCore(t, d.Factors, -1)
+	return d
+}
+
+// DecomposeTucker fits a Tucker model with the given core size by
+// higher order orthogonal iteration, starting from HOSVD. Each step
+// projects t onto the other modes' factors and takes the leading
+// singular vectors of what's left for one mode, until the fit stops
+// improving or after maxIter sweeps.
+func DecomposeTucker(t *Tensor, ranks []int, maxIter int) *Tucker {
+	d := HOSVD(t, ranks)
+	fit := frobeniusTensor(d.Core)
+	for iter := 0; iter < maxIter; iter++ {
+		for mode := range d.Factors {
+			Y := tuckerCore(t, d.Factors, mode)
+			d.Factors[mode] = leadingLeftSingularVectors(Y.Unfold(mode), ranks[mode])
+		}
+		d.Core = tuckerCore(t, d.Factors, -1)
+		// The factors are orthonormal, so the core holds all of the
+		// fitted energy and a bigger core is a better fit.
+		next := frobeniusTensor(d.Core)
+		if next-fit <= 1e-12*next {
+			break
+		}
+		fit = next
+	}
+	return d
+}
+
+func checkTuckerRanks(t *Tensor, ranks []int) {
+	if len(ranks) != t.Rank() {
+		panic(fmt.Errorf("%d ranks for a rank %d tensor", len(ranks), t.Rank()))
+	}
+	for mode, r := range ranks {
+		if r < 1 || r > t.dims[mode] {
+			panic(fmt.Errorf("rank %d for mode %d of size %d", r, mode, t.dims[mode]))
+		}
+	}
+}
+
+// tuckerCore multiplies t by the duals of the factors on every mode but
+// skip.
+func tuckerCore(t *Tensor, factors []Matrix, skip int) *Tensor {
+	for mode, U := range factors {
+		if mode != skip {
+			t = ModeProduct(t, Dual(U), mode)
+		}
+	}
+	return t
+}
+
+func leadingLeftSingularVectors(A Matrix, r int) Matrix {
+	_, outs := A.Shape()
+	U, _, _ := DecomposeSVD(A)
+	return Copy(Slice(U, 0, r, 0, outs))
+}
+
+func frobeniusTensor(t *Tensor) float64 {
+	sum := 0.0
+	for _, f := range t.data {
+		sum += f * f
+	}
+	return math.Sqrt(sum)
+}
+
+// CP is a tensor given as a weighted sum of outer products of vectors,
+// one from each mode: the columns (inputs) of each factor, which have
+// unit length, with the components' weights in Weights.
+type CP struct {
+	Weights []float64
+	Factors []Matrix
+}
+
+// Tensor adds up the components.
+func (d *CP) Tensor() *Tensor {
+	dims := make([]int, len(d.Factors))
+	for mode, A := range d.Factors {
+		_, dims[mode] = A.Shape()
+	}
+	t := NewTensor(dims...)
+	t.forEach(func(index []int, _ float64) {
+		sum := 0.0
+		for r, w := range d.Weights {
+			p := w
+			for mode, A := range d.Factors {
+				p *= A.Get(r, index[mode])
+			}
+			sum += p
+		}
+		t.SetAt(sum, index...)
+	})
+	return t
+}
+
+// DecomposeCP fits t with the given number of components (a CP or
+// PARAFAC model) by alternating least squares, starting from random
+// factors. Each step solves for one mode's factor with the others
+// fixed, a linear least squares problem whose normal equations only
+// need the products of the other factors' small Gram matrices. It stops
+// when the relative change in the residual is below tol or after
+// maxIter sweeps. CP fits can have local minima, so trying a few rngs
+// can help.
+func DecomposeCP(t *Tensor, rank int, tol float64, maxIter int, rng *rand.Rand) *CP {
+	if rank < 1 {
+		panic(fmt.Errorf("rank %d is less than 1", rank))
+	}
+	n := t.Rank()
+	d := &CP{Weights: make([]float64, rank), Factors: make([]Matrix, n)}
+	for mode := range d.Factors {
+		A := NewArrayMatrix(rank, t.dims[mode])
+		for o := 0; o < t.dims[mode]; o++ {
+			for r := 0; r < rank; r++ {
+				A.Set(r, o, rng.NormFloat64())
+			}
+		}
+		d.Factors[mode] = A
+	}
+	for r := range d.Weights {
+		d.Weights[r] = 1
+	}
+
+	norm := frobeniusTensor(t)
+	prev := math.Inf(1)
+	for iter := 0; iter < maxIter; iter++ {
+		for mode := range d.Factors {
+			// V is the elementwise product of the other factors' Gram
+			// matrices, and M is t's unfolding times their Khatri-Rao
+			// product, summed entry by entry rather than formed.
+			V := NewArrayMatrix(rank, rank)
+			for p := 0; p < rank; p++ {
+				for q := 0; q < rank; q++ {
+					V.Set(q, p, 1)
+				}
+			}
+			for other, A := range d.Factors {
+				if other == mode {
+					continue
+				}
+				G := Compose(A, Dual(A))
+				for p := 0; p < rank; p++ {
+					for q := 0; q < rank; q++ {
+						V.Set(q, p, V.Get(q, p)*G.Get(q, p))
+					}
+				}
+			}
+			M := NewArrayMatrix(rank, t.dims[mode])
+			t.forEach(func(index []int, value float64) {
+				if value == 0 {
+					return
+				}
+				o := index[mode]
+				for r := 0; r < rank; r++ {
+					p := value
+					for other, A := range d.Factors {
+						if other != mode {
+							p *= A.Get(r, index[other])
+						}
+					}
+					M.Set(r, o, M.Get(r, o)+p)
+				}
+			})
+			A := Apply(M, PseudoInverse(V))
+			for r := 0; r < rank; r++ {
+				col := Slice(A, r, r+1, 0, t.dims[mode])
+				w := L2Norm(col)
+				d.Weights[r] = w
+				if w != 0 {
+					for o := 0; o < t.dims[mode]; o++ {
+						col.Set(0, o, col.Get(0, o)/w)
+					}
+				}
+			}
+			d.Factors[mode] = A
+		}
+		residual := cpResidual(t, d) / norm
+		if residual <= tol || prev-residual <= tol*residual {
+			break
+		}
+		prev = residual
+	}
+	return d
+}
+
+func cpResidual(t *Tensor, d *CP) float64 {
+	fit := d.Tensor()
+	sum := 0.0
+	for off, f := range t.data {
+		r := f - fit.data[off]
+		sum += r * r
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,101 @@
+package linear
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func expectTensor(expect, got *Tensor, tol float64, t *testing.T) {
+	t.Helper()
+	dims := expect.Dims()
+	gotDims := got.Dims()
+	ExpectInt(len(dims), len(gotDims), t)
+	for k := range dims {
+		ExpectInt(dims[k], gotDims[k], t)
+	}
+	for off, f := range expect.data {
+		if math.Abs(f-got.data[off]) > tol {
+			t.Fatalf("expected %v but got %v at offset %d", f, got.data[off], off)
+		}
+	}
+}
+
+// randomCP makes a tensor that's exactly the sum of rank components.
+func randomCP(rng *rand.Rand, rank int, dims ...int) *Tensor {
+	d := &CP{Weights: make([]float64, rank)}
+	for r := range d.Weights {
+		d.Weights[r] = 1
+	}
+	for _, n := range dims {
+		A := NewArrayMatrix(rank, n)
+		for o := 0; o < n; o++ {
+			for r := 0; r < rank; r++ {
+				A.Set(r, o, rng.NormFloat64())
+			}
+		}
+		d.Factors = append(d.Factors, A)
+	}
+	return d.Tensor()
+}
+
+func TestModeProduct(t *testing.T) {
+	X := exampleTensor()
+	A := MatrixFromSlice([]float64{1, 3, 5, 2, 4, 6}, 3, 2, 3)
+	Y := ModeProduct(X, A, 0)
+	ExpectInt(2, Y.Dims()[0], t)
+	ExpectMatrix(Apply(A, X.Unfold(0)), Y.Unfold(0), t)
+	// The entry (0, 0, 0) is the first row of A against the first
+	// column fiber 1, 2, 3.
+	ExpectFloat(22, Y.At(0, 0, 0), t)
+}
+
+func TestHOSVD(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	X := randomCP(rng, 2, 4, 5, 3)
+
+	full := HOSVD(X, []int{4, 5, 3})
+	expectTensor(X, full.Tensor(), 1e-9, t)
+
+	// A sum of 2 components has multilinear rank at most (2, 2, 2).
+	d := HOSVD(X, []int{2, 2, 2})
+	ExpectInt(2, d.Core.Dims()[1], t)
+	expectTensor(X, d.Tensor(), 1e-9, t)
+	for _, U := range d.Factors {
+		ExpectMatrix(Identity(2), Compose(U, Dual(U)), t)
+	}
+	expectPanic(t, func() { HOSVD(X, []int{2, 2}) })
+	expectPanic(t, func() { HOSVD(X, []int{5, 2, 2}) })
+}
+
+func TestDecomposeTucker(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	X := randomCP(rng, 4, 5, 5, 5)
+	residual := func(d *Tucker) float64 {
+		fit := d.Tensor()
+		sum := 0.0
+		for off, f := range X.data {
+			sum += (f - fit.data[off]) * (f - fit.data[off])
+		}
+		return math.Sqrt(sum)
+	}
+	ranks := []int{2, 2, 2}
+	hosvd := residual(HOSVD(X, ranks))
+	hooi := residual(DecomposeTucker(X, ranks, 50))
+	if hooi > hosvd+1e-9 {
+		t.Errorf("expected iteration to improve on HOSVD's %f but got %f", hosvd, hooi)
+	}
+}
+
+func TestDecomposeCP(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	X := randomCP(rng, 2, 4, 3, 5)
+	d := DecomposeCP(X, 2, 1e-12, 500, rng)
+	expectTensor(X, d.Tensor(), 1e-6, t)
+	for _, A := range d.Factors {
+		for r := 0; r < 2; r++ {
+			_, n := A.Shape()
+			ExpectFloat(1, L2Norm(Slice(A, r, r+1, 0, n)), t)
+		}
+	}
+}
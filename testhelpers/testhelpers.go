@@ -0,0 +1,158 @@
+// Package testhelpers generates matrices with known properties and
+// checks the invariants that decompositions and solvers in package
+// linear (or anywhere else) should satisfy, so that tests can validate
+// implementations the same way.
+package testhelpers
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ornerylawn/linear"
+)
+
+// RandomMatrix returns a matrix with independent standard normal
+// entries.
+func RandomMatrix(r *rand.Rand, ins, outs int) linear.Matrix {
+	A := linear.NewArrayMatrix(ins, outs)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			A.Set(i, o, r.NormFloat64())
+		}
+	}
+	return A
+}
+
+// RandomOrthogonal returns a random dim by dim orthogonal matrix.
+func RandomOrthogonal(r *rand.Rand, dim int) linear.Matrix {
+	Q, _ := linear.DecomposeQR(RandomMatrix(r, dim, dim))
+	return Q
+}
+
+// RandomWithSingularValues returns a random matrix with outs rows and
+// a column for each of the given singular values.
+func RandomWithSingularValues(r *rand.Rand, outs int, sigma []float64) linear.Matrix {
+	ins := len(sigma)
+	if outs < ins {
+		panic(fmt.Errorf("need at least %d outs but have %d", ins, outs))
+	}
+	U := linear.Slice(RandomOrthogonal(r, outs), 0, ins, 0, outs)
+	V := RandomOrthogonal(r, ins)
+	S := linear.NewArrayMatrix(ins, ins)
+	for i, s := range sigma {
+		S.Set(i, i, s)
+	}
+	return linear.Apply(linear.Apply(U, S), linear.Dual(V))
+}
+
+// RandomWithRank returns a random matrix of the given rank.
+func RandomWithRank(r *rand.Rand, ins, outs, rank int) linear.Matrix {
+	if rank > ins || rank > outs {
+		panic(fmt.Errorf("can't have rank %d with shape (%d, %d)", rank, ins, outs))
+	}
+	return linear.Apply(RandomMatrix(r, rank, outs), RandomMatrix(r, ins, rank))
+}
+
+// RandomWithCondition returns a random dim by dim matrix whose
+// condition number (largest over smallest singular value) is cond,
+// with singular values spaced geometrically in between.
+func RandomWithCondition(r *rand.Rand, dim int, cond float64) linear.Matrix {
+	sigma := make([]float64, dim)
+	for i := range sigma {
+		if dim == 1 {
+			sigma[i] = 1
+			continue
+		}
+		sigma[i] = math.Pow(cond, -float64(i)/float64(dim-1))
+	}
+	return RandomWithSingularValues(r, dim, sigma)
+}
+
+// CheckOrthonormalColumns fails the test unless Dual(Q)*Q is the
+// identity to within tol.
+func CheckOrthonormalColumns(t testing.TB, Q linear.Matrix, tol float64) {
+	t.Helper()
+	ins, _ := Q.Shape()
+	QtQ := linear.Apply(linear.Dual(Q), Q)
+	for o := 0; o < ins; o++ {
+		for i := 0; i < ins; i++ {
+			expect := 0.0
+			if i == o {
+				expect = 1
+			}
+			if got := QtQ.Get(i, o); math.Abs(got-expect) > tol {
+				t.Errorf("columns %d and %d of Q have inner product %v, expected %v", i, o, got, expect)
+			}
+		}
+	}
+}
+
+// CheckUpperTriangular fails the test unless everything below the
+// diagonal of R is zero to within tol.
+func CheckUpperTriangular(t testing.TB, R linear.Matrix, tol float64) {
+	t.Helper()
+	ins, outs := R.Shape()
+	for o := 0; o < outs; o++ {
+		for i := 0; i < o && i < ins; i++ {
+			if got := R.Get(i, o); math.Abs(got) > tol {
+				t.Errorf("R has %v below the diagonal at (%d, %d)", got, i, o)
+			}
+		}
+	}
+}
+
+// CheckQROrthogonality fails the test unless Q has orthonormal
+// columns, R is upper triangular and Q*R reconstructs A, each to within
+// tol relative to the largest entry of A.
+func CheckQROrthogonality(t testing.TB, A, Q, R linear.Matrix, tol float64) {
+	t.Helper()
+	scale := math.Max(linear.MaxAbs(A), 1e-300)
+	CheckOrthonormalColumns(t, Q, tol)
+	CheckUpperTriangular(t, R, tol*scale)
+	CheckClose(t, A, linear.Apply(Q, R), tol*scale)
+}
+
+// CheckSolveResidual fails the test unless A*x is close to b, with the
+// residual measured relative to the sizes of A, x and b, as a backward
+// stable solver guarantees.
+func CheckSolveResidual(t testing.TB, A, x, b linear.Matrix, tol float64) {
+	t.Helper()
+	residual := linear.MaxAbs(subtract(linear.Apply(A, x), b))
+	ins, _ := A.Shape()
+	bound := tol * (linear.MaxAbs(A)*linear.MaxAbs(x)*float64(ins) + linear.MaxAbs(b))
+	if residual > bound {
+		t.Errorf("residual %v is more than %v", residual, bound)
+	}
+}
+
+// CheckClose fails the test unless A and B have the same shape and
+// every entry differs by at most tol.
+func CheckClose(t testing.TB, A, B linear.Matrix, tol float64) {
+	t.Helper()
+	aIns, aOuts := A.Shape()
+	bIns, bOuts := B.Shape()
+	if aIns != bIns || aOuts != bOuts {
+		t.Errorf("shape (%d, %d) vs (%d, %d)", aIns, aOuts, bIns, bOuts)
+		return
+	}
+	for o := 0; o < aOuts; o++ {
+		for i := 0; i < aIns; i++ {
+			if d := math.Abs(A.Get(i, o) - B.Get(i, o)); d > tol {
+				t.Errorf("entry (%d, %d) is %v vs %v", i, o, A.Get(i, o), B.Get(i, o))
+			}
+		}
+	}
+}
+
+func subtract(A, B linear.Matrix) linear.Matrix {
+	ins, outs := A.Shape()
+	C := linear.NewArrayMatrix(ins, outs)
+	for o := 0; o < outs; o++ {
+		for i := 0; i < ins; i++ {
+			C.Set(i, o, A.Get(i, o)-B.Get(i, o))
+		}
+	}
+	return C
+}
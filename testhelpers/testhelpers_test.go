@@ -0,0 +1,48 @@
+package testhelpers
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ornerylawn/linear"
+)
+
+func TestDecomposeQRInvariants(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, shape := range [][2]int{{3, 3}, {2, 5}, {4, 4}} {
+		A := RandomMatrix(r, shape[0], shape[1])
+		Q, R := linear.DecomposeQR(A)
+		CheckQROrthogonality(t, A, Q, R, 1e-12)
+	}
+}
+
+func TestRandomWithCondition(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	A := RandomWithCondition(r, 5, 1e6)
+
+	_, sigma, _ := linear.DecomposeSVD(A)
+
+	if got := sigma.Get(0, 0) / sigma.Get(0, 4); got < 0.999e6 || got > 1.001e6 {
+		t.Errorf("expected condition 1e6 but got %v", got)
+	}
+}
+
+func TestRandomWithRank(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	A := RandomWithRank(r, 4, 6, 2)
+
+	_, sigma, _ := linear.DecomposeSVD(A)
+
+	if sigma.Get(0, 1) < 1e-6 || sigma.Get(0, 2) > 1e-9 {
+		t.Errorf("expected rank 2 but got singular values %v, %v", sigma.Get(0, 1), sigma.Get(0, 2))
+	}
+}
+
+func TestCheckSolveResidual(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	A := RandomWithCondition(r, 4, 100)
+	b := RandomMatrix(r, 1, 4)
+	Q, R := linear.DecomposeQR(A)
+	x := linear.FindInputUpperTriangular(R, linear.Apply(linear.Dual(Q), b))
+	CheckSolveResidual(t, A, x, b, 1e-12)
+}
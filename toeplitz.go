@@ -0,0 +1,137 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// Autocovariance returns the covariance of the series x with itself
+// shifted by 0 through maxLag steps, dividing by the length of the
+// series for every lag so that the result is positive semidefinite.
+func Autocovariance(x Vector, maxLag int) Vector {
+	CheckVector(x)
+	_, n := x.Shape()
+	if maxLag < 0 || maxLag >= n {
+		panic(fmt.Errorf("lag %d out of range for %d observations", maxLag, n))
+	}
+	mean := 0.0
+	for t := 0; t < n; t++ {
+		mean += x.Get(0, t)
+	}
+	mean /= float64(n)
+
+	r := NewVector(maxLag + 1)
+	for lag := 0; lag <= maxLag; lag++ {
+		sum := 0.0
+		for t := lag; t < n; t++ {
+			sum += (x.Get(0, t) - mean) * (x.Get(0, t-lag) - mean)
+		}
+		r.Set(0, lag, sum/float64(n))
+	}
+	return r
+}
+
+// Autocorrelation is Autocovariance scaled so that lag 0 is 1.
+func Autocorrelation(x Vector, maxLag int) Vector {
+	r := Autocovariance(x, maxLag)
+	r0 := r.Get(0, 0)
+	CheckNotCloseToZero(r0)
+	for lag := 0; lag <= maxLag; lag++ {
+		r.Set(0, lag, r.Get(0, lag)/r0)
+	}
+	return r
+}
+
+// LevinsonDurbin solves the Yule-Walker equations for the coefficients
+// phi of an autoregressive model with the given order, given the
+// autocovariance r up to at least that lag. It also returns the
+// variance of the innovations. Each step extends the solution of one
+// order to the next in O(order) by exploiting the Toeplitz structure.
+func LevinsonDurbin(r Vector, order int) (phi Vector, variance float64) {
+	CheckVector(r)
+	_, n := r.Shape()
+	if order < 0 || order >= n {
+		panic(fmt.Errorf("order %d needs autocovariance up to lag %d but got %d", order, order, n-1))
+	}
+	phi = NewVector(order)
+	prev := make([]float64, order)
+	variance = r.Get(0, 0)
+	for k := 1; k <= order; k++ {
+		// The reflection coefficient is the part of lag k that the
+		// order k-1 model fails to predict.
+		num := r.Get(0, k)
+		for j := 1; j < k; j++ {
+			num -= phi.Get(0, j-1) * r.Get(0, k-j)
+		}
+		CheckNotCloseToZero(variance)
+		kappa := num / variance
+
+		for j := 1; j < k; j++ {
+			prev[j-1] = phi.Get(0, j-1)
+		}
+		for j := 1; j < k; j++ {
+			phi.Set(0, j-1, prev[j-1]-kappa*prev[k-j-1])
+		}
+		phi.Set(0, k-1, kappa)
+		variance *= 1 - kappa*kappa
+	}
+	return phi, variance
+}
+
+// FitAR fits an autoregressive model with the given order to the
+// series x by the Yule-Walker method.
+func FitAR(x Vector, order int) (phi Vector, variance float64) {
+	return LevinsonDurbin(Autocovariance(x, order), order)
+}
+
+// SolveToeplitz finds x such that T*x = b where T is the symmetric
+// positive definite Toeplitz matrix with first column r, meaning its
+// entry at (i, o) is r[|i-o|]. This is Levinson's recursion, which
+// takes O(n^2) time instead of the O(n^3) of a general solver.
+func SolveToeplitz(r, b Vector) Vector {
+	CheckVector(r)
+	CheckVector(b)
+	CheckSameShape(r, b)
+	_, n := r.Shape()
+	x := NewVector(n)
+	if n == 0 {
+		return x
+	}
+
+	// f solves T*f = e0 for the leading block, and since T is
+	// symmetric the reverse of f solves it for the last basis vector.
+	r0 := r.Get(0, 0)
+	CheckNotCloseToZero(r0)
+	f := []float64{1 / r0}
+	x.Set(0, 0, b.Get(0, 0)/r0)
+	for k := 1; k < n; k++ {
+		// Extending f with a zero gets everything right except the
+		// last entry, which is ef instead of 0.
+		ef := 0.0
+		for i := 0; i < k; i++ {
+			ef += r.Get(0, k-i) * f[i]
+		}
+		denom := 1 - ef*ef
+		CheckNotCloseToZero(denom)
+		next := make([]float64, k+1)
+		for i := 0; i <= k; i++ {
+			if i < k {
+				next[i] += f[i] / denom
+			}
+			if i > 0 {
+				next[i] -= ef * f[k-i] / denom
+			}
+		}
+		f = next
+
+		// Similarly extending x with a zero gets the last entry wrong,
+		// which the backward vector can fix.
+		ex := 0.0
+		for i := 0; i < k; i++ {
+			ex += r.Get(0, k-i) * x.Get(0, i)
+		}
+		for i := 0; i <= k; i++ {
+			x.Set(0, i, x.Get(0, i)+(b.Get(0, k)-ex)*f[k-i])
+		}
+	}
+	return x
+}
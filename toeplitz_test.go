@@ -0,0 +1,96 @@
+package linear
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAutocorrelation(t *testing.T) {
+	x := NewVector(4)
+	x.Set(0, 0, 1)
+	x.Set(0, 1, -1)
+	x.Set(0, 2, 1)
+	x.Set(0, 3, -1)
+
+	r := Autocovariance(x, 2)
+
+	ExpectFloat(1, r.Get(0, 0), t)
+	ExpectFloat(-0.75, r.Get(0, 1), t)
+	ExpectFloat(0.5, r.Get(0, 2), t)
+
+	rho := Autocorrelation(x, 1)
+
+	ExpectFloat(1, rho.Get(0, 0), t)
+	ExpectFloat(-0.75, rho.Get(0, 1), t)
+}
+
+func TestLevinsonDurbin(t *testing.T) {
+	// The autocovariance of an AR(1) process with coefficient 0.5 and
+	// unit innovations.
+	r := NewVector(4)
+	for lag := 0; lag < 4; lag++ {
+		rl := 4.0 / 3.0
+		for l := 0; l < lag; l++ {
+			rl *= 0.5
+		}
+		r.Set(0, lag, rl)
+	}
+
+	phi, variance := LevinsonDurbin(r, 3)
+
+	ExpectFloat(0.5, phi.Get(0, 0), t)
+	ExpectFloat(0, phi.Get(0, 1), t)
+	ExpectFloat(0, phi.Get(0, 2), t)
+	ExpectFloat(1, variance, t)
+}
+
+func TestFitAR(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	x := NewVector(20000)
+	for s := 2; s < 20000; s++ {
+		x.Set(0, s, 0.6*x.Get(0, s-1)-0.3*x.Get(0, s-2)+rng.NormFloat64())
+	}
+
+	phi, variance := FitAR(x, 2)
+
+	if phi.Get(0, 0) < 0.55 || phi.Get(0, 0) > 0.65 {
+		t.Errorf("expected about 0.6 but got %f", phi.Get(0, 0))
+	}
+	if phi.Get(0, 1) < -0.35 || phi.Get(0, 1) > -0.25 {
+		t.Errorf("expected about -0.3 but got %f", phi.Get(0, 1))
+	}
+	if variance < 0.95 || variance > 1.05 {
+		t.Errorf("expected about 1 but got %f", variance)
+	}
+}
+
+func TestSolveToeplitz(t *testing.T) {
+	r := NewVector(4)
+	r.Set(0, 0, 4)
+	r.Set(0, 1, 2)
+	r.Set(0, 2, 1)
+	r.Set(0, 3, 0.5)
+
+	b := NewVector(4)
+	b.Set(0, 0, 1)
+	b.Set(0, 1, 2)
+	b.Set(0, 2, 3)
+	b.Set(0, 3, 4)
+
+	x := SolveToeplitz(r, b)
+
+	T := NewArrayMatrix(4, 4)
+	for o := 0; o < 4; o++ {
+		for i := 0; i < 4; i++ {
+			lag := i - o
+			if lag < 0 {
+				lag = -lag
+			}
+			T.Set(i, o, r.Get(0, lag))
+		}
+	}
+	Tx := Apply(T, x)
+	for d := 0; d < 4; d++ {
+		ExpectFloat(b.Get(0, d), Tx.Get(0, d), t)
+	}
+}
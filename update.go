@@ -0,0 +1,75 @@
+package linear
+
+// Solver finds the input vector that maps to a given output vector
+// under some fixed map, typically by reusing a factorization of it.
+type Solver interface {
+	SolveVec(b Vector) Vector
+}
+
+// SolverFunc adapts a function to the Solver interface.
+type SolverFunc func(b Vector) Vector
+
+// SolveVec calls f(b).
+func (f SolverFunc) SolveVec(b Vector) Vector {
+	return f(b)
+}
+
+// SolveWithRank1Update finds x such that (A + u*Dual(v))*x = b given a
+// Solver for A, using the Sherman-Morrison formula so that A doesn't
+// need to be factored again.
+func SolveWithRank1Update(factored Solver, u, v, b Vector) Vector {
+	CheckVector(u)
+	CheckVector(v)
+	CheckVector(b)
+	CheckSameShape(u, v)
+	CheckSameShape(u, b)
+
+	// (A + u*v')^-1 = A^-1 - A^-1*u*v'*A^-1 / (1 + v'*A^-1*u)
+	z := factored.SolveVec(b)
+	y := factored.SolveVec(u)
+	denom := 1 + DotProduct(y, Dual(v))
+	CheckNotCloseToZero(denom)
+	scale := DotProduct(z, Dual(v)) / denom
+	_, dim := z.Shape()
+	x := NewVector(dim)
+	for d := 0; d < dim; d++ {
+		x.Set(0, d, z.Get(0, d)-scale*y.Get(0, d))
+	}
+	return x
+}
+
+// SolveWithLowRankUpdate finds x such that (A + U*C*Dual(V))*x = b
+// given a Solver for A, using the Woodbury identity so that only a
+// small system the size of C has to be solved. A nil C means the
+// identity.
+func SolveWithLowRankUpdate(factored Solver, U, C, V Matrix, b Vector) Vector {
+	CheckVector(b)
+	CheckSameShape(U, V)
+	CheckSameOuts(U, b)
+	k, n := U.Shape()
+	if C == nil {
+		C = Identity(k)
+	}
+	CheckComposable(C, U)
+	CheckComposable(Dual(V), C)
+
+	// (A + U*C*V')^-1 = A^-1 - A^-1*U*(I + C*V'*A^-1*U)^-1*C*V'*A^-1
+	z := factored.SolveVec(b)
+	Y := NewArrayMatrix(k, n)
+	for i := 0; i < k; i++ {
+		CopyInto(factored.SolveVec(Slice(U, i, i+1, 0, n)), Slice(Y, i, i+1, 0, n))
+	}
+	CV := Apply(C, Dual(V))
+	S := Apply(CV, Y)
+	for d := 0; d < k; d++ {
+		S.Set(d, d, S.Get(d, d)+1)
+	}
+	// S is singular exactly when the updated matrix is, so LU panics.
+	w := FactorLU(S).SolveVec(Apply(CV, z))
+	Yw := Apply(Y, w)
+	x := NewVector(n)
+	for d := 0; d < n; d++ {
+		x.Set(0, d, z.Get(0, d)-Yw.Get(0, d))
+	}
+	return x
+}
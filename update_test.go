@@ -0,0 +1,80 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestSolveWithRank1Update(t *testing.T) {
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 2)
+	A.Set(1, 1, 4)
+	L := DecomposeCholesky(A)
+	factored := SolverFunc(func(b Vector) Vector { return SolveCholesky(L, b) })
+
+	u := NewVector(2)
+	u.Set(0, 0, 1)
+	u.Set(0, 1, 1)
+
+	v := NewVector(2)
+	v.Set(0, 0, 1)
+	v.Set(0, 1, -1)
+
+	b := NewVector(2)
+	b.Set(0, 0, 3)
+	b.Set(0, 1, 5)
+
+	x := SolveWithRank1Update(factored, u, v, b)
+
+	// A + u*v' = [3 -1; 1 3]
+	B := Copy(A)
+	B.Set(0, 0, 3)
+	B.Set(1, 0, -1)
+	B.Set(0, 1, 1)
+	B.Set(1, 1, 3)
+	Bx := Apply(B, x)
+	ExpectFloat(3, Bx.Get(0, 0), t)
+	ExpectFloat(5, Bx.Get(0, 1), t)
+}
+
+func TestSolveWithLowRankUpdate(t *testing.T) {
+	A := Identity(3)
+	factored := SolverFunc(func(b Vector) Vector { return Copy(b) })
+
+	U := NewArrayMatrix(2, 3)
+	U.Set(0, 0, 1)
+	U.Set(1, 1, 1)
+	U.Set(1, 2, 1)
+
+	C := NewArrayMatrix(2, 2)
+	C.Set(0, 0, 2)
+	C.Set(1, 1, 3)
+
+	b := NewVector(3)
+	b.Set(0, 0, 1)
+	b.Set(0, 1, 2)
+	b.Set(0, 2, 3)
+
+	x := SolveWithLowRankUpdate(factored, U, C, U, b)
+
+	B := Copy(A)
+	UCUt := Apply(Apply(U, C), Dual(U))
+	for o := 0; o < 3; o++ {
+		for i := 0; i < 3; i++ {
+			B.Set(i, o, B.Get(i, o)+UCUt.Get(i, o))
+		}
+	}
+	Bx := Apply(B, x)
+	ExpectFloat(1, Bx.Get(0, 0), t)
+	ExpectFloat(2, Bx.Get(0, 1), t)
+	ExpectFloat(3, Bx.Get(0, 2), t)
+
+	x = SolveWithLowRankUpdate(factored, U, nil, U, b)
+
+	ExpectFloat(1.0/2.0, x.Get(0, 0), t)
+
+	// Taking the first entry off the diagonal of I leaves it singular.
+	e := NewArrayMatrix(1, 3)
+	e.Set(0, 0, 1)
+	minus := MatrixFromSlice([]float64{-1}, 1, 1, 1)
+	expectPanic(t, func() { SolveWithLowRankUpdate(factored, e, minus, e, b) })
+}
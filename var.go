@@ -0,0 +1,87 @@
+package linear
+
+import (
+	"fmt"
+)
+
+// VAR is a vector autoregression, which predicts the next observation
+// of a multivariate time series as an affine function of the previous
+// ones.
+type VAR struct {
+	// Intercept is the constant part of each prediction.
+	Intercept Vector
+	// Lags[l] maps the observation l+1 steps back to its contribution
+	// to the prediction.
+	Lags []Matrix
+}
+
+// FitVAR fits a vector autoregression with the given number of lags
+// to a series with one output (row) per time step and one input
+// (column) per variable, by least squares on the lagged design matrix.
+func FitVAR(series Matrix, order int) *VAR {
+	k, steps := series.Shape()
+	n := steps - order
+	params := 1 + k*order
+	if order < 1 || n < params {
+		panic(fmt.Errorf("%d steps is too few to fit %d lags of %d variables", steps, order, k))
+	}
+
+	// Each output of X is [1, y(t-1), ..., y(t-order)] and the
+	// corresponding output of Y is y(t).
+	X := NewArrayMatrix(params, n)
+	Y := NewArrayMatrix(k, n)
+	for o := 0; o < n; o++ {
+		t := o + order
+		X.Set(0, o, 1)
+		for l := 0; l < order; l++ {
+			for j := 0; j < k; j++ {
+				X.Set(1+l*k+j, o, series.Get(j, t-l-1))
+			}
+		}
+		for j := 0; j < k; j++ {
+			Y.Set(j, o, series.Get(j, t))
+		}
+	}
+
+	Theta := OrdinaryLeastSquares(X, Y)
+
+	v := &VAR{Intercept: NewVector(k)}
+	for i := 0; i < k; i++ {
+		v.Intercept.Set(0, i, Theta.Get(i, 0))
+	}
+	for l := 0; l < order; l++ {
+		A := NewArrayMatrix(k, k)
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				A.Set(j, i, Theta.Get(i, 1+l*k+j))
+			}
+		}
+		v.Lags = append(v.Lags, A)
+	}
+	return v
+}
+
+// Forecast continues the series for the given number of steps, feeding
+// each prediction back in as the most recent observation. The history
+// must have at least as many steps as there are lags.
+func (v *VAR) Forecast(history Matrix, steps int) Matrix {
+	k, have := history.Shape()
+	order := len(v.Lags)
+	if have < order {
+		panic(fmt.Errorf("%d steps of history is too few for %d lags", have, order))
+	}
+	all := NewArrayMatrix(k, have+steps)
+	CopyInto(history, Slice(all, 0, k, 0, have))
+	for t := have; t < have+steps; t++ {
+		for i := 0; i < k; i++ {
+			y := v.Intercept.Get(0, i)
+			for l, A := range v.Lags {
+				for j := 0; j < k; j++ {
+					y += A.Get(j, i) * all.Get(j, t-l-1)
+				}
+			}
+			all.Set(i, t, y)
+		}
+	}
+	return Slice(all, 0, k, have, have+steps)
+}
@@ -0,0 +1,47 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestFitVAR(t *testing.T) {
+	// y(t) = c + A*y(t-1) exactly, starting from (1, 0).
+	A := NewArrayMatrix(2, 2)
+	A.Set(0, 0, 0.5)
+	A.Set(1, 0, 0.2)
+	A.Set(0, 1, -0.3)
+	A.Set(1, 1, 0.8)
+
+	c := NewVector(2)
+	c.Set(0, 0, 1)
+	c.Set(0, 1, -1)
+
+	series := NewArrayMatrix(2, 8)
+	series.Set(0, 0, 1)
+	series.Set(1, 0, 0)
+	for s := 1; s < 8; s++ {
+		prev := Dual(Slice(series, 0, 2, s-1, s))
+		next := Apply(A, prev)
+		series.Set(0, s, next.Get(0, 0)+c.Get(0, 0))
+		series.Set(1, s, next.Get(0, 1)+c.Get(0, 1))
+	}
+
+	v := FitVAR(Slice(series, 0, 2, 0, 7), 1)
+
+	ExpectInt(1, len(v.Lags), t)
+	ExpectFloat(1, v.Intercept.Get(0, 0), t)
+	ExpectFloat(-1, v.Intercept.Get(0, 1), t)
+	for o := 0; o < 2; o++ {
+		for i := 0; i < 2; i++ {
+			ExpectFloat(A.Get(i, o), v.Lags[0].Get(i, o), t)
+		}
+	}
+
+	next := v.Forecast(Slice(series, 0, 2, 0, 7), 1)
+
+	ins, outs := next.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(1, outs, t)
+	ExpectFloat(series.Get(0, 7), next.Get(0, 0), t)
+	ExpectFloat(series.Get(1, 7), next.Get(1, 0), t)
+}
@@ -0,0 +1,50 @@
+package linear
+
+// Vector is a Matrix with a single input, a column, which is an
+// element of a vector space. The type is only a name for the reader;
+// the shape is checked at runtime with CheckVector.
+type Vector interface {
+	Matrix
+}
+
+// Covector is a Matrix with a single output, a row, which is a linear
+// map from a vector space to scalars. The type is only a name for the
+// reader; the shape is checked at runtime with CheckCovector.
+type Covector interface {
+	Matrix
+}
+
+// NewVector makes a new zero vector with the given dimension.
+func NewVector(dim int) Vector {
+	return NewArrayMatrix(1, dim)
+}
+
+// NewCovector makes a new zero covector with the given dimension.
+func NewCovector(dim int) Covector {
+	return NewArrayMatrix(dim, 1)
+}
+
+// Pair applies the covector to the vector, getting a scalar.
+func Pair(c Covector, v Vector) float64 {
+	return DotProduct(v, c)
+}
+
+// DualOf returns the covector that pairs with w to give the inner
+// product of v and w under the given metric, which is a symmetric
+// positive definite map. A nil metric means the identity, in which
+// case the covector has the same entries as v.
+func DualOf(v Vector, metric Matrix) Covector {
+	CheckVector(v)
+	_, dim := v.Shape()
+	c := NewCovector(dim)
+	if metric == nil {
+		for d := 0; d < dim; d++ {
+			c.Set(d, 0, v.Get(0, d))
+		}
+		return c
+	}
+	CheckComposable(metric, Dual(v))
+	CheckSameIns(metric, c)
+	ComposeInto(metric, Dual(v), c)
+	return c
+}
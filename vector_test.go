@@ -0,0 +1,50 @@
+package linear
+
+import (
+	"testing"
+)
+
+func TestPair(t *testing.T) {
+	c := NewCovector(2)
+	c.Set(0, 0, 2)
+	c.Set(1, 0, -1)
+
+	v := NewVector(2)
+	v.Set(0, 0, 3)
+	v.Set(0, 1, 4)
+
+	ExpectFloat(2, Pair(c, v), t)
+}
+
+func TestDualOf(t *testing.T) {
+	v := NewVector(2)
+	v.Set(0, 0, 3)
+	v.Set(0, 1, 4)
+
+	c := DualOf(v, nil)
+
+	ins, outs := c.Shape()
+	ExpectInt(2, ins, t)
+	ExpectInt(1, outs, t)
+	ExpectFloat(3, c.Get(0, 0), t)
+	ExpectFloat(4, c.Get(1, 0), t)
+	ExpectFloat(25, Pair(c, v), t)
+
+	M := NewArrayMatrix(2, 2)
+	M.Set(0, 0, 2)
+	M.Set(1, 0, 1)
+	M.Set(0, 1, 1)
+	M.Set(1, 1, 3)
+
+	c = DualOf(v, M)
+
+	ExpectFloat(10, c.Get(0, 0), t)
+	ExpectFloat(15, c.Get(1, 0), t)
+
+	w := NewVector(2)
+	w.Set(0, 0, 1)
+	w.Set(0, 1, 0)
+
+	// The inner product under M is Dual(v)*M*w.
+	ExpectFloat(10, Pair(c, w), t)
+}
@@ -0,0 +1,208 @@
+package linear
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// This is just enough of the protocol buffer wire format to read and
+// write the messages the package exchanges, without depending on a
+// protobuf library. A message is a sequence of fields, each a varint
+// tag (field number << 3 | wire type) followed by its value.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated protocol buffer")
+
+// wireDecoder reads the fields of one message.
+type wireDecoder struct {
+	data []byte
+}
+
+func (d *wireDecoder) done() bool { return len(d.data) == 0 }
+
+func (d *wireDecoder) varint() (uint64, error) {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+// field reads the next tag.
+func (d *wireDecoder) field() (num int, typ int, err error) {
+	tag, err := d.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(tag >> 3), int(tag & 7), nil
+}
+
+func (d *wireDecoder) bytes() ([]byte, error) {
+	n, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.data)) {
+		return nil, errTruncated
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+func (d *wireDecoder) fixed64() (uint64, error) {
+	if len(d.data) < 8 {
+		return 0, errTruncated
+	}
+	v := binary.LittleEndian.Uint64(d.data)
+	d.data = d.data[8:]
+	return v, nil
+}
+
+func (d *wireDecoder) fixed32() (uint32, error) {
+	if len(d.data) < 4 {
+		return 0, errTruncated
+	}
+	v := binary.LittleEndian.Uint32(d.data)
+	d.data = d.data[4:]
+	return v, nil
+}
+
+// skip reads past a value of the given wire type.
+func (d *wireDecoder) skip(typ int) error {
+	var err error
+	switch typ {
+	case wireVarint:
+		_, err = d.varint()
+	case wireFixed64:
+		_, err = d.fixed64()
+	case wireBytes:
+		_, err = d.bytes()
+	case wireFixed32:
+		_, err = d.fixed32()
+	default:
+		err = fmt.Errorf("unsupported wire type %d", typ)
+	}
+	return err
+}
+
+// int64s reads a repeated int64 field, which may be packed or not.
+func (d *wireDecoder) int64s(typ int, into []int64) ([]int64, error) {
+	if typ == wireVarint {
+		v, err := d.varint()
+		return append(into, int64(v)), err
+	}
+	if typ != wireBytes {
+		return into, fmt.Errorf("unexpected wire type %d for integers", typ)
+	}
+	b, err := d.bytes()
+	if err != nil {
+		return into, err
+	}
+	packed := &wireDecoder{b}
+	for !packed.done() {
+		v, err := packed.varint()
+		if err != nil {
+			return into, err
+		}
+		into = append(into, int64(v))
+	}
+	return into, nil
+}
+
+// doubles reads a repeated double field, which may be packed or not.
+func (d *wireDecoder) doubles(typ int, into []float64) ([]float64, error) {
+	if typ == wireFixed64 {
+		v, err := d.fixed64()
+		return append(into, math.Float64frombits(v)), err
+	}
+	if typ != wireBytes {
+		return into, fmt.Errorf("unexpected wire type %d for doubles", typ)
+	}
+	b, err := d.bytes()
+	if err != nil {
+		return into, err
+	}
+	if len(b)%8 != 0 {
+		return into, errTruncated
+	}
+	for k := 0; k < len(b); k += 8 {
+		into = append(into, math.Float64frombits(binary.LittleEndian.Uint64(b[k:])))
+	}
+	return into, nil
+}
+
+// floats reads a repeated float field, which may be packed or not.
+func (d *wireDecoder) floats(typ int, into []float64) ([]float64, error) {
+	if typ == wireFixed32 {
+		v, err := d.fixed32()
+		return append(into, float64(math.Float32frombits(v))), err
+	}
+	if typ != wireBytes {
+		return into, fmt.Errorf("unexpected wire type %d for floats", typ)
+	}
+	b, err := d.bytes()
+	if err != nil {
+		return into, err
+	}
+	if len(b)%4 != 0 {
+		return into, errTruncated
+	}
+	for k := 0; k < len(b); k += 4 {
+		into = append(into, float64(math.Float32frombits(binary.LittleEndian.Uint32(b[k:]))))
+	}
+	return into, nil
+}
+
+// wireEncoder appends the fields of one message.
+type wireEncoder struct {
+	data []byte
+}
+
+func (e *wireEncoder) tag(num, typ int) {
+	e.data = binary.AppendUvarint(e.data, uint64(num)<<3|uint64(typ))
+}
+
+func (e *wireEncoder) varint(num int, v uint64) {
+	e.tag(num, wireVarint)
+	e.data = binary.AppendUvarint(e.data, v)
+}
+
+func (e *wireEncoder) bytes(num int, b []byte) {
+	e.tag(num, wireBytes)
+	e.data = binary.AppendUvarint(e.data, uint64(len(b)))
+	e.data = append(e.data, b...)
+}
+
+func (e *wireEncoder) packedInt64s(num int, vs []int64) {
+	var packed []byte
+	for _, v := range vs {
+		packed = binary.AppendUvarint(packed, uint64(v))
+	}
+	e.bytes(num, packed)
+}
+
+func (e *wireEncoder) packedDoubles(num int, vs []float64) {
+	packed := make([]byte, 8*len(vs))
+	for k, v := range vs {
+		binary.LittleEndian.PutUint64(packed[8*k:], math.Float64bits(v))
+	}
+	e.bytes(num, packed)
+}
+
+func (e *wireEncoder) packedFloats(num int, vs []float64) {
+	packed := make([]byte, 4*len(vs))
+	for k, v := range vs {
+		binary.LittleEndian.PutUint32(packed[4*k:], math.Float32bits(float32(v)))
+	}
+	e.bytes(num, packed)
+}